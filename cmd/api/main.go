@@ -12,8 +12,11 @@ import (
 	"github.com/paulochiaradia/smart-gondola-backend/internal/di"
 	// Alias "router" para evitar conflito com pacote "net/http"
 	router "github.com/paulochiaradia/smart-gondola-backend/internal/interface/http"
+	customMiddleware "github.com/paulochiaradia/smart-gondola-backend/internal/interface/http/middleware"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/auth"
 	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/config"
 	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/database"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/hasher"
 	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/logger"
 )
 
@@ -35,6 +38,18 @@ func main() {
 	}
 	// ---------------------------
 
+	// Chaves de assinatura dos JWTs: sem elas nenhum login funciona
+	if err := auth.InitKeys(cfg); err != nil {
+		log.Error("Falha crítica ao carregar chaves JWT", "error", err)
+		os.Exit(1)
+	}
+
+	// Algoritmo e custo do hash de senha: configuração inválida não pode virar erro no primeiro login
+	if err := hasher.Init(cfg); err != nil {
+		log.Error("Falha crítica ao configurar hash de senha", "error", err)
+		os.Exit(1)
+	}
+
 	// 2. Dependências (Database Connection Pool & Container DI)
 	container, cleanup, err := di.NewContainer(cfg)
 	if err != nil {
@@ -48,6 +63,12 @@ func main() {
 	// A main delega a criação do http.Handler para a camada de interface
 	httpHandler := router.NewRouter(container)
 
+	// Nenhuma rota pode referenciar papel/permissão fora do catálogo
+	if err := customMiddleware.CheckAccessRules(); err != nil {
+		log.Error("Falha crítica nas regras de acesso", "error", err)
+		os.Exit(1)
+	}
+
 	// 4. Server Start
 	serverPort := fmt.Sprintf(":%s", cfg.ServerPort)
 	server := &http.Server{
@@ -62,6 +83,33 @@ func main() {
 	stop := make(chan os.Signal, 1)
 	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
 
+	// Job: organizações com período de teste vencido voltam para o plano free e as trocas de plano
+	// agendadas são aplicadas quando vencem
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+	go func() {
+		ticker := time.NewTicker(cfg.TrialSweepInterval)
+		defer ticker.Stop()
+		for {
+			if n, err := container.OrgUseCase.ExpireTrials(jobsCtx, time.Now()); err != nil {
+				log.Error("Erro ao encerrar períodos de teste vencidos", "error", err)
+			} else if n > 0 {
+				log.Info("Períodos de teste encerrados", "organizations", n)
+			}
+			if n, err := container.PlanUseCase.ApplyScheduled(jobsCtx, time.Now()); err != nil {
+				log.Error("Erro ao aplicar trocas de plano agendadas", "error", err)
+			} else if n > 0 {
+				log.Info("Trocas de plano agendadas aplicadas", "organizations", n)
+			}
+
+			select {
+			case <-jobsCtx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
 	go func() {
 		log.Info("Servidor HTTP rodando", "port", serverPort)
 		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
@@ -73,6 +121,7 @@ func main() {
 	// Bloqueia a execução aqui esperando CTRL+C ou Stop do Docker
 	<-stop
 	log.Info("Sinal de parada recebido. Desligando...")
+	stopJobs()
 
 	// Dá 5 segundos para as requisições atuais terminarem antes de matar
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -81,6 +130,8 @@ func main() {
 	if err := server.Shutdown(ctx); err != nil {
 		log.Error("Erro ao desligar servidor forçadamente", "error", err)
 	}
+	// Links de redefinição de senha ainda sendo enviados
+	container.PassUseCase.Wait()
 
 	log.Info("Servidor finalizado com sucesso.")
 }
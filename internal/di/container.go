@@ -12,15 +12,34 @@ import (
 	userRepo "github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/infrastructure/repository"
 	userHandler "github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/interface/http/handler"
 
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/breached"
 	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/config"
 	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/database"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/mailer"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/oidc"
 )
 
 type Container struct {
-	UserHandler  *userHandler.UserHandler
-	OrgHandler   *orgHandler.OrganizationHandler
-	StoreHandler *orgHandler.StoreHandler
-	DB           *sql.DB //ex: health check simples)
+	UserUseCase    *userUseCase.UserUseCase        // Exposto para o AuthMiddleware validar sessões
+	OrgUseCase     *orgUseCase.OrganizationUseCase // Exposto para o job que encerra os testes vencidos
+	PlanUseCase    *orgUseCase.PlanChangeUseCase   // Exposto para o job que aplica as trocas agendadas
+	UserHandler    *userHandler.UserHandler
+	SignupHandler  *userHandler.SignupHandler
+	PassHandler    *userHandler.PasswordHandler
+	PassUseCase    *userUseCase.PasswordResetUseCase // Exposto para esperar os envios pendentes no desligamento
+	InvHandler     *userHandler.InviteHandler
+	DevHandler     *userHandler.DeviceHandler
+	ImpUseCase     *userUseCase.ImpersonationUseCase // Exposto para o middleware de auditoria
+	ImpHandler     *userHandler.ImpersonationHandler
+	KeyUseCase     *userUseCase.APIKeyUseCase // Exposto para o AuthMiddleware aceitar chaves de API
+	KeyHandler     *userHandler.APIKeyHandler
+	SSOHandler     *userHandler.SSOHandler
+	ThrHandler     *userHandler.LoginThrottleHandler
+	OrgHandler     *orgHandler.OrganizationHandler
+	PlanHandler    *orgHandler.PlanHandler
+	StoreHandler   *orgHandler.StoreHandler
+	SSOConfHandler *orgHandler.SSOConfigHandler
+	DB             *sql.DB //ex: health check simples)
 }
 
 // NewContainer inicializa tudo e retorna:
@@ -41,25 +60,96 @@ func NewContainer(cfg *config.Config) (*Container, func(), error) {
 		}
 	}
 
-	// --- Módulo Users ---
-	uRepo := userRepo.NewUserRepository(db)
-	uUseCase := userUseCase.NewUserUseCase(uRepo)
-	uHandler := userHandler.NewUserHandler(uUseCase)
+	mail := mailer.New(cfg)
+	txManager := database.NewTxManager(db)
+
+	breachedList, err := breached.New(cfg)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
 
 	// --- Módulo Organizations ---
 	oRepo := orgRepo.NewOrganizationRepository(db)
-	oUseCase := orgUseCase.NewOrganizationUseCase(oRepo)
-	oHandler := orgHandler.NewOrganizationHandler(oUseCase)
+	planRepo := orgRepo.NewPlanHistoryRepository(db)
+	oUseCase := orgUseCase.NewOrganizationUseCase(oRepo, planRepo, txManager)
+	// Cotas do plano: consultadas por quem cria usuários, aparelhos e lojas
+	quotaUseCase := orgUseCase.NewQuotaUseCase(oUseCase, orgRepo.NewUsageRepository(db))
+	oHandler := orgHandler.NewOrganizationHandler(oUseCase, quotaUseCase)
+	// Troca de plano: recusa ou agenda rebaixamentos que não cabem nos novos limites
+	planUseCase := orgUseCase.NewPlanChangeUseCase(quotaUseCase, orgRepo.NewPlanChangeRepository(db))
+	planHandler := orgHandler.NewPlanHandler(planUseCase)
 
 	// --- Módulo Stores  ---
 	sRepo := orgRepo.NewStoreRepository(db)
-	sUseCase := orgUseCase.NewStoreUseCase(sRepo)
+	sUseCase := orgUseCase.NewStoreUseCase(sRepo, quotaUseCase)
 	sHandler := orgHandler.NewStoreHandler(sUseCase)
 
+	// --- Login corporativo (configuração por organização) ---
+	ssoConfRepo := orgRepo.NewSSOConfigRepository(db)
+	ssoConfUseCase := orgUseCase.NewSSOConfigUseCase(oRepo, ssoConfRepo)
+	ssoConfHandler := orgHandler.NewSSOConfigHandler(ssoConfUseCase)
+
+	// --- Módulo Users ---
+	uRepo := userRepo.NewUserRepository(db)
+	rtRepo := userRepo.NewRefreshTokenRepository(db)
+	revRepo := userRepo.NewRevokedTokenRepository(db)
+	devRepo := userRepo.NewDeviceRepository(db)
+	laRepo := userRepo.NewLoginAttemptRepository(db)
+	phRepo := userRepo.NewPasswordHistoryRepository(db)
+	passwords := userUseCase.NewPasswordPolicyEnforcer(oRepo, phRepo, breachedList)
+	thrRepo := userRepo.NewLoginThrottleRepository(db)
+	thrUseCase := userUseCase.NewLoginThrottleUseCase(thrRepo, laRepo)
+	thrHandler := userHandler.NewLoginThrottleHandler(thrUseCase)
+	uUseCase := userUseCase.NewUserUseCase(uRepo, rtRepo, revRepo, oRepo, sRepo, devRepo, laRepo, thrUseCase, passwords, quotaUseCase, mail)
+	uHandler := userHandler.NewUserHandler(uUseCase)
+
+	suUseCase := userUseCase.NewSignupUseCase(oUseCase, sUseCase, uUseCase, txManager)
+	suHandler := userHandler.NewSignupHandler(suUseCase)
+
+	prRepo := userRepo.NewPasswordResetTokenRepository(db)
+	pUseCase := userUseCase.NewPasswordResetUseCase(uRepo, prRepo, rtRepo, passwords, thrUseCase, txManager, mail)
+	pHandler := userHandler.NewPasswordHandler(pUseCase)
+
+	invRepo := userRepo.NewInviteRepository(db)
+	invUseCase := userUseCase.NewInviteUseCase(uRepo, invRepo, sRepo, passwords, quotaUseCase, txManager, mail)
+	invHandler := userHandler.NewInviteHandler(invUseCase)
+
+	devUseCase := userUseCase.NewDeviceUseCase(uRepo, devRepo, rtRepo, sRepo)
+	devHandler := userHandler.NewDeviceHandler(devUseCase)
+
+	impRepo := userRepo.NewImpersonationRepository(db)
+	impUseCase := userUseCase.NewImpersonationUseCase(uRepo, impRepo, revRepo)
+	impHandler := userHandler.NewImpersonationHandler(impUseCase)
+
+	keyRepo := userRepo.NewAPIKeyRepository(db)
+	keyUseCase := userUseCase.NewAPIKeyUseCase(uRepo, keyRepo)
+	keyHandler := userHandler.NewAPIKeyHandler(keyUseCase)
+
+	ssoRepo := userRepo.NewSSORepository(db)
+	ssoUseCase := userUseCase.NewSSOUseCase(uUseCase, oRepo, ssoConfRepo, ssoRepo, txManager, oidc.NewClient(nil))
+	ssoHandler := userHandler.NewSSOHandler(ssoUseCase)
+
 	return &Container{
-		UserHandler:  uHandler,
-		OrgHandler:   oHandler,
-		StoreHandler: sHandler,
-		DB:           db,
+		UserUseCase:    uUseCase,
+		OrgUseCase:     oUseCase,
+		PlanUseCase:    planUseCase,
+		UserHandler:    uHandler,
+		SignupHandler:  suHandler,
+		PassHandler:    pHandler,
+		PassUseCase:    pUseCase,
+		InvHandler:     invHandler,
+		DevHandler:     devHandler,
+		ImpUseCase:     impUseCase,
+		ImpHandler:     impHandler,
+		KeyUseCase:     keyUseCase,
+		KeyHandler:     keyHandler,
+		SSOHandler:     ssoHandler,
+		ThrHandler:     thrHandler,
+		OrgHandler:     oHandler,
+		PlanHandler:    planHandler,
+		StoreHandler:   sHandler,
+		SSOConfHandler: ssoConfHandler,
+		DB:             db,
 	}, cleanup, nil
 }
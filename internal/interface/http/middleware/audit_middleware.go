@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	chiMiddleware "github.com/go-chi/chi/v5/middleware"
+	"github.com/google/uuid"
+)
+
+// ImpersonationAuditor grava cada requisição feita com um token de impersonação
+type ImpersonationAuditor interface {
+	RecordImpersonatedRequest(ctx context.Context, sessionID uuid.UUID, method, path string, status int)
+}
+
+// AuditImpersonation registra método, caminho e status de toda requisição impersonada.
+// Deve ser usado logo após o AuthMiddleware (e antes da sessão de banco do tenant).
+func AuditImpersonation(auditor ImpersonationAuditor) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			imp := GetImpersonation(r.Context())
+			if imp == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ww := chiMiddleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			next.ServeHTTP(ww, r)
+
+			status := ww.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+			// A auditoria não pode se perder se o cliente desconectar
+			auditor.RecordImpersonatedRequest(context.WithoutCancel(r.Context()), imp.SessionID, r.Method, r.URL.Path, status)
+		})
+	}
+}
@@ -2,80 +2,154 @@ package middleware
 
 import (
 	"context"
-	"fmt"
+	"errors"
 	"net/http"
 	"strings"
+	"time"
 
-	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 	"github.com/paulochiaradia/smart-gondola-backend/internal/interface/http/response"
-	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/config"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/domain/entity"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/auth"
 )
 
 // Chaves para salvar/recuperar dados do contexto
 type contextKey string
 
 const (
-	UserContextKey = contextKey("user_id")
-	OrgContextKey  = contextKey("org_id")
-	RoleContextKey = contextKey("role")
+	UserContextKey          = contextKey("user_id")
+	OrgContextKey           = contextKey("org_id")
+	StoreContextKey         = contextKey("store_id")
+	RoleContextKey          = contextKey("role")
+	TokenIDContextKey       = contextKey("jti")
+	TokenExpiresContextKey  = contextKey("token_expires_at")
+	ReadOnlyContextKey      = contextKey("read_only")
+	ImpersonationContextKey = contextKey("impersonation")
+	APIKeyContextKey        = contextKey("api_key")
 )
 
-func AuthMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		authHeader := r.Header.Get("Authorization")
-		if authHeader == "" {
-			response.Error(w, http.StatusUnauthorized, "Header 'Authorization' é obrigatório")
-			return
-		}
+// Impersonation descreve quem realmente age quando a equipe da plataforma usa o token de outro usuário
+type Impersonation struct {
+	SessionID uuid.UUID
+	ActorID   uuid.UUID
+	ActorRole string
+}
 
-		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
-		if tokenString == authHeader {
-			response.Error(w, http.StatusUnauthorized, "Formato do token inválido. Use 'Bearer <token>'")
-			return
-		}
+// SessionValidator confirma se a sessão do token ainda vale (denylist, usuário suspenso, logout-all).
+// Na impersonação, o ator também precisa continuar apto.
+type SessionValidator interface {
+	IsSessionActive(ctx context.Context, userID uuid.UUID, tokenID string, issuedAt time.Time) (bool, error)
+	IsImpersonatorActive(ctx context.Context, actorID uuid.UUID, issuedAt time.Time, writeAccess bool) (bool, error)
+}
 
-		cfg := config.Get()
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fmt.Errorf("método de assinatura inesperado: %v", token.Header["alg"])
-			}
-			return []byte(cfg.JWTSecret), nil
-		})
+// APIKeyAuthenticator resolve as chaves de API das integrações (nil = chave inválida, expirada ou revogada)
+type APIKeyAuthenticator interface {
+	AuthenticateAPIKey(ctx context.Context, rawKey string) (*entity.APIKey, error)
+}
 
-		if err != nil || !token.Valid {
-			response.Error(w, http.StatusUnauthorized, "Token inválido ou expirado")
-			return
-		}
+// AuthMiddleware valida o JWT e consulta o SessionValidator para revogação imediata.
+// Também aceita chaves de API (header X-API-Key ou "Bearer sgk_..."): nesse caso não há usuário,
+// só a organização e os escopos da chave (ver RequirePermission).
+func AuthMiddleware(sessions SessionValidator, apiKeys APIKeyAuthenticator) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			authHeader := r.Header.Get("Authorization")
+			rawKey := r.Header.Get("X-API-Key")
+			if bearer := strings.TrimPrefix(authHeader, "Bearer "); rawKey == "" && auth.IsAPIKey(bearer) {
+				rawKey = bearer
+			}
+			if rawKey != "" {
+				serveAPIKey(w, r, next, apiKeys, rawKey)
+				return
+			}
 
-		claims, ok := token.Claims.(jwt.MapClaims)
-		if !ok {
-			response.Error(w, http.StatusUnauthorized, "Payload do token inválido")
-			return
-		}
+			if authHeader == "" {
+				response.Error(w, http.StatusUnauthorized, "Header 'Authorization' é obrigatório")
+				return
+			}
 
-		// --- ADICIONE ESTA LINHA TEMPORÁRIA AQUI ---
-		fmt.Printf("\n[DEBUG] Claims recebidos no Token: %+v\n\n", claims)
-		// ------------------------------------------
+			tokenString := strings.TrimPrefix(authHeader, "Bearer ")
+			if tokenString == authHeader {
+				response.Error(w, http.StatusUnauthorized, "Formato do token inválido. Use 'Bearer <token>'")
+				return
+			}
 
-		userIDStr, _ := claims["user_id"].(string)
-		orgIDStr, _ := claims["org_id"].(string)
-		role, _ := claims["role"].(string)
+			claims, err := auth.ValidateAccessToken(tokenString)
+			if errors.Is(err, auth.ErrNotAccessToken) {
+				// Tokens de desafio 2FA (e outros de finalidade específica) não abrem rotas protegidas
+				response.Error(w, http.StatusUnauthorized, "Token não pode ser usado para acessar a API")
+				return
+			}
+			if errors.Is(err, auth.ErrMalformedClaims) {
+				response.Error(w, http.StatusUnauthorized, "Token não contém IDs válidos")
+				return
+			}
+			if err != nil {
+				response.Error(w, http.StatusUnauthorized, "Token inválido ou expirado")
+				return
+			}
 
-		userID, errU := uuid.Parse(userIDStr)
-		orgID, errO := uuid.Parse(orgIDStr)
+			// Revogação server-side: logout, logout-all, troca de senha ou usuário suspenso
+			active, err := sessions.IsSessionActive(r.Context(), claims.UserID, claims.ID, claims.IssuedAtPrecise())
+			if err != nil {
+				response.Error(w, http.StatusInternalServerError, "Erro ao validar sessão")
+				return
+			}
+			if !active {
+				response.Error(w, http.StatusUnauthorized, "Sessão encerrada. Faça login novamente")
+				return
+			}
 
-		if errU != nil || errO != nil {
-			response.Error(w, http.StatusUnauthorized, "Token não contém IDs válidos")
-			return
-		}
+			ctx := context.WithValue(r.Context(), UserContextKey, claims.UserID)
+			ctx = context.WithValue(ctx, OrgContextKey, claims.OrgID)
+			// Loja opcional: presente só para usuários presos a uma loja (gerente/operador)
+			ctx = context.WithValue(ctx, StoreContextKey, claims.StoreID)
+			ctx = context.WithValue(ctx, RoleContextKey, claims.Role)
+			ctx = context.WithValue(ctx, TokenIDContextKey, claims.ID)
+			ctx = context.WithValue(ctx, TokenExpiresContextKey, claims.ExpiresAt.Time)
+			ctx = context.WithValue(ctx, ReadOnlyContextKey, claims.ReadOnly)
+
+			// Token de impersonação: a resposta sinaliza que não é o próprio usuário agindo
+			if claims.ImpersonationID != uuid.Nil {
+				if claims.Act == nil || claims.Act.UserID == uuid.Nil {
+					response.Error(w, http.StatusUnauthorized, "Token de impersonação inválido")
+					return
+				}
+				active, err := sessions.IsImpersonatorActive(r.Context(), claims.Act.UserID, claims.IssuedAtPrecise(), !claims.ReadOnly)
+				if err != nil {
+					response.Error(w, http.StatusInternalServerError, "Erro ao validar sessão")
+					return
+				}
+				if !active {
+					response.Error(w, http.StatusUnauthorized, "Sessão encerrada. Faça login novamente")
+					return
+				}
+				imp := &Impersonation{SessionID: claims.ImpersonationID, ActorID: claims.Act.UserID, ActorRole: claims.Act.Role}
+				ctx = context.WithValue(ctx, ImpersonationContextKey, imp)
+				w.Header().Set("X-Impersonation-Id", imp.SessionID.String())
+				w.Header().Set("X-Impersonated-By", imp.ActorID.String())
+			}
 
-		ctx := context.WithValue(r.Context(), UserContextKey, userID)
-		ctx = context.WithValue(ctx, OrgContextKey, orgID)
-		ctx = context.WithValue(ctx, RoleContextKey, role)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
 
-		next.ServeHTTP(w, r.WithContext(ctx))
-	})
+func serveAPIKey(w http.ResponseWriter, r *http.Request, next http.Handler, apiKeys APIKeyAuthenticator, rawKey string) {
+	key, err := apiKeys.AuthenticateAPIKey(r.Context(), rawKey)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Erro ao validar chave de API")
+		return
+	}
+	if key == nil {
+		response.Error(w, http.StatusUnauthorized, "Chave de API inválida, expirada ou revogada")
+		return
+	}
+
+	ctx := context.WithValue(r.Context(), OrgContextKey, key.OrganizationID)
+	ctx = context.WithValue(ctx, StoreContextKey, (*uuid.UUID)(nil))
+	ctx = context.WithValue(ctx, APIKeyContextKey, key)
+	next.ServeHTTP(w, r.WithContext(ctx))
 }
 
 // Helpers para recuperar dados do contexto nos Handlers
@@ -89,7 +163,86 @@ func GetOrgID(ctx context.Context) uuid.UUID {
 	return id
 }
 
+// GetStoreID devolve a loja à qual a sessão está restrita (nil = organização inteira)
+func GetStoreID(ctx context.Context) *uuid.UUID {
+	id, _ := ctx.Value(StoreContextKey).(*uuid.UUID)
+	return id
+}
+
 func GetRole(ctx context.Context) string {
 	role, _ := ctx.Value(RoleContextKey).(string)
 	return role
 }
+
+// GetTokenID devolve o "jti" do access token em uso (necessário no logout)
+func GetTokenID(ctx context.Context) string {
+	id, _ := ctx.Value(TokenIDContextKey).(string)
+	return id
+}
+
+// GetTokenExpiresAt devolve a expiração do access token em uso
+func GetTokenExpiresAt(ctx context.Context) time.Time {
+	exp, _ := ctx.Value(TokenExpiresContextKey).(time.Time)
+	return exp
+}
+
+// IsReadOnly indica se a sessão atual é somente leitura (ex: email não verificado)
+func IsReadOnly(ctx context.Context) bool {
+	readOnly, _ := ctx.Value(ReadOnlyContextKey).(bool)
+	return readOnly
+}
+
+// GetImpersonation devolve os dados da impersonação em curso (nil = o próprio usuário)
+func GetImpersonation(ctx context.Context) *Impersonation {
+	imp, _ := ctx.Value(ImpersonationContextKey).(*Impersonation)
+	return imp
+}
+
+// GetAPIKey devolve a chave de API da requisição (nil = sessão de usuário)
+func GetAPIKey(ctx context.Context) *entity.APIKey {
+	key, _ := ctx.Value(APIKeyContextKey).(*entity.APIKey)
+	return key
+}
+
+// DenyAPIKey restringe a rota a sessões de usuário (perfil, senha, 2FA, logout: chave de API não tem "eu")
+func DenyAPIKey(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if GetAPIKey(r.Context()) != nil {
+			response.Error(w, http.StatusForbidden, "Rota disponível apenas para usuários autenticados")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// DenyImpersonation bloqueia ações que só o próprio usuário pode fazer (senha, 2FA),
+// mesmo em impersonação com acesso de escrita
+func DenyImpersonation(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if GetImpersonation(r.Context()) != nil {
+			response.Error(w, http.StatusForbidden, "Ação indisponível durante a impersonação")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// EnforceReadOnly bloqueia métodos de escrita para sessões somente leitura.
+// Deve ser usado depois do AuthMiddleware.
+func EnforceReadOnly(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+		default:
+			if IsReadOnly(r.Context()) {
+				if GetImpersonation(r.Context()) != nil {
+					response.Error(w, http.StatusForbidden, "Sessão de impersonação somente leitura")
+					return
+				}
+				response.Error(w, http.StatusForbidden, "Confirme seu email para realizar alterações")
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/config"
+)
+
+// ClientIP devolve o IP de quem fez a requisição.
+// Headers de proxy só são considerados com TRUST_PROXY_HEADERS (senão qualquer cliente forjaria o IP).
+func ClientIP(r *http.Request) string {
+	peer := remoteIP(r)
+	cfg := config.Get()
+	if !cfg.TrustProxyHeaders {
+		return peer
+	}
+
+	// Cada proxy acrescenta à direita quem conectou nele; o que está à esquerda veio do cliente e
+	// pode ser forjado. Vale o endereço mais à direita que não seja um dos nossos proxies
+	// (quem conecta na API é sempre um deles).
+	if fwd := r.Header.Get("X-Forwarded-For"); fwd != "" {
+		hops := strings.Split(fwd, ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			ip := net.ParseIP(strings.TrimSpace(hops[i]))
+			if ip == nil {
+				break
+			}
+			if !isTrustedProxy(ip, cfg.TrustedProxies) {
+				return ip.String()
+			}
+		}
+		return peer
+	}
+	if ip := strings.TrimSpace(r.Header.Get("X-Real-IP")); net.ParseIP(ip) != nil {
+		return ip
+	}
+	return peer
+}
+
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// isTrustedProxy diz se o IP está em TRUSTED_PROXIES (IP único ou CIDR)
+func isTrustedProxy(ip net.IP, proxies []string) bool {
+	for _, proxy := range proxies {
+		if _, network, err := net.ParseCIDR(proxy); err == nil {
+			if network.Contains(ip) {
+				return true
+			}
+		} else if trusted := net.ParseIP(proxy); trusted != nil && trusted.Equal(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -1,30 +1,128 @@
 package middleware
 
 import (
+	"fmt"
 	"net/http"
+	"sort"
+	"strings"
+	"sync"
 
 	"github.com/paulochiaradia/smart-gondola-backend/internal/interface/http/response"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/domain/entity"
 )
 
-// RequireRole é um middleware que bloqueia o acesso se o usuário não tiver uma das roles permitidas.
-// Ele DEVE ser usado nas rotas logo após o AuthMiddleware.
-func RequireRole(allowedRoles ...string) func(http.Handler) http.Handler {
+const (
+	forbiddenMessage       = "Acesso negado: seu perfil não tem permissão para esta ação"
+	apiKeyForbiddenMessage = "Acesso negado: a chave de API não tem este escopo"
+)
+
+// accessRules guarda os nomes desconhecidos referenciados ao montar as rotas,
+// para que a aplicação se recuse a subir com uma regra de acesso digitada errada.
+var accessRules = struct {
+	sync.Mutex
+	unknown map[string]struct{}
+}{unknown: map[string]struct{}{}}
+
+func registerUnknown(kind, name string) {
+	accessRules.Lock()
+	defer accessRules.Unlock()
+	accessRules.unknown[kind+" "+name] = struct{}{}
+}
+
+// CheckAccessRules retorna erro se alguma rota referenciou papel ou permissão fora do catálogo.
+// Deve ser chamada na inicialização, depois de montar o router.
+func CheckAccessRules() error {
+	accessRules.Lock()
+	defer accessRules.Unlock()
+
+	if len(accessRules.unknown) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(accessRules.unknown))
+	for name := range accessRules.unknown {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return fmt.Errorf("regras de acesso inválidas: %s", strings.Join(names, ", "))
+}
+
+// currentRole lê o papel do token (fora do catálogo = sem acesso)
+func currentRole(r *http.Request) (entity.UserRole, bool) {
+	return entity.ParseRole(GetRole(r.Context()))
+}
+
+// RequirePermission bloqueia o acesso se o papel do usuário (ou os escopos da chave de API)
+// não conceder todas as permissões informadas. Ele DEVE ser usado nas rotas logo após o AuthMiddleware.
+func RequirePermission(perms ...string) func(http.Handler) http.Handler {
+	required := make([]entity.Permission, 0, len(perms))
+	valid := len(perms) > 0
+	for _, name := range perms {
+		perm, ok := entity.ParsePermission(name)
+		if !ok {
+			registerUnknown("permissão", name)
+			valid = false
+			continue
+		}
+		required = append(required, perm)
+	}
+
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			// Pegamos a role que o AuthMiddleware injetou no contexto
-			userRole := GetRole(r.Context())
+			if key := GetAPIKey(r.Context()); key != nil {
+				if !valid {
+					response.Error(w, http.StatusForbidden, apiKeyForbiddenMessage)
+					return
+				}
+				for _, perm := range required {
+					if !key.Can(perm) {
+						response.Error(w, http.StatusForbidden, apiKeyForbiddenMessage)
+						return
+					}
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
 
-			// Verifica se a role do usuário está na lista de permitidas
-			for _, role := range allowedRoles {
-				if userRole == role {
-					// Tem permissão! Passa para o próximo (o Handler da rota)
-					next.ServeHTTP(w, r)
+			role, ok := currentRole(r)
+			// Regra mal configurada falha fechada
+			if !ok || !valid {
+				response.Error(w, http.StatusForbidden, forbiddenMessage)
+				return
+			}
+			for _, perm := range required {
+				if !role.Can(perm) {
+					response.Error(w, http.StatusForbidden, forbiddenMessage)
 					return
 				}
 			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireRole bloqueia o acesso se o usuário não tiver um dos papéis permitidos.
+// Prefira RequirePermission; mantido para regras que dependem do papel em si.
+func RequireRole(allowedRoles ...string) func(http.Handler) http.Handler {
+	allowed := make(map[entity.UserRole]struct{}, len(allowedRoles))
+	for _, name := range allowedRoles {
+		role, ok := entity.ParseRole(name)
+		if !ok {
+			registerUnknown("papel", name)
+			continue
+		}
+		allowed[role] = struct{}{}
+	}
 
-			// Se chegou aqui, não tem permissão. Retorna 403 Forbidden.
-			response.Error(w, http.StatusForbidden, "Acesso negado: seu perfil não tem permissão para esta ação")
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			role, ok := currentRole(r)
+			if ok {
+				if _, found := allowed[role]; found {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+			response.Error(w, http.StatusForbidden, forbiddenMessage)
 		})
 	}
 }
@@ -0,0 +1,92 @@
+package middleware
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/interface/http/response"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/domain/entity"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/database"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/tenant"
+)
+
+// TenantScope limita a requisição à organização (e à loja, se houver) do token. Apenas super_admin/support
+// recebem acesso entre organizações. Deve ser usado logo após o AuthMiddleware.
+func TenantScope(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		role, _ := entity.ParseRole(GetRole(r.Context()))
+		ctx := tenant.WithScope(r.Context(), tenant.Scope{
+			OrgID:       GetOrgID(r.Context()),
+			StoreID:     GetStoreID(r.Context()),
+			CrossTenant: role.IsPlatform(),
+		})
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// TenantDatabase abre a sessão de banco da requisição com a RLS aplicada à organização do escopo
+// (segunda linha de defesa: um WHERE esquecido não vaza dados). Deve ser usado depois do TenantScope.
+func TenantDatabase(db *sql.DB) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			scope, ok := tenant.FromContext(r.Context())
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ctx, release, err := database.WithTenantSession(r.Context(), db, scope.OrgID, scope.CrossTenant)
+			if err != nil {
+				response.Error(w, http.StatusInternalServerError, "Erro ao preparar conexão com o banco")
+				return
+			}
+			defer release()
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// RequireOrgParam responde 404 quando a organização da URL está fora do escopo da requisição
+// (não revelamos se ela existe). Deve ser usado depois do TenantScope.
+func RequireOrgParam(param string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			orgID, err := uuid.Parse(chi.URLParam(r, param))
+			if err != nil {
+				response.Error(w, http.StatusBadRequest, "ID da organização inválido")
+				return
+			}
+
+			scope, ok := tenant.FromContext(r.Context())
+			if !ok || !scope.Allows(orgID) {
+				response.Error(w, http.StatusNotFound, "Organização não encontrada")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireStoreParam responde 404 quando a loja da URL está fora da loja do token.
+// A posse da loja pela organização continua sendo verificada no repositório.
+func RequireStoreParam(param string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			storeID, err := uuid.Parse(chi.URLParam(r, param))
+			if err != nil {
+				response.Error(w, http.StatusBadRequest, "ID da loja inválido")
+				return
+			}
+
+			scope, ok := tenant.FromContext(r.Context())
+			if !ok || !scope.AllowsStore(storeID) {
+				response.Error(w, http.StatusNotFound, "Loja não encontrada")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
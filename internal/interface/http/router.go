@@ -1,6 +1,7 @@
 package http
 
 import (
+	"encoding/json"
 	"net/http"
 	"os"
 	"strings"
@@ -13,6 +14,8 @@ import (
 
 	"github.com/paulochiaradia/smart-gondola-backend/internal/di"
 	customMiddleware "github.com/paulochiaradia/smart-gondola-backend/internal/interface/http/middleware"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/interface/http/response"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/auth"
 )
 
 func NewRouter(container *di.Container) http.Handler {
@@ -33,7 +36,7 @@ func NewRouter(container *di.Container) http.Handler {
 	r.Use(cors.Handler(cors.Options{
 		AllowedOrigins:   origins,
 		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", "X-API-Key"},
 		ExposedHeaders:   []string{"Link"},
 		AllowCredentials: true,
 		MaxAge:           300,
@@ -49,6 +52,9 @@ func NewRouter(container *di.Container) http.Handler {
 	r.Use(customMiddleware.LimitPayloadSize(2 * 1024 * 1024))
 	r.Use(httprate.LimitByIP(100, 1*time.Minute))
 
+	// Chaves públicas para validar nossos JWTs (outros serviços, gateways)
+	r.Get("/.well-known/jwks.json", jwks)
+
 	r.Route("/api/v1", func(r chi.Router) {
 		// ===========================
 		// ROTAS PÚBLICAS (Sem Token)
@@ -58,25 +64,193 @@ func NewRouter(container *di.Container) http.Handler {
 		})
 
 		r.Post("/auth/login", container.UserHandler.Login)
-		r.Post("/auth/register", container.UserHandler.Register)
+		r.With(httprate.LimitByIP(5, 15*time.Minute)).
+			Post("/auth/signup", container.SignupHandler.Signup)
+		r.Post("/auth/refresh", container.UserHandler.Refresh)
+		r.Post("/auth/2fa/verify", container.UserHandler.VerifyTwoFactor)
+		r.Post("/auth/2fa/setup", container.UserHandler.StartTwoFactorSetup)
+		r.Post("/auth/2fa/setup/confirm", container.UserHandler.ConfirmTwoFactorSetup)
+		r.Post("/auth/password/expired", container.UserHandler.ChangeExpiredPassword)
+
+		// Login corporativo (OIDC) das organizações enterprise
+		r.With(httprate.LimitByIP(20, time.Minute)).
+			Post("/auth/sso/start", container.SSOHandler.Start)
+		r.With(httprate.LimitByIP(20, time.Minute)).
+			Post("/auth/sso/callback", container.SSOHandler.Callback)
+
+		// Recuperação de senha (limite extra: cada pedido dispara um email)
+		r.With(httprate.LimitByIP(5, 15*time.Minute)).
+			Post("/auth/password/forgot", container.PassHandler.Forgot)
+		r.Post("/auth/password/reset", container.PassHandler.Reset)
+
+		// Aceite de convite (o convidado ainda não tem conta)
+		r.Post("/auth/invites/accept", container.InvHandler.Accept)
+
+		// Confirmação de email
+		r.Post("/auth/email/verify", container.UserHandler.VerifyEmail)
+		r.With(httprate.LimitByIP(5, 15*time.Minute)).
+			Post("/auth/email/resend", container.UserHandler.ResendVerification)
 
 		// ===========================
 		// ROTAS PROTEGIDAS (Com Token)
 		// ===========================
 		r.Group(func(r chi.Router) {
-			r.Use(customMiddleware.AuthMiddleware)
+			r.Use(customMiddleware.AuthMiddleware(container.UserUseCase, container.KeyUseCase))
+			r.Use(customMiddleware.AuditImpersonation(container.ImpUseCase))
+			r.Use(customMiddleware.TenantScope)
+			r.Use(customMiddleware.TenantDatabase(container.DB))
+
+			// Rotas do próprio usuário: exigem login (chave de API não tem "eu")
+			r.Group(func(r chi.Router) {
+				r.Use(customMiddleware.DenyAPIKey)
+
+				// Sessão (liberadas mesmo para sessões somente leitura)
+				r.Post("/auth/logout", container.UserHandler.Logout)
+				r.With(customMiddleware.DenyImpersonation).
+					Post("/auth/logout-all", container.UserHandler.LogoutAll)
+				r.Post("/auth/impersonation/end", container.ImpHandler.EndCurrent)
+
+				// Aparelhos do próprio usuário (remover um aparelho também encerra as sessões dele)
+				r.Get("/me/devices", container.DevHandler.ListMine)
+				r.With(customMiddleware.DenyImpersonation).
+					Delete("/me/devices/{id}", container.DevHandler.Remove)
+				r.Get("/me/security/logins", container.UserHandler.MyLogins)
+				r.With(customMiddleware.DenyImpersonation).
+					Put("/me/password", container.UserHandler.ChangePassword)
+
+				r.Group(func(r chi.Router) {
+					// Sessão somente leitura também não altera o perfil nem o 2FA
+					r.Use(customMiddleware.EnforceReadOnly)
+
+					// Autenticação em dois fatores (só o próprio usuário, nunca numa impersonação)
+					r.Group(func(r chi.Router) {
+						r.Use(customMiddleware.DenyImpersonation)
+						r.Post("/me/2fa/enroll", container.UserHandler.EnrollTwoFactor)
+						r.Post("/me/2fa/confirm", container.UserHandler.ConfirmTwoFactor)
+						r.Post("/me/2fa/disable", container.UserHandler.DisableTwoFactor)
+						r.Post("/me/2fa/recovery-codes", container.UserHandler.RegenerateRecoveryCodes)
+					})
+
+					// Perfil do próprio usuário
+					r.Get("/me", container.UserHandler.Me)
+					r.Patch("/me", container.UserHandler.UpdateMe)
+				})
+			})
 
-			// Rotas de Organização
-			r.Get("/organizations/{id}", container.OrgHandler.GetByID)
+			r.Group(func(r chi.Router) {
+				// Email não verificado + política "read_only": apenas leitura daqui para baixo
+				r.Use(customMiddleware.EnforceReadOnly)
 
-			// Rotas de Lojas
-			r.With(customMiddleware.RequireRole("admin", "tenant")).
-				Post("/stores", container.StoreHandler.Create)
+				// Gestão de usuários da organização do token (hierarquia de papéis aplicada no use case)
+				r.Group(func(r chi.Router) {
+					r.Use(customMiddleware.RequirePermission("users:read"))
+					r.Get("/users", container.UserHandler.ListUsers)
+					r.Get("/users/{id}", container.UserHandler.GetUser)
+				})
+				r.Group(func(r chi.Router) {
+					r.Use(customMiddleware.RequirePermission("users:manage"))
+					r.Post("/users", container.UserHandler.CreateUser)
+					r.Patch("/users/{id}", container.UserHandler.UpdateUser)
+					r.Put("/users/{id}/role", container.UserHandler.ChangeRole)
+					r.Put("/users/{id}/store", container.UserHandler.MoveStore)
+					r.Post("/users/{id}/suspend", container.UserHandler.Suspend)
+					r.Post("/users/{id}/reactivate", container.UserHandler.Reactivate)
+					r.Post("/users/{id}/unlock", container.UserHandler.UnlockLogin)
+				})
+				r.With(customMiddleware.RequirePermission("users:audit")).
+					Get("/users/{id}/security/logins", container.UserHandler.UserLogins)
 
-			r.With(customMiddleware.RequireRole("admin", "tenant", "manager")).
-				Get("/organizations/{orgId}/stores", container.StoreHandler.ListByOrg)
+				// Convites (gerente só convida operadores; regra no use case)
+				r.Group(func(r chi.Router) {
+					r.Use(customMiddleware.RequirePermission("users:invite"))
+					r.Post("/invites", container.InvHandler.Create)
+					r.Get("/invites", container.InvHandler.List)
+					r.Post("/invites/{id}/resend", container.InvHandler.Resend)
+					r.Delete("/invites/{id}", container.InvHandler.Revoke)
+				})
+
+				// Rotas de Organização (outra organização na URL = 404, exceto para a plataforma)
+				r.With(customMiddleware.RequirePermission("organizations:create")).
+					Post("/organizations", container.OrgHandler.Create)
+				r.Group(func(r chi.Router) {
+					r.Use(customMiddleware.RequirePermission("organizations:read"), customMiddleware.RequireOrgParam("id"))
+					r.Get("/organizations/{id}", container.OrgHandler.GetByID)
+					r.Get("/organizations/{id}/plan-history", container.OrgHandler.PlanHistory)
+					r.Get("/organizations/{id}/usage", container.OrgHandler.Usage)
+				})
+				r.With(customMiddleware.RequirePermission("organizations:manage"), customMiddleware.RequireOrgParam("id")).
+					Put("/organizations/{id}/settings", container.OrgHandler.UpdateSettings)
+				r.Group(func(r chi.Router) {
+					r.Use(customMiddleware.RequirePermission("organizations:manage"), customMiddleware.RequireOrgParam("id"))
+					r.Get("/organizations/{id}/sso", container.SSOConfHandler.Get)
+					r.Put("/organizations/{id}/sso", container.SSOConfHandler.Update)
+				})
+				// Troca de plano self-service (só rebaixamento; contratação e enterprise pela plataforma)
+				r.Group(func(r chi.Router) {
+					r.Use(customMiddleware.RequirePermission("organizations:manage"), customMiddleware.RequireOrgParam("id"))
+					r.Get("/organizations/{id}/plan/preview", container.PlanHandler.Preview)
+					r.Put("/organizations/{id}/plan", container.PlanHandler.Change)
+					r.Get("/organizations/{id}/plan/scheduled", container.PlanHandler.Scheduled)
+					r.Delete("/organizations/{id}/plan/scheduled", container.PlanHandler.CancelScheduled)
+				})
+
+				// Rotas de Lojas
+				r.With(customMiddleware.RequirePermission("stores:create")).
+					Post("/stores", container.StoreHandler.Create)
+
+				r.With(customMiddleware.RequirePermission("stores:read"), customMiddleware.RequireOrgParam("orgId")).
+					Get("/organizations/{orgId}/stores", container.StoreHandler.ListByOrg)
+
+				// Suporte da plataforma: impersonação com prazo, motivo e auditoria
+				r.Group(func(r chi.Router) {
+					r.Use(customMiddleware.RequirePermission("users:impersonate"))
+					r.Post("/admin/impersonations", container.ImpHandler.Start)
+					r.Get("/admin/impersonations", container.ImpHandler.List)
+					r.Get("/admin/impersonations/{id}/events", container.ImpHandler.Events)
+					r.Delete("/admin/impersonations/{id}", container.ImpHandler.End)
+				})
+
+				// Segurança da plataforma: bloqueios de login por IP/email
+				r.Group(func(r chi.Router) {
+					r.Use(customMiddleware.RequirePermission("security:manage"))
+					r.Get("/admin/login-throttles", container.ThrHandler.List)
+					r.Post("/admin/login-throttles/unlock", container.ThrHandler.Unlock)
+				})
+
+				// Planos da plataforma: qualquer plano e exceções de cota por tenant
+				r.Group(func(r chi.Router) {
+					r.Use(customMiddleware.RequirePermission("plans:manage"))
+					r.Put("/admin/organizations/{id}/plan", container.PlanHandler.ChangeAsPlatform)
+					r.Put("/admin/organizations/{id}/limits", container.PlanHandler.SetLimits)
+				})
+
+				// Chaves de API da organização (uma chave não gerencia outras)
+				r.Group(func(r chi.Router) {
+					r.Use(customMiddleware.RequirePermission("api_keys:manage"), customMiddleware.DenyAPIKey)
+					r.Post("/api-keys", container.KeyHandler.Create)
+					r.Get("/api-keys", container.KeyHandler.List)
+					r.Delete("/api-keys/{id}", container.KeyHandler.Revoke)
+				})
+
+				// Quem recebe os alertas de cada loja
+				r.With(customMiddleware.RequirePermission("devices:read"), customMiddleware.RequireStoreParam("id")).
+					Get("/stores/{id}/devices", container.DevHandler.ListByStore)
+			})
 		})
 	})
 
 	return r
 }
+
+// jwks publica as chaves no formato da RFC 7517 (sem o envelope "data" das demais respostas)
+func jwks(w http.ResponseWriter, r *http.Request) {
+	set, err := auth.JWKS()
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Chaves de assinatura indisponíveis")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	// Cache curto: uma chave nova aparece para os clientes logo depois da rotação
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	json.NewEncoder(w).Encode(set)
+}
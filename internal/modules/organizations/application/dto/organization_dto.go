@@ -14,6 +14,7 @@ type CreateOrganizationRequest struct {
 	Slug     string                    `json:"slug" validate:"required"`
 	Sector   entity.OrganizationSector `json:"sector" validate:"required,oneof=supermarket pharmacy retail warehouse other"`
 	Plan     entity.OrganizationPlan   `json:"plan" validate:"required,oneof=free pro enterprise"`
+	Trial    bool                      `json:"trial"` // Só no pro: começa como período de teste (TRIAL_DURATION)
 }
 
 // UpdateOrganizationRequest para atualizações cadastrais
@@ -23,6 +24,13 @@ type UpdateOrganizationRequest struct {
 	Sector   entity.OrganizationSector `json:"sector" validate:"required,oneof=supermarket pharmacy retail warehouse other"`
 }
 
+// UpdateOrganizationSettingsRequest altera as políticas do tenant (campos nil são mantidos)
+type UpdateOrganizationSettingsRequest struct {
+	RequireTwoFactor      *bool                         `json:"require_two_factor"`
+	UnverifiedEmailPolicy *entity.UnverifiedEmailPolicy `json:"unverified_email_policy" validate:"omitempty,oneof=allow block read_only"`
+	PasswordPolicy        *entity.PasswordPolicy        `json:"password_policy"` // Substitui a política inteira
+}
+
 // OrganizationResponse é o que devolvemos para o frontend
 type OrganizationResponse struct {
 	ID        uuid.UUID                   `json:"id"`
@@ -34,4 +42,88 @@ type OrganizationResponse struct {
 	Settings  entity.OrganizationSettings `json:"settings"`
 	IsActive  bool                        `json:"is_active"`
 	CreatedAt time.Time                   `json:"created_at"`
+
+	TrialEndsAt *time.Time `json:"trial_ends_at,omitempty"` // Presente enquanto o plano está em teste
+}
+
+// PlanPeriodResponse é um período do histórico de planos (effective_to ausente = vigente)
+type PlanPeriodResponse struct {
+	Plan          entity.OrganizationPlan `json:"plan"`
+	Trial         bool                    `json:"trial"`
+	Limits        entity.PlanLimits       `json:"limits"`
+	Reason        entity.PlanChangeReason `json:"reason"`
+	ChangedBy     *uuid.UUID              `json:"changed_by,omitempty"` // Ausente = sistema
+	EffectiveFrom time.Time               `json:"effective_from"`
+	EffectiveTo   *time.Time              `json:"effective_to,omitempty"`
+}
+
+// ChangePlanRequest troca o plano. Sem effective_at a troca é imediata e recusada se o consumo
+// não couber nos novos limites; com effective_at (futuro) ela é agendada.
+type ChangePlanRequest struct {
+	Plan        entity.OrganizationPlan `json:"plan" validate:"required,oneof=free pro enterprise"`
+	EffectiveAt *time.Time              `json:"effective_at"`
+}
+
+// UpdateLimitsRequest substitui as exceções de cota do tenant (0 = segue o padrão do plano)
+type UpdateLimitsRequest struct {
+	MaxUsers   int `json:"max_users" validate:"min=0"`
+	MaxDevices int `json:"max_devices" validate:"min=0"`
+	MaxStores  int `json:"max_stores" validate:"min=0"`
+}
+
+// LimitImpact compara o limite atual e o do plano pretendido com o consumo do recurso
+type LimitImpact struct {
+	Used         int  `json:"used"`
+	CurrentLimit int  `json:"current_limit"`
+	NewLimit     int  `json:"new_limit"`
+	Exceeded     bool `json:"exceeded"` // O consumo atual não cabe no novo limite
+}
+
+// PlanChangePreviewResponse mostra o efeito da troca antes de pedi-la
+type PlanChangePreviewResponse struct {
+	OrganizationID uuid.UUID                `json:"organization_id"`
+	CurrentPlan    entity.OrganizationPlan  `json:"current_plan"`
+	TargetPlan     entity.OrganizationPlan  `json:"target_plan"`
+	Users          LimitImpact              `json:"users"`
+	Devices        LimitImpact              `json:"devices"`
+	Stores         LimitImpact              `json:"stores"`
+	Violations     []entity.LimitViolation  `json:"violations"`
+	AllowedNow     bool                     `json:"allowed_now"`         // false = só agendando, depois de reduzir o consumo
+	Scheduled      *ScheduledChangeResponse `json:"scheduled,omitempty"` // Troca pendente ou bloqueada, se houver
+}
+
+// ScheduledChangeResponse é uma troca de plano agendada
+type ScheduledChangeResponse struct {
+	ID          uuid.UUID               `json:"id"`
+	FromPlan    entity.OrganizationPlan `json:"from_plan"`
+	ToPlan      entity.OrganizationPlan `json:"to_plan"`
+	RequestedBy uuid.UUID               `json:"requested_by"`
+	EffectiveAt time.Time               `json:"effective_at"`
+	Status      entity.PlanChangeStatus `json:"status"`               // pending ou blocked
+	Violations  []entity.LimitViolation `json:"violations,omitempty"` // Excessos que bloquearam a troca na data
+	CreatedAt   time.Time               `json:"created_at"`
+}
+
+// PlanChangeResponse é o resultado da troca: aplicada agora ou agendada
+type PlanChangeResponse struct {
+	Applied      bool                     `json:"applied"`
+	Organization *OrganizationResponse    `json:"organization"`
+	Scheduled    *ScheduledChangeResponse `json:"scheduled,omitempty"`
+	Violations   []entity.LimitViolation  `json:"violations,omitempty"` // O que reduzir até a data da troca agendada
+}
+
+// QuotaUsage é o consumo de um recurso frente ao limite do plano
+type QuotaUsage struct {
+	Used  int `json:"used"`
+	Limit int `json:"limit"`
+}
+
+// UsageResponse é o consumo da organização em cada cota do plano
+type UsageResponse struct {
+	OrganizationID uuid.UUID               `json:"organization_id"`
+	Plan           entity.OrganizationPlan `json:"plan"`
+	TrialEndsAt    *time.Time              `json:"trial_ends_at,omitempty"`
+	Users          QuotaUsage              `json:"users"`
+	Devices        QuotaUsage              `json:"devices"`
+	Stores         QuotaUsage              `json:"stores"`
 }
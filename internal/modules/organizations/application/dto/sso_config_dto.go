@@ -0,0 +1,30 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UpdateSSOConfigRequest configura o login corporativo (OIDC). O segredo só é obrigatório
+// na primeira configuração; vazio mantém o atual.
+type UpdateSSOConfigRequest struct {
+	Issuer         string   `json:"issuer" validate:"required,url"`
+	ClientID       string   `json:"client_id" validate:"required,max=255"`
+	ClientSecret   string   `json:"client_secret" validate:"omitempty,max=512"`
+	AllowedDomains []string `json:"allowed_domains" validate:"required,min=1,dive,required,fqdn"`
+	DefaultRole    string   `json:"default_role" validate:"required,oneof=manager operator"`
+	Enabled        bool     `json:"enabled"`
+}
+
+// SSOConfigResponse devolve a configuração (nunca o segredo)
+type SSOConfigResponse struct {
+	OrganizationID  uuid.UUID `json:"organization_id"`
+	Issuer          string    `json:"issuer"`
+	ClientID        string    `json:"client_id"`
+	HasClientSecret bool      `json:"has_client_secret"`
+	AllowedDomains  []string  `json:"allowed_domains"`
+	DefaultRole     string    `json:"default_role"`
+	Enabled         bool      `json:"enabled"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
@@ -18,9 +18,9 @@ type AddressInput struct {
 	ZipCode    string `json:"zip_code"`
 }
 
-// CreateStoreRequest entrada para criar loja
+// CreateStoreRequest entrada para criar loja (sem organization_id, usa a organização do token)
 type CreateStoreRequest struct {
-	OrganizationID uuid.UUID    `json:"organization_id" validate:"required"`
+	OrganizationID uuid.UUID    `json:"organization_id"`
 	Name           string       `json:"name" validate:"required"`
 	Code           string       `json:"code" validate:"required"`
 	Timezone       string       `json:"timezone"`
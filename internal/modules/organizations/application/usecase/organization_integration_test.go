@@ -3,7 +3,10 @@ package usecase_test
 import (
 	"context"
 	"database/sql"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/suite"
 
@@ -32,7 +35,7 @@ func (s *OrganizationSuite) SetupSuite() {
 	s.db = db
 
 	repo := repository.NewOrganizationRepository(db)
-	s.useCase = usecase.NewOrganizationUseCase(repo)
+	s.useCase = usecase.NewOrganizationUseCase(repo, repository.NewPlanHistoryRepository(db), database.NewTxManager(db))
 }
 
 func (s *OrganizationSuite) SetupTest() {
@@ -116,6 +119,113 @@ func (s *OrganizationSuite) TestCreateOrganization_DuplicateSlug() {
 	s.Contains(err.Error(), "slug") // Agora sim vai dar erro de slug, pois o CNPJ é válido
 }
 
+func (s *OrganizationSuite) TestTrial_ExpiresToFreeAndKeepsPlanHistory() {
+	ctx := context.Background()
+
+	// Teste só existe no pro
+	_, err := s.useCase.Create(ctx, dto.CreateOrganizationRequest{
+		Name: "Loja Free", Document: "13347016000117", Slug: "loja-free", Sector: entity.SectorRetail, Plan: entity.PlanFree, Trial: true,
+	})
+	s.ErrorIs(err, entity.ErrTrialNotAllowed)
+
+	res, err := s.useCase.Create(ctx, dto.CreateOrganizationRequest{
+		Name: "Loja Trial", Document: "15436940000103", Slug: "loja-trial", Sector: entity.SectorRetail, Plan: entity.PlanPro, Trial: true,
+	})
+	s.Require().NoError(err)
+	s.Equal(entity.PlanPro, res.Plan)
+	s.Require().NotNil(res.TrialEndsAt)
+	s.True(res.TrialEndsAt.After(time.Now()))
+
+	// Ainda no prazo: o job não mexe
+	expired, err := s.useCase.ExpireTrials(ctx, time.Now())
+	s.Require().NoError(err)
+	s.Equal(0, expired)
+
+	// Prazo vencido há dois dias
+	endedAt := time.Now().UTC().Add(-48 * time.Hour).Truncate(time.Second)
+	_, err = s.db.Exec(`UPDATE organizations SET trial_ends_at = $1 WHERE id = $2`, endedAt, res.ID)
+	s.Require().NoError(err)
+
+	// Consultar já rebaixa, mesmo antes do job passar
+	org, err := s.useCase.GetByID(ctx, res.ID)
+	s.Require().NoError(err)
+	s.Equal(entity.PlanFree, org.Plan)
+	s.Nil(org.TrialEndsAt)
+	s.Equal(2, org.Settings.MaxUsers)
+	s.Equal(10, org.Settings.MaxDevices)
+
+	// O job não rebaixa duas vezes
+	expired, err = s.useCase.ExpireTrials(ctx, time.Now())
+	s.Require().NoError(err)
+	s.Equal(0, expired)
+
+	history, err := s.useCase.PlanHistory(ctx, res.ID)
+	s.Require().NoError(err)
+	s.Require().Len(history, 2)
+
+	// Mais recente primeiro: o free vale desde o fim do teste, não desde a consulta
+	s.Equal(entity.PlanFree, history[0].Plan)
+	s.Equal(entity.PlanChangeTrialExpired, history[0].Reason)
+	s.False(history[0].Trial)
+	s.True(history[0].EffectiveFrom.Equal(endedAt), history[0].EffectiveFrom)
+	s.Nil(history[0].EffectiveTo)
+
+	s.Equal(entity.PlanPro, history[1].Plan)
+	s.Equal(entity.PlanChangeCreated, history[1].Reason)
+	s.True(history[1].Trial)
+	s.Require().NotNil(history[1].EffectiveTo)
+	s.True(history[1].EffectiveTo.Equal(endedAt))
+}
+
+func (s *OrganizationSuite) TestExpireTrials_DowngradesEveryExpiredOrganization() {
+	ctx := context.Background()
+
+	for _, in := range []dto.CreateOrganizationRequest{
+		{Name: "Trial A", Document: "13347016000117", Slug: "trial-a", Sector: entity.SectorRetail, Plan: entity.PlanPro, Trial: true},
+		{Name: "Trial B", Document: "15436940000103", Slug: "trial-b", Sector: entity.SectorRetail, Plan: entity.PlanPro, Trial: true},
+		{Name: "Pago", Document: "06990590000123", Slug: "pago", Sector: entity.SectorRetail, Plan: entity.PlanPro},
+	} {
+		_, err := s.useCase.Create(ctx, in)
+		s.Require().NoError(err)
+	}
+	_, err := s.db.Exec(`UPDATE organizations SET trial_ends_at = NOW() - INTERVAL '1 hour' WHERE slug IN ('trial-a', 'trial-b')`)
+	s.Require().NoError(err)
+
+	// Uma organização que falha não segura o rebaixamento das outras
+	_, err = s.db.Exec(`
+		CREATE FUNCTION block_trial_a() RETURNS trigger AS $$
+		BEGIN RAISE EXCEPTION 'organização com defeito'; END $$ LANGUAGE plpgsql;
+		CREATE TRIGGER block_trial_a BEFORE UPDATE ON organizations
+		FOR EACH ROW WHEN (OLD.slug = 'trial-a') EXECUTE FUNCTION block_trial_a();
+	`)
+	s.Require().NoError(err)
+	expired, err := s.useCase.ExpireTrials(ctx, time.Now())
+	_, dropErr := s.db.Exec(`DROP TRIGGER block_trial_a ON organizations; DROP FUNCTION block_trial_a();`)
+	s.Require().NoError(dropErr)
+	s.Require().NoError(err)
+	s.Equal(1, expired)
+
+	// Execuções simultâneas do job: cada teste conta uma vez só
+	var wg sync.WaitGroup
+	var total atomic.Int64
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			n, err := s.useCase.ExpireTrials(ctx, time.Now())
+			s.NoError(err)
+			total.Add(int64(n))
+		}()
+	}
+	wg.Wait()
+	s.Equal(int64(1), total.Load())
+
+	var free, pro int
+	s.Require().NoError(s.db.QueryRow(`SELECT COUNT(*) FILTER (WHERE plan = 'free'), COUNT(*) FILTER (WHERE plan = 'pro') FROM organizations`).Scan(&free, &pro))
+	s.Equal(2, free)
+	s.Equal(1, pro, "plano pago não tem teste e não é rebaixado")
+}
+
 func TestOrganizationSuite(t *testing.T) {
 	suite.Run(t, new(OrganizationSuite))
 }
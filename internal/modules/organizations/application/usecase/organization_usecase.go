@@ -4,19 +4,25 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log/slog"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/organizations/application/dto"
 	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/organizations/domain/entity"
 	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/organizations/domain/repository"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/config"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/database"
 )
 
 type OrganizationUseCase struct {
-	repo repository.OrganizationRepository
+	repo    repository.OrganizationRepository
+	history repository.PlanHistoryRepository
+	tx      database.Transactor
 }
 
-func NewOrganizationUseCase(repo repository.OrganizationRepository) *OrganizationUseCase {
-	return &OrganizationUseCase{repo: repo}
+func NewOrganizationUseCase(repo repository.OrganizationRepository, history repository.PlanHistoryRepository, tx database.Transactor) *OrganizationUseCase {
+	return &OrganizationUseCase{repo: repo, history: history, tx: tx}
 }
 
 // Create cria uma nova organização (Geralmente chamado pelo SuperAdmin ou no Sign Up)
@@ -36,8 +42,20 @@ func (uc *OrganizationUseCase) Create(ctx context.Context, input dto.CreateOrgan
 		return nil, err
 	}
 
-	// 3. Persiste no banco
-	if err := uc.repo.Create(ctx, org); err != nil {
+	if input.Trial {
+		if err := org.StartTrial(config.Get().TrialDuration); err != nil {
+			return nil, err
+		}
+	}
+
+	// 3. Persiste no banco, já abrindo o histórico de planos
+	err = uc.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := uc.repo.Create(ctx, org); err != nil {
+			return err
+		}
+		return uc.history.Start(ctx, entity.NewPlanPeriod(org, entity.PlanChangeCreated, org.CreatedAt))
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -55,6 +73,11 @@ func (uc *OrganizationUseCase) GetByID(ctx context.Context, id uuid.UUID) (*dto.
 		return nil, errors.New("organização não encontrada")
 	}
 
+	// O job pode ainda não ter passado: quem consulta nunca vê um teste vencido
+	if _, err := uc.expireTrial(ctx, org); err != nil {
+		return nil, err
+	}
+
 	return uc.toResponse(org), nil
 }
 
@@ -82,6 +105,114 @@ func (uc *OrganizationUseCase) Update(ctx context.Context, id uuid.UUID, input d
 	return uc.toResponse(org), nil
 }
 
+// UpdateSettings altera as políticas de segurança definidas pelo tenant admin
+func (uc *OrganizationUseCase) UpdateSettings(ctx context.Context, id uuid.UUID, input dto.UpdateOrganizationSettingsRequest) (*dto.OrganizationResponse, error) {
+	org, err := uc.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if org == nil {
+		return nil, errors.New("organização não encontrada")
+	}
+
+	if input.RequireTwoFactor != nil {
+		org.SetRequireTwoFactor(*input.RequireTwoFactor)
+	}
+	if input.UnverifiedEmailPolicy != nil {
+		if err := org.SetUnverifiedEmailPolicy(*input.UnverifiedEmailPolicy); err != nil {
+			return nil, err
+		}
+	}
+	if input.PasswordPolicy != nil {
+		if err := org.SetPasswordPolicy(*input.PasswordPolicy); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := uc.repo.Update(ctx, org); err != nil {
+		return nil, err
+	}
+
+	return uc.toResponse(org), nil
+}
+
+// PlanHistory lista os períodos de plano da organização, do mais recente para o mais antigo
+func (uc *OrganizationUseCase) PlanHistory(ctx context.Context, id uuid.UUID) ([]*dto.PlanPeriodResponse, error) {
+	org, err := uc.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if org == nil {
+		return nil, errors.New("organização não encontrada")
+	}
+	if _, err := uc.expireTrial(ctx, org); err != nil {
+		return nil, err
+	}
+
+	periods, err := uc.history.ListByOrganization(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]*dto.PlanPeriodResponse, 0, len(periods))
+	for _, p := range periods {
+		res = append(res, &dto.PlanPeriodResponse{
+			Plan:          p.Plan,
+			Trial:         p.Trial,
+			Limits:        p.Limits,
+			Reason:        p.Reason,
+			ChangedBy:     p.ChangedBy,
+			EffectiveFrom: p.EffectiveFrom,
+			EffectiveTo:   p.EffectiveTo,
+		})
+	}
+	return res, nil
+}
+
+// ExpireTrials rebaixa para o free todas as organizações com teste vencido (job da plataforma).
+// A falha de uma organização é registrada e não impede as demais. Devolve quantas foram rebaixadas.
+func (uc *OrganizationUseCase) ExpireTrials(ctx context.Context, now time.Time) (int, error) {
+	orgs, err := uc.repo.ListExpiredTrials(ctx, now)
+	if err != nil {
+		return 0, err
+	}
+
+	expired := 0
+	for _, org := range orgs {
+		ended, err := uc.expireTrial(ctx, org)
+		if err != nil {
+			slog.ErrorContext(ctx, "Falha ao encerrar período de teste", "organization_id", org.ID, "error", err)
+			continue
+		}
+		if ended {
+			expired++
+		}
+	}
+	return expired, nil
+}
+
+// expireTrial aplica o rebaixamento se o teste já venceu. O novo período começa quando o teste
+// terminou, não quando o rebaixamento foi percebido. Devolve false se outra execução já o encerrou.
+func (uc *OrganizationUseCase) expireTrial(ctx context.Context, org *entity.Organization) (bool, error) {
+	if !org.TrialExpired(time.Now()) {
+		return false, nil
+	}
+
+	endsAt := *org.TrialEndsAt
+	org.ExpireTrial()
+
+	ended := false
+	err := uc.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		ended, err = uc.repo.EndTrial(ctx, org, endsAt)
+		if err != nil || !ended {
+			return err
+		}
+		return uc.history.Start(ctx, entity.NewPlanPeriod(org, entity.PlanChangeTrialExpired, endsAt))
+	})
+	return ended && err == nil, err
+}
+
 // Helper para converter Entity -> Response DTO
 func (uc *OrganizationUseCase) toResponse(org *entity.Organization) *dto.OrganizationResponse {
 	return &dto.OrganizationResponse{
@@ -94,5 +225,7 @@ func (uc *OrganizationUseCase) toResponse(org *entity.Organization) *dto.Organiz
 		Settings:  org.Settings,
 		IsActive:  org.IsActive,
 		CreatedAt: org.CreatedAt,
+
+		TrialEndsAt: org.TrialEndsAt,
 	}
 }
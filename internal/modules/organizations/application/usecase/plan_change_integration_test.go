@@ -0,0 +1,311 @@
+package usecase_test
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/organizations/application/dto"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/organizations/application/usecase"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/organizations/domain/entity"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/organizations/infrastructure/repository"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/config"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/database"
+)
+
+type PlanChangeSuite struct {
+	suite.Suite
+	db           *sql.DB
+	orgUseCase   *usecase.OrganizationUseCase
+	storeUseCase *usecase.StoreUseCase
+	useCase      *usecase.PlanChangeUseCase
+
+	actorID uuid.UUID
+}
+
+func (s *PlanChangeSuite) SetupSuite() {
+	cfg := config.Get()
+	if cfg.DBHost == "localhost" {
+		cfg.DBHost = "127.0.0.1"
+	}
+
+	db, err := database.NewPostgres(cfg)
+	s.Require().NoError(err)
+	s.db = db
+
+	s.orgUseCase = usecase.NewOrganizationUseCase(repository.NewOrganizationRepository(db), repository.NewPlanHistoryRepository(db), database.NewTxManager(db))
+	quota := usecase.NewQuotaUseCase(s.orgUseCase, repository.NewUsageRepository(db))
+	s.storeUseCase = usecase.NewStoreUseCase(repository.NewStoreRepository(db), quota)
+	s.useCase = usecase.NewPlanChangeUseCase(quota, repository.NewPlanChangeRepository(db))
+}
+
+func (s *PlanChangeSuite) SetupTest() {
+	_, err := s.db.Exec("TRUNCATE organizations CASCADE")
+	s.Require().NoError(err)
+	s.actorID = uuid.New()
+}
+
+func (s *PlanChangeSuite) TearDownSuite() {
+	if s.db != nil {
+		s.db.Close()
+	}
+}
+
+// createOrg cria uma organização com "stores" lojas
+func (s *PlanChangeSuite) createOrg(plan entity.OrganizationPlan, stores int) uuid.UUID {
+	org, err := s.orgUseCase.Create(context.Background(), dto.CreateOrganizationRequest{
+		Name: "Mercado", Document: "47960950000121", Slug: "mercado", Sector: entity.SectorSupermarket, Plan: plan,
+	})
+	s.Require().NoError(err)
+
+	for i := 0; i < stores; i++ {
+		_, err := s.storeUseCase.Create(context.Background(), dto.CreateStoreRequest{
+			OrganizationID: org.ID, Name: fmt.Sprintf("Loja %d", i), Code: fmt.Sprintf("LJ-%02d", i),
+		})
+		s.Require().NoError(err)
+	}
+	return org.ID
+}
+
+func (s *PlanChangeSuite) TestDowngrade_RefusedOrScheduledUntilUsageFits() {
+	ctx := context.Background()
+	orgID := s.createOrg(entity.PlanPro, 3)
+
+	// Free = 1 loja: a prévia aponta o excesso
+	preview, err := s.useCase.Preview(ctx, orgID, entity.PlanFree)
+	s.Require().NoError(err)
+	s.False(preview.AllowedNow)
+	s.Equal(dto.LimitImpact{Used: 3, CurrentLimit: 10, NewLimit: 1, Exceeded: true}, preview.Stores)
+	s.False(preview.Users.Exceeded)
+	s.Equal([]entity.LimitViolation{{Resource: entity.QuotaStores, Used: 3, Limit: 1}}, preview.Violations)
+
+	// Troca imediata é recusada e nada muda
+	_, err = s.useCase.ChangePlan(ctx, s.actorID, orgID, dto.ChangePlanRequest{Plan: entity.PlanFree})
+	s.ErrorIs(err, entity.ErrLimitsViolated)
+	org, err := s.orgUseCase.GetByID(ctx, orgID)
+	s.Require().NoError(err)
+	s.Equal(entity.PlanPro, org.Plan)
+
+	// Agendar no passado não vale
+	past := time.Now().Add(-time.Hour)
+	_, err = s.useCase.ChangePlan(ctx, s.actorID, orgID, dto.ChangePlanRequest{Plan: entity.PlanFree, EffectiveAt: &past})
+	s.ErrorIs(err, entity.ErrInvalidEffectiveAt)
+
+	effectiveAt := time.Now().Add(time.Hour)
+	res, err := s.useCase.ChangePlan(ctx, s.actorID, orgID, dto.ChangePlanRequest{Plan: entity.PlanFree, EffectiveAt: &effectiveAt})
+	s.Require().NoError(err)
+	s.False(res.Applied)
+	s.Require().NotNil(res.Scheduled)
+	s.Equal(entity.PlanFree, res.Scheduled.ToPlan)
+	s.Equal(s.actorID, res.Scheduled.RequestedBy)
+	s.Equal(entity.PlanChangePending, res.Scheduled.Status)
+	s.Equal(preview.Violations, res.Violations, "o agendamento mostra o que reduzir até a data")
+
+	// Antes da data o job não mexe
+	applied, err := s.useCase.ApplyScheduled(ctx, time.Now())
+	s.Require().NoError(err)
+	s.Equal(0, applied)
+
+	// O tenant reduz o consumo antes da data e o job aplica
+	_, err = s.db.Exec(`UPDATE stores SET deleted_at = NOW() WHERE organization_id = $1 AND code <> 'LJ-00'`, orgID)
+	s.Require().NoError(err)
+	applied, err = s.useCase.ApplyScheduled(ctx, effectiveAt.Add(time.Minute))
+	s.Require().NoError(err)
+	s.Equal(1, applied)
+
+	org, err = s.orgUseCase.GetByID(ctx, orgID)
+	s.Require().NoError(err)
+	s.Equal(entity.PlanFree, org.Plan)
+	s.Equal(1, org.Settings.MaxStores)
+
+	_, err = s.useCase.Scheduled(ctx, orgID)
+	s.ErrorIs(err, entity.ErrNoScheduledPlanChange)
+
+	history, err := s.orgUseCase.PlanHistory(ctx, orgID)
+	s.Require().NoError(err)
+	s.Require().Len(history, 2)
+	s.Equal(entity.PlanChangeScheduled, history[0].Reason)
+	s.Equal(entity.PlanFree, history[0].Plan)
+	s.Require().NotNil(history[0].ChangedBy)
+	s.Equal(s.actorID, *history[0].ChangedBy, "quem agendou responde pela troca")
+	s.Equal(entity.LimitsFor(entity.PlanFree), history[0].Limits)
+}
+
+func (s *PlanChangeSuite) TestScheduledDowngrade_BlockedWhenUsageStillExceedsOnDate() {
+	ctx := context.Background()
+	orgID := s.createOrg(entity.PlanPro, 3)
+
+	effectiveAt := time.Now().Add(time.Hour)
+	res, err := s.useCase.ChangePlan(ctx, s.actorID, orgID, dto.ChangePlanRequest{Plan: entity.PlanFree, EffectiveAt: &effectiveAt})
+	s.Require().NoError(err)
+
+	// Na data o consumo ainda não cabe: a troca fica bloqueada com os excessos, sem nova tentativa
+	applied, err := s.useCase.ApplyScheduled(ctx, effectiveAt.Add(time.Minute))
+	s.Require().NoError(err)
+	s.Equal(0, applied)
+
+	blocked, err := s.useCase.Scheduled(ctx, orgID)
+	s.Require().NoError(err)
+	s.Equal(res.Scheduled.ID, blocked.ID)
+	s.Equal(entity.PlanChangeBlocked, blocked.Status)
+	s.Equal([]entity.LimitViolation{{Resource: entity.QuotaStores, Used: 3, Limit: 1}}, blocked.Violations)
+
+	preview, err := s.useCase.Preview(ctx, orgID, entity.PlanFree)
+	s.Require().NoError(err)
+	s.Require().NotNil(preview.Scheduled)
+	s.Equal(entity.PlanChangeBlocked, preview.Scheduled.Status)
+
+	// Um novo agendamento substitui a bloqueada
+	later := time.Now().Add(2 * time.Hour)
+	res, err = s.useCase.ChangePlan(ctx, s.actorID, orgID, dto.ChangePlanRequest{Plan: entity.PlanFree, EffectiveAt: &later})
+	s.Require().NoError(err)
+	s.Equal(blocked.Violations, res.Violations)
+	scheduled, err := s.useCase.Scheduled(ctx, orgID)
+	s.Require().NoError(err)
+	s.Equal(res.Scheduled.ID, scheduled.ID)
+	s.Equal(entity.PlanChangePending, scheduled.Status)
+	s.Empty(scheduled.Violations)
+
+	// Bloqueada de novo, o tenant descarta
+	_, err = s.useCase.ApplyScheduled(ctx, later.Add(time.Minute))
+	s.Require().NoError(err)
+	scheduled, err = s.useCase.Scheduled(ctx, orgID)
+	s.Require().NoError(err)
+	s.Equal(entity.PlanChangeBlocked, scheduled.Status)
+	s.Require().NoError(s.useCase.CancelScheduled(ctx, s.actorID, orgID))
+	_, err = s.useCase.Scheduled(ctx, orgID)
+	s.ErrorIs(err, entity.ErrNoScheduledPlanChange)
+
+	// Reduzir o consumo depois não ressuscita troca vencida
+	_, err = s.db.Exec(`UPDATE stores SET deleted_at = NOW() WHERE organization_id = $1 AND code <> 'LJ-00'`, orgID)
+	s.Require().NoError(err)
+	applied, err = s.useCase.ApplyScheduled(ctx, later.Add(time.Hour))
+	s.Require().NoError(err)
+	s.Equal(0, applied)
+	org, err := s.orgUseCase.GetByID(ctx, orgID)
+	s.Require().NoError(err)
+	s.Equal(entity.PlanPro, org.Plan)
+}
+
+func (s *PlanChangeSuite) TestChangePlan_KeepsLimitOverridesAndRecordsActor() {
+	ctx := context.Background()
+	orgID := s.createOrg(entity.PlanPro, 2)
+
+	// Exceção abaixo do consumo é recusada
+	_, err := s.useCase.SetLimits(ctx, s.actorID, orgID, dto.UpdateLimitsRequest{MaxStores: 1})
+	s.ErrorIs(err, entity.ErrLimitsViolated)
+
+	org, err := s.useCase.SetLimits(ctx, s.actorID, orgID, dto.UpdateLimitsRequest{MaxStores: 25})
+	s.Require().NoError(err)
+	s.Equal(25, org.Settings.MaxStores)
+	s.Equal(10, org.Settings.MaxUsers)
+
+	// A exceção sobrevive às trocas de plano; o resto segue o plano
+	res, err := s.useCase.ChangePlanAsPlatform(ctx, s.actorID, orgID, dto.ChangePlanRequest{Plan: entity.PlanEnterprise})
+	s.Require().NoError(err)
+	s.True(res.Applied)
+	s.Equal(25, res.Organization.Settings.MaxStores)
+	s.Equal(9999, res.Organization.Settings.MaxUsers)
+
+	res, err = s.useCase.ChangePlanAsPlatform(ctx, s.actorID, orgID, dto.ChangePlanRequest{Plan: entity.PlanFree})
+	s.Require().NoError(err)
+	s.Equal(25, res.Organization.Settings.MaxStores, "loja extra negociada não se perde no rebaixamento")
+	s.Equal(2, res.Organization.Settings.MaxUsers)
+
+	// Remover a exceção volta ao padrão do plano
+	org, err = s.useCase.SetLimits(ctx, s.actorID, orgID, dto.UpdateLimitsRequest{})
+	s.ErrorIs(err, entity.ErrLimitsViolated, "2 lojas não cabem no padrão do free")
+	s.Nil(org)
+
+	history, err := s.orgUseCase.PlanHistory(ctx, orgID)
+	s.Require().NoError(err)
+	s.Require().Len(history, 4)
+	reasons := []entity.PlanChangeReason{history[0].Reason, history[1].Reason, history[2].Reason, history[3].Reason}
+	s.Equal([]entity.PlanChangeReason{entity.PlanChangeRequested, entity.PlanChangeRequested, entity.PlanChangeLimits, entity.PlanChangeCreated}, reasons)
+	for _, p := range history[:3] {
+		s.Require().NotNil(p.ChangedBy)
+		s.Equal(s.actorID, *p.ChangedBy)
+	}
+	s.Nil(history[3].ChangedBy, "criação é do sistema")
+	s.Equal(25, history[2].Limits.MaxStores)
+}
+
+func (s *PlanChangeSuite) TestChangePlan_SelfServiceRules() {
+	ctx := context.Background()
+	orgID := s.createOrg(entity.PlanFree, 0)
+
+	_, err := s.useCase.ChangePlan(ctx, s.actorID, orgID, dto.ChangePlanRequest{Plan: entity.PlanEnterprise})
+	s.ErrorIs(err, entity.ErrPlanNotSelfService)
+
+	_, err = s.useCase.ChangePlan(ctx, s.actorID, orgID, dto.ChangePlanRequest{Plan: entity.PlanFree})
+	s.ErrorIs(err, entity.ErrSamePlan)
+
+	// Contratar depende de cobrança: nem imediato, nem agendado
+	later := time.Now().Add(24 * time.Hour)
+	_, err = s.useCase.ChangePlan(ctx, s.actorID, orgID, dto.ChangePlanRequest{Plan: entity.PlanPro})
+	s.ErrorIs(err, entity.ErrUpgradeNotSelfService)
+	_, err = s.useCase.ChangePlan(ctx, s.actorID, orgID, dto.ChangePlanRequest{Plan: entity.PlanPro, EffectiveAt: &later})
+	s.ErrorIs(err, entity.ErrUpgradeNotSelfService)
+
+	_, err = s.useCase.ChangePlanAsPlatform(ctx, s.actorID, orgID, dto.ChangePlanRequest{Plan: entity.PlanPro})
+	s.Require().NoError(err)
+
+	// Um agendamento pendente é substituído pela troca imediata
+	_, err = s.useCase.ChangePlan(ctx, s.actorID, orgID, dto.ChangePlanRequest{Plan: entity.PlanFree, EffectiveAt: &later})
+	s.Require().NoError(err)
+
+	res, err := s.useCase.ChangePlan(ctx, s.actorID, orgID, dto.ChangePlanRequest{Plan: entity.PlanFree})
+	s.Require().NoError(err)
+	s.True(res.Applied)
+	s.Equal(entity.PlanFree, res.Organization.Plan)
+	_, err = s.useCase.Scheduled(ctx, orgID)
+	s.ErrorIs(err, entity.ErrNoScheduledPlanChange)
+
+	// Enterprise não sai por self-service
+	_, err = s.useCase.ChangePlanAsPlatform(ctx, s.actorID, orgID, dto.ChangePlanRequest{Plan: entity.PlanEnterprise})
+	s.Require().NoError(err)
+	_, err = s.useCase.ChangePlan(ctx, s.actorID, orgID, dto.ChangePlanRequest{Plan: entity.PlanPro})
+	s.ErrorIs(err, entity.ErrPlanNotSelfService)
+}
+
+func (s *PlanChangeSuite) TestChangePlan_TrialCannotBecomePermanentProBySelfService() {
+	ctx := context.Background()
+	trial, err := s.orgUseCase.Create(ctx, dto.CreateOrganizationRequest{
+		Name: "Em Teste", Document: "15436940000103", Slug: "em-teste", Sector: entity.SectorRetail, Plan: entity.PlanPro, Trial: true,
+	})
+	s.Require().NoError(err)
+	s.Require().NotNil(trial.TrialEndsAt)
+
+	// Efetivar o teste pelo próprio tenant é recusado e o teste continua correndo
+	_, err = s.useCase.ChangePlan(ctx, s.actorID, trial.ID, dto.ChangePlanRequest{Plan: entity.PlanPro})
+	s.ErrorIs(err, entity.ErrUpgradeNotSelfService)
+	org, err := s.orgUseCase.GetByID(ctx, trial.ID)
+	s.Require().NoError(err)
+	s.Equal(entity.PlanPro, org.Plan)
+	s.Require().NotNil(org.TrialEndsAt, "o rebaixamento automático do fim do teste continua valendo")
+
+	// Cancelar o teste (voltar ao free) é self-service
+	res, err := s.useCase.ChangePlan(ctx, s.actorID, trial.ID, dto.ChangePlanRequest{Plan: entity.PlanFree})
+	s.Require().NoError(err)
+	s.Equal(entity.PlanFree, res.Organization.Plan)
+	s.Nil(res.Organization.TrialEndsAt)
+
+	// Só a plataforma (ou a cobrança) efetiva um teste
+	other, err := s.orgUseCase.Create(ctx, dto.CreateOrganizationRequest{
+		Name: "Contratou", Document: "13347016000117", Slug: "contratou", Sector: entity.SectorRetail, Plan: entity.PlanPro, Trial: true,
+	})
+	s.Require().NoError(err)
+	res, err = s.useCase.ChangePlanAsPlatform(ctx, s.actorID, other.ID, dto.ChangePlanRequest{Plan: entity.PlanPro})
+	s.Require().NoError(err)
+	s.Equal(entity.PlanPro, res.Organization.Plan)
+	s.Nil(res.Organization.TrialEndsAt)
+}
+
+func TestPlanChangeSuite(t *testing.T) {
+	suite.Run(t, new(PlanChangeSuite))
+}
@@ -0,0 +1,326 @@
+package usecase
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/organizations/application/dto"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/organizations/domain/entity"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/organizations/domain/repository"
+)
+
+// PlanChangeUseCase troca o plano e as exceções de cota da organização sem deixar o consumo
+// acima dos novos limites: rebaixamento que não cabe é recusado ou agendado.
+type PlanChangeUseCase struct {
+	quota   *QuotaUseCase
+	changes repository.PlanChangeRepository
+}
+
+func NewPlanChangeUseCase(quota *QuotaUseCase, changes repository.PlanChangeRepository) *PlanChangeUseCase {
+	return &PlanChangeUseCase{quota: quota, changes: changes}
+}
+
+// Preview mostra como ficariam os limites no plano pretendido frente ao consumo atual
+func (uc *PlanChangeUseCase) Preview(ctx context.Context, orgID uuid.UUID, plan entity.OrganizationPlan) (*dto.PlanChangePreviewResponse, error) {
+	if !entity.IsValidPlan(plan) {
+		return nil, entity.ErrInvalidPlan
+	}
+
+	org, err := uc.quota.organization(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	usage, err := uc.quota.usage.Usage(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	scheduled, err := uc.changes.GetOpen(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	limits := org.LimitsOn(plan)
+	impact := func(resource entity.QuotaResource) dto.LimitImpact {
+		used, newLimit := usage.Of(resource), limits.Of(resource)
+		return dto.LimitImpact{Used: used, CurrentLimit: org.Limit(resource), NewLimit: newLimit, Exceeded: used > newLimit}
+	}
+	violations := limits.Violations(*usage)
+	if violations == nil {
+		violations = []entity.LimitViolation{}
+	}
+
+	return &dto.PlanChangePreviewResponse{
+		OrganizationID: org.ID,
+		CurrentPlan:    org.Plan,
+		TargetPlan:     plan,
+		Users:          impact(entity.QuotaUsers),
+		Devices:        impact(entity.QuotaDevices),
+		Stores:         impact(entity.QuotaStores),
+		Violations:     violations,
+		AllowedNow:     len(violations) == 0,
+		Scheduled:      toScheduledResponse(scheduled),
+	}, nil
+}
+
+// ChangePlan é a troca self-service do tenant: só rebaixamento, inclusive cancelar o teste do pro
+// (contratação e enterprise passam pelo comercial)
+func (uc *PlanChangeUseCase) ChangePlan(ctx context.Context, actorID, orgID uuid.UUID, input dto.ChangePlanRequest) (*dto.PlanChangeResponse, error) {
+	return uc.changePlan(ctx, actorID, orgID, input, true)
+}
+
+// ChangePlanAsPlatform é a troca feita pela plataforma (ou pela cobrança): qualquer plano, inclusive
+// efetivar um teste, com as mesmas travas de consumo
+func (uc *PlanChangeUseCase) ChangePlanAsPlatform(ctx context.Context, actorID, orgID uuid.UUID, input dto.ChangePlanRequest) (*dto.PlanChangeResponse, error) {
+	return uc.changePlan(ctx, actorID, orgID, input, false)
+}
+
+func (uc *PlanChangeUseCase) changePlan(ctx context.Context, actorID, orgID uuid.UUID, input dto.ChangePlanRequest, selfService bool) (*dto.PlanChangeResponse, error) {
+	if !entity.IsValidPlan(input.Plan) {
+		return nil, entity.ErrInvalidPlan
+	}
+	if _, err := uc.quota.organization(ctx, orgID); err != nil {
+		return nil, err
+	}
+
+	var res *dto.PlanChangeResponse
+	err := uc.quota.orgs.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		// Trava a organização: nenhuma criação com cota passa entre a conferência e a troca
+		if err := uc.quota.usage.LockOrganization(ctx, orgID); err != nil {
+			return err
+		}
+		org, err := uc.quota.organization(ctx, orgID)
+		if err != nil {
+			return err
+		}
+
+		if selfService && (input.Plan == entity.PlanEnterprise || org.Plan == entity.PlanEnterprise) {
+			return entity.ErrPlanNotSelfService
+		}
+		// Mesmo plano só faz sentido para efetivar um teste
+		if input.Plan == org.Plan && org.TrialEndsAt == nil {
+			return entity.ErrSamePlan
+		}
+		// Sem cobrança, o tenant só desce de plano (o que também encerra o teste). Subir ou efetivar
+		// o teste no mesmo plano fica com a plataforma.
+		if selfService && !entity.IsDowngrade(org.Plan, input.Plan) {
+			return entity.ErrUpgradeNotSelfService
+		}
+
+		usage, err := uc.quota.usage.Usage(ctx, orgID)
+		if err != nil {
+			return err
+		}
+		violations := org.LimitsOn(input.Plan).Violations(*usage)
+
+		if input.EffectiveAt != nil {
+			change, err := entity.NewScheduledPlanChange(org, input.Plan, actorID, *input.EffectiveAt)
+			if err != nil {
+				return err
+			}
+			if err := uc.changes.Schedule(ctx, change); err != nil {
+				return err
+			}
+			res = &dto.PlanChangeResponse{Organization: uc.quota.orgs.toResponse(org), Scheduled: toScheduledResponse(change), Violations: violations}
+			return nil
+		}
+
+		if len(violations) > 0 {
+			return &entity.LimitsViolatedError{Plan: input.Plan, Violations: violations}
+		}
+
+		org.ChangePlan(input.Plan)
+		if err := uc.quota.orgs.repo.Update(ctx, org); err != nil {
+			return err
+		}
+		if err := uc.quota.orgs.history.Start(ctx, entity.NewPlanPeriod(org, entity.PlanChangeRequested, org.UpdatedAt).By(actorID)); err != nil {
+			return err
+		}
+		// A troca imediata substitui qualquer agendamento
+		if _, err := uc.cancelOpen(ctx, actorID, orgID); err != nil {
+			return err
+		}
+
+		res = &dto.PlanChangeResponse{Applied: true, Organization: uc.quota.orgs.toResponse(org)}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// Scheduled devolve a troca agendada pendente ou a que ficou bloqueada na data
+func (uc *PlanChangeUseCase) Scheduled(ctx context.Context, orgID uuid.UUID) (*dto.ScheduledChangeResponse, error) {
+	if _, err := uc.quota.organization(ctx, orgID); err != nil {
+		return nil, err
+	}
+	change, err := uc.changes.GetOpen(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if change == nil {
+		return nil, entity.ErrNoScheduledPlanChange
+	}
+	return toScheduledResponse(change), nil
+}
+
+// CancelScheduled cancela a troca agendada pendente (ou descarta a bloqueada)
+func (uc *PlanChangeUseCase) CancelScheduled(ctx context.Context, actorID, orgID uuid.UUID) error {
+	if _, err := uc.quota.organization(ctx, orgID); err != nil {
+		return err
+	}
+	canceled, err := uc.cancelOpen(ctx, actorID, orgID)
+	if err != nil {
+		return err
+	}
+	if !canceled {
+		return entity.ErrNoScheduledPlanChange
+	}
+	return nil
+}
+
+// SetLimits substitui as exceções de cota do tenant (só a plataforma). Assim como no rebaixamento,
+// limites abaixo do consumo atual são recusados.
+func (uc *PlanChangeUseCase) SetLimits(ctx context.Context, actorID, orgID uuid.UUID, input dto.UpdateLimitsRequest) (*dto.OrganizationResponse, error) {
+	if _, err := uc.quota.organization(ctx, orgID); err != nil {
+		return nil, err
+	}
+
+	var res *dto.OrganizationResponse
+	err := uc.quota.orgs.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := uc.quota.usage.LockOrganization(ctx, orgID); err != nil {
+			return err
+		}
+		org, err := uc.quota.organization(ctx, orgID)
+		if err != nil {
+			return err
+		}
+
+		if err := org.SetLimitOverrides(entity.PlanLimits{MaxUsers: input.MaxUsers, MaxDevices: input.MaxDevices, MaxStores: input.MaxStores}); err != nil {
+			return err
+		}
+		usage, err := uc.quota.usage.Usage(ctx, orgID)
+		if err != nil {
+			return err
+		}
+		if violations := org.LimitsOn(org.Plan).Violations(*usage); len(violations) > 0 {
+			return &entity.LimitsViolatedError{Plan: org.Plan, Violations: violations}
+		}
+
+		if err := uc.quota.orgs.repo.Update(ctx, org); err != nil {
+			return err
+		}
+		if err := uc.quota.orgs.history.Start(ctx, entity.NewPlanPeriod(org, entity.PlanChangeLimits, org.UpdatedAt).By(actorID)); err != nil {
+			return err
+		}
+
+		res = uc.quota.orgs.toResponse(org)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// ApplyScheduled aplica as trocas agendadas já vencidas (job da plataforma). Troca cujo consumo
+// ainda não cabe nos novos limites fica bloqueada com os excessos, visível ao tenant, e não é
+// tentada de novo; a falha de uma organização é registrada e não impede as demais. Devolve
+// quantas foram aplicadas.
+func (uc *PlanChangeUseCase) ApplyScheduled(ctx context.Context, now time.Time) (int, error) {
+	due, err := uc.changes.ListDue(ctx, now)
+	if err != nil {
+		return 0, err
+	}
+
+	applied := 0
+	for _, change := range due {
+		ok, err := uc.applyScheduled(ctx, change)
+		if err != nil {
+			slog.ErrorContext(ctx, "Falha ao aplicar troca de plano agendada",
+				"organization_id", change.OrganizationID, "change_id", change.ID, "error", err)
+			continue
+		}
+		if ok {
+			applied++
+		}
+	}
+	return applied, nil
+}
+
+func (uc *PlanChangeUseCase) applyScheduled(ctx context.Context, change *entity.ScheduledPlanChange) (bool, error) {
+	applied := false
+	err := uc.quota.orgs.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := uc.quota.usage.LockOrganization(ctx, change.OrganizationID); err != nil {
+			return err
+		}
+		org, err := uc.quota.organization(ctx, change.OrganizationID)
+		if err != nil {
+			return err
+		}
+
+		usage, err := uc.quota.usage.Usage(ctx, org.ID)
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		if violations := org.LimitsOn(change.ToPlan).Violations(*usage); len(violations) > 0 {
+			change.Block(violations, now)
+			if _, err := uc.changes.Resolve(ctx, change); err != nil {
+				return err
+			}
+			slog.WarnContext(ctx, "Troca de plano agendada bloqueada: consumo acima dos novos limites",
+				"organization_id", org.ID, "change_id", change.ID, "to_plan", change.ToPlan)
+			return nil
+		}
+
+		change.Status, change.ResolvedAt = entity.PlanChangeApplied, &now
+		resolved, err := uc.changes.Resolve(ctx, change)
+		if err != nil || !resolved {
+			return err
+		}
+
+		// O plano pode já ter mudado por outro caminho (ex.: fim do teste para o free)
+		if org.Plan != change.ToPlan || org.TrialEndsAt != nil {
+			org.ChangePlan(change.ToPlan)
+			if err := uc.quota.orgs.repo.Update(ctx, org); err != nil {
+				return err
+			}
+			if err := uc.quota.orgs.history.Start(ctx, entity.NewPlanPeriod(org, entity.PlanChangeScheduled, now).By(change.RequestedBy)); err != nil {
+				return err
+			}
+		}
+		applied = true
+		return nil
+	})
+	return applied, err
+}
+
+// cancelOpen cancela a troca pendente ou bloqueada, se houver
+func (uc *PlanChangeUseCase) cancelOpen(ctx context.Context, actorID, orgID uuid.UUID) (bool, error) {
+	change, err := uc.changes.GetOpen(ctx, orgID)
+	if err != nil || change == nil {
+		return false, err
+	}
+	now := time.Now()
+	change.Status, change.ResolvedBy, change.ResolvedAt = entity.PlanChangeCanceled, &actorID, &now
+	return uc.changes.Resolve(ctx, change)
+}
+
+func toScheduledResponse(c *entity.ScheduledPlanChange) *dto.ScheduledChangeResponse {
+	if c == nil {
+		return nil
+	}
+	return &dto.ScheduledChangeResponse{
+		ID:          c.ID,
+		FromPlan:    c.FromPlan,
+		ToPlan:      c.ToPlan,
+		RequestedBy: c.RequestedBy,
+		EffectiveAt: c.EffectiveAt,
+		Status:      c.Status,
+		Violations:  c.Violations,
+		CreatedAt:   c.CreatedAt,
+	}
+}
@@ -0,0 +1,86 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/organizations/application/dto"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/organizations/domain/entity"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/organizations/domain/repository"
+)
+
+// QuotaUseCase aplica as cotas do plano (usuários, aparelhos, lojas) em quem cria esses recursos
+type QuotaUseCase struct {
+	orgs  *OrganizationUseCase
+	usage repository.UsageRepository
+}
+
+func NewQuotaUseCase(orgs *OrganizationUseCase, usage repository.UsageRepository) *QuotaUseCase {
+	return &QuotaUseCase{orgs: orgs, usage: usage}
+}
+
+// Reserve executa "create" só se a organização ainda tiver cota do recurso.
+// A conferência e a criação rodam na mesma transação, com a organização travada: criações
+// simultâneas esperam umas pelas outras e nunca passam do limite.
+func (uc *QuotaUseCase) Reserve(ctx context.Context, orgID uuid.UUID, resource entity.QuotaResource, create func(ctx context.Context) error) error {
+	return uc.orgs.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := uc.usage.LockOrganization(ctx, orgID); err != nil {
+			return err
+		}
+
+		org, err := uc.organization(ctx, orgID)
+		if err != nil {
+			return err
+		}
+
+		used, err := uc.usage.Count(ctx, orgID, resource)
+		if err != nil {
+			return err
+		}
+		if limit := org.Limit(resource); used >= limit {
+			return &entity.QuotaExceededError{Resource: resource, Plan: org.Plan, Limit: limit}
+		}
+
+		return create(ctx)
+	})
+}
+
+// Usage mostra o consumo atual de cada recurso frente aos limites do plano
+func (uc *QuotaUseCase) Usage(ctx context.Context, orgID uuid.UUID) (*dto.UsageResponse, error) {
+	org, err := uc.organization(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	usage, err := uc.usage.Usage(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	quota := func(resource entity.QuotaResource) dto.QuotaUsage {
+		return dto.QuotaUsage{Used: usage.Of(resource), Limit: org.Limit(resource)}
+	}
+	return &dto.UsageResponse{
+		OrganizationID: org.ID,
+		Plan:           org.Plan,
+		TrialEndsAt:    org.TrialEndsAt,
+		Users:          quota(entity.QuotaUsers),
+		Devices:        quota(entity.QuotaDevices),
+		Stores:         quota(entity.QuotaStores),
+	}, nil
+}
+
+// organization carrega a organização já com o teste vencido aplicado (limites do free)
+func (uc *QuotaUseCase) organization(ctx context.Context, orgID uuid.UUID) (*entity.Organization, error) {
+	org, err := uc.orgs.repo.GetByID(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if org == nil {
+		return nil, ErrOrganizationNotFound
+	}
+	if _, err := uc.orgs.expireTrial(ctx, org); err != nil {
+		return nil, err
+	}
+	return org, nil
+}
@@ -0,0 +1,115 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/organizations/application/dto"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/organizations/domain/entity"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/organizations/domain/repository"
+)
+
+var (
+	ErrOrganizationNotFound   = errors.New("organização não encontrada")
+	ErrSSONotConfigured       = errors.New("login corporativo não configurado")
+	ErrSSORequiresEnterprise  = errors.New("login corporativo disponível apenas no plano enterprise")
+	ErrSSOInsecureIssuer      = errors.New("o issuer precisa usar https")
+	ErrSSOClientSecretMissing = errors.New("client_secret é obrigatório na primeira configuração")
+)
+
+// SSOConfigUseCase administra o login corporativo (OIDC) da organização
+type SSOConfigUseCase struct {
+	orgRepo repository.OrganizationRepository
+	ssoRepo repository.SSOConfigRepository
+}
+
+func NewSSOConfigUseCase(orgRepo repository.OrganizationRepository, ssoRepo repository.SSOConfigRepository) *SSOConfigUseCase {
+	return &SSOConfigUseCase{orgRepo: orgRepo, ssoRepo: ssoRepo}
+}
+
+// Get devolve a configuração atual
+func (uc *SSOConfigUseCase) Get(ctx context.Context, orgID uuid.UUID) (*dto.SSOConfigResponse, error) {
+	cfg, err := uc.ssoRepo.GetByOrganization(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if cfg == nil {
+		return nil, ErrSSONotConfigured
+	}
+	return toSSOConfigResponse(cfg), nil
+}
+
+// Update cria ou altera a configuração (apenas plano enterprise)
+func (uc *SSOConfigUseCase) Update(ctx context.Context, orgID uuid.UUID, input dto.UpdateSSOConfigRequest) (*dto.SSOConfigResponse, error) {
+	org, err := uc.orgRepo.GetByID(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if org == nil {
+		return nil, ErrOrganizationNotFound
+	}
+	if org.Plan != entity.PlanEnterprise {
+		return nil, ErrSSORequiresEnterprise
+	}
+	if !isSecureIssuer(input.Issuer) {
+		return nil, ErrSSOInsecureIssuer
+	}
+
+	cfg, err := uc.ssoRepo.GetByOrganization(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	if cfg == nil {
+		if input.ClientSecret == "" {
+			return nil, ErrSSOClientSecretMissing
+		}
+		cfg = &entity.SSOConfig{OrganizationID: orgID, CreatedAt: now}
+	}
+
+	cfg.Issuer = input.Issuer
+	cfg.ClientID = input.ClientID
+	if input.ClientSecret != "" {
+		cfg.ClientSecret = input.ClientSecret
+	}
+	cfg.AllowedDomains = entity.NormalizeDomains(input.AllowedDomains)
+	cfg.DefaultRole = input.DefaultRole
+	cfg.Enabled = input.Enabled
+	cfg.UpdatedAt = now
+
+	if err := uc.ssoRepo.Upsert(ctx, cfg); err != nil {
+		return nil, err
+	}
+	return toSSOConfigResponse(cfg), nil
+}
+
+// isSecureIssuer exige https (http só para provedores locais, em desenvolvimento/testes)
+func isSecureIssuer(issuer string) bool {
+	u, err := url.Parse(issuer)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	if u.Scheme == "https" {
+		return true
+	}
+	host := u.Hostname()
+	ip := net.ParseIP(host)
+	return u.Scheme == "http" && (host == "localhost" || (ip != nil && ip.IsLoopback()))
+}
+
+func toSSOConfigResponse(cfg *entity.SSOConfig) *dto.SSOConfigResponse {
+	return &dto.SSOConfigResponse{
+		OrganizationID:  cfg.OrganizationID,
+		Issuer:          cfg.Issuer,
+		ClientID:        cfg.ClientID,
+		HasClientSecret: cfg.ClientSecret != "",
+		AllowedDomains:  cfg.AllowedDomains,
+		DefaultRole:     cfg.DefaultRole,
+		Enabled:         cfg.Enabled,
+		UpdatedAt:       cfg.UpdatedAt,
+	}
+}
@@ -3,6 +3,9 @@ package usecase_test
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
 	"testing"
 
 	"github.com/google/uuid"
@@ -37,10 +40,10 @@ func (s *StoreSuite) SetupSuite() {
 
 	// Inicializa Repos e UseCases
 	orgRepo := repository.NewOrganizationRepository(db)
-	s.orgUseCase = usecase.NewOrganizationUseCase(orgRepo)
+	s.orgUseCase = usecase.NewOrganizationUseCase(orgRepo, repository.NewPlanHistoryRepository(db), database.NewTxManager(db))
 
 	storeRepo := repository.NewStoreRepository(db)
-	s.storeUseCase = usecase.NewStoreUseCase(storeRepo)
+	s.storeUseCase = usecase.NewStoreUseCase(storeRepo, usecase.NewQuotaUseCase(s.orgUseCase, repository.NewUsageRepository(db)))
 
 	s.db = db
 }
@@ -161,6 +164,57 @@ func (s *StoreSuite) TestCreateStore_SameCodeDifferentOrg() {
 	s.Equal("MATRIZ", res.Code)
 }
 
+func (s *StoreSuite) TestCreateStore_ConcurrentCreationsRespectQuota() {
+	// Free = 1 loja
+	org, err := s.orgUseCase.Create(context.Background(), dto.CreateOrganizationRequest{
+		Name:     "Mercado Free",
+		Document: "45543915000181",
+		Slug:     "mercado-free",
+		Sector:   entity.SectorSupermarket,
+		Plan:     entity.PlanFree,
+	})
+	s.Require().NoError(err)
+
+	const attempts = 6
+	var wg sync.WaitGroup
+	errs := make(chan error, attempts)
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, err := s.storeUseCase.Create(context.Background(), dto.CreateStoreRequest{
+				OrganizationID: org.ID,
+				Name:           fmt.Sprintf("Loja %d", i),
+				Code:           fmt.Sprintf("LJ-%02d", i),
+			})
+			errs <- err
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+
+	created, exceeded := 0, 0
+	for err := range errs {
+		switch {
+		case err == nil:
+			created++
+		case errors.Is(err, entity.ErrQuotaExceeded):
+			exceeded++
+		default:
+			s.Fail("erro inesperado", err.Error())
+		}
+	}
+	s.Equal(1, created)
+	s.Equal(attempts-1, exceeded)
+
+	// Loja excluída libera a cota
+	var storeID uuid.UUID
+	s.Require().NoError(s.db.QueryRow(`SELECT id FROM stores WHERE organization_id = $1`, org.ID).Scan(&storeID))
+	s.Require().NoError(s.storeUseCase.Delete(context.Background(), storeID))
+	_, err = s.storeUseCase.Create(context.Background(), dto.CreateStoreRequest{OrganizationID: org.ID, Name: "Nova", Code: "LJ-NOVA"})
+	s.NoError(err)
+}
+
 func TestStoreSuite(t *testing.T) {
 	suite.Run(t, new(StoreSuite))
 }
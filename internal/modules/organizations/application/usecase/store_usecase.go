@@ -10,19 +10,21 @@ import (
 	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/organizations/domain/entity"
 	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/organizations/domain/repository"
 	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/pagination" // Importe o pacote de paginação
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/tenant"
 )
 
 type StoreUseCase struct {
-	repo repository.StoreRepository
+	repo  repository.StoreRepository
+	quota *QuotaUseCase
 }
 
-func NewStoreUseCase(repo repository.StoreRepository) *StoreUseCase {
-	return &StoreUseCase{repo: repo}
+func NewStoreUseCase(repo repository.StoreRepository, quota *QuotaUseCase) *StoreUseCase {
+	return &StoreUseCase{repo: repo, quota: quota}
 }
 
 func (uc *StoreUseCase) Create(ctx context.Context, input dto.CreateStoreRequest) (*dto.StoreResponse, error) {
 	// 1. Cria a Entidade
-	store, err := entity.NewStore(input.OrganizationID, input.Name, input.Code, input.Timezone)
+	store, err := entity.NewStore(tenant.Resolve(ctx, input.OrganizationID), input.Name, input.Code, input.Timezone)
 	if err != nil {
 		return nil, err
 	}
@@ -38,8 +40,11 @@ func (uc *StoreUseCase) Create(ctx context.Context, input dto.CreateStoreRequest
 		ZipCode:    input.Address.ZipCode,
 	})
 
-	// 3. Persiste no Banco
-	if err := uc.repo.Create(ctx, store); err != nil {
+	// 3. Persiste no Banco, dentro da cota de lojas do plano
+	err = uc.quota.Reserve(ctx, store.OrganizationID, entity.QuotaStores, func(ctx context.Context) error {
+		return uc.repo.Create(ctx, store)
+	})
+	if err != nil {
 		if strings.Contains(err.Error(), "uq_stores_org_code") {
 			return nil, errors.New("já existe uma loja com este código nesta organização")
 		}
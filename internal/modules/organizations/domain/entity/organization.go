@@ -19,6 +19,28 @@ const (
 	PlanEnterprise OrganizationPlan = "enterprise"
 )
 
+// ErrTrialNotAllowed indica pedido de período de teste fora do plano pro
+var ErrTrialNotAllowed = errors.New("período de teste só está disponível no plano pro")
+
+// ErrInvalidLimitOverride indica exceção de cota negativa
+var ErrInvalidLimitOverride = errors.New("limites personalizados não podem ser negativos")
+
+// IsValidPlan indica se o plano existe no catálogo
+func IsValidPlan(p OrganizationPlan) bool {
+	switch p {
+	case PlanFree, PlanPro, PlanEnterprise:
+		return true
+	}
+	return false
+}
+
+var planRank = map[OrganizationPlan]int{PlanFree: 0, PlanPro: 1, PlanEnterprise: 2}
+
+// IsDowngrade indica se "to" é um plano abaixo de "from"
+func IsDowngrade(from, to OrganizationPlan) bool {
+	return planRank[to] < planRank[from]
+}
+
 type OrganizationSector string
 
 const (
@@ -29,22 +51,94 @@ const (
 	SectorOther       OrganizationSector = "other"
 )
 
+// UnverifiedEmailPolicy define o que acontece com quem ainda não confirmou o email
+type UnverifiedEmailPolicy string
+
+const (
+	UnverifiedEmailAllow    UnverifiedEmailPolicy = "allow"     // Acesso normal (padrão)
+	UnverifiedEmailBlock    UnverifiedEmailPolicy = "block"     // Login bloqueado até confirmar
+	UnverifiedEmailReadOnly UnverifiedEmailPolicy = "read_only" // Login liberado, mas só leitura
+)
+
+// PlanLimits são as cotas padrão de cada plano
+type PlanLimits struct {
+	MaxUsers   int `json:"max_users"`
+	MaxDevices int `json:"max_devices"`
+	MaxStores  int `json:"max_stores"`
+}
+
+var planLimits = map[OrganizationPlan]PlanLimits{
+	PlanFree:       {MaxUsers: 2, MaxDevices: 10, MaxStores: 1},
+	PlanPro:        {MaxUsers: 10, MaxDevices: 500, MaxStores: 10},
+	PlanEnterprise: {MaxUsers: 9999, MaxDevices: 99999, MaxStores: 9999},
+}
+
+// LimitsFor devolve as cotas do plano (plano desconhecido = free)
+func LimitsFor(plan OrganizationPlan) PlanLimits {
+	if limits, ok := planLimits[plan]; ok {
+		return limits
+	}
+	return planLimits[PlanFree]
+}
+
+// Of devolve o limite de um recurso
+func (l PlanLimits) Of(resource QuotaResource) int {
+	switch resource {
+	case QuotaUsers:
+		return l.MaxUsers
+	case QuotaDevices:
+		return l.MaxDevices
+	case QuotaStores:
+		return l.MaxStores
+	}
+	return 0
+}
+
 type OrganizationSettings struct {
+	// Limites efetivos: exceção negociada do tenant ou, sem ela, o padrão do plano
 	MaxUsers   int `json:"max_users"`
 	MaxDevices int `json:"max_devices"`
+	MaxStores  int `json:"max_stores"`
+
+	// Exceções de cota negociadas com a plataforma (zero = segue o plano). Sobrevivem à troca de plano.
+	LimitOverrides *PlanLimits `json:"limit_overrides,omitempty"`
+
+	// Segurança (definida pelo tenant admin)
+	RequireTwoFactor      bool                  `json:"require_two_factor"`                // Obriga 2FA para todos os usuários
+	UnverifiedEmailPolicy UnverifiedEmailPolicy `json:"unverified_email_policy,omitempty"` // Vazio = allow
+	Password              *PasswordPolicy       `json:"password_policy,omitempty"`         // nil = política padrão
+}
+
+// EmailPolicy devolve a política efetiva (organizações antigas não têm o campo)
+func (s OrganizationSettings) EmailPolicy() UnverifiedEmailPolicy {
+	if s.UnverifiedEmailPolicy == "" {
+		return UnverifiedEmailAllow
+	}
+	return s.UnverifiedEmailPolicy
+}
+
+// PasswordPolicy devolve a política de senha efetiva (organizações antigas não têm o campo)
+func (s OrganizationSettings) PasswordPolicy() PasswordPolicy {
+	if s.Password == nil {
+		return DefaultPasswordPolicy()
+	}
+	return *s.Password
 }
 
 type Organization struct {
-	ID        uuid.UUID            `json:"id"`
-	Name      string               `json:"name"`
-	Document  string               `json:"document"` // Será salvo sempre LIMPO (apenas números)
-	Slug      string               `json:"slug"`
-	Plan      OrganizationPlan     `json:"plan"`
-	Sector    OrganizationSector   `json:"sector"`
-	IsActive  bool                 `json:"is_active"`
-	Settings  OrganizationSettings `json:"settings"`
-	CreatedAt time.Time            `json:"created_at"`
-	UpdatedAt time.Time            `json:"updated_at"`
+	ID       uuid.UUID            `json:"id"`
+	Name     string               `json:"name"`
+	Document string               `json:"document"` // Será salvo sempre LIMPO (apenas números)
+	Slug     string               `json:"slug"`
+	Plan     OrganizationPlan     `json:"plan"`
+	Sector   OrganizationSector   `json:"sector"`
+	IsActive bool                 `json:"is_active"`
+	Settings OrganizationSettings `json:"settings"`
+
+	TrialEndsAt *time.Time `json:"trial_ends_at,omitempty"` // Preenchido enquanto o plano é um período de teste
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
 }
 
 func NewOrganization(name, document, slug string, sector OrganizationSector, plan OrganizationPlan) (*Organization, error) {
@@ -70,17 +164,8 @@ func NewOrganization(name, document, slug string, sector OrganizationSector, pla
 	}
 
 	// 3. Define Limites
-	defaultSettings := OrganizationSettings{}
-	switch plan {
-	case PlanFree:
-		defaultSettings = OrganizationSettings{MaxUsers: 2, MaxDevices: 10}
-	case PlanPro:
-		defaultSettings = OrganizationSettings{MaxUsers: 10, MaxDevices: 500}
-	case PlanEnterprise:
-		defaultSettings = OrganizationSettings{MaxUsers: 9999, MaxDevices: 99999}
-	default:
-		defaultSettings = OrganizationSettings{MaxUsers: 2, MaxDevices: 10}
-	}
+	limits := LimitsFor(plan)
+	defaultSettings := OrganizationSettings{MaxUsers: limits.MaxUsers, MaxDevices: limits.MaxDevices, MaxStores: limits.MaxStores}
 
 	return &Organization{
 		ID:        uuid.New(),
@@ -121,17 +206,118 @@ func (o *Organization) Update(name, document string, sector OrganizationSector)
 
 func (o *Organization) ChangePlan(newPlan OrganizationPlan) {
 	o.Plan = newPlan
-	switch newPlan {
-	case PlanFree:
-		o.Settings = OrganizationSettings{MaxUsers: 2, MaxDevices: 10}
-	case PlanPro:
-		o.Settings = OrganizationSettings{MaxUsers: 10, MaxDevices: 500}
-	case PlanEnterprise:
-		o.Settings = OrganizationSettings{MaxUsers: 9999, MaxDevices: 99999}
+	o.TrialEndsAt = nil // Trocar de plano encerra qualquer período de teste
+	// Troca apenas os limites: as configurações de segurança e as exceções do tenant são preservadas
+	if IsValidPlan(newPlan) {
+		o.applyLimits(o.LimitsOn(newPlan))
 	}
 	o.UpdatedAt = time.Now()
 }
 
+// LimitsOn devolve os limites que a organização teria no plano: padrão do plano com as exceções por cima
+func (o *Organization) LimitsOn(plan OrganizationPlan) PlanLimits {
+	limits := LimitsFor(plan)
+	if custom := o.Settings.LimitOverrides; custom != nil {
+		if custom.MaxUsers > 0 {
+			limits.MaxUsers = custom.MaxUsers
+		}
+		if custom.MaxDevices > 0 {
+			limits.MaxDevices = custom.MaxDevices
+		}
+		if custom.MaxStores > 0 {
+			limits.MaxStores = custom.MaxStores
+		}
+	}
+	return limits
+}
+
+// SetLimitOverrides substitui as exceções de cota (tudo zero = remove) e recalcula os limites efetivos
+func (o *Organization) SetLimitOverrides(overrides PlanLimits) error {
+	if overrides.MaxUsers < 0 || overrides.MaxDevices < 0 || overrides.MaxStores < 0 {
+		return ErrInvalidLimitOverride
+	}
+	if overrides == (PlanLimits{}) {
+		o.Settings.LimitOverrides = nil
+	} else {
+		o.Settings.LimitOverrides = &overrides
+	}
+	o.applyLimits(o.LimitsOn(o.Plan))
+	o.UpdatedAt = time.Now()
+	return nil
+}
+
+func (o *Organization) applyLimits(limits PlanLimits) {
+	o.Settings.MaxUsers, o.Settings.MaxDevices, o.Settings.MaxStores = limits.MaxUsers, limits.MaxDevices, limits.MaxStores
+}
+
+// Limit devolve a cota efetiva do recurso. Zero no settings (organizações antigas) = padrão do plano.
+func (o *Organization) Limit(resource QuotaResource) int {
+	plan := LimitsFor(o.Plan)
+	pick := func(custom, fallback int) int {
+		if custom > 0 {
+			return custom
+		}
+		return fallback
+	}
+
+	switch resource {
+	case QuotaUsers:
+		return pick(o.Settings.MaxUsers, plan.MaxUsers)
+	case QuotaDevices:
+		return pick(o.Settings.MaxDevices, plan.MaxDevices)
+	case QuotaStores:
+		return pick(o.Settings.MaxStores, plan.MaxStores)
+	}
+	return 0
+}
+
+// StartTrial transforma o plano atual num período de teste que termina após "duration"
+func (o *Organization) StartTrial(duration time.Duration) error {
+	if o.Plan != PlanPro {
+		return ErrTrialNotAllowed
+	}
+	if duration <= 0 {
+		return errors.New("duração do período de teste inválida")
+	}
+	endsAt := time.Now().Add(duration)
+	o.TrialEndsAt = &endsAt
+	o.UpdatedAt = time.Now()
+	return nil
+}
+
+// IsTrialing indica se a organização está num período de teste ainda válido
+func (o *Organization) IsTrialing(now time.Time) bool {
+	return o.TrialEndsAt != nil && o.TrialEndsAt.After(now)
+}
+
+// TrialExpired indica se o período de teste acabou e a organização ainda não voltou para o free
+func (o *Organization) TrialExpired(now time.Time) bool {
+	return o.TrialEndsAt != nil && !o.TrialEndsAt.After(now)
+}
+
+// ExpireTrial encerra o período de teste e rebaixa a organização para o plano free
+func (o *Organization) ExpireTrial() {
+	o.ChangePlan(PlanFree)
+}
+
+// SetRequireTwoFactor liga/desliga a obrigatoriedade de 2FA para toda a organização
+func (o *Organization) SetRequireTwoFactor(required bool) {
+	o.Settings.RequireTwoFactor = required
+	o.UpdatedAt = time.Now()
+}
+
+// SetUnverifiedEmailPolicy define a política para usuários com email não confirmado
+func (o *Organization) SetUnverifiedEmailPolicy(policy UnverifiedEmailPolicy) error {
+	switch policy {
+	case UnverifiedEmailAllow, UnverifiedEmailBlock, UnverifiedEmailReadOnly:
+	default:
+		return errors.New("política de email não verificado inválida")
+	}
+	o.Settings.UnverifiedEmailPolicy = policy
+	o.UpdatedAt = time.Now()
+	return nil
+}
+
 func isValidSector(s OrganizationSector) bool {
 	switch s {
 	case SectorSupermarket, SectorPharmacy, SectorRetail, SectorWarehouse, SectorOther:
@@ -0,0 +1,106 @@
+package entity
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	"unicode"
+)
+
+// Limites aceitos na configuração da política de senha
+const (
+	MinPasswordLength  = 8   // Nenhuma organização pode exigir menos que isso
+	MaxPasswordLength  = 72  // Limite do bcrypt
+	MaxPasswordHistory = 12  // Cada senha do histórico custa uma comparação de hash na troca
+	MaxPasswordAgeDays = 365 // Expiração mais longa configurável (0 = nunca expira)
+)
+
+// ErrInvalidPasswordPolicy indica uma configuração fora dos limites aceitos
+var ErrInvalidPasswordPolicy = errors.New("política de senha inválida")
+
+// PasswordPolicy são as regras de senha definidas pelo tenant admin
+type PasswordPolicy struct {
+	MinLength        int  `json:"min_length"`
+	RequireUppercase bool `json:"require_uppercase"`
+	RequireLowercase bool `json:"require_lowercase"`
+	RequireDigit     bool `json:"require_digit"`
+	RequireSymbol    bool `json:"require_symbol"`
+	HistorySize      int  `json:"history_size"` // Senhas anteriores que não podem ser reutilizadas (0 = sem restrição)
+	MaxAgeDays       int  `json:"max_age_days"` // Troca obrigatória depois de N dias (0 = nunca expira)
+}
+
+// DefaultPasswordPolicy vale para organizações que não configuraram a sua
+func DefaultPasswordPolicy() PasswordPolicy {
+	return PasswordPolicy{MinLength: MinPasswordLength}
+}
+
+// Validate confere se a configuração está dentro dos limites
+func (p PasswordPolicy) Validate() error {
+	if p.MinLength < MinPasswordLength || p.MinLength > MaxPasswordLength {
+		return fmt.Errorf("tamanho mínimo da senha deve estar entre %d e %d", MinPasswordLength, MaxPasswordLength)
+	}
+	if p.HistorySize < 0 || p.HistorySize > MaxPasswordHistory {
+		return fmt.Errorf("histórico de senhas deve estar entre 0 e %d", MaxPasswordHistory)
+	}
+	if p.MaxAgeDays < 0 || p.MaxAgeDays > MaxPasswordAgeDays {
+		return fmt.Errorf("validade da senha deve estar entre 0 e %d dias", MaxPasswordAgeDays)
+	}
+	return nil
+}
+
+// Violations lista as regras que a senha não atende (vazio = senha aceita)
+func (p PasswordPolicy) Violations(password string) []string {
+	var violations []string
+
+	if n := len([]rune(password)); n < p.MinLength {
+		violations = append(violations, fmt.Sprintf("deve ter no mínimo %d caracteres", p.MinLength))
+	}
+	if len(password) > MaxPasswordLength {
+		violations = append(violations, fmt.Sprintf("deve ter no máximo %d bytes", MaxPasswordLength))
+	}
+
+	var upper, lower, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsPunct(r), unicode.IsSymbol(r), unicode.IsSpace(r):
+			symbol = true
+		}
+	}
+	if p.RequireUppercase && !upper {
+		violations = append(violations, "deve conter uma letra maiúscula")
+	}
+	if p.RequireLowercase && !lower {
+		violations = append(violations, "deve conter uma letra minúscula")
+	}
+	if p.RequireDigit && !digit {
+		violations = append(violations, "deve conter um número")
+	}
+	if p.RequireSymbol && !symbol {
+		violations = append(violations, "deve conter um símbolo")
+	}
+	return violations
+}
+
+// IsExpired indica se a senha trocada em changedAt já passou da validade
+func (p PasswordPolicy) IsExpired(changedAt *time.Time, now time.Time) bool {
+	if p.MaxAgeDays == 0 || changedAt == nil {
+		return false
+	}
+	return now.After(changedAt.AddDate(0, 0, p.MaxAgeDays))
+}
+
+// SetPasswordPolicy define as regras de senha da organização
+func (o *Organization) SetPasswordPolicy(policy PasswordPolicy) error {
+	if err := policy.Validate(); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidPasswordPolicy, err)
+	}
+	o.Settings.Password = &policy
+	o.UpdatedAt = time.Now()
+	return nil
+}
@@ -0,0 +1,66 @@
+package entity
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrSamePlan              = errors.New("a organização já está neste plano")
+	ErrPlanNotSelfService    = errors.New("o plano enterprise é contratado com a equipe comercial")
+	ErrUpgradeNotSelfService = errors.New("contratar um plano ou efetivar o período de teste depende de cobrança: fale com a equipe comercial")
+	ErrInvalidEffectiveAt    = errors.New("a data da troca agendada deve estar no futuro")
+	ErrNoScheduledPlanChange = errors.New("nenhuma troca de plano agendada")
+	ErrInvalidPlan           = errors.New("plano inválido")
+)
+
+// PlanChangeStatus é a situação de uma troca agendada
+type PlanChangeStatus string
+
+const (
+	PlanChangePending  PlanChangeStatus = "pending" // Aguardando a data
+	PlanChangeApplied  PlanChangeStatus = "applied"
+	PlanChangeBlocked  PlanChangeStatus = "blocked"  // Venceu com o consumo acima dos novos limites: não é tentada de novo
+	PlanChangeCanceled PlanChangeStatus = "canceled" // Cancelada ou substituída por outra troca
+)
+
+// ScheduledPlanChange é uma troca de plano marcada para uma data. Rebaixamentos que não cabem
+// nos novos limites só podem ser agendados: o tenant tem até a data para reduzir o consumo. Se na
+// data ainda não couber, a troca fica bloqueada com os excessos até ser cancelada ou substituída.
+type ScheduledPlanChange struct {
+	ID             uuid.UUID
+	OrganizationID uuid.UUID
+	FromPlan       OrganizationPlan
+	ToPlan         OrganizationPlan
+	RequestedBy    uuid.UUID
+	EffectiveAt    time.Time
+	Status         PlanChangeStatus
+	Violations     []LimitViolation // Excessos que bloquearam a troca na data
+	ResolvedBy     *uuid.UUID       // Quem cancelou (nil quando aplicada pelo job)
+	ResolvedAt     *time.Time
+	CreatedAt      time.Time
+}
+
+// NewScheduledPlanChange agenda a troca do plano atual da organização para "to" em "effectiveAt"
+func NewScheduledPlanChange(org *Organization, to OrganizationPlan, requestedBy uuid.UUID, effectiveAt time.Time) (*ScheduledPlanChange, error) {
+	if !effectiveAt.After(time.Now()) {
+		return nil, ErrInvalidEffectiveAt
+	}
+	return &ScheduledPlanChange{
+		ID:             uuid.New(),
+		OrganizationID: org.ID,
+		FromPlan:       org.Plan,
+		ToPlan:         to,
+		RequestedBy:    requestedBy,
+		EffectiveAt:    effectiveAt,
+		Status:         PlanChangePending,
+		CreatedAt:      time.Now(),
+	}, nil
+}
+
+// Block encerra a tentativa de aplicar a troca vencida que não coube nos novos limites
+func (c *ScheduledPlanChange) Block(violations []LimitViolation, at time.Time) {
+	c.Status, c.Violations, c.ResolvedAt = PlanChangeBlocked, violations, &at
+}
@@ -0,0 +1,50 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PlanChangeReason explica por que um período de plano começou
+type PlanChangeReason string
+
+const (
+	PlanChangeCreated      PlanChangeReason = "created"        // Plano escolhido na criação da organização
+	PlanChangeTrialExpired PlanChangeReason = "trial_expired"  // Fim do teste: rebaixamento automático para o free
+	PlanChangeRequested    PlanChangeReason = "changed"        // Troca imediata pedida pelo tenant ou pela plataforma
+	PlanChangeScheduled    PlanChangeReason = "scheduled"      // Troca agendada aplicada pelo job
+	PlanChangeLimits       PlanChangeReason = "limits_changed" // Mesmo plano, exceções de cota alteradas pela plataforma
+)
+
+// PlanPeriod é um trecho do histórico de planos: de EffectiveFrom até EffectiveTo (nil = vigente)
+type PlanPeriod struct {
+	ID             uuid.UUID        `json:"id"`
+	OrganizationID uuid.UUID        `json:"organization_id"`
+	Plan           OrganizationPlan `json:"plan"`
+	Trial          bool             `json:"trial"`
+	Limits         PlanLimits       `json:"limits"` // Limites efetivos no período
+	Reason         PlanChangeReason `json:"reason"`
+	ChangedBy      *uuid.UUID       `json:"changed_by,omitempty"` // nil = sistema (cadastro, fim do teste)
+	EffectiveFrom  time.Time        `json:"effective_from"`
+	EffectiveTo    *time.Time       `json:"effective_to,omitempty"`
+}
+
+// NewPlanPeriod abre um período com o plano atual da organização a partir de "from"
+func NewPlanPeriod(org *Organization, reason PlanChangeReason, from time.Time) *PlanPeriod {
+	return &PlanPeriod{
+		ID:             uuid.New(),
+		OrganizationID: org.ID,
+		Plan:           org.Plan,
+		Trial:          org.TrialEndsAt != nil,
+		Limits:         PlanLimits{MaxUsers: org.Settings.MaxUsers, MaxDevices: org.Settings.MaxDevices, MaxStores: org.Settings.MaxStores},
+		Reason:         reason,
+		EffectiveFrom:  from,
+	}
+}
+
+// By registra quem fez a troca
+func (p *PlanPeriod) By(actorID uuid.UUID) *PlanPeriod {
+	p.ChangedBy = &actorID
+	return p
+}
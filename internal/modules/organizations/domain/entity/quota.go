@@ -0,0 +1,94 @@
+package entity
+
+import (
+	"errors"
+	"fmt"
+)
+
+// QuotaResource é um recurso limitado pelo plano
+type QuotaResource string
+
+const (
+	QuotaUsers   QuotaResource = "users"
+	QuotaDevices QuotaResource = "devices" // Aparelhos do App registrados pelos usuários da organização
+	QuotaStores  QuotaResource = "stores"  // Lojas não excluídas
+)
+
+// ErrQuotaExceeded é o erro base de qualquer cota estourada (use errors.Is)
+var ErrQuotaExceeded = errors.New("limite do plano atingido")
+
+// QuotaExceededError detalha qual cota impediu a criação
+type QuotaExceededError struct {
+	Resource QuotaResource
+	Plan     OrganizationPlan
+	Limit    int
+}
+
+var quotaNames = map[QuotaResource]string{QuotaUsers: "usuários", QuotaDevices: "aparelhos", QuotaStores: "lojas"}
+
+func (e *QuotaExceededError) Error() string {
+	return fmt.Sprintf("limite de %s do plano %s atingido (%d)", quotaNames[e.Resource], e.Plan, e.Limit)
+}
+
+func (e *QuotaExceededError) Is(target error) bool {
+	return target == ErrQuotaExceeded
+}
+
+// Usage é o consumo atual de cada recurso
+type Usage struct {
+	Users   int
+	Devices int
+	Stores  int
+}
+
+// Of devolve o consumo de um recurso
+func (u Usage) Of(resource QuotaResource) int {
+	switch resource {
+	case QuotaUsers:
+		return u.Users
+	case QuotaDevices:
+		return u.Devices
+	case QuotaStores:
+		return u.Stores
+	}
+	return 0
+}
+
+// LimitViolation é um recurso cujo consumo atual não cabe num limite
+type LimitViolation struct {
+	Resource QuotaResource `json:"resource"`
+	Used     int           `json:"used"`
+	Limit    int           `json:"limit"`
+}
+
+func (v LimitViolation) String() string {
+	return fmt.Sprintf("%s: %d em uso, limite %d", quotaNames[v.Resource], v.Used, v.Limit)
+}
+
+// Violations lista os recursos cujo consumo passa dos limites
+func (l PlanLimits) Violations(u Usage) []LimitViolation {
+	var violations []LimitViolation
+	for _, resource := range []QuotaResource{QuotaUsers, QuotaDevices, QuotaStores} {
+		if used, limit := u.Of(resource), l.Of(resource); used > limit {
+			violations = append(violations, LimitViolation{Resource: resource, Used: used, Limit: limit})
+		}
+	}
+	return violations
+}
+
+// ErrLimitsViolated é o erro base de troca de plano/limites que não comporta o consumo atual
+var ErrLimitsViolated = errors.New("o consumo atual da organização não cabe nos novos limites")
+
+// LimitsViolatedError detalha quais recursos precisam ser reduzidos antes da troca
+type LimitsViolatedError struct {
+	Plan       OrganizationPlan
+	Violations []LimitViolation
+}
+
+func (e *LimitsViolatedError) Error() string {
+	return fmt.Sprintf("o consumo atual da organização não cabe nos limites do plano %s", e.Plan)
+}
+
+func (e *LimitsViolatedError) Is(target error) bool {
+	return target == ErrLimitsViolated
+}
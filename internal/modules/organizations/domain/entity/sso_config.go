@@ -0,0 +1,51 @@
+package entity
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SSOConfig é o login corporativo (OpenID Connect) de uma organização
+type SSOConfig struct {
+	OrganizationID uuid.UUID `json:"organization_id"`
+	Issuer         string    `json:"issuer"`
+	ClientID       string    `json:"client_id"`
+	ClientSecret   string    `json:"-"`
+	AllowedDomains []string  `json:"allowed_domains"` // Domínios de email aceitos (ex: "empresa.com.br")
+	DefaultRole    string    `json:"default_role"`    // Papel de quem entra pela primeira vez (provisionamento JIT)
+	Enabled        bool      `json:"enabled"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// AllowsEmail indica se o email pertence a um dos domínios liberados
+func (c *SSOConfig) AllowsEmail(email string) bool {
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return false
+	}
+	domain := strings.ToLower(email[at+1:])
+	for _, allowed := range c.AllowedDomains {
+		if domain == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// NormalizeDomains deixa os domínios em minúsculas e sem duplicatas
+func NormalizeDomains(domains []string) []string {
+	seen := make(map[string]struct{}, len(domains))
+	res := make([]string, 0, len(domains))
+	for _, d := range domains {
+		d = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(d), "@"))
+		if _, dup := seen[d]; d == "" || dup {
+			continue
+		}
+		seen[d] = struct{}{}
+		res = append(res, d)
+	}
+	return res
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/organizations/domain/entity"
@@ -11,8 +12,11 @@ type OrganizationRepository interface {
 	// Escrita
 	Create(ctx context.Context, org *entity.Organization) error
 	Update(ctx context.Context, org *entity.Organization) error // <--- Novo
+	// EndTrial rebaixa a organização se o teste que terminou em "endsAt" ainda estiver valendo (false = outro já fez)
+	EndTrial(ctx context.Context, org *entity.Organization, endsAt time.Time) (bool, error)
 
 	// Leitura
 	GetByID(ctx context.Context, id uuid.UUID) (*entity.Organization, error)
 	GetBySlug(ctx context.Context, slug string) (*entity.Organization, error) // Útil para checar duplicidade
+	ListExpiredTrials(ctx context.Context, now time.Time) ([]*entity.Organization, error)
 }
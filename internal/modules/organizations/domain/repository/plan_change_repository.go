@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/organizations/domain/entity"
+)
+
+type PlanChangeRepository interface {
+	// Schedule grava a troca agendada, cancelando a pendente ou bloqueada anterior em nome de change.RequestedBy
+	Schedule(ctx context.Context, change *entity.ScheduledPlanChange) error
+	// GetOpen devolve a troca pendente ou bloqueada da organização (nil, nil se não houver)
+	GetOpen(ctx context.Context, orgID uuid.UUID) (*entity.ScheduledPlanChange, error)
+	// ListDue devolve as trocas pendentes com data até "now" (job da plataforma)
+	ListDue(ctx context.Context, now time.Time) ([]*entity.ScheduledPlanChange, error)
+	// Resolve grava o novo status se a troca ainda estiver pendente; a bloqueada só pode ser
+	// cancelada (false = já resolvida por outro)
+	Resolve(ctx context.Context, change *entity.ScheduledPlanChange) (bool, error)
+}
@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/organizations/domain/entity"
+)
+
+type PlanHistoryRepository interface {
+	// Start encerra o período vigente em period.EffectiveFrom e abre o novo
+	Start(ctx context.Context, period *entity.PlanPeriod) error
+	// ListByOrganization devolve os períodos do mais recente para o mais antigo
+	ListByOrganization(ctx context.Context, orgID uuid.UUID) ([]*entity.PlanPeriod, error)
+}
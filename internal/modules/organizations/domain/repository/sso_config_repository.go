@@ -0,0 +1,14 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/organizations/domain/entity"
+)
+
+// SSOConfigRepository guarda a configuração de login corporativo (uma por organização)
+type SSOConfigRepository interface {
+	GetByOrganization(ctx context.Context, orgID uuid.UUID) (*entity.SSOConfig, error)
+	Upsert(ctx context.Context, cfg *entity.SSOConfig) error
+}
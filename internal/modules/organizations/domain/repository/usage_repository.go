@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/organizations/domain/entity"
+)
+
+// UsageRepository conta o que a organização consome das cotas do plano
+type UsageRepository interface {
+	// LockOrganization serializa as criações da organização até o fim da transação do context
+	LockOrganization(ctx context.Context, orgID uuid.UUID) error
+	Count(ctx context.Context, orgID uuid.UUID, resource entity.QuotaResource) (int, error)
+	Usage(ctx context.Context, orgID uuid.UUID) (*entity.Usage, error)
+}
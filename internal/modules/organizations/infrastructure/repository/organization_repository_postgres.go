@@ -6,10 +6,13 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/organizations/domain/entity"
 	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/organizations/domain/repository"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/database"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/tenant"
 )
 
 type OrganizationRepoPostgres struct {
@@ -31,13 +34,13 @@ func (r *OrganizationRepoPostgres) Create(ctx context.Context, org *entity.Organ
 
 	query := `
 		INSERT INTO organizations (
-			id, name, document, slug, plan, sector, settings, is_active, created_at, updated_at
+			id, name, document, slug, plan, sector, settings, is_active, trial_ends_at, created_at, updated_at
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
 		)
 	`
 
-	_, err = r.db.ExecContext(ctx, query,
+	_, err = database.Conn(ctx, r.db).ExecContext(ctx, query,
 		org.ID,
 		org.Name,
 		org.Document,
@@ -46,6 +49,7 @@ func (r *OrganizationRepoPostgres) Create(ctx context.Context, org *entity.Organ
 		org.Sector,
 		settingsJSON, // Grava o JSON no banco
 		org.IsActive,
+		org.TrialEndsAt,
 		org.CreatedAt,
 		org.UpdatedAt,
 	)
@@ -55,6 +59,10 @@ func (r *OrganizationRepoPostgres) Create(ctx context.Context, org *entity.Organ
 
 // Update atualiza os dados da organização
 func (r *OrganizationRepoPostgres) Update(ctx context.Context, org *entity.Organization) error {
+	if !tenant.Allows(ctx, org.ID) {
+		return tenant.ErrOutOfScope
+	}
+
 	settingsJSON, err := json.Marshal(org.Settings)
 	if err != nil {
 		return fmt.Errorf("erro ao serializar settings no update: %w", err)
@@ -68,17 +76,19 @@ func (r *OrganizationRepoPostgres) Update(ctx context.Context, org *entity.Organ
 			plan = $4,
 			settings = $5,
 			is_active = $6,
-			updated_at = $7
-		WHERE id = $8
+			trial_ends_at = $7,
+			updated_at = $8
+		WHERE id = $9
 	`
 
-	_, err = r.db.ExecContext(ctx, query,
+	_, err = database.Conn(ctx, r.db).ExecContext(ctx, query,
 		org.Name,
 		org.Document,
 		org.Sector,
 		org.Plan,
 		settingsJSON,
 		org.IsActive,
+		org.TrialEndsAt,
 		org.UpdatedAt, // Importante atualizar a data
 		org.ID,
 	)
@@ -86,73 +96,93 @@ func (r *OrganizationRepoPostgres) Update(ctx context.Context, org *entity.Organ
 	return err
 }
 
-// GetByID busca pelo ID
+// GetByID busca pelo ID (organização de outro tenant é tratada como inexistente)
 func (r *OrganizationRepoPostgres) GetByID(ctx context.Context, id uuid.UUID) (*entity.Organization, error) {
-	query := `
-		SELECT 
-			id, name, document, slug, plan, sector, settings, is_active, created_at, updated_at
-		FROM organizations
-		WHERE id = $1
-	`
+	if !tenant.Allows(ctx, id) {
+		return nil, nil
+	}
 
-	var org entity.Organization
-	var settingsBytes []byte // Buffer temporário para o JSON
+	query := `SELECT ` + organizationColumns + ` FROM organizations WHERE id = $1`
+	return scanOrganization(database.Conn(ctx, r.db).QueryRowContext(ctx, query, id))
+}
 
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&org.ID,
-		&org.Name,
-		&org.Document,
-		&org.Slug,
-		&org.Plan,
-		&org.Sector,
-		&settingsBytes, // O banco joga os bytes aqui
-		&org.IsActive,
-		&org.CreatedAt,
-		&org.UpdatedAt,
-	)
+// GetBySlug busca pelo slug (útil para verificar duplicidade ou rota de API).
+// Sem guard de tenant: a unicidade do slug é global.
+func (r *OrganizationRepoPostgres) GetBySlug(ctx context.Context, slug string) (*entity.Organization, error) {
+	query := `SELECT ` + organizationColumns + ` FROM organizations WHERE slug = $1`
+	return scanOrganization(database.Conn(ctx, r.db).QueryRowContext(ctx, query, slug))
+}
 
+// ListExpiredTrials busca as organizações cujo período de teste já acabou.
+// Sem guard de tenant: usado pelo job da plataforma.
+func (r *OrganizationRepoPostgres) ListExpiredTrials(ctx context.Context, now time.Time) ([]*entity.Organization, error) {
+	query := `SELECT ` + organizationColumns + ` FROM organizations WHERE trial_ends_at <= $1 ORDER BY trial_ends_at`
+
+	rows, err := database.Conn(ctx, r.db).QueryContext(ctx, query, now)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return nil, nil
-		}
 		return nil, err
 	}
+	defer rows.Close()
 
-	// Converte de volta: JSON (banco) -> Struct (Go)
-	if len(settingsBytes) > 0 {
-		if err := json.Unmarshal(settingsBytes, &org.Settings); err != nil {
-			return nil, fmt.Errorf("erro ao desserializar settings: %w", err)
+	var orgs []*entity.Organization
+	for rows.Next() {
+		org, err := scanOrganization(rows)
+		if err != nil {
+			return nil, err
 		}
+		orgs = append(orgs, org)
 	}
-
-	return &org, nil
+	return orgs, rows.Err()
 }
 
-// GetBySlug busca pelo slug (útil para verificar duplicidade ou rota de API)
-func (r *OrganizationRepoPostgres) GetBySlug(ctx context.Context, slug string) (*entity.Organization, error) {
+// EndTrial grava o rebaixamento do fim do teste, só se o teste encerrado em "endsAt" ainda estiver
+// valendo (job e requisição podem tentar ao mesmo tempo; só um vence)
+func (r *OrganizationRepoPostgres) EndTrial(ctx context.Context, org *entity.Organization, endsAt time.Time) (bool, error) {
+	if !tenant.Allows(ctx, org.ID) {
+		return false, tenant.ErrOutOfScope
+	}
+
+	settingsJSON, err := json.Marshal(org.Settings)
+	if err != nil {
+		return false, fmt.Errorf("erro ao serializar settings: %w", err)
+	}
+
 	query := `
-		SELECT 
-			id, name, document, slug, plan, sector, settings, is_active, created_at, updated_at
-		FROM organizations
-		WHERE slug = $1
+		UPDATE organizations SET plan = $1, settings = $2, trial_ends_at = NULL, updated_at = $3
+		WHERE id = $4 AND trial_ends_at = $5
 	`
+	res, err := database.Conn(ctx, r.db).ExecContext(ctx, query, org.Plan, settingsJSON, org.UpdatedAt, org.ID, endsAt)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n == 1, err
+}
+
+const organizationColumns = `id, name, document, slug, plan, sector, settings, is_active, trial_ends_at, created_at, updated_at`
 
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanOrganization lê uma linha com organizationColumns (nil, nil se não existir)
+func scanOrganization(row rowScanner) (*entity.Organization, error) {
 	var org entity.Organization
-	var settingsBytes []byte
+	var settingsBytes []byte // Buffer temporário para o JSON
 
-	err := r.db.QueryRowContext(ctx, query, slug).Scan(
+	err := row.Scan(
 		&org.ID,
 		&org.Name,
 		&org.Document,
 		&org.Slug,
 		&org.Plan,
 		&org.Sector,
-		&settingsBytes,
+		&settingsBytes, // O banco joga os bytes aqui
 		&org.IsActive,
+		&org.TrialEndsAt,
 		&org.CreatedAt,
 		&org.UpdatedAt,
 	)
-
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, nil
@@ -160,6 +190,7 @@ func (r *OrganizationRepoPostgres) GetBySlug(ctx context.Context, slug string) (
 		return nil, err
 	}
 
+	// Converte de volta: JSON (banco) -> Struct (Go)
 	if len(settingsBytes) > 0 {
 		if err := json.Unmarshal(settingsBytes, &org.Settings); err != nil {
 			return nil, fmt.Errorf("erro ao desserializar settings: %w", err)
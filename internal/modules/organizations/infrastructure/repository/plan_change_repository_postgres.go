@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/organizations/domain/entity"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/organizations/domain/repository"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/database"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/tenant"
+)
+
+type PlanChangeRepoPostgres struct {
+	db *sql.DB
+}
+
+// NewPlanChangeRepository cria uma nova instância do repositório
+func NewPlanChangeRepository(db *sql.DB) repository.PlanChangeRepository {
+	return &PlanChangeRepoPostgres{db: db}
+}
+
+// Schedule substitui a troca pendente ou bloqueada da organização pela nova (chamar dentro de uma transação)
+func (r *PlanChangeRepoPostgres) Schedule(ctx context.Context, c *entity.ScheduledPlanChange) error {
+	if !tenant.Allows(ctx, c.OrganizationID) {
+		return tenant.ErrOutOfScope
+	}
+
+	conn := database.Conn(ctx, r.db)
+	_, err := conn.ExecContext(ctx, `
+		UPDATE organization_plan_changes SET status = $1, resolved_by = $2, resolved_at = $3
+		WHERE organization_id = $4 AND status IN ($5, $6)
+	`, entity.PlanChangeCanceled, c.RequestedBy, c.CreatedAt, c.OrganizationID, entity.PlanChangePending, entity.PlanChangeBlocked)
+	if err != nil {
+		return err
+	}
+
+	_, err = conn.ExecContext(ctx, `
+		INSERT INTO organization_plan_changes (
+			id, organization_id, from_plan, to_plan, requested_by, effective_at, status, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`, c.ID, c.OrganizationID, c.FromPlan, c.ToPlan, c.RequestedBy, c.EffectiveAt, c.Status, c.CreatedAt)
+	return err
+}
+
+// GetOpen busca a troca pendente ou bloqueada da organização
+func (r *PlanChangeRepoPostgres) GetOpen(ctx context.Context, orgID uuid.UUID) (*entity.ScheduledPlanChange, error) {
+	if !tenant.Allows(ctx, orgID) {
+		return nil, nil
+	}
+
+	query := `SELECT ` + planChangeColumns + ` FROM organization_plan_changes WHERE organization_id = $1 AND status IN ($2, $3)`
+	return scanPlanChange(database.Conn(ctx, r.db).QueryRowContext(ctx, query, orgID, entity.PlanChangePending, entity.PlanChangeBlocked))
+}
+
+// ListDue busca as trocas pendentes já vencidas, da mais antiga para a mais nova.
+// Sem guard de tenant: usado pelo job da plataforma.
+func (r *PlanChangeRepoPostgres) ListDue(ctx context.Context, now time.Time) ([]*entity.ScheduledPlanChange, error) {
+	query := `SELECT ` + planChangeColumns + ` FROM organization_plan_changes WHERE status = $1 AND effective_at <= $2 ORDER BY effective_at`
+
+	rows, err := database.Conn(ctx, r.db).QueryContext(ctx, query, entity.PlanChangePending, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var changes []*entity.ScheduledPlanChange
+	for rows.Next() {
+		c, err := scanPlanChange(rows)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, c)
+	}
+	return changes, rows.Err()
+}
+
+// Resolve grava o novo status, só se a troca ainda estiver pendente (job e cancelamento podem
+// concorrer). A bloqueada só sai por cancelamento.
+func (r *PlanChangeRepoPostgres) Resolve(ctx context.Context, c *entity.ScheduledPlanChange) (bool, error) {
+	if !tenant.Allows(ctx, c.OrganizationID) {
+		return false, tenant.ErrOutOfScope
+	}
+
+	var violationsJSON []byte
+	if len(c.Violations) > 0 {
+		var err error
+		if violationsJSON, err = json.Marshal(c.Violations); err != nil {
+			return false, err
+		}
+	}
+	from := entity.PlanChangePending
+	if c.Status == entity.PlanChangeCanceled {
+		from = entity.PlanChangeBlocked
+	}
+
+	res, err := database.Conn(ctx, r.db).ExecContext(ctx, `
+		UPDATE organization_plan_changes SET status = $1, violations = $2, resolved_by = $3, resolved_at = $4
+		WHERE id = $5 AND status IN ($6, $7)
+	`, c.Status, violationsJSON, c.ResolvedBy, c.ResolvedAt, c.ID, entity.PlanChangePending, from)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n == 1, err
+}
+
+const planChangeColumns = `id, organization_id, from_plan, to_plan, requested_by, effective_at, status, violations, resolved_by, resolved_at, created_at`
+
+// scanPlanChange lê uma linha com planChangeColumns (nil, nil se não existir)
+func scanPlanChange(row rowScanner) (*entity.ScheduledPlanChange, error) {
+	var c entity.ScheduledPlanChange
+	var violations []byte
+	err := row.Scan(&c.ID, &c.OrganizationID, &c.FromPlan, &c.ToPlan, &c.RequestedBy, &c.EffectiveAt, &c.Status, &violations, &c.ResolvedBy, &c.ResolvedAt, &c.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if violations != nil {
+		if err := json.Unmarshal(violations, &c.Violations); err != nil {
+			return nil, err
+		}
+	}
+	return &c, nil
+}
@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/organizations/domain/entity"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/organizations/domain/repository"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/database"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/tenant"
+)
+
+type PlanHistoryRepoPostgres struct {
+	db *sql.DB
+}
+
+// NewPlanHistoryRepository cria uma nova instância do repositório
+func NewPlanHistoryRepository(db *sql.DB) repository.PlanHistoryRepository {
+	return &PlanHistoryRepoPostgres{db: db}
+}
+
+// Start fecha o período vigente e abre o novo (chamar dentro da transação que muda o plano)
+func (r *PlanHistoryRepoPostgres) Start(ctx context.Context, p *entity.PlanPeriod) error {
+	if !tenant.Allows(ctx, p.OrganizationID) {
+		return tenant.ErrOutOfScope
+	}
+
+	limitsJSON, err := json.Marshal(p.Limits)
+	if err != nil {
+		return fmt.Errorf("erro ao serializar limites: %w", err)
+	}
+
+	conn := database.Conn(ctx, r.db)
+	_, err = conn.ExecContext(ctx, `
+		UPDATE organization_plan_history SET effective_to = $1
+		WHERE organization_id = $2 AND effective_to IS NULL
+	`, p.EffectiveFrom, p.OrganizationID)
+	if err != nil {
+		return err
+	}
+
+	_, err = conn.ExecContext(ctx, `
+		INSERT INTO organization_plan_history (id, organization_id, plan, is_trial, limits, reason, changed_by, effective_from)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`, p.ID, p.OrganizationID, p.Plan, p.Trial, limitsJSON, p.Reason, p.ChangedBy, p.EffectiveFrom)
+	return err
+}
+
+// ListByOrganization devolve o histórico de planos, do período mais recente para o mais antigo
+func (r *PlanHistoryRepoPostgres) ListByOrganization(ctx context.Context, orgID uuid.UUID) ([]*entity.PlanPeriod, error) {
+	if !tenant.Allows(ctx, orgID) {
+		return nil, nil
+	}
+
+	query := `
+		SELECT id, organization_id, plan, is_trial, limits, reason, changed_by, effective_from, effective_to
+		FROM organization_plan_history
+		WHERE organization_id = $1
+		ORDER BY effective_from DESC, created_at DESC
+	`
+	rows, err := database.Conn(ctx, r.db).QueryContext(ctx, query, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var periods []*entity.PlanPeriod
+	for rows.Next() {
+		var p entity.PlanPeriod
+		var limitsBytes []byte
+		if err := rows.Scan(&p.ID, &p.OrganizationID, &p.Plan, &p.Trial, &limitsBytes, &p.Reason, &p.ChangedBy, &p.EffectiveFrom, &p.EffectiveTo); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(limitsBytes, &p.Limits); err != nil {
+			return nil, fmt.Errorf("erro ao desserializar limites: %w", err)
+		}
+		periods = append(periods, &p)
+	}
+	return periods, rows.Err()
+}
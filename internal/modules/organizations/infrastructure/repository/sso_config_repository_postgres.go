@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/organizations/domain/entity"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/organizations/domain/repository"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/database"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/tenant"
+)
+
+type SSOConfigRepoPostgres struct {
+	db *sql.DB
+}
+
+// NewSSOConfigRepository cria uma nova instância do repositório
+func NewSSOConfigRepository(db *sql.DB) repository.SSOConfigRepository {
+	return &SSOConfigRepoPostgres{db: db}
+}
+
+// GetByOrganization busca a configuração (nil se a organização não configurou SSO)
+func (r *SSOConfigRepoPostgres) GetByOrganization(ctx context.Context, orgID uuid.UUID) (*entity.SSOConfig, error) {
+	if !tenant.Allows(ctx, orgID) {
+		return nil, nil
+	}
+
+	query := `
+		SELECT organization_id, issuer, client_id, client_secret, allowed_domains, default_role, enabled, created_at, updated_at
+		FROM organization_sso_configs
+		WHERE organization_id = $1
+	`
+	var cfg entity.SSOConfig
+	var domains []byte
+	err := database.Conn(ctx, r.db).QueryRowContext(ctx, query, orgID).Scan(
+		&cfg.OrganizationID, &cfg.Issuer, &cfg.ClientID, &cfg.ClientSecret, &domains,
+		&cfg.DefaultRole, &cfg.Enabled, &cfg.CreatedAt, &cfg.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(domains, &cfg.AllowedDomains); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// Upsert cria ou substitui a configuração da organização
+func (r *SSOConfigRepoPostgres) Upsert(ctx context.Context, cfg *entity.SSOConfig) error {
+	if !tenant.Allows(ctx, cfg.OrganizationID) {
+		return tenant.ErrOutOfScope
+	}
+
+	domains, err := json.Marshal(cfg.AllowedDomains)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO organization_sso_configs (
+			organization_id, issuer, client_id, client_secret, allowed_domains, default_role, enabled, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (organization_id) DO UPDATE SET
+			issuer = EXCLUDED.issuer,
+			client_id = EXCLUDED.client_id,
+			client_secret = EXCLUDED.client_secret,
+			allowed_domains = EXCLUDED.allowed_domains,
+			default_role = EXCLUDED.default_role,
+			enabled = EXCLUDED.enabled,
+			updated_at = EXCLUDED.updated_at
+	`
+	_, err = database.Conn(ctx, r.db).ExecContext(ctx, query,
+		cfg.OrganizationID, cfg.Issuer, cfg.ClientID, cfg.ClientSecret, domains,
+		cfg.DefaultRole, cfg.Enabled, cfg.CreatedAt, cfg.UpdatedAt,
+	)
+	return err
+}
@@ -4,12 +4,15 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/organizations/domain/entity"
 	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/organizations/domain/repository"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/database"
 	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/pagination"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/tenant"
 )
 
 type StoreRepoPostgres struct {
@@ -21,6 +24,10 @@ func NewStoreRepository(db *sql.DB) repository.StoreRepository {
 }
 
 func (r *StoreRepoPostgres) Create(ctx context.Context, s *entity.Store) error {
+	if !tenant.Allows(ctx, s.OrganizationID) {
+		return tenant.ErrOutOfScope
+	}
+
 	query := `
 		INSERT INTO stores (
 			id, organization_id, name, code, timezone, is_active,
@@ -33,7 +40,7 @@ func (r *StoreRepoPostgres) Create(ctx context.Context, s *entity.Store) error {
 			$14, $15, $16
 		)
 	`
-	_, err := r.db.ExecContext(ctx, query,
+	_, err := database.Conn(ctx, r.db).ExecContext(ctx, query,
 		s.ID, s.OrganizationID, s.Name, s.Code, s.Timezone, s.IsActive,
 		// Mapeando a struct Address para as colunas
 		s.Address.Street, s.Address.Number, s.Address.Complement, s.Address.District,
@@ -44,6 +51,10 @@ func (r *StoreRepoPostgres) Create(ctx context.Context, s *entity.Store) error {
 }
 
 func (r *StoreRepoPostgres) Update(ctx context.Context, s *entity.Store) error {
+	if !tenant.Allows(ctx, s.OrganizationID) || !tenant.AllowsStore(ctx, s.ID) {
+		return tenant.ErrOutOfScope
+	}
+
 	query := `
 		UPDATE stores SET
 			name = $1, 
@@ -51,13 +62,13 @@ func (r *StoreRepoPostgres) Update(ctx context.Context, s *entity.Store) error {
 			address_street = $3, address_number = $4, address_complement = $5,
 			address_district = $6, address_city = $7, address_state = $8, address_zip_code = $9,
 			updated_at = $10
-		WHERE id = $11 AND deleted_at IS NULL
+		WHERE id = $11 AND organization_id = $12 AND deleted_at IS NULL
 	`
-	_, err := r.db.ExecContext(ctx, query,
+	_, err := database.Conn(ctx, r.db).ExecContext(ctx, query,
 		s.Name, s.Timezone,
 		s.Address.Street, s.Address.Number, s.Address.Complement,
 		s.Address.District, s.Address.City, s.Address.State, s.Address.ZipCode,
-		s.UpdatedAt, s.ID,
+		s.UpdatedAt, s.ID, s.OrganizationID,
 	)
 	return err
 }
@@ -65,7 +76,19 @@ func (r *StoreRepoPostgres) Update(ctx context.Context, s *entity.Store) error {
 func (r *StoreRepoPostgres) Delete(ctx context.Context, id uuid.UUID) error {
 	// Soft Delete: Apenas preenche o deleted_at
 	query := `UPDATE stores SET deleted_at = $1, is_active = false WHERE id = $2`
-	_, err := r.db.ExecContext(ctx, query, time.Now(), id)
+	args := []interface{}{time.Now().UTC(), id}
+
+	// Fora do escopo a loja simplesmente não é encontrada
+	if orgID, restricted := tenant.Restriction(ctx); restricted {
+		args = append(args, orgID)
+		query += fmt.Sprintf(` AND organization_id = $%d`, len(args))
+	}
+	if storeID, restricted := tenant.StoreRestriction(ctx); restricted {
+		args = append(args, storeID)
+		query += fmt.Sprintf(` AND id = $%d`, len(args))
+	}
+
+	_, err := database.Conn(ctx, r.db).ExecContext(ctx, query, args...)
 	return err
 }
 
@@ -84,7 +107,7 @@ func (r *StoreRepoPostgres) GetByID(ctx context.Context, id uuid.UUID) (*entity.
 	// Precisamos de variaveis auxiliares para NullStrings se o endereço for opcional,
 	// mas como definimos colunas normais, vamos assumir string vazia se for null.
 
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
+	err := database.Conn(ctx, r.db).QueryRowContext(ctx, query, id).Scan(
 		&s.ID, &s.OrganizationID, &s.Name, &s.Code, &s.Timezone, &s.IsActive,
 		&s.Address.Street, &s.Address.Number, &s.Address.Complement, &s.Address.District,
 		&s.Address.City, &s.Address.State, &s.Address.ZipCode,
@@ -97,27 +120,43 @@ func (r *StoreRepoPostgres) GetByID(ctx context.Context, id uuid.UUID) (*entity.
 		}
 		return nil, err
 	}
+	if !tenant.Allows(ctx, s.OrganizationID) || !tenant.AllowsStore(ctx, s.ID) {
+		return nil, nil
+	}
 	return &s, nil
 }
+
 func (r *StoreRepoPostgres) ListByOrganization(ctx context.Context, orgID uuid.UUID, pageParams pagination.Params) ([]*entity.Store, int64, error) {
+	if !tenant.Allows(ctx, orgID) {
+		return []*entity.Store{}, 0, nil
+	}
+
+	// Gerente/operador só enxerga a própria loja
+	where := `WHERE organization_id = $1`
+	args := []interface{}{orgID}
+	if storeID, restricted := tenant.StoreRestriction(ctx); restricted {
+		args = append(args, storeID)
+		where += ` AND id = $2`
+	}
+
 	var totalItems int64
-	countQuery := `SELECT COUNT(*) FROM stores WHERE organization_id = $1`
-	err := r.db.QueryRowContext(ctx, countQuery, orgID).Scan(&totalItems)
+	countQuery := `SELECT COUNT(*) FROM stores ` + where
+	err := database.Conn(ctx, r.db).QueryRowContext(ctx, countQuery, args...).Scan(&totalItems)
 	if err != nil {
 		return nil, 0, err
 	}
 
-	query := `
+	query := fmt.Sprintf(`
 		SELECT id, organization_id, name, code, timezone,
 			address_street, address_number, address_complement, address_district, address_city, address_state, address_zip_code,
 			created_at, updated_at
 		FROM stores
-		WHERE organization_id = $1
+		%s
 		ORDER BY created_at DESC
-		LIMIT $2 OFFSET $3
-	`
+		LIMIT $%d OFFSET $%d
+	`, where, len(args)+1, len(args)+2)
 
-	rows, err := r.db.QueryContext(ctx, query, orgID, pageParams.Limit, pageParams.Offset())
+	rows, err := database.Conn(ctx, r.db).QueryContext(ctx, query, append(args, pageParams.Limit, pageParams.Offset())...)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/organizations/domain/entity"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/organizations/domain/repository"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/database"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/tenant"
+)
+
+type UsageRepoPostgres struct {
+	db *sql.DB
+}
+
+// NewUsageRepository cria uma nova instância do repositório
+func NewUsageRepository(db *sql.DB) repository.UsageRepository {
+	return &UsageRepoPostgres{db: db}
+}
+
+// Consultas de consumo de cada recurso (parâmetro $1 = organização). Usuário suspenso (e os
+// aparelhos dele) não ocupa cota: o tenant no limite consegue substituir quem saiu.
+var usageQueries = map[entity.QuotaResource]string{
+	entity.QuotaUsers: `SELECT COUNT(*) FROM users WHERE organization_id = $1 AND status <> 'suspended'`,
+	entity.QuotaDevices: `
+		SELECT COUNT(*) FROM user_devices d
+		JOIN users u ON u.id = d.user_id
+		WHERE u.organization_id = $1 AND u.status <> 'suspended'`,
+	entity.QuotaStores: `SELECT COUNT(*) FROM stores WHERE organization_id = $1 AND deleted_at IS NULL`,
+}
+
+// LockOrganization trava a linha da organização. NO KEY UPDATE não bloqueia os inserts que só
+// referenciam a organização pela FK, apenas outras reservas de cota.
+func (r *UsageRepoPostgres) LockOrganization(ctx context.Context, orgID uuid.UUID) error {
+	if !tenant.Allows(ctx, orgID) {
+		return tenant.ErrOutOfScope
+	}
+
+	var id uuid.UUID
+	err := database.Conn(ctx, r.db).QueryRowContext(ctx,
+		`SELECT id FROM organizations WHERE id = $1 FOR NO KEY UPDATE`, orgID,
+	).Scan(&id)
+	return err
+}
+
+// Count devolve o consumo atual de um recurso
+func (r *UsageRepoPostgres) Count(ctx context.Context, orgID uuid.UUID, resource entity.QuotaResource) (int, error) {
+	if !tenant.Allows(ctx, orgID) {
+		return 0, tenant.ErrOutOfScope
+	}
+
+	query, ok := usageQueries[resource]
+	if !ok {
+		return 0, fmt.Errorf("recurso de cota desconhecido: %s", resource)
+	}
+
+	var n int
+	err := database.Conn(ctx, r.db).QueryRowContext(ctx, query, orgID).Scan(&n)
+	return n, err
+}
+
+// Usage devolve o consumo de todos os recursos
+func (r *UsageRepoPostgres) Usage(ctx context.Context, orgID uuid.UUID) (*entity.Usage, error) {
+	var u entity.Usage
+	for resource, dst := range map[entity.QuotaResource]*int{
+		entity.QuotaUsers:   &u.Users,
+		entity.QuotaDevices: &u.Devices,
+		entity.QuotaStores:  &u.Stores,
+	} {
+		n, err := r.Count(ctx, orgID, resource)
+		if err != nil {
+			return nil, err
+		}
+		*dst = n
+	}
+	return &u, nil
+}
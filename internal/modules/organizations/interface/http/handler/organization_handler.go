@@ -2,6 +2,7 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 
 	"github.com/go-chi/chi/v5"
@@ -9,16 +10,18 @@ import (
 	"github.com/paulochiaradia/smart-gondola-backend/internal/interface/http/response"
 	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/organizations/application/dto"
 	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/organizations/application/usecase"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/organizations/domain/entity"
 	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/validator"
 )
 
 type OrganizationHandler struct {
 	useCase *usecase.OrganizationUseCase
+	quota   *usecase.QuotaUseCase
 }
 
 // NewOrganizationHandler cria o controller
-func NewOrganizationHandler(uc *usecase.OrganizationUseCase) *OrganizationHandler {
-	return &OrganizationHandler{useCase: uc}
+func NewOrganizationHandler(uc *usecase.OrganizationUseCase, quota *usecase.QuotaUseCase) *OrganizationHandler {
+	return &OrganizationHandler{useCase: uc, quota: quota}
 }
 
 // Create trata POST /organizations
@@ -44,7 +47,7 @@ func (h *OrganizationHandler) Create(w http.ResponseWriter, r *http.Request) {
 			response.Error(w, http.StatusConflict, err.Error())
 			return
 		}
-		if err.Error() == "CNPJ inválido" || err.Error() == "setor de atuação inválido" {
+		if err.Error() == "CNPJ inválido" || err.Error() == "setor de atuação inválido" || errors.Is(err, entity.ErrTrialNotAllowed) {
 			response.Error(w, http.StatusBadRequest, err.Error())
 			return
 		}
@@ -107,6 +110,96 @@ func (h *OrganizationHandler) Update(w http.ResponseWriter, r *http.Request) {
 	response.OK(w, res)
 }
 
+// UpdateSettings trata PUT /organizations/{id}/settings
+func (h *OrganizationHandler) UpdateSettings(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "ID inválido")
+		return
+	}
+
+	var req dto.UpdateOrganizationSettingsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "JSON inválido")
+		return
+	}
+
+	if validationErrors := validator.ValidateStruct(req); len(validationErrors) > 0 {
+		response.Error(w, http.StatusBadRequest, "Falha na validação dos dados", validationErrors...)
+		return
+	}
+
+	res, err := h.useCase.UpdateSettings(r.Context(), id, req)
+	if err != nil {
+		if err.Error() == "organização não encontrada" {
+			response.Error(w, http.StatusNotFound, "Organização não encontrada")
+			return
+		}
+		if err.Error() == "política de email não verificado inválida" || errors.Is(err, entity.ErrInvalidPasswordPolicy) {
+			response.Error(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		response.Error(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.OK(w, res)
+}
+
+// PlanHistory trata GET /organizations/{id}/plan-history
+func (h *OrganizationHandler) PlanHistory(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "ID inválido")
+		return
+	}
+
+	res, err := h.useCase.PlanHistory(r.Context(), id)
+	if err != nil {
+		if err.Error() == "organização não encontrada" {
+			response.Error(w, http.StatusNotFound, "Organização não encontrada")
+			return
+		}
+		response.Error(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.OK(w, res)
+}
+
+// Usage trata GET /organizations/{id}/usage
+func (h *OrganizationHandler) Usage(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "ID inválido")
+		return
+	}
+
+	res, err := h.quota.Usage(r.Context(), id)
+	if err != nil {
+		if err.Error() == "organização não encontrada" {
+			response.Error(w, http.StatusNotFound, "Organização não encontrada")
+			return
+		}
+		response.Error(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.OK(w, res)
+}
+
+// writeQuotaExceeded responde 402 quando a criação esbarrou no limite do plano.
+// Devolve false (sem responder) quando o erro é outro.
+func writeQuotaExceeded(w http.ResponseWriter, err error) bool {
+	var quota *entity.QuotaExceededError
+	if !errors.As(err, &quota) {
+		return false
+	}
+	response.Error(w, http.StatusPaymentRequired, quota.Error(), "Faça upgrade do plano para continuar")
+	return true
+}
+
 // RegisterRoutes registra as rotas no router principal
 func (h *OrganizationHandler) RegisterRoutes(router chi.Router) {
 	// Agrupamento /organizations
@@ -114,5 +207,8 @@ func (h *OrganizationHandler) RegisterRoutes(router chi.Router) {
 		r.Post("/", h.Create)     // Criar empresa
 		r.Get("/{id}", h.GetByID) // Buscar empresa
 		r.Put("/{id}", h.Update)  // Atualizar dados
+		r.Put("/{id}/settings", h.UpdateSettings)
+		r.Get("/{id}/plan-history", h.PlanHistory)
+		r.Get("/{id}/usage", h.Usage)
 	})
 }
@@ -0,0 +1,190 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/interface/http/middleware"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/interface/http/response"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/organizations/application/dto"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/organizations/application/usecase"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/organizations/domain/entity"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/tenant"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/validator"
+)
+
+type PlanHandler struct {
+	useCase *usecase.PlanChangeUseCase
+}
+
+// NewPlanHandler cria o controller de troca de plano e limites
+func NewPlanHandler(uc *usecase.PlanChangeUseCase) *PlanHandler {
+	return &PlanHandler{useCase: uc}
+}
+
+// Preview GET /organizations/{id}/plan/preview?plan=free
+func (h *PlanHandler) Preview(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "ID inválido")
+		return
+	}
+
+	res, err := h.useCase.Preview(r.Context(), id, entity.OrganizationPlan(r.URL.Query().Get("plan")))
+	if err != nil {
+		writePlanError(w, err)
+		return
+	}
+
+	response.OK(w, res)
+}
+
+// Change PUT /organizations/{id}/plan (self-service do tenant)
+func (h *PlanHandler) Change(w http.ResponseWriter, r *http.Request) {
+	h.change(w, r, h.useCase.ChangePlan)
+}
+
+// ChangeAsPlatform PUT /admin/organizations/{id}/plan
+func (h *PlanHandler) ChangeAsPlatform(w http.ResponseWriter, r *http.Request) {
+	h.change(w, r, h.useCase.ChangePlanAsPlatform)
+}
+
+type changePlanFunc func(ctx context.Context, actorID, orgID uuid.UUID, input dto.ChangePlanRequest) (*dto.PlanChangeResponse, error)
+
+func (h *PlanHandler) change(w http.ResponseWriter, r *http.Request, changePlan changePlanFunc) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "ID inválido")
+		return
+	}
+
+	var req dto.ChangePlanRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "JSON inválido")
+		return
+	}
+
+	if validationErrors := validator.ValidateStruct(req); len(validationErrors) > 0 {
+		response.Error(w, http.StatusBadRequest, "Falha na validação dos dados", validationErrors...)
+		return
+	}
+
+	ctx := r.Context()
+	res, err := changePlan(ctx, middleware.GetUserID(ctx), id, req)
+	if err != nil {
+		writePlanError(w, err)
+		return
+	}
+
+	// Agendada = aceita, mas ainda não aplicada
+	if !res.Applied {
+		response.JSON(w, http.StatusAccepted, res)
+		return
+	}
+	response.OK(w, res)
+}
+
+// Scheduled GET /organizations/{id}/plan/scheduled
+func (h *PlanHandler) Scheduled(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "ID inválido")
+		return
+	}
+
+	res, err := h.useCase.Scheduled(r.Context(), id)
+	if err != nil {
+		writePlanError(w, err)
+		return
+	}
+
+	response.OK(w, res)
+}
+
+// CancelScheduled DELETE /organizations/{id}/plan/scheduled
+func (h *PlanHandler) CancelScheduled(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "ID inválido")
+		return
+	}
+
+	ctx := r.Context()
+	if err := h.useCase.CancelScheduled(ctx, middleware.GetUserID(ctx), id); err != nil {
+		writePlanError(w, err)
+		return
+	}
+
+	response.NoContent(w)
+}
+
+// SetLimits PUT /admin/organizations/{id}/limits
+func (h *PlanHandler) SetLimits(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "ID inválido")
+		return
+	}
+
+	var req dto.UpdateLimitsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "JSON inválido")
+		return
+	}
+
+	if validationErrors := validator.ValidateStruct(req); len(validationErrors) > 0 {
+		response.Error(w, http.StatusBadRequest, "Falha na validação dos dados", validationErrors...)
+		return
+	}
+
+	ctx := r.Context()
+	res, err := h.useCase.SetLimits(ctx, middleware.GetUserID(ctx), id, req)
+	if err != nil {
+		writePlanError(w, err)
+		return
+	}
+
+	response.OK(w, res)
+}
+
+// writePlanError traduz os erros da troca de plano para status HTTP. Consumo acima dos novos
+// limites vira 409 com um detalhe por recurso a reduzir.
+func writePlanError(w http.ResponseWriter, err error) {
+	var violated *entity.LimitsViolatedError
+	switch {
+	case errors.As(err, &violated):
+		details := make([]string, 0, len(violated.Violations))
+		for _, v := range violated.Violations {
+			details = append(details, v.String())
+		}
+		response.Error(w, http.StatusConflict, violated.Error(), details...)
+	case errors.Is(err, usecase.ErrOrganizationNotFound), errors.Is(err, tenant.ErrOutOfScope):
+		response.Error(w, http.StatusNotFound, "Organização não encontrada")
+	case errors.Is(err, entity.ErrNoScheduledPlanChange):
+		response.Error(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, entity.ErrPlanNotSelfService), errors.Is(err, entity.ErrUpgradeNotSelfService):
+		response.Error(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, entity.ErrSamePlan):
+		response.Error(w, http.StatusConflict, err.Error())
+	case errors.Is(err, entity.ErrInvalidPlan), errors.Is(err, entity.ErrInvalidEffectiveAt), errors.Is(err, entity.ErrInvalidLimitOverride):
+		response.Error(w, http.StatusBadRequest, err.Error())
+	default:
+		response.Error(w, http.StatusInternalServerError, "Erro ao trocar o plano")
+	}
+}
+
+// RegisterRoutes registra as rotas no router principal
+func (h *PlanHandler) RegisterRoutes(router chi.Router) {
+	router.Route("/organizations/{id}/plan", func(r chi.Router) {
+		r.Get("/preview", h.Preview)
+		r.Put("/", h.Change)
+		r.Get("/scheduled", h.Scheduled)
+		r.Delete("/scheduled", h.CancelScheduled)
+	})
+	router.Put("/admin/organizations/{id}/plan", h.ChangeAsPlatform)
+	router.Put("/admin/organizations/{id}/limits", h.SetLimits)
+}
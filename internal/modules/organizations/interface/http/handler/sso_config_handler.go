@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/interface/http/response"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/organizations/application/dto"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/organizations/application/usecase"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/tenant"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/validator"
+)
+
+type SSOConfigHandler struct {
+	useCase *usecase.SSOConfigUseCase
+}
+
+// NewSSOConfigHandler cria o controller da configuração de login corporativo
+func NewSSOConfigHandler(uc *usecase.SSOConfigUseCase) *SSOConfigHandler {
+	return &SSOConfigHandler{useCase: uc}
+}
+
+// Get GET /organizations/{id}/sso
+func (h *SSOConfigHandler) Get(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "ID inválido")
+		return
+	}
+
+	res, err := h.useCase.Get(r.Context(), id)
+	if err != nil {
+		writeSSOConfigError(w, err)
+		return
+	}
+
+	response.OK(w, res)
+}
+
+// Update PUT /organizations/{id}/sso
+func (h *SSOConfigHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "ID inválido")
+		return
+	}
+
+	var req dto.UpdateSSOConfigRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, http.StatusBadRequest, "JSON inválido")
+		return
+	}
+
+	if validationErrors := validator.ValidateStruct(req); len(validationErrors) > 0 {
+		response.Error(w, http.StatusBadRequest, "Falha na validação dos dados", validationErrors...)
+		return
+	}
+
+	res, err := h.useCase.Update(r.Context(), id, req)
+	if err != nil {
+		writeSSOConfigError(w, err)
+		return
+	}
+
+	response.OK(w, res)
+}
+
+func writeSSOConfigError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, usecase.ErrOrganizationNotFound), errors.Is(err, tenant.ErrOutOfScope):
+		response.Error(w, http.StatusNotFound, "Organização não encontrada")
+	case errors.Is(err, usecase.ErrSSONotConfigured):
+		response.Error(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, usecase.ErrSSORequiresEnterprise):
+		response.Error(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, usecase.ErrSSOInsecureIssuer), errors.Is(err, usecase.ErrSSOClientSecretMissing):
+		response.Error(w, http.StatusBadRequest, err.Error())
+	default:
+		response.Error(w, http.StatusInternalServerError, "Erro ao processar login corporativo")
+	}
+}
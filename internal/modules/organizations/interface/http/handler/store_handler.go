@@ -2,6 +2,7 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 
 	"github.com/go-chi/chi/v5"
@@ -10,6 +11,7 @@ import (
 	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/organizations/application/dto"
 	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/organizations/application/usecase"
 	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/pagination"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/tenant"
 	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/validator"
 )
 
@@ -36,10 +38,17 @@ func (h *StoreHandler) Create(w http.ResponseWriter, r *http.Request) {
 
 	res, err := h.useCase.Create(r.Context(), req)
 	if err != nil {
+		if errors.Is(err, tenant.ErrOutOfScope) {
+			response.Error(w, http.StatusNotFound, "Organização não encontrada")
+			return
+		}
 		if err.Error() == "já existe uma loja com este código nesta organização" {
 			response.Error(w, http.StatusConflict, err.Error())
 			return
 		}
+		if writeQuotaExceeded(w, err) {
+			return
+		}
 		response.Error(w, http.StatusInternalServerError, "Erro interno ao criar loja", err.Error())
 		return
 	}
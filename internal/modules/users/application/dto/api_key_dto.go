@@ -0,0 +1,35 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/domain/entity"
+)
+
+// CreateAPIKeyRequest cria uma chave para integração. Os escopos não podem passar das permissões de quem cria.
+type CreateAPIKeyRequest struct {
+	Name          string   `json:"name" validate:"required,min=3,max=100"`
+	Scopes        []string `json:"scopes" validate:"required,min=1,dive,required"`
+	ExpiresInDays int      `json:"expires_in_days" validate:"required,min=1,max=365"`
+}
+
+// APIKeyResponse descreve uma chave (sem o segredo)
+type APIKeyResponse struct {
+	ID         uuid.UUID           `json:"id"`
+	Name       string              `json:"name"`
+	Prefix     string              `json:"prefix"`
+	Scopes     []entity.Permission `json:"scopes"`
+	Active     bool                `json:"active"`
+	CreatedBy  *uuid.UUID          `json:"created_by,omitempty"`
+	ExpiresAt  time.Time           `json:"expires_at"`
+	LastUsedAt *time.Time          `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time          `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time           `json:"created_at"`
+}
+
+// APIKeyCreatedResponse inclui a chave completa: é a única vez que ela é exibida
+type APIKeyCreatedResponse struct {
+	APIKeyResponse
+	Key string `json:"key"`
+}
@@ -0,0 +1,34 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/domain/entity"
+)
+
+// DeviceResponse não expõe o push token, apenas se o aparelho recebe notificações
+type DeviceResponse struct {
+	ID           uuid.UUID `json:"id"`
+	DeviceID     string    `json:"device_id"`
+	DeviceName   string    `json:"device_name,omitempty"`
+	Platform     string    `json:"platform,omitempty"`
+	AppVersion   string    `json:"app_version,omitempty"`
+	PushEnabled  bool      `json:"push_enabled"`
+	LastActiveAt time.Time `json:"last_active_at"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// DeviceOwnerResponse identifica quem recebe os alertas naquele aparelho
+type DeviceOwnerResponse struct {
+	ID    uuid.UUID       `json:"id"`
+	Name  string          `json:"name"`
+	Email string          `json:"email"`
+	Role  entity.UserRole `json:"role"`
+}
+
+// StoreDeviceResponse é um item da listagem de aparelhos por loja (visão do admin)
+type StoreDeviceResponse struct {
+	DeviceResponse
+	User DeviceOwnerResponse `json:"user"`
+}
@@ -0,0 +1,49 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/domain/entity"
+)
+
+// StartImpersonationRequest abre uma sessão de suporte "como" o usuário. O motivo fica na auditoria.
+type StartImpersonationRequest struct {
+	UserID      uuid.UUID  `json:"user_id" validate:"required"`
+	Reason      string     `json:"reason" validate:"required,min=10,max=500"`
+	WriteAccess bool       `json:"write_access"` // Padrão: somente leitura (escrita apenas para super_admin)
+	Client      ClientInfo `json:"-"`
+}
+
+// ImpersonationResponse devolve o token da sessão (sem refresh: expira e acabou)
+type ImpersonationResponse struct {
+	SessionID   uuid.UUID     `json:"session_id"`
+	AccessToken string        `json:"access_token"`
+	ExpiresAt   time.Time     `json:"expires_at"`
+	ReadOnly    bool          `json:"read_only"`
+	User        *UserResponse `json:"user"`
+}
+
+// ImpersonationSessionResponse é um item da auditoria de impersonações
+type ImpersonationSessionResponse struct {
+	ID           uuid.UUID       `json:"id"`
+	ActorID      uuid.UUID       `json:"actor_id"`
+	ActorRole    entity.UserRole `json:"actor_role"`
+	TargetUserID uuid.UUID       `json:"target_user_id"`
+	TargetOrgID  uuid.UUID       `json:"target_org_id"`
+	Reason       string          `json:"reason"`
+	ReadOnly     bool            `json:"read_only"`
+	Active       bool            `json:"active"`
+	IPAddress    string          `json:"ip_address,omitempty"`
+	ExpiresAt    time.Time       `json:"expires_at"`
+	EndedAt      *time.Time      `json:"ended_at,omitempty"`
+	CreatedAt    time.Time       `json:"created_at"`
+}
+
+// ImpersonationEventResponse é uma requisição feita durante a sessão
+type ImpersonationEventResponse struct {
+	Method    string    `json:"method"`
+	Path      string    `json:"path"`
+	Status    int       `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+}
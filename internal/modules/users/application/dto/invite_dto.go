@@ -0,0 +1,35 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/domain/entity"
+)
+
+// --- Invite DTOs ---
+
+type CreateInviteRequest struct {
+	Email   string          `json:"email" validate:"required,email"`
+	Role    entity.UserRole `json:"role" validate:"required,oneof=tenant_admin manager operator"`
+	StoreID *uuid.UUID      `json:"store_id"` // Opcional: restringe o convidado a uma loja
+}
+
+// AcceptInviteRequest é enviado pelo convidado (rota pública, o token vem do link)
+type AcceptInviteRequest struct {
+	Token    string `json:"token" validate:"required"`
+	Name     string `json:"name" validate:"required"`
+	Password string `json:"password" validate:"required"`
+	Phone    string `json:"phone"`
+}
+
+type InviteResponse struct {
+	ID        uuid.UUID           `json:"id"`
+	Email     string              `json:"email"`
+	Role      entity.UserRole     `json:"role"`
+	StoreID   *uuid.UUID          `json:"store_id,omitempty"`
+	Status    entity.InviteStatus `json:"status"`
+	InvitedBy uuid.UUID           `json:"invited_by"`
+	ExpiresAt time.Time           `json:"expires_at"`
+	CreatedAt time.Time           `json:"created_at"`
+}
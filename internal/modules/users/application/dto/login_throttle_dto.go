@@ -0,0 +1,28 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/domain/entity"
+)
+
+// LoginThrottleResponse é um bloqueio de login ativo (painel de segurança da plataforma)
+type LoginThrottleResponse struct {
+	Scope        entity.ThrottleScope   `json:"scope"`
+	IPAddress    string                 `json:"ip_address,omitempty"`
+	Email        string                 `json:"email,omitempty"`
+	Reason       *entity.ThrottleReason `json:"reason,omitempty"`
+	Lockouts     int                    `json:"lockouts"` // Bloqueios seguidos (o próximo dura o dobro)
+	BlockedUntil *time.Time             `json:"blocked_until,omitempty"`
+}
+
+// UnlockLoginRequest libera um email, um IP ou os dois (pelo menos um é obrigatório)
+type UnlockLoginRequest struct {
+	Email     string `json:"email" validate:"required_without=IPAddress,omitempty,email"`
+	IPAddress string `json:"ip_address" validate:"required_without=Email,omitempty,ip"`
+}
+
+// UnlockLoginResponse informa quantos contadores foram apagados
+type UnlockLoginResponse struct {
+	Cleared int64 `json:"cleared"`
+}
@@ -0,0 +1,38 @@
+package dto
+
+import (
+	orgDTO "github.com/paulochiaradia/smart-gondola-backend/internal/modules/organizations/application/dto"
+)
+
+// SignupRequest cria uma nova empresa junto com o seu primeiro usuário (o dono da conta) e a primeira loja.
+// Entrar numa empresa já existente só é possível por convite ou pela criação feita por um admin.
+// Planos no cadastro: free, ou pro sempre como período de teste; enterprise é contratado com a equipe comercial.
+type SignupRequest struct {
+	Organization orgDTO.CreateOrganizationRequest `json:"organization"`
+	Owner        SignupOwnerRequest               `json:"owner"`
+	Store        *SignupStoreRequest              `json:"store"` // Opcional: sem ele, cria uma loja padrão
+}
+
+// SignupOwnerRequest são os dados do dono. O papel é sempre tenant admin, nunca escolhido pelo cliente.
+type SignupOwnerRequest struct {
+	Name     string `json:"name" validate:"required"`
+	Email    string `json:"email" validate:"required,email"`
+	Phone    string `json:"phone"`
+	Password string `json:"password" validate:"required"`
+	Timezone string `json:"timezone"`
+	Language string `json:"language"`
+}
+
+// SignupStoreRequest são os dados da primeira loja
+type SignupStoreRequest struct {
+	Name     string              `json:"name" validate:"required"`
+	Code     string              `json:"code" validate:"required"`
+	Timezone string              `json:"timezone"`
+	Address  orgDTO.AddressInput `json:"address"`
+}
+
+type SignupResponse struct {
+	Organization *orgDTO.OrganizationResponse `json:"organization"`
+	User         *UserResponse                `json:"user"`
+	Store        *orgDTO.StoreResponse        `json:"store"`
+}
@@ -0,0 +1,23 @@
+package dto
+
+import "time"
+
+// SSOStartRequest inicia o login corporativo da organização (identificada pelo slug)
+type SSOStartRequest struct {
+	Organization string `json:"organization" validate:"required"`
+}
+
+// SSOStartResponse traz a URL do provedor para onde o navegador deve ir
+type SSOStartResponse struct {
+	AuthorizationURL string    `json:"authorization_url"`
+	ExpiresAt        time.Time `json:"expires_at"`
+}
+
+// SSOCallbackRequest é o que o provedor devolveu ao frontend (code + state) no redirecionamento
+type SSOCallbackRequest struct {
+	Code  string `json:"code" validate:"required"`
+	State string `json:"state" validate:"required"`
+
+	DeviceInfo
+	Client ClientInfo `json:"-"`
+}
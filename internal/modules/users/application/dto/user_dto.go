@@ -9,11 +9,8 @@ import (
 
 // --- Auth DTOs (Login/Refresh) ---
 
-type LoginRequest struct {
-	Email    string `json:"email" validate:"required,email"`
-	Password string `json:"password" validate:"required"`
-
-	// Metadados do App Mobile (Opcionais, mas vitais para UserDevice)
+// DeviceInfo Metadados do App Mobile (Opcionais, mas vitais para UserDevice)
+type DeviceInfo struct {
 	DeviceID   string `json:"device_id,omitempty"`
 	DeviceName string `json:"device_name,omitempty"`
 	PushToken  string `json:"push_token,omitempty"`
@@ -21,47 +18,163 @@ type LoginRequest struct {
 	AppVersion string `json:"app_version,omitempty"`
 }
 
+// ClientInfo é preenchido pelo handler a partir da requisição (nunca vem do JSON)
+type ClientInfo struct {
+	IP        string
+	UserAgent string
+}
+
+type LoginRequest struct {
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"required"`
+
+	DeviceInfo
+	Client ClientInfo `json:"-"`
+}
+
 type LoginResponse struct {
-	AccessToken  string       `json:"access_token"`
-	RefreshToken string       `json:"refresh_token"`
-	ExpiresIn    int          `json:"expires_in"`
-	TokenType    string       `json:"token_type"`
-	User         UserResponse `json:"user"` // Retorna dados básicos e avatar
+	AccessToken  string        `json:"access_token,omitempty"`
+	RefreshToken string        `json:"refresh_token,omitempty"`
+	ExpiresIn    int           `json:"expires_in,omitempty"`
+	TokenType    string        `json:"token_type,omitempty"`
+	User         *UserResponse `json:"user,omitempty"` // Retorna dados básicos e avatar
+
+	// Login em duas etapas: quando preenchidos, não há access token ainda.
+	// O ChallengeToken deve ser enviado para /auth/2fa/verify (ou /auth/2fa/setup),
+	// ou para /auth/password/expired quando a senha passou da validade.
+	TwoFactorRequired      bool   `json:"two_factor_required,omitempty"`
+	TwoFactorSetupRequired bool   `json:"two_factor_setup_required,omitempty"`
+	PasswordChangeRequired bool   `json:"password_change_required,omitempty"`
+	ChallengeToken         string `json:"challenge_token,omitempty"`
+
+	// Exibidos uma única vez, ao concluir o cadastro obrigatório do 2FA
+	RecoveryCodes []string `json:"recovery_codes,omitempty"`
 }
 
 type RefreshTokenRequest struct {
 	RefreshToken string `json:"refresh_token" validate:"required"`
 }
 
+type LogoutRequest struct {
+	// Opcional: encerra também a sessão (refresh token) do aparelho
+	RefreshToken string `json:"refresh_token"`
+}
+
+// --- Two-Factor DTOs (TOTP) ---
+
+// TwoFactorLoginRequest conclui o login com o código do app autenticador ou um código de recuperação
+type TwoFactorLoginRequest struct {
+	ChallengeToken string `json:"challenge_token" validate:"required"`
+	Code           string `json:"code" validate:"required"`
+
+	DeviceInfo
+	Client ClientInfo `json:"-"`
+}
+
+// TwoFactorSetupRequest inicia o cadastro obrigatório (organização exige 2FA) usando o token do login
+type TwoFactorSetupRequest struct {
+	ChallengeToken string `json:"challenge_token" validate:"required"`
+}
+
+// TwoFactorSetupConfirmRequest confirma o cadastro obrigatório e conclui o login
+type TwoFactorSetupConfirmRequest struct {
+	ChallengeToken string `json:"challenge_token" validate:"required"`
+	Code           string `json:"code" validate:"required,len=6"`
+
+	DeviceInfo
+	Client ClientInfo `json:"-"`
+}
+
+// TwoFactorCodeRequest confirma uma operação com o código TOTP atual
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" validate:"required"`
+}
+
+type TwoFactorDisableRequest struct {
+	Password string `json:"password" validate:"required"`
+	Code     string `json:"code" validate:"required"`
+}
+
+type TwoFactorEnrollmentResponse struct {
+	Secret    string `json:"secret"`      // Para digitação manual no app
+	QRCodeURL string `json:"qr_code_url"` // URI otpauth:// para gerar o QR Code
+}
+
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
 // --- User Management DTOs (CRUD) ---
 
 type CreateUserRequest struct {
-	// OrganizationID vem do token do admin ou do código de convite no Service
-	OrganizationID uuid.UUID  `json:"organization_id" validate:"required"`
-	StoreID        *uuid.UUID `json:"store_id"` // Opcional
+	// A organização vem sempre do token de quem cria (nunca do corpo da requisição)
+	StoreID *uuid.UUID `json:"store_id"` // Opcional
 
 	Name     string          `json:"name" validate:"required"`
 	Email    string          `json:"email" validate:"required,email"`
 	Phone    string          `json:"phone"`
-	Password string          `json:"password" validate:"required,min=6"`
-	Role     entity.UserRole `json:"role" validate:"oneof=tenant manager operator"`
+	Password string          `json:"password" validate:"required"`
+	Role     entity.UserRole `json:"role" validate:"required,oneof=tenant_admin manager operator"`
 
 	Timezone string `json:"timezone"`
 	Language string `json:"language"`
 }
 
+// UpdateUserRequest altera o perfil (campos nil são mantidos).
+// Papel, status e loja têm rotas próprias, com as regras de hierarquia.
 type UpdateUserRequest struct {
-	ID        uuid.UUID          `json:"id"`
-	Name      string             `json:"name"`
-	Phone     string             `json:"phone"`
-	AvatarURL string             `json:"avatar_url"`
-	Role      entity.UserRole    `json:"role"`
-	Status    *entity.UserStatus `json:"status"` // Ponteiro para permitir mudança de status
+	Name      *string `json:"name" validate:"omitempty,min=1"`
+	Phone     *string `json:"phone"`
+	AvatarURL *string `json:"avatar_url" validate:"omitempty,url"`
+	Timezone  *string `json:"timezone" validate:"omitempty,timezone"`
+	Language  *string `json:"language"`
+}
+
+type ChangeRoleRequest struct {
+	Role entity.UserRole `json:"role" validate:"required,oneof=tenant_admin manager operator"`
+}
+
+// MoveUserStoreRequest vincula o usuário a uma loja (nil = acesso a toda a organização)
+type MoveUserStoreRequest struct {
+	StoreID *uuid.UUID `json:"store_id"`
+}
+
+// ListUsersFilter são os filtros da listagem (query string)
+type ListUsersFilter struct {
+	Role    *entity.UserRole   `validate:"omitempty,oneof=super_admin support tenant_admin manager operator"`
+	Status  *entity.UserStatus `validate:"omitempty,oneof=pending active suspended"`
+	StoreID *uuid.UUID
 }
 
 type ChangePasswordRequest struct {
-	OldPassword string `json:"old_password"`
-	NewPassword string `json:"new_password" validate:"min=6"`
+	OldPassword string `json:"old_password" validate:"required"`
+	NewPassword string `json:"new_password" validate:"required"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+type ResendVerificationRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+// ExpiredPasswordRequest define a nova senha com o token do login e conclui o login
+type ExpiredPasswordRequest struct {
+	ChallengeToken string `json:"challenge_token" validate:"required"`
+	NewPassword    string `json:"new_password" validate:"required"`
+
+	DeviceInfo
+	Client ClientInfo `json:"-"`
+}
+
+type ResetPasswordRequest struct {
+	Token       string `json:"token" validate:"required"`
+	NewPassword string `json:"new_password" validate:"required"`
 }
 
 // --- Responses ---
@@ -81,10 +194,12 @@ type UserResponse struct {
 	LastLogin      *time.Time        `json:"last_login,omitempty"`
 }
 
-// AuthLogResponse para histórico de segurança
+// RecentLoginResponse para histórico de segurança
 type RecentLoginResponse struct {
-	Date   time.Time `json:"date"`
-	IP     string    `json:"ip"`
-	Device string    `json:"device"` // Ex: "iPhone 13"
-	Status string    `json:"status"` // Sucesso/Falha
+	Date      time.Time `json:"date"`
+	IP        string    `json:"ip"`
+	Device    string    `json:"device"` // Ex: "iPhone 13"
+	UserAgent string    `json:"user_agent,omitempty"`
+	Status    string    `json:"status"`  // success, invalid_password, locked, inactive...
+	Success   bool      `json:"success"` // Atalho para a tela: Sucesso/Falha
 }
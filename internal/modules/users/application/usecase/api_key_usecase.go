@@ -0,0 +1,124 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/application/dto"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/domain/entity"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/domain/repository"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/auth"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/pagination"
+)
+
+var (
+	ErrAPIKeyNotFound        = errors.New("chave de API não encontrada")
+	ErrAPIKeyScopeNotAllowed = errors.New("escopo não permitido para chaves de API")
+)
+
+// apiKeyUsageWindow limita a frequência de escrita do last_used_at
+const apiKeyUsageWindow = time.Minute
+
+// APIKeyUseCase gerencia as chaves de API das organizações (integrações sem login humano)
+type APIKeyUseCase struct {
+	repo    repository.UserRepository
+	keyRepo repository.APIKeyRepository
+}
+
+func NewAPIKeyUseCase(repo repository.UserRepository, keyRepo repository.APIKeyRepository) *APIKeyUseCase {
+	return &APIKeyUseCase{repo: repo, keyRepo: keyRepo}
+}
+
+// Create gera a chave e devolve o valor completo (exibido uma única vez)
+func (uc *APIKeyUseCase) Create(ctx context.Context, actorID, orgID uuid.UUID, input dto.CreateAPIKeyRequest) (*dto.APIKeyCreatedResponse, error) {
+	actor, err := uc.repo.GetByID(ctx, actorID)
+	if err != nil {
+		return nil, err
+	}
+	if actor == nil || actor.Status != entity.StatusActive || actor.OrganizationID != orgID || !actor.Role.Can(entity.PermAPIKeysManage) {
+		return nil, ErrRoleNotAllowed
+	}
+
+	scopes := make([]entity.Permission, 0, len(input.Scopes))
+	seen := make(map[entity.Permission]struct{}, len(input.Scopes))
+	for _, name := range input.Scopes {
+		perm, ok := entity.ParsePermission(name)
+		// A chave nunca recebe mais do que quem a criou
+		if !ok || !perm.AllowedForAPIKey() || !actor.Role.Can(perm) {
+			return nil, fmt.Errorf("%w: %s", ErrAPIKeyScopeNotAllowed, name)
+		}
+		if _, dup := seen[perm]; dup {
+			continue
+		}
+		seen[perm] = struct{}{}
+		scopes = append(scopes, perm)
+	}
+
+	rawKey, prefix, err := auth.GenerateAPIKey()
+	if err != nil {
+		return nil, err
+	}
+
+	key := entity.NewAPIKey(orgID, input.Name, prefix, auth.HashToken(rawKey), scopes, actor.ID, time.Duration(input.ExpiresInDays)*24*time.Hour)
+	if err := uc.keyRepo.Create(ctx, key); err != nil {
+		return nil, fmt.Errorf("erro ao salvar chave de API: %w", err)
+	}
+
+	slog.InfoContext(ctx, "Chave de API criada", "api_key_id", key.ID, "org_id", orgID, "created_by", actor.ID)
+
+	return &dto.APIKeyCreatedResponse{APIKeyResponse: *toAPIKeyResponse(key), Key: rawKey}, nil
+}
+
+// List pagina as chaves da organização
+func (uc *APIKeyUseCase) List(ctx context.Context, orgID uuid.UUID, params pagination.Params) ([]*dto.APIKeyResponse, int64, error) {
+	keys, totalItems, err := uc.keyRepo.ListByOrganization(ctx, orgID, params)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	res := make([]*dto.APIKeyResponse, 0, len(keys))
+	for _, k := range keys {
+		res = append(res, toAPIKeyResponse(k))
+	}
+	return res, totalItems, nil
+}
+
+// Revoke invalida a chave na hora (a próxima requisição com ela já recebe 401)
+func (uc *APIKeyUseCase) Revoke(ctx context.Context, orgID, id uuid.UUID) error {
+	key, err := uc.keyRepo.GetByID(ctx, orgID, id)
+	if err != nil {
+		return err
+	}
+	if key == nil {
+		return ErrAPIKeyNotFound
+	}
+	return uc.keyRepo.Revoke(ctx, orgID, id, time.Now().UTC())
+}
+
+// AuthenticateAPIKey resolve a chave apresentada na requisição (nil = inválida, expirada ou revogada)
+func (uc *APIKeyUseCase) AuthenticateAPIKey(ctx context.Context, rawKey string) (*entity.APIKey, error) {
+	key, err := uc.keyRepo.GetByHash(ctx, auth.HashToken(rawKey))
+	if err != nil {
+		return nil, err
+	}
+	if key == nil || !key.IsActive() {
+		return nil, nil
+	}
+
+	if err := uc.keyRepo.TouchLastUsed(ctx, key.ID, time.Now().UTC(), apiKeyUsageWindow); err != nil {
+		slog.ErrorContext(ctx, "Falha ao registrar uso da chave de API", "api_key_id", key.ID, "error", err)
+	}
+	return key, nil
+}
+
+func toAPIKeyResponse(k *entity.APIKey) *dto.APIKeyResponse {
+	return &dto.APIKeyResponse{
+		ID: k.ID, Name: k.Name, Prefix: k.Prefix, Scopes: k.Scopes, Active: k.IsActive(),
+		CreatedBy: k.CreatedBy, ExpiresAt: k.ExpiresAt, LastUsedAt: k.LastUsedAt,
+		RevokedAt: k.RevokedAt, CreatedAt: k.CreatedAt,
+	}
+}
@@ -0,0 +1,102 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	orgRepository "github.com/paulochiaradia/smart-gondola-backend/internal/modules/organizations/domain/repository"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/application/dto"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/domain/entity"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/domain/repository"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/pagination"
+)
+
+var ErrDeviceNotFound = errors.New("dispositivo não encontrado")
+
+// DeviceUseCase gerencia os aparelhos registrados no login (o registro em si acontece no UserUseCase)
+type DeviceUseCase struct {
+	repo        repository.UserRepository
+	deviceRepo  repository.DeviceRepository
+	refreshRepo repository.RefreshTokenRepository
+	storeRepo   orgRepository.StoreRepository
+}
+
+func NewDeviceUseCase(
+	repo repository.UserRepository,
+	deviceRepo repository.DeviceRepository,
+	refreshRepo repository.RefreshTokenRepository,
+	storeRepo orgRepository.StoreRepository,
+) *DeviceUseCase {
+	return &DeviceUseCase{repo: repo, deviceRepo: deviceRepo, refreshRepo: refreshRepo, storeRepo: storeRepo}
+}
+
+// ListMine lista os aparelhos do próprio usuário
+func (uc *DeviceUseCase) ListMine(ctx context.Context, userID uuid.UUID) ([]*dto.DeviceResponse, error) {
+	devices, err := uc.deviceRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]*dto.DeviceResponse, 0, len(devices))
+	for _, d := range devices {
+		res = append(res, toDeviceResponse(d))
+	}
+	return res, nil
+}
+
+// Remove esquece o aparelho e encerra as sessões abertas nele (ele para de receber alertas)
+func (uc *DeviceUseCase) Remove(ctx context.Context, userID, id uuid.UUID) error {
+	device, err := uc.deviceRepo.Delete(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+	if device == nil {
+		return ErrDeviceNotFound
+	}
+
+	if err := uc.refreshRepo.RevokeByDevice(ctx, userID, device.DeviceID); err != nil {
+		return fmt.Errorf("erro ao encerrar sessões do dispositivo: %w", err)
+	}
+	return nil
+}
+
+// ListByStore mostra quais aparelhos recebem os alertas de uma loja.
+// Quem está preso a uma loja só enxerga a própria.
+func (uc *DeviceUseCase) ListByStore(ctx context.Context, actorID, orgID, storeID uuid.UUID, params pagination.Params) ([]*dto.StoreDeviceResponse, int64, error) {
+	actor, err := uc.repo.GetByID(ctx, actorID)
+	if err != nil {
+		return nil, 0, err
+	}
+	if actor == nil || actor.OrganizationID != orgID {
+		return nil, 0, ErrStoreNotFound
+	}
+	if _, err := resolveStore(ctx, uc.storeRepo, actor, &storeID); err != nil {
+		return nil, 0, err
+	}
+
+	devices, total, err := uc.deviceRepo.ListByStore(ctx, orgID, storeID, params)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	res := make([]*dto.StoreDeviceResponse, 0, len(devices))
+	for _, d := range devices {
+		res = append(res, &dto.StoreDeviceResponse{
+			DeviceResponse: *toDeviceResponse(&d.UserDevice),
+			User: dto.DeviceOwnerResponse{
+				ID: d.UserID, Name: d.UserName, Email: d.UserEmail, Role: d.UserRole,
+			},
+		})
+	}
+	return res, total, nil
+}
+
+func toDeviceResponse(d *entity.UserDevice) *dto.DeviceResponse {
+	return &dto.DeviceResponse{
+		ID: d.ID, DeviceID: d.DeviceID, DeviceName: d.DeviceName, Platform: d.Platform,
+		AppVersion: d.AppVersion, PushEnabled: d.HasPush(),
+		LastActiveAt: d.LastActiveAt, CreatedAt: d.CreatedAt,
+	}
+}
@@ -0,0 +1,112 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+	"time"
+
+	orgEntity "github.com/paulochiaradia/smart-gondola-backend/internal/modules/organizations/domain/entity"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/application/dto"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/domain/entity"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/auth"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/config"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/mailer"
+)
+
+// Ciclo de vida da confirmação de email do UserUseCase
+
+const verificationResendInterval = 2 * time.Minute // Intervalo mínimo entre dois envios do link
+
+var (
+	ErrEmailNotVerified         = errors.New("email não verificado")
+	ErrInvalidVerificationToken = errors.New("link de verificação inválido ou expirado")
+)
+
+// VerifyEmail confirma o email a partir do link assinado (idempotente)
+func (uc *UserUseCase) VerifyEmail(ctx context.Context, input dto.VerifyEmailRequest) error {
+	claims, err := auth.ValidateScopedToken(input.Token, auth.TokenUseEmailVerification)
+	if err != nil {
+		return ErrInvalidVerificationToken
+	}
+
+	user, err := uc.repo.GetByID(ctx, claims.UserID)
+	if err != nil {
+		return err
+	}
+	// O link vale apenas para o email ao qual foi enviado
+	if user == nil || !strings.EqualFold(user.Email, claims.Email) {
+		return ErrInvalidVerificationToken
+	}
+	if user.IsEmailVerified() {
+		return nil
+	}
+
+	user.MarkEmailVerified()
+	return uc.repo.UpdateEmailVerification(ctx, user)
+}
+
+// ResendVerification reenvia o link. Não revela se o email existe e respeita o intervalo mínimo.
+func (uc *UserUseCase) ResendVerification(ctx context.Context, input dto.ResendVerificationRequest) error {
+	user, err := uc.repo.GetByEmail(ctx, input.Email)
+	if err != nil {
+		return err
+	}
+	if user == nil || user.IsEmailVerified() || user.Status == entity.StatusSuspended {
+		return nil
+	}
+	if !user.CanResendVerification(verificationResendInterval) {
+		return nil
+	}
+
+	return uc.sendVerificationEmail(ctx, user)
+}
+
+// sendVerificationEmail envia o link assinado e registra o horário do envio
+func (uc *UserUseCase) sendVerificationEmail(ctx context.Context, user *entity.User) error {
+	cfg := config.Get()
+	token, err := auth.GenerateEmailVerificationToken(user.ID, user.Email, cfg.EmailVerificationExpiration)
+	if err != nil {
+		return fmt.Errorf("erro ao gerar link de verificação: %w", err)
+	}
+
+	link := strings.TrimRight(cfg.AppURL, "/") + "/verify-email?token=" + url.QueryEscape(token)
+	msg := mailer.Message{
+		To:      user.Email,
+		Subject: "Confirme seu email - Smart Gondola",
+		Body: fmt.Sprintf(
+			"Olá, %s.\n\nConfirme seu email acessando o link abaixo (válido por %s):\n\n%s\n",
+			user.Name, cfg.EmailVerificationExpiration, link,
+		),
+	}
+	if err := uc.mailer.Send(ctx, msg); err != nil {
+		return fmt.Errorf("erro ao enviar email de verificação: %w", err)
+	}
+
+	user.MarkVerificationSent()
+	return uc.repo.UpdateEmailVerification(ctx, user)
+}
+
+// unverifiedEmailPolicy devolve a política da organização que se aplica ao usuário.
+// Usuários com email confirmado não consultam a organização.
+func (uc *UserUseCase) unverifiedEmailPolicy(ctx context.Context, user *entity.User) (orgEntity.UnverifiedEmailPolicy, error) {
+	if user.IsEmailVerified() {
+		return orgEntity.UnverifiedEmailAllow, nil
+	}
+
+	settings, err := uc.organizationSettings(ctx, user.OrganizationID)
+	if err != nil {
+		return "", err
+	}
+	return settings.EmailPolicy(), nil
+}
+
+// notifyVerification envia o link após o cadastro; falhas de envio não desfazem o cadastro
+func (uc *UserUseCase) notifyVerification(ctx context.Context, user *entity.User) {
+	if err := uc.sendVerificationEmail(ctx, user); err != nil {
+		slog.ErrorContext(ctx, "Falha ao enviar verificação de email", "user_id", user.ID, "error", err)
+	}
+}
@@ -0,0 +1,171 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/application/dto"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/domain/entity"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/domain/repository"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/auth"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/config"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/pagination"
+)
+
+var (
+	ErrImpersonationNotAllowed      = errors.New("este usuário não pode ser impersonado")
+	ErrImpersonationWriteNotAllowed = errors.New("apenas super admins podem impersonar com acesso de escrita")
+	ErrImpersonationNotFound        = errors.New("sessão de impersonação não encontrada")
+)
+
+// ImpersonationUseCase permite à equipe da plataforma agir como um usuário do tenant (suporte),
+// sempre com prazo, motivo e trilha de auditoria
+type ImpersonationUseCase struct {
+	repo        repository.UserRepository
+	impRepo     repository.ImpersonationRepository
+	revokedRepo repository.RevokedTokenRepository
+}
+
+func NewImpersonationUseCase(
+	repo repository.UserRepository,
+	impRepo repository.ImpersonationRepository,
+	revokedRepo repository.RevokedTokenRepository,
+) *ImpersonationUseCase {
+	return &ImpersonationUseCase{repo: repo, impRepo: impRepo, revokedRepo: revokedRepo}
+}
+
+// Start abre a sessão e emite o token do usuário impersonado (com o claim "act" do ator real)
+func (uc *ImpersonationUseCase) Start(ctx context.Context, actorID uuid.UUID, input dto.StartImpersonationRequest) (*dto.ImpersonationResponse, error) {
+	actor, err := uc.repo.GetByID(ctx, actorID)
+	if err != nil {
+		return nil, err
+	}
+	if actor == nil || actor.Status != entity.StatusActive || !actor.Role.Can(entity.PermUsersImpersonate) {
+		return nil, ErrRoleNotAllowed
+	}
+	if input.WriteAccess && actor.Role != entity.RoleSuperAdmin {
+		return nil, ErrImpersonationWriteNotAllowed
+	}
+
+	target, err := uc.repo.GetByID(ctx, input.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if target == nil {
+		return nil, ErrUserNotFound
+	}
+	// Ninguém impersona a si mesmo, outro membro da plataforma ou um usuário sem acesso
+	if target.ID == actor.ID || target.Role.IsPlatform() || target.Status != entity.StatusActive {
+		return nil, ErrImpersonationNotAllowed
+	}
+
+	session := entity.NewImpersonationSession(actor, target, input.Reason, input.WriteAccess, input.Client.IP, config.Get().ImpersonationTTL)
+	if err := uc.impRepo.Create(ctx, session); err != nil {
+		return nil, fmt.Errorf("erro ao registrar impersonação: %w", err)
+	}
+
+	token, err := auth.GenerateImpersonationToken(target.ID, target.OrganizationID, target.BoundStoreID(), string(target.Role), session.ReadOnly, auth.Impersonation{
+		SessionID: session.ID,
+		ActorID:   actor.ID,
+		ActorRole: string(actor.Role),
+		TokenID:   session.TokenID,
+		ExpiresAt: session.ExpiresAt,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("erro ao gerar token de impersonação: %w", err)
+	}
+
+	slog.InfoContext(ctx, "Impersonação iniciada",
+		"session_id", session.ID, "actor_id", actor.ID, "target_user_id", target.ID, "read_only", session.ReadOnly)
+
+	return &dto.ImpersonationResponse{
+		SessionID:   session.ID,
+		AccessToken: token,
+		ExpiresAt:   session.ExpiresAt,
+		ReadOnly:    session.ReadOnly,
+		User:        toUserResponse(target),
+	}, nil
+}
+
+// End encerra uma sessão pelo painel da plataforma (revoga o token na hora)
+func (uc *ImpersonationUseCase) End(ctx context.Context, sessionID uuid.UUID) error {
+	session, err := uc.impRepo.GetByID(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+	if session == nil {
+		return ErrImpersonationNotFound
+	}
+	if session.EndedAt != nil {
+		return nil
+	}
+	return uc.end(ctx, session.TokenID, session.TargetUserID, session.ExpiresAt)
+}
+
+// EndCurrent encerra a sessão do próprio token de impersonação
+func (uc *ImpersonationUseCase) EndCurrent(ctx context.Context, userID uuid.UUID, tokenID string, tokenExpiresAt time.Time) error {
+	return uc.end(ctx, tokenID, userID, tokenExpiresAt)
+}
+
+func (uc *ImpersonationUseCase) end(ctx context.Context, tokenID string, userID uuid.UUID, expiresAt time.Time) error {
+	if err := uc.revokedRepo.Revoke(ctx, tokenID, userID, expiresAt); err != nil {
+		return fmt.Errorf("erro ao revogar token de impersonação: %w", err)
+	}
+	if err := uc.impRepo.EndByTokenID(ctx, tokenID, time.Now().UTC()); err != nil {
+		return fmt.Errorf("erro ao encerrar impersonação: %w", err)
+	}
+	return nil
+}
+
+// RecordImpersonatedRequest grava a requisição na trilha de auditoria. Falhas são logadas, não derrubam a requisição.
+func (uc *ImpersonationUseCase) RecordImpersonatedRequest(ctx context.Context, sessionID uuid.UUID, method, path string, status int) {
+	event := entity.NewImpersonationEvent(sessionID, method, path, status)
+	if err := uc.impRepo.RecordEvent(ctx, event); err != nil {
+		slog.ErrorContext(ctx, "Falha ao auditar requisição impersonada", "session_id", sessionID, "path", path, "error", err)
+	}
+}
+
+// List pagina as sessões de impersonação (auditoria da plataforma)
+func (uc *ImpersonationUseCase) List(ctx context.Context, params pagination.Params) ([]*dto.ImpersonationSessionResponse, int64, error) {
+	sessions, totalItems, err := uc.impRepo.List(ctx, params)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	res := make([]*dto.ImpersonationSessionResponse, 0, len(sessions))
+	for _, s := range sessions {
+		res = append(res, &dto.ImpersonationSessionResponse{
+			ID: s.ID, ActorID: s.ActorID, ActorRole: s.ActorRole,
+			TargetUserID: s.TargetUserID, TargetOrgID: s.TargetOrgID,
+			Reason: s.Reason, ReadOnly: s.ReadOnly, Active: s.IsActive(), IPAddress: s.IPAddress,
+			ExpiresAt: s.ExpiresAt, EndedAt: s.EndedAt, CreatedAt: s.CreatedAt,
+		})
+	}
+	return res, totalItems, nil
+}
+
+// ListEvents devolve a trilha de requisições de uma sessão
+func (uc *ImpersonationUseCase) ListEvents(ctx context.Context, sessionID uuid.UUID, params pagination.Params) ([]*dto.ImpersonationEventResponse, int64, error) {
+	session, err := uc.impRepo.GetByID(ctx, sessionID)
+	if err != nil {
+		return nil, 0, err
+	}
+	if session == nil {
+		return nil, 0, ErrImpersonationNotFound
+	}
+
+	events, totalItems, err := uc.impRepo.ListEvents(ctx, sessionID, params)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	res := make([]*dto.ImpersonationEventResponse, 0, len(events))
+	for _, e := range events {
+		res = append(res, &dto.ImpersonationEventResponse{Method: e.Method, Path: e.Path, Status: e.Status, CreatedAt: e.CreatedAt})
+	}
+	return res, totalItems, nil
+}
@@ -0,0 +1,272 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	orgUseCase "github.com/paulochiaradia/smart-gondola-backend/internal/modules/organizations/application/usecase"
+	orgEntity "github.com/paulochiaradia/smart-gondola-backend/internal/modules/organizations/domain/entity"
+	orgRepository "github.com/paulochiaradia/smart-gondola-backend/internal/modules/organizations/domain/repository"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/application/dto"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/domain/entity"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/domain/repository"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/auth"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/config"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/database"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/mailer"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/pagination"
+)
+
+// InviteUseCase cuida da entrada de usuários em uma organização existente por convite
+type InviteUseCase struct {
+	repo       repository.UserRepository
+	inviteRepo repository.InviteRepository
+	storeRepo  orgRepository.StoreRepository
+	passwords  *PasswordPolicyEnforcer
+	quota      *orgUseCase.QuotaUseCase
+	tx         database.Transactor
+	mailer     mailer.Mailer
+}
+
+var (
+	ErrInviteNotFound    = errors.New("convite não encontrado")
+	ErrInvalidInvite     = errors.New("convite inválido ou expirado")
+	ErrInviteAlreadyOpen = errors.New("já existe um convite em aberto para este email")
+)
+
+func NewInviteUseCase(
+	repo repository.UserRepository,
+	inviteRepo repository.InviteRepository,
+	storeRepo orgRepository.StoreRepository,
+	passwords *PasswordPolicyEnforcer,
+	quota *orgUseCase.QuotaUseCase,
+	tx database.Transactor,
+	m mailer.Mailer,
+) *InviteUseCase {
+	return &InviteUseCase{repo: repo, inviteRepo: inviteRepo, storeRepo: storeRepo, passwords: passwords, quota: quota, tx: tx, mailer: m}
+}
+
+// Create convida um email para a organização de quem convida
+func (uc *InviteUseCase) Create(ctx context.Context, inviterID, orgID uuid.UUID, input dto.CreateInviteRequest) (*dto.InviteResponse, error) {
+	inviter, err := uc.inviter(ctx, inviterID, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if !inviter.Role.CanAssign(input.Role) {
+		return nil, ErrRoleNotAllowed
+	}
+
+	storeID, err := resolveStore(ctx, uc.storeRepo, inviter, input.StoreID)
+	if err != nil {
+		return nil, err
+	}
+
+	email := entity.NormalizeEmail(input.Email)
+	// A sessão da requisição só enxerga a própria organização: a checagem precisa ser global
+	exists, err := uc.repo.EmailExists(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, ErrEmailAlreadyInUse
+	}
+
+	// Convite expirado em aberto é descartado; um ainda válido deve ser reenviado, não duplicado
+	open, err := uc.inviteRepo.GetOpenByEmail(ctx, orgID, email)
+	if err != nil {
+		return nil, err
+	}
+	if open != nil {
+		if open.Status() == entity.InvitePending {
+			return nil, ErrInviteAlreadyOpen
+		}
+		if err := open.Revoke(); err != nil {
+			return nil, err
+		}
+		if err := uc.inviteRepo.Update(ctx, open); err != nil {
+			return nil, fmt.Errorf("erro ao descartar convite expirado: %w", err)
+		}
+	}
+
+	rawToken, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+
+	invite, err := entity.NewInvite(orgID, inviter.ID, storeID, email, input.Role, auth.HashToken(rawToken), config.Get().InviteExpiration)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.inviteRepo.Create(ctx, invite); err != nil {
+		return nil, fmt.Errorf("erro ao salvar convite: %w", err)
+	}
+
+	uc.sendInvite(ctx, inviter, invite, rawToken)
+	return toInviteResponse(invite), nil
+}
+
+// ListOpen lista os convites em aberto da organização
+func (uc *InviteUseCase) ListOpen(ctx context.Context, orgID uuid.UUID, params pagination.Params) ([]*dto.InviteResponse, int64, error) {
+	invites, total, err := uc.inviteRepo.ListOpenByOrganization(ctx, orgID, params)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	res := make([]*dto.InviteResponse, 0, len(invites))
+	for _, i := range invites {
+		res = append(res, toInviteResponse(i))
+	}
+	return res, total, nil
+}
+
+// Resend gera um novo link (o anterior deixa de valer) e renova a validade
+func (uc *InviteUseCase) Resend(ctx context.Context, actorID, orgID, inviteID uuid.UUID) (*dto.InviteResponse, error) {
+	actor, invite, err := uc.manageableInvite(ctx, actorID, orgID, inviteID)
+	if err != nil {
+		return nil, err
+	}
+
+	rawToken, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+	if err := invite.Renew(auth.HashToken(rawToken), config.Get().InviteExpiration); err != nil {
+		return nil, ErrInvalidInvite
+	}
+	if err := uc.inviteRepo.Update(ctx, invite); err != nil {
+		return nil, fmt.Errorf("erro ao renovar convite: %w", err)
+	}
+
+	uc.sendInvite(ctx, actor, invite, rawToken)
+	return toInviteResponse(invite), nil
+}
+
+// Revoke cancela um convite em aberto
+func (uc *InviteUseCase) Revoke(ctx context.Context, actorID, orgID, inviteID uuid.UUID) error {
+	_, invite, err := uc.manageableInvite(ctx, actorID, orgID, inviteID)
+	if err != nil {
+		return err
+	}
+
+	if err := invite.Revoke(); err != nil {
+		return ErrInvalidInvite
+	}
+	return uc.inviteRepo.Update(ctx, invite)
+}
+
+// Accept cria o usuário convidado. Consumir o convite e criar o usuário acontecem na mesma transação.
+func (uc *InviteUseCase) Accept(ctx context.Context, input dto.AcceptInviteRequest) (*dto.UserResponse, error) {
+	invite, err := uc.inviteRepo.GetByTokenHash(ctx, auth.HashToken(input.Token))
+	if err != nil {
+		return nil, err
+	}
+	if invite == nil || invite.Status() != entity.InvitePending {
+		return nil, ErrInvalidInvite
+	}
+
+	existing, err := uc.repo.GetByEmail(ctx, invite.Email)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, ErrEmailAlreadyInUse
+	}
+
+	if err := uc.passwords.Validate(ctx, invite.OrganizationID, nil, input.Password); err != nil {
+		return nil, err
+	}
+
+	user, err := entity.NewUser(invite.OrganizationID, input.Name, invite.Email, input.Password, invite.Role)
+	if err != nil {
+		return nil, err
+	}
+	user.StoreID = invite.StoreID
+	user.InvitedBy = &invite.InvitedBy
+	user.Phone = input.Phone
+	// O link chegou pelo email convidado: a caixa de entrada já está comprovada
+	user.MarkEmailVerified()
+
+	err = uc.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		accepted, err := uc.inviteRepo.MarkAccepted(ctx, invite.ID, time.Now().UTC())
+		if err != nil {
+			return err
+		}
+		if !accepted {
+			return ErrInvalidInvite
+		}
+		return uc.quota.Reserve(ctx, invite.OrganizationID, orgEntity.QuotaUsers, func(ctx context.Context) error {
+			return uc.repo.Create(ctx, user)
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return toUserResponse(user), nil
+}
+
+// --- Helpers ---
+
+// inviter carrega quem está convidando (papel e loja vêm do banco, não do token)
+func (uc *InviteUseCase) inviter(ctx context.Context, userID, orgID uuid.UUID) (*entity.User, error) {
+	user, err := uc.repo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil || user.OrganizationID != orgID || user.Status != entity.StatusActive {
+		return nil, ErrRoleNotAllowed
+	}
+	return user, nil
+}
+
+// manageableInvite busca o convite da organização que o ator tem permissão de gerenciar
+func (uc *InviteUseCase) manageableInvite(ctx context.Context, actorID, orgID, inviteID uuid.UUID) (*entity.User, *entity.Invite, error) {
+	actor, err := uc.inviter(ctx, actorID, orgID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	invite, err := uc.inviteRepo.GetByID(ctx, inviteID)
+	if err != nil {
+		return nil, nil, err
+	}
+	// Convite de outra organização responde como inexistente
+	if invite == nil || invite.OrganizationID != orgID {
+		return nil, nil, ErrInviteNotFound
+	}
+	if !actor.Role.CanAssign(invite.Role) {
+		return nil, nil, ErrRoleNotAllowed
+	}
+	return actor, invite, nil
+}
+
+// sendInvite envia o link; falhas de envio não desfazem o convite (pode ser reenviado)
+func (uc *InviteUseCase) sendInvite(ctx context.Context, inviter *entity.User, invite *entity.Invite, rawToken string) {
+	cfg := config.Get()
+	link := strings.TrimRight(cfg.AppURL, "/") + "/accept-invite?token=" + url.QueryEscape(rawToken)
+
+	msg := mailer.Message{
+		To:      invite.Email,
+		Subject: "Você foi convidado para o Smart Gondola",
+		Body: fmt.Sprintf(
+			"Olá!\n\n%s convidou você para acessar o Smart Gondola. Para criar seu acesso, use o link abaixo (válido até %s):\n\n%s\n",
+			inviter.Name, invite.ExpiresAt.Format("02/01/2006 15:04 MST"), link,
+		),
+	}
+	if err := uc.mailer.Send(ctx, msg); err != nil {
+		slog.ErrorContext(ctx, "Falha ao enviar convite", "invite_id", invite.ID, "error", err)
+	}
+}
+
+func toInviteResponse(i *entity.Invite) *dto.InviteResponse {
+	return &dto.InviteResponse{
+		ID: i.ID, Email: i.Email, Role: i.Role, StoreID: i.StoreID, Status: i.Status(),
+		InvitedBy: i.InvitedBy, ExpiresAt: i.ExpiresAt, CreatedAt: i.CreatedAt,
+	}
+}
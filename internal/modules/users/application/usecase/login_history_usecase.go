@@ -0,0 +1,61 @@
+package usecase
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/application/dto"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/domain/entity"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/pagination"
+)
+
+// recordLoginAttempt grava a tentativa no histórico. Falhas aqui não derrubam o login, só são logadas.
+func (uc *UserUseCase) recordLoginAttempt(ctx context.Context, user *entity.User, email string, status entity.LoginAttemptStatus, device dto.DeviceInfo, client dto.ClientInfo) {
+	var userID *uuid.UUID
+	if user != nil {
+		userID = &user.ID
+	}
+
+	attempt := entity.NewLoginAttempt(userID, email, status, client.IP, client.UserAgent, device.DeviceID, device.DeviceName)
+	if err := uc.attemptRepo.Create(ctx, attempt); err != nil {
+		slog.ErrorContext(ctx, "Falha ao registrar tentativa de login", "email", email, "status", status, "error", err)
+	}
+}
+
+// ListMyLogins devolve o histórico de logins do próprio usuário (tela "Segurança")
+func (uc *UserUseCase) ListMyLogins(ctx context.Context, userID uuid.UUID, params pagination.Params) ([]*dto.RecentLoginResponse, int64, error) {
+	return uc.listLogins(ctx, userID, params)
+}
+
+// ListUserLogins é a visão do admin sobre o histórico de um usuário da mesma organização
+func (uc *UserUseCase) ListUserLogins(ctx context.Context, orgID, userID uuid.UUID, params pagination.Params) ([]*dto.RecentLoginResponse, int64, error) {
+	user, err := uc.repo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, 0, err
+	}
+	if user == nil || user.OrganizationID != orgID {
+		return nil, 0, ErrUserNotFound
+	}
+	return uc.listLogins(ctx, userID, params)
+}
+
+func (uc *UserUseCase) listLogins(ctx context.Context, userID uuid.UUID, params pagination.Params) ([]*dto.RecentLoginResponse, int64, error) {
+	attempts, total, err := uc.attemptRepo.ListByUser(ctx, userID, params)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	res := make([]*dto.RecentLoginResponse, 0, len(attempts))
+	for _, a := range attempts {
+		device := a.DeviceName
+		if device == "" {
+			device = a.DeviceID
+		}
+		res = append(res, &dto.RecentLoginResponse{
+			Date: a.CreatedAt, IP: a.IPAddress, Device: device, UserAgent: a.UserAgent,
+			Status: string(a.Status), Success: a.Succeeded(),
+		})
+	}
+	return res, total, nil
+}
@@ -0,0 +1,266 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/application/dto"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/domain/entity"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/domain/repository"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/pagination"
+)
+
+// Regras do throttle de login. Cada falha conta nos três escopos; o que distingue
+// a pessoa errando a própria senha de um ataque é qual deles estoura primeiro.
+var (
+	// Mesmo IP + mesmo email: quase sempre o próprio usuário digitando errado
+	pairThrottleRule = entity.ThrottleRule{
+		MaxFailures: 5, Window: 15 * time.Minute,
+		BaseLockout: time.Minute, MaxLockout: time.Hour, Decay: 24 * time.Hour,
+	}
+	// Mesmo email a partir de vários IPs: ataque distribuído contra a conta.
+	// Não vale para IPs de onde o usuário já entrou, então o atacante não consegue trancar a vítima.
+	emailThrottleRule = entity.ThrottleRule{
+		MaxFailures: 20, Window: time.Hour,
+		BaseLockout: 5 * time.Minute, MaxLockout: time.Hour, Decay: 24 * time.Hour,
+	}
+	// Mesmo IP, qualquer email: volume de erros ou muitas contas diferentes (credential stuffing)
+	ipThrottleRule = entity.ThrottleRule{
+		MaxFailures: 50, Window: 15 * time.Minute,
+		BaseLockout: 15 * time.Minute, MaxLockout: 24 * time.Hour, Decay: 7 * 24 * time.Hour,
+	}
+)
+
+const (
+	stuffingDistinctEmails = 10                  // Contas diferentes erradas pelo mesmo IP na janela do ipThrottleRule
+	knownIPWindow          = 90 * 24 * time.Hour // IP com login de sucesso nesse período é "conhecido" do email
+)
+
+var ErrLoginThrottled = errors.New("muitas tentativas de login")
+
+// LoginThrottledError é o bloqueio em vigor para a tentativa (429 com Retry-After)
+type LoginThrottledError struct {
+	Scope      entity.ThrottleScope
+	RetryAfter time.Duration
+}
+
+func (e *LoginThrottledError) Error() string {
+	if e.Scope == entity.ThrottleIP {
+		return "muitas tentativas de login a partir deste endereço, tente novamente mais tarde"
+	}
+	return "conta temporariamente bloqueada..."
+}
+
+func (e *LoginThrottledError) Unwrap() error {
+	return ErrLoginThrottled
+}
+
+// LoginThrottleUseCase conta as falhas de login por IP, por email e por IP+email,
+// bloqueando com backoff exponencial, e permite aos administradores desbloquear
+type LoginThrottleUseCase struct {
+	repo        repository.LoginThrottleRepository
+	attemptRepo repository.LoginAttemptRepository
+}
+
+func NewLoginThrottleUseCase(repo repository.LoginThrottleRepository, attemptRepo repository.LoginAttemptRepository) *LoginThrottleUseCase {
+	return &LoginThrottleUseCase{repo: repo, attemptRepo: attemptRepo}
+}
+
+// Check devolve *LoginThrottledError se algum bloqueio vale para este IP + email
+func (uc *LoginThrottleUseCase) Check(ctx context.Context, ip, email string) error {
+	email = normalizeThrottleEmail(email)
+	now := time.Now().UTC()
+
+	blocking, err := uc.repo.Blocking(ctx, throttleKeys(ip, email), now)
+	if err != nil {
+		return fmt.Errorf("erro ao consultar bloqueios de login: %w", err)
+	}
+
+	// Ordenados do bloqueio mais longo para o mais curto
+	for _, t := range blocking {
+		if t.Scope == entity.ThrottleEmail {
+			known, err := uc.knownIP(ctx, ip, email, now)
+			if err != nil {
+				return err
+			}
+			if known {
+				continue
+			}
+		}
+		return &LoginThrottledError{Scope: t.Scope, RetryAfter: t.BlockedUntil.Sub(now)}
+	}
+	return nil
+}
+
+// RegisterFailure conta uma falha (senha, 2FA ou email inexistente). Deve ser chamado depois de
+// gravar a tentativa no histórico, que é onde o credential stuffing é detectado.
+// Devolve *LoginThrottledError se a falha fez esta tentativa ser bloqueada.
+func (uc *LoginThrottleUseCase) RegisterFailure(ctx context.Context, ip, email string) error {
+	email = normalizeThrottleEmail(email)
+	now := time.Now().UTC()
+	var blocked *entity.LoginThrottle
+
+	keep := func(t *entity.LoginThrottle) {
+		if t != nil && t.IsBlocked(now) && (blocked == nil || t.BlockedUntil.After(*blocked.BlockedUntil)) {
+			blocked = t
+		}
+	}
+
+	if ip != "" {
+		t, err := uc.repo.RegisterFailure(ctx, entity.NewThrottleKey(entity.ThrottleIPEmail, ip, email), pairThrottleRule, entity.ThrottleReasonTypos, now)
+		if err != nil {
+			return fmt.Errorf("erro ao registrar falha de login: %w", err)
+		}
+		keep(t)
+	}
+
+	// Erros vindos de um IP conhecido ficam só no par: não trancam a conta para os outros aparelhos
+	known, err := uc.knownIP(ctx, ip, email, now)
+	if err != nil {
+		return err
+	}
+	if !known {
+		t, err := uc.repo.RegisterFailure(ctx, entity.NewThrottleKey(entity.ThrottleEmail, ip, email), emailThrottleRule, entity.ThrottleReasonDistributed, now)
+		if err != nil {
+			return fmt.Errorf("erro ao registrar falha de login: %w", err)
+		}
+		keep(t)
+	}
+
+	if ip != "" {
+		t, err := uc.registerIPFailure(ctx, ip, now)
+		if err != nil {
+			return err
+		}
+		keep(t)
+	}
+
+	if blocked == nil {
+		return nil
+	}
+	return &LoginThrottledError{Scope: blocked.Scope, RetryAfter: blocked.BlockedUntil.Sub(now)}
+}
+
+// registerIPFailure conta a falha no IP e o bloqueia na hora se ele já errou contas demais
+func (uc *LoginThrottleUseCase) registerIPFailure(ctx context.Context, ip string, now time.Time) (*entity.LoginThrottle, error) {
+	key := entity.NewThrottleKey(entity.ThrottleIP, ip, "")
+
+	t, err := uc.repo.RegisterFailure(ctx, key, ipThrottleRule, entity.ThrottleReasonCredentialStuffing, now)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao registrar falha de login: %w", err)
+	}
+	if t.IsBlocked(now) {
+		slog.WarnContext(ctx, "IP bloqueado por excesso de falhas de login", "ip", ip, "lockouts", t.Lockouts)
+		return t, nil
+	}
+
+	accounts, err := uc.attemptRepo.CountFailedEmailsByIP(ctx, ip, now.Add(-ipThrottleRule.Window))
+	if err != nil {
+		return nil, fmt.Errorf("erro ao consultar histórico de logins: %w", err)
+	}
+	if accounts < stuffingDistinctEmails {
+		return t, nil
+	}
+
+	locked, err := uc.repo.Block(ctx, key, ipThrottleRule, entity.ThrottleReasonCredentialStuffing, now)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao bloquear IP: %w", err)
+	}
+	if locked != nil {
+		slog.WarnContext(ctx, "Credential stuffing detectado, IP bloqueado", "ip", ip, "accounts", accounts, "lockouts", locked.Lockouts)
+	}
+	return locked, nil
+}
+
+// RegisterSuccess zera o contador do par IP + email (quem acertou a senha não está digitando errado).
+// Os contadores do email e do IP continuam: um ataque em andamento não recomeça do zero.
+func (uc *LoginThrottleUseCase) RegisterSuccess(ctx context.Context, ip, email string) error {
+	if ip == "" {
+		return nil
+	}
+	if err := uc.repo.Reset(ctx, entity.NewThrottleKey(entity.ThrottleIPEmail, ip, normalizeThrottleEmail(email))); err != nil {
+		return fmt.Errorf("erro ao zerar falhas de login: %w", err)
+	}
+	return nil
+}
+
+// UnlockEmail apaga os bloqueios da conta (desbloqueio pelo admin ou senha redefinida)
+func (uc *LoginThrottleUseCase) UnlockEmail(ctx context.Context, email string) (int64, error) {
+	n, err := uc.repo.ResetEmail(ctx, normalizeThrottleEmail(email))
+	if err != nil {
+		return 0, fmt.Errorf("erro ao desbloquear login: %w", err)
+	}
+	return n, nil
+}
+
+// Unlock é o desbloqueio da plataforma: email, IP ou os dois
+func (uc *LoginThrottleUseCase) Unlock(ctx context.Context, input dto.UnlockLoginRequest) (*dto.UnlockLoginResponse, error) {
+	var res dto.UnlockLoginResponse
+	if input.Email != "" {
+		n, err := uc.UnlockEmail(ctx, input.Email)
+		if err != nil {
+			return nil, err
+		}
+		res.Cleared += n
+	}
+	if input.IPAddress != "" {
+		n, err := uc.repo.ResetIP(ctx, input.IPAddress)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao desbloquear login: %w", err)
+		}
+		res.Cleared += n
+	}
+	return &res, nil
+}
+
+// ListBlocked pagina os bloqueios em vigor
+func (uc *LoginThrottleUseCase) ListBlocked(ctx context.Context, params pagination.Params) ([]dto.LoginThrottleResponse, int64, error) {
+	throttles, totalItems, err := uc.repo.ListBlocked(ctx, time.Now().UTC(), params)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	res := make([]dto.LoginThrottleResponse, 0, len(throttles))
+	for _, t := range throttles {
+		res = append(res, dto.LoginThrottleResponse{
+			Scope:        t.Scope,
+			IPAddress:    t.IPAddress,
+			Email:        t.Email,
+			Reason:       t.Reason,
+			Lockouts:     t.Lockouts,
+			BlockedUntil: t.BlockedUntil,
+		})
+	}
+	return res, totalItems, nil
+}
+
+// knownIP indica se o usuário já entrou com sucesso a partir deste IP
+func (uc *LoginThrottleUseCase) knownIP(ctx context.Context, ip, email string, now time.Time) (bool, error) {
+	if ip == "" {
+		return false, nil
+	}
+	known, err := uc.attemptRepo.HasSucceededFrom(ctx, email, ip, now.Add(-knownIPWindow))
+	if err != nil {
+		return false, fmt.Errorf("erro ao consultar histórico de logins: %w", err)
+	}
+	return known, nil
+}
+
+func throttleKeys(ip, email string) []entity.ThrottleKey {
+	keys := []entity.ThrottleKey{entity.NewThrottleKey(entity.ThrottleEmail, ip, email)}
+	if ip != "" {
+		keys = append(keys,
+			entity.NewThrottleKey(entity.ThrottleIPEmail, ip, email),
+			entity.NewThrottleKey(entity.ThrottleIP, ip, email),
+		)
+	}
+	return keys
+}
+
+func normalizeThrottleEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
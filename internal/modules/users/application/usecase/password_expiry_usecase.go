@@ -0,0 +1,60 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/application/dto"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/domain/entity"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/auth"
+)
+
+// Senha vencida (validade da política da organização) durante o login
+
+const passwordChangeChallengeTTL = 10 * time.Minute
+
+var ErrInvalidPasswordChallenge = errors.New("troca de senha expirada ou inválida, faça login novamente")
+
+// ChangeExpiredPassword troca a senha vencida com o token devolvido pelo login e segue com o login
+// (o 2FA, se houver, ainda é pedido depois)
+func (uc *UserUseCase) ChangeExpiredPassword(ctx context.Context, input dto.ExpiredPasswordRequest) (*dto.LoginResponse, error) {
+	claims, err := auth.ValidateScopedToken(input.ChallengeToken, auth.TokenUsePasswordChange)
+	if err != nil {
+		return nil, ErrInvalidPasswordChallenge
+	}
+	user, err := uc.repo.GetByID(ctx, claims.UserID)
+	if err != nil {
+		return nil, err
+	}
+	// O token vale só para a senha que venceu: depois da troca (ou de um logout-all), não serve de novo
+	if user == nil || user.Status != entity.StatusActive || !issuedAfterCutoff(user, claims.IssuedAtPrecise()) {
+		return nil, ErrInvalidPasswordChallenge
+	}
+
+	if err := uc.passwords.SetPassword(ctx, user, input.NewPassword); err != nil {
+		return nil, err
+	}
+	if err := uc.repo.UpdateSecurity(ctx, user); err != nil {
+		return nil, fmt.Errorf("erro ao salvar nova senha: %w", err)
+	}
+	if err := uc.refreshRepo.RevokeAllByUser(ctx, user.ID); err != nil {
+		return nil, fmt.Errorf("erro ao encerrar sessões: %w", err)
+	}
+
+	return uc.continueLogin(ctx, user, input.DeviceInfo, input.Client)
+}
+
+func (uc *UserUseCase) passwordChangeChallenge(userID uuid.UUID) (*dto.LoginResponse, error) {
+	token, err := auth.GenerateScopedToken(userID, auth.TokenUsePasswordChange, passwordChangeChallengeTTL)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao gerar token de troca de senha: %w", err)
+	}
+	return &dto.LoginResponse{
+		PasswordChangeRequired: true,
+		ChallengeToken:         token,
+		ExpiresIn:              int(passwordChangeChallengeTTL.Seconds()),
+	}, nil
+}
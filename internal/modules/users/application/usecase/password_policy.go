@@ -0,0 +1,137 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	orgEntity "github.com/paulochiaradia/smart-gondola-backend/internal/modules/organizations/domain/entity"
+	orgRepository "github.com/paulochiaradia/smart-gondola-backend/internal/modules/organizations/domain/repository"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/domain/entity"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/domain/repository"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/breached"
+)
+
+var ErrWeakPassword = errors.New("senha não atende à política da organização")
+
+// PasswordPolicyError lista as regras que a senha não atende (vão nos "details" da resposta)
+type PasswordPolicyError struct {
+	Violations []string
+}
+
+func (e *PasswordPolicyError) Error() string {
+	return ErrWeakPassword.Error() + ": " + strings.Join(e.Violations, "; ")
+}
+
+func (e *PasswordPolicyError) Unwrap() error {
+	return ErrWeakPassword
+}
+
+// PasswordPolicyEnforcer aplica a política de senha da organização no cadastro, na redefinição
+// e na troca: formato, lista local de senhas vazadas, histórico e validade
+type PasswordPolicyEnforcer struct {
+	orgRepo     orgRepository.OrganizationRepository
+	historyRepo repository.PasswordHistoryRepository
+	breached    breached.Checker
+}
+
+func NewPasswordPolicyEnforcer(
+	orgRepo orgRepository.OrganizationRepository,
+	historyRepo repository.PasswordHistoryRepository,
+	checker breached.Checker,
+) *PasswordPolicyEnforcer {
+	return &PasswordPolicyEnforcer{orgRepo: orgRepo, historyRepo: historyRepo, breached: checker}
+}
+
+// Validate confere uma senha nova. No cadastro o usuário ainda não existe (user nil) e não há histórico.
+func (p *PasswordPolicyEnforcer) Validate(ctx context.Context, orgID uuid.UUID, user *entity.User, password string) error {
+	policy, err := p.policy(ctx, orgID)
+	if err != nil {
+		return err
+	}
+	if violations := policy.Violations(password); len(violations) > 0 {
+		return &PasswordPolicyError{Violations: violations}
+	}
+
+	isBreached, err := p.breached.IsBreached(ctx, password)
+	if err != nil {
+		return fmt.Errorf("erro ao consultar senhas vazadas: %w", err)
+	}
+	if isBreached {
+		return &PasswordPolicyError{Violations: []string{"aparece em vazamentos de dados conhecidos"}}
+	}
+
+	if user == nil || policy.HistorySize == 0 {
+		return nil
+	}
+	reused, err := p.reused(ctx, user, password, policy.HistorySize)
+	if err != nil {
+		return err
+	}
+	if reused {
+		return &PasswordPolicyError{Violations: []string{fmt.Sprintf("não pode repetir a senha atual nem as %d anteriores", policy.HistorySize)}}
+	}
+	return nil
+}
+
+// SetPassword valida e troca a senha, guardando a anterior no histórico.
+// Quem chama continua responsável por persistir o usuário (UpdateSecurity).
+func (p *PasswordPolicyEnforcer) SetPassword(ctx context.Context, user *entity.User, password string) error {
+	if err := p.Validate(ctx, user.OrganizationID, user, password); err != nil {
+		return err
+	}
+
+	previous := user.PasswordHash
+	if err := user.SetPassword(password); err != nil {
+		return err
+	}
+
+	// O histórico é guardado sempre (até o máximo): aumentar a política depois já vale na hora
+	if err := p.historyRepo.Add(ctx, user.ID, previous); err != nil {
+		return fmt.Errorf("erro ao registrar histórico de senhas: %w", err)
+	}
+	if err := p.historyRepo.Prune(ctx, user.ID, orgEntity.MaxPasswordHistory); err != nil {
+		return fmt.Errorf("erro ao registrar histórico de senhas: %w", err)
+	}
+	return nil
+}
+
+// Expired indica se a senha do usuário passou da validade definida pela organização
+func (p *PasswordPolicyEnforcer) Expired(ctx context.Context, user *entity.User) (bool, error) {
+	policy, err := p.policy(ctx, user.OrganizationID)
+	if err != nil {
+		return false, err
+	}
+	return policy.IsExpired(user.PasswordChangedAt, time.Now().UTC()), nil
+}
+
+func (p *PasswordPolicyEnforcer) policy(ctx context.Context, orgID uuid.UUID) (orgEntity.PasswordPolicy, error) {
+	org, err := p.orgRepo.GetByID(ctx, orgID)
+	if err != nil {
+		return orgEntity.PasswordPolicy{}, fmt.Errorf("erro ao buscar políticas da organização: %w", err)
+	}
+	if org == nil {
+		return orgEntity.DefaultPasswordPolicy(), nil
+	}
+	return org.Settings.PasswordPolicy(), nil
+}
+
+// reused compara a senha com a atual e com as "size" anteriores
+func (p *PasswordPolicyEnforcer) reused(ctx context.Context, user *entity.User, password string, size int) (bool, error) {
+	if user.CheckPassword(password) {
+		return true, nil
+	}
+	hashes, err := p.historyRepo.ListRecent(ctx, user.ID, size)
+	if err != nil {
+		return false, fmt.Errorf("erro ao buscar histórico de senhas: %w", err)
+	}
+	for _, h := range hashes {
+		if entity.PasswordMatchesHash(h, password) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
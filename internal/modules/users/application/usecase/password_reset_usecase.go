@@ -0,0 +1,150 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/application/dto"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/domain/entity"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/domain/repository"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/auth"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/config"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/database"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/mailer"
+)
+
+// PasswordResetUseCase cuida do fluxo "esqueci minha senha"
+type PasswordResetUseCase struct {
+	repo        repository.UserRepository
+	resetRepo   repository.PasswordResetTokenRepository
+	refreshRepo repository.RefreshTokenRepository
+	passwords   *PasswordPolicyEnforcer
+	throttle    *LoginThrottleUseCase
+	tx          database.Transactor
+	mailer      mailer.Mailer
+
+	sending sync.WaitGroup // Links sendo gerados/enviados fora da requisição
+}
+
+var ErrInvalidResetToken = errors.New("link de redefinição inválido ou expirado")
+
+func NewPasswordResetUseCase(
+	repo repository.UserRepository,
+	resetRepo repository.PasswordResetTokenRepository,
+	refreshRepo repository.RefreshTokenRepository,
+	passwords *PasswordPolicyEnforcer,
+	throttle *LoginThrottleUseCase,
+	tx database.Transactor,
+	m mailer.Mailer,
+) *PasswordResetUseCase {
+	return &PasswordResetUseCase{
+		repo: repo, resetRepo: resetRepo, refreshRepo: refreshRepo,
+		passwords: passwords, throttle: throttle, tx: tx, mailer: m,
+	}
+}
+
+// ForgotPassword envia o link de redefinição.
+// Nunca revela se o email existe: erros de "não encontrado" e de envio são silenciosos, e o link é
+// gerado e enviado fora da requisição, para que o tempo de resposta seja o mesmo com ou sem conta.
+func (uc *PasswordResetUseCase) ForgotPassword(ctx context.Context, input dto.ForgotPasswordRequest) error {
+	user, err := uc.repo.GetByEmail(ctx, input.Email)
+	if err != nil {
+		return err
+	}
+	if user == nil || user.Status != entity.StatusActive {
+		return nil
+	}
+
+	uc.sending.Add(1)
+	go func(ctx context.Context) {
+		defer uc.sending.Done()
+		if err := uc.sendResetLink(ctx, user); err != nil {
+			slog.ErrorContext(ctx, "Falha ao enviar email de redefinição de senha", "user_id", user.ID, "error", err)
+		}
+	}(context.WithoutCancel(ctx))
+
+	return nil
+}
+
+// Wait espera os envios de link em andamento (desligamento do servidor e testes)
+func (uc *PasswordResetUseCase) Wait() {
+	uc.sending.Wait()
+}
+
+// sendResetLink invalida os links anteriores, grava o novo e envia o email
+func (uc *PasswordResetUseCase) sendResetLink(ctx context.Context, user *entity.User) error {
+	// Apenas o último link pedido vale
+	if err := uc.resetRepo.InvalidateAllByUser(ctx, user.ID); err != nil {
+		return fmt.Errorf("erro ao invalidar links anteriores: %w", err)
+	}
+
+	cfg := config.Get()
+	rawToken, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		return err
+	}
+
+	token := entity.NewPasswordResetToken(user.ID, auth.HashToken(rawToken), cfg.PasswordResetExpiration)
+	if err := uc.resetRepo.Create(ctx, token); err != nil {
+		return fmt.Errorf("erro ao salvar token de redefinição: %w", err)
+	}
+
+	link := strings.TrimRight(cfg.AppURL, "/") + "/reset-password?token=" + url.QueryEscape(rawToken)
+	msg := mailer.Message{
+		To:      user.Email,
+		Subject: "Redefinição de senha - Smart Gondola",
+		Body: fmt.Sprintf(
+			"Olá, %s.\n\nRecebemos um pedido para redefinir sua senha. Acesse o link abaixo (válido por %s):\n\n%s\n\nSe você não fez este pedido, ignore este email.\n",
+			user.Name, cfg.PasswordResetExpiration, link,
+		),
+	}
+	return uc.mailer.Send(ctx, msg)
+}
+
+// ResetPassword troca a senha, desbloqueia a conta e derruba todas as sessões abertas.
+// Tudo na mesma transação: uma senha recusada pela política não gasta o link.
+func (uc *PasswordResetUseCase) ResetPassword(ctx context.Context, input dto.ResetPasswordRequest) error {
+	return uc.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		return uc.resetPassword(ctx, input)
+	})
+}
+
+func (uc *PasswordResetUseCase) resetPassword(ctx context.Context, input dto.ResetPasswordRequest) error {
+	token, err := uc.resetRepo.Consume(ctx, auth.HashToken(input.Token), time.Now().UTC())
+	if err != nil {
+		return err
+	}
+	if token == nil {
+		return ErrInvalidResetToken
+	}
+
+	user, err := uc.repo.GetByID(ctx, token.UserID)
+	if err != nil {
+		return err
+	}
+	if user == nil || user.Status != entity.StatusActive {
+		return ErrInvalidResetToken
+	}
+
+	// SetPassword atualiza o PasswordChangedAt: access tokens anteriores deixam de valer
+	if err := uc.passwords.SetPassword(ctx, user, input.NewPassword); err != nil {
+		return err
+	}
+	if err := uc.repo.UpdateSecurity(ctx, user); err != nil {
+		return fmt.Errorf("erro ao salvar nova senha: %w", err)
+	}
+	// Quem recebeu o link é o dono do email: libera a conta em qualquer IP
+	if _, err := uc.throttle.UnlockEmail(ctx, user.Email); err != nil {
+		return err
+	}
+	if err := uc.refreshRepo.RevokeAllByUser(ctx, user.ID); err != nil {
+		return fmt.Errorf("erro ao encerrar sessões: %w", err)
+	}
+	return uc.resetRepo.InvalidateAllByUser(ctx, user.ID)
+}
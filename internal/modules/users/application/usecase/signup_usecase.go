@@ -0,0 +1,98 @@
+package usecase
+
+import (
+	"context"
+
+	orgDTO "github.com/paulochiaradia/smart-gondola-backend/internal/modules/organizations/application/dto"
+	orgUseCase "github.com/paulochiaradia/smart-gondola-backend/internal/modules/organizations/application/usecase"
+	orgEntity "github.com/paulochiaradia/smart-gondola-backend/internal/modules/organizations/domain/entity"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/application/dto"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/domain/entity"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/database"
+)
+
+// ErrPlanNotSelfService indica um plano que não pode ser escolhido no cadastro público
+var ErrPlanNotSelfService = orgEntity.ErrPlanNotSelfService
+
+// Loja criada quando o cadastro não informa a primeira loja
+const (
+	defaultStoreName = "Loja Principal"
+	defaultStoreCode = "LJ-001"
+)
+
+// SignupUseCase é a única porta de entrada anônima: cria uma empresa nova, o seu dono e a primeira loja
+type SignupUseCase struct {
+	orgs   *orgUseCase.OrganizationUseCase
+	stores *orgUseCase.StoreUseCase
+	users  *UserUseCase
+	tx     database.Transactor
+}
+
+func NewSignupUseCase(orgs *orgUseCase.OrganizationUseCase, stores *orgUseCase.StoreUseCase, users *UserUseCase, tx database.Transactor) *SignupUseCase {
+	return &SignupUseCase{orgs: orgs, stores: stores, users: users, tx: tx}
+}
+
+// Signup cria a organização, o usuário dono e a primeira loja na mesma transação (ou tudo, ou nada).
+// Sem cobrança no cadastro, o pro sempre começa como período de teste.
+func (uc *SignupUseCase) Signup(ctx context.Context, input dto.SignupRequest) (*dto.SignupResponse, error) {
+	switch input.Organization.Plan {
+	case orgEntity.PlanFree:
+		input.Organization.Trial = false
+	case orgEntity.PlanPro:
+		input.Organization.Trial = true
+	default:
+		return nil, ErrPlanNotSelfService
+	}
+
+	storeInput := orgDTO.CreateStoreRequest{Name: defaultStoreName, Code: defaultStoreCode, Timezone: input.Owner.Timezone}
+	if input.Store != nil {
+		storeInput = orgDTO.CreateStoreRequest{
+			Name:     input.Store.Name,
+			Code:     input.Store.Code,
+			Timezone: input.Store.Timezone,
+			Address:  input.Store.Address,
+		}
+	}
+
+	var res dto.SignupResponse
+	var owner *entity.User
+
+	err := uc.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		org, err := uc.orgs.Create(ctx, input.Organization)
+		if err != nil {
+			return err
+		}
+
+		storeInput.OrganizationID = org.ID
+		store, err := uc.stores.Create(ctx, storeInput)
+		if err != nil {
+			return err
+		}
+
+		owner, err = uc.users.createUser(ctx, org.ID, dto.CreateUserRequest{
+			Name:     input.Owner.Name,
+			Email:    input.Owner.Email,
+			Phone:    input.Owner.Phone,
+			Password: input.Owner.Password,
+			Role:     entity.RoleTenantAdmin,
+			Timezone: input.Owner.Timezone,
+			Language: input.Owner.Language,
+		})
+		if err != nil {
+			return err
+		}
+
+		res.Organization = org
+		res.Store = store
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Só envia o email depois do commit, para não mandar link de um usuário que não existe
+	uc.users.notifyVerification(ctx, owner)
+
+	res.User = toUserResponse(owner)
+	return &res, nil
+}
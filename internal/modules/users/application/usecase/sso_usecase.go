@@ -0,0 +1,245 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	orgEntity "github.com/paulochiaradia/smart-gondola-backend/internal/modules/organizations/domain/entity"
+	orgRepository "github.com/paulochiaradia/smart-gondola-backend/internal/modules/organizations/domain/repository"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/application/dto"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/domain/entity"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/domain/repository"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/auth"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/config"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/database"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/oidc"
+)
+
+var (
+	ErrSSOUnavailable         = errors.New("login corporativo indisponível para esta organização")
+	ErrSSOProviderUnavailable = errors.New("provedor de identidade indisponível")
+	ErrSSOInvalidState        = errors.New("login corporativo expirado ou inválido, tente novamente")
+	ErrSSOProviderRejected    = errors.New("não foi possível validar o login no provedor de identidade")
+	ErrSSOEmailNotAllowed     = errors.New("email não autorizado para o login corporativo desta organização")
+	ErrSSOAccountConflict     = errors.New("esta identidade já pertence a outra organização")
+	ErrSSOUserInactive        = errors.New("usuário inativo")
+)
+
+// ssoLoginTTL é o tempo que a pessoa tem para concluir o login no provedor
+const ssoLoginTTL = 10 * time.Minute
+
+// SSOUseCase é o login corporativo (OpenID Connect, authorization code + PKCE).
+// O primeiro acesso de um email liberado cria o usuário na organização (provisionamento JIT).
+type SSOUseCase struct {
+	users      *UserUseCase
+	orgRepo    orgRepository.OrganizationRepository
+	configRepo orgRepository.SSOConfigRepository
+	ssoRepo    repository.SSORepository
+	tx         database.Transactor
+	oidc       *oidc.Client
+}
+
+func NewSSOUseCase(
+	users *UserUseCase,
+	orgRepo orgRepository.OrganizationRepository,
+	configRepo orgRepository.SSOConfigRepository,
+	ssoRepo repository.SSORepository,
+	tx database.Transactor,
+	oidcClient *oidc.Client,
+) *SSOUseCase {
+	return &SSOUseCase{users: users, orgRepo: orgRepo, configRepo: configRepo, ssoRepo: ssoRepo, tx: tx, oidc: oidcClient}
+}
+
+// Start registra o login em andamento e devolve a URL do provedor
+func (uc *SSOUseCase) Start(ctx context.Context, input dto.SSOStartRequest) (*dto.SSOStartResponse, error) {
+	org, err := uc.orgRepo.GetBySlug(ctx, strings.ToLower(input.Organization))
+	if err != nil {
+		return nil, err
+	}
+	if org == nil {
+		return nil, ErrSSOUnavailable
+	}
+	ssoCfg, provider, err := uc.provider(ctx, org)
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+	verifier, challenge, err := oidc.NewPKCE()
+	if err != nil {
+		return nil, err
+	}
+
+	redirectURI := config.Get().SSORedirectURL
+	req := entity.NewSSOLoginRequest(org.ID, auth.HashToken(state), nonce, verifier, redirectURI, ssoLoginTTL)
+	if err := uc.ssoRepo.CreateLoginRequest(ctx, req); err != nil {
+		return nil, fmt.Errorf("erro ao registrar login corporativo: %w", err)
+	}
+
+	return &dto.SSOStartResponse{
+		AuthorizationURL: provider.AuthCodeURL(ssoCfg.ClientID, redirectURI, state, nonce, challenge),
+		ExpiresAt:        req.ExpiresAt,
+	}, nil
+}
+
+// Callback troca o code pelo ID token, identifica (ou provisiona) o usuário e emite as nossas credenciais.
+// O 2FA local não é pedido: a autenticação forte fica a cargo do provedor corporativo.
+func (uc *SSOUseCase) Callback(ctx context.Context, input dto.SSOCallbackRequest) (*dto.LoginResponse, error) {
+	req, err := uc.ssoRepo.ConsumeLoginRequest(ctx, auth.HashToken(input.State))
+	if err != nil {
+		return nil, err
+	}
+	if req == nil || req.IsExpired() {
+		return nil, ErrSSOInvalidState
+	}
+
+	org, err := uc.orgRepo.GetByID(ctx, req.OrganizationID)
+	if err != nil {
+		return nil, err
+	}
+	if org == nil {
+		return nil, ErrSSOUnavailable
+	}
+	ssoCfg, provider, err := uc.provider(ctx, org)
+	if err != nil {
+		return nil, err
+	}
+
+	rawIDToken, err := uc.oidc.Exchange(ctx, provider, ssoCfg.ClientID, ssoCfg.ClientSecret, input.Code, req.RedirectURI, req.CodeVerifier)
+	if err != nil {
+		slog.WarnContext(ctx, "Falha na troca do code OIDC", "org_id", org.ID, "error", err)
+		return nil, ErrSSOProviderRejected
+	}
+	idToken, err := uc.oidc.VerifyIDToken(ctx, provider, rawIDToken, ssoCfg.ClientID, req.Nonce)
+	if err != nil {
+		slog.WarnContext(ctx, "ID token OIDC recusado", "org_id", org.ID, "error", err)
+		return nil, ErrSSOProviderRejected
+	}
+
+	user, err := uc.resolveUser(ctx, org, ssoCfg, provider.Issuer, idToken)
+	if err != nil {
+		return nil, err
+	}
+	if user.Status != entity.StatusActive {
+		return nil, ErrSSOUserInactive
+	}
+
+	return uc.users.completeLogin(ctx, user, input.DeviceInfo, input.Client)
+}
+
+// provider confere se a organização pode usar SSO e descobre o provedor configurado
+func (uc *SSOUseCase) provider(ctx context.Context, org *orgEntity.Organization) (*orgEntity.SSOConfig, *oidc.Provider, error) {
+	if !org.IsActive || org.Plan != orgEntity.PlanEnterprise {
+		return nil, nil, ErrSSOUnavailable
+	}
+	ssoCfg, err := uc.configRepo.GetByOrganization(ctx, org.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if ssoCfg == nil || !ssoCfg.Enabled {
+		return nil, nil, ErrSSOUnavailable
+	}
+
+	provider, err := uc.oidc.Discover(ctx, ssoCfg.Issuer)
+	if err != nil {
+		slog.ErrorContext(ctx, "Falha no discovery OIDC", "org_id", org.ID, "issuer", ssoCfg.Issuer, "error", err)
+		return nil, nil, ErrSSOProviderUnavailable
+	}
+	return ssoCfg, provider, nil
+}
+
+// resolveUser encontra o usuário da identidade: pelo vínculo (issuer + sub), pelo email
+// na própria organização ou, no primeiro acesso, criando-o com o papel padrão
+func (uc *SSOUseCase) resolveUser(ctx context.Context, org *orgEntity.Organization, ssoCfg *orgEntity.SSOConfig, issuer string, idToken *oidc.IDToken) (*entity.User, error) {
+	identity, err := uc.ssoRepo.GetIdentity(ctx, issuer, idToken.Subject)
+	if err != nil {
+		return nil, err
+	}
+	if identity != nil {
+		user, err := uc.users.repo.GetByID(ctx, identity.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if user == nil || user.OrganizationID != org.ID {
+			return nil, ErrSSOAccountConflict
+		}
+		return user, nil
+	}
+
+	// Sem vínculo: só confiamos no email se o provedor o confirmou e o domínio é da empresa
+	email := strings.ToLower(strings.TrimSpace(idToken.Email))
+	if email == "" || !idToken.EmailVerified || !ssoCfg.AllowsEmail(email) {
+		return nil, ErrSSOEmailNotAllowed
+	}
+
+	var user *entity.User
+	provisioned := false
+	err = uc.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		user, err = uc.users.repo.GetByEmail(ctx, email)
+		if err != nil {
+			return err
+		}
+		if user != nil && user.OrganizationID != org.ID {
+			return ErrSSOAccountConflict
+		}
+
+		if user == nil {
+			user, err = uc.provisionUser(ctx, org, ssoCfg, email, idToken.Name)
+			if err != nil {
+				return err
+			}
+			provisioned = true
+		}
+
+		// O provedor já confirmou o email
+		if !user.IsEmailVerified() {
+			user.MarkEmailVerified()
+			if err := uc.users.repo.UpdateEmailVerification(ctx, user); err != nil {
+				return err
+			}
+		}
+		return uc.ssoRepo.CreateIdentity(ctx, entity.NewUserIdentity(user.ID, issuer, idToken.Subject))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if provisioned {
+		slog.InfoContext(ctx, "Usuário provisionado via login corporativo", "user_id", user.ID, "org_id", org.ID)
+	}
+	return user, nil
+}
+
+// provisionUser cria o usuário do primeiro acesso. A senha é aleatória e ninguém a conhece:
+// quem quiser entrar também com senha usa a recuperação de senha.
+func (uc *SSOUseCase) provisionUser(ctx context.Context, org *orgEntity.Organization, ssoCfg *orgEntity.SSOConfig, email, name string) (*entity.User, error) {
+	role, ok := entity.ParseRole(ssoCfg.DefaultRole)
+	if !ok || role.IsPlatform() {
+		return nil, fmt.Errorf("papel padrão do SSO inválido: %q", ssoCfg.DefaultRole)
+	}
+	password, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(name) == "" {
+		name = email[:strings.Index(email, "@")]
+	}
+
+	return uc.users.insertUser(ctx, org.ID, dto.CreateUserRequest{
+		Name:     name,
+		Email:    email,
+		Password: password,
+		Role:     role,
+	})
+}
@@ -0,0 +1,297 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/application/dto"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/domain/entity"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/auth"
+)
+
+// Fluxos de autenticação em dois fatores (TOTP) do UserUseCase
+
+const (
+	twoFactorIssuer       = "Smart Gondola" // Nome exibido no app autenticador
+	twoFactorChallengeTTL = 5 * time.Minute
+	recoveryCodesCount    = 10
+)
+
+var (
+	ErrInvalidTwoFactorChallenge = errors.New("desafio 2FA inválido ou expirado")
+	ErrInvalidTwoFactorCode      = errors.New("código 2FA inválido")
+	ErrTwoFactorAlreadyEnabled   = errors.New("autenticação em dois fatores já está ativa")
+	ErrTwoFactorNotEnabled       = errors.New("autenticação em dois fatores não está ativa")
+	ErrTwoFactorNoEnrollment     = errors.New("nenhum cadastro de 2FA em andamento")
+	ErrTwoFactorRequiredByOrg    = errors.New("a organização exige autenticação em dois fatores")
+	ErrInvalidPassword           = errors.New("senha incorreta")
+)
+
+// VerifyTwoFactorLogin é a segunda etapa do login: troca o desafio + código pelos tokens
+func (uc *UserUseCase) VerifyTwoFactorLogin(ctx context.Context, input dto.TwoFactorLoginRequest) (*dto.LoginResponse, error) {
+	user, err := uc.userFromChallenge(ctx, input.ChallengeToken, auth.TokenUseTwoFactorChallenge)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.throttle.Check(ctx, input.Client.IP, user.Email); err != nil {
+		if errors.Is(err, ErrLoginThrottled) {
+			uc.recordLoginAttempt(ctx, user, user.Email, entity.LoginLocked, input.DeviceInfo, input.Client)
+		}
+		return nil, err
+	}
+
+	// Códigos errados contam como tentativa de login: o desafio não vira força bruta
+	if !verifySecondFactor(user, input.Code) {
+		uc.recordLoginAttempt(ctx, user, user.Email, entity.LoginInvalidTwoFactor, input.DeviceInfo, input.Client)
+		return nil, uc.loginFailure(ctx, input.Client.IP, user.Email, ErrInvalidTwoFactorCode)
+	}
+
+	// Persiste a janela TOTP usada ou o código de recuperação consumido
+	if err := uc.repo.UpdateTwoFactor(ctx, user); err != nil {
+		return nil, fmt.Errorf("erro ao salvar 2fa: %w", err)
+	}
+
+	return uc.completeLogin(ctx, user, input.DeviceInfo, input.Client)
+}
+
+// StartRequiredTwoFactorSetup inicia o cadastro exigido pela organização (usuário ainda sem sessão)
+func (uc *UserUseCase) StartRequiredTwoFactorSetup(ctx context.Context, input dto.TwoFactorSetupRequest) (*dto.TwoFactorEnrollmentResponse, error) {
+	user, err := uc.userFromChallenge(ctx, input.ChallengeToken, auth.TokenUseTwoFactorSetup)
+	if err != nil {
+		return nil, err
+	}
+	return uc.startEnrollment(ctx, user)
+}
+
+// ConfirmRequiredTwoFactorSetup ativa o 2FA e conclui o login que estava pendente
+func (uc *UserUseCase) ConfirmRequiredTwoFactorSetup(ctx context.Context, input dto.TwoFactorSetupConfirmRequest) (*dto.LoginResponse, error) {
+	user, err := uc.userFromChallenge(ctx, input.ChallengeToken, auth.TokenUseTwoFactorSetup)
+	if err != nil {
+		return nil, err
+	}
+
+	codes, err := uc.confirmEnrollment(ctx, user, input.Code)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := uc.completeLogin(ctx, user, input.DeviceInfo, input.Client)
+	if err != nil {
+		return nil, err
+	}
+	res.RecoveryCodes = codes
+	return res, nil
+}
+
+// EnrollTwoFactor gera um novo segredo para o usuário logado (ainda não ativo)
+func (uc *UserUseCase) EnrollTwoFactor(ctx context.Context, userID uuid.UUID) (*dto.TwoFactorEnrollmentResponse, error) {
+	user, err := uc.getActiveUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return uc.startEnrollment(ctx, user)
+}
+
+// ConfirmTwoFactor ativa o 2FA após o primeiro código válido e devolve os códigos de recuperação
+func (uc *UserUseCase) ConfirmTwoFactor(ctx context.Context, userID uuid.UUID, input dto.TwoFactorCodeRequest) (*dto.RecoveryCodesResponse, error) {
+	user, err := uc.getActiveUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	codes, err := uc.confirmEnrollment(ctx, user, input.Code)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// DisableTwoFactor desliga o 2FA (exige senha e código); bloqueado se a organização obriga
+func (uc *UserUseCase) DisableTwoFactor(ctx context.Context, userID uuid.UUID, input dto.TwoFactorDisableRequest) error {
+	user, err := uc.getActiveUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !user.HasTwoFactor() {
+		return ErrTwoFactorNotEnabled
+	}
+	if !user.CheckPassword(input.Password) {
+		return ErrInvalidPassword
+	}
+	if !verifySecondFactor(user, input.Code) {
+		return ErrInvalidTwoFactorCode
+	}
+
+	required, err := uc.organizationRequiresTwoFactor(ctx, user.OrganizationID)
+	if err != nil {
+		return err
+	}
+	if required {
+		return ErrTwoFactorRequiredByOrg
+	}
+
+	user.DisableTwoFactor()
+	return uc.repo.UpdateTwoFactor(ctx, user)
+}
+
+// RegenerateRecoveryCodes invalida os códigos antigos e gera novos (exige código TOTP do app)
+func (uc *UserUseCase) RegenerateRecoveryCodes(ctx context.Context, userID uuid.UUID, input dto.TwoFactorCodeRequest) (*dto.RecoveryCodesResponse, error) {
+	user, err := uc.getActiveUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.HasTwoFactor() {
+		return nil, ErrTwoFactorNotEnabled
+	}
+
+	// Aqui só vale o app: quem perdeu o celular não deve conseguir gerar novos códigos com um código antigo
+	ok, step := auth.ValidateTOTP(user.TwoFactor.Secret, input.Code, time.Now())
+	if !ok || !user.MarkTOTPStepUsed(step) {
+		return nil, ErrInvalidTwoFactorCode
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	user.TwoFactor.RecoveryCodes = hashes
+
+	if err := uc.repo.UpdateTwoFactor(ctx, user); err != nil {
+		return nil, fmt.Errorf("erro ao salvar 2fa: %w", err)
+	}
+	return &dto.RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// --- Helpers ---
+
+// twoFactorChallenge devolve a resposta "parcial" do login, sem access token
+func (uc *UserUseCase) twoFactorChallenge(userID uuid.UUID, tokenUse string) (*dto.LoginResponse, error) {
+	token, err := auth.GenerateScopedToken(userID, tokenUse, twoFactorChallengeTTL)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao gerar desafio 2FA: %w", err)
+	}
+
+	res := &dto.LoginResponse{ChallengeToken: token, ExpiresIn: int(twoFactorChallengeTTL.Seconds())}
+	if tokenUse == auth.TokenUseTwoFactorSetup {
+		res.TwoFactorSetupRequired = true
+	} else {
+		res.TwoFactorRequired = true
+	}
+	return res, nil
+}
+
+func (uc *UserUseCase) organizationRequiresTwoFactor(ctx context.Context, orgID uuid.UUID) (bool, error) {
+	settings, err := uc.organizationSettings(ctx, orgID)
+	if err != nil {
+		return false, err
+	}
+	return settings.RequireTwoFactor, nil
+}
+
+func (uc *UserUseCase) userFromChallenge(ctx context.Context, challengeToken, tokenUse string) (*entity.User, error) {
+	claims, err := auth.ValidateScopedToken(challengeToken, tokenUse)
+	if err != nil {
+		return nil, ErrInvalidTwoFactorChallenge
+	}
+
+	user, err := uc.repo.GetByID(ctx, claims.UserID)
+	if err != nil {
+		return nil, err
+	}
+	// Como os access tokens, o desafio cai com um logout-all ou troca de senha posterior
+	if user == nil || user.Status != entity.StatusActive || !issuedAfterCutoff(user, claims.IssuedAtPrecise()) {
+		return nil, ErrInvalidTwoFactorChallenge
+	}
+	return user, nil
+}
+
+func (uc *UserUseCase) getActiveUser(ctx context.Context, userID uuid.UUID) (*entity.User, error) {
+	user, err := uc.repo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil || user.Status != entity.StatusActive {
+		return nil, ErrUserNotFound
+	}
+	return user, nil
+}
+
+func (uc *UserUseCase) startEnrollment(ctx context.Context, user *entity.User) (*dto.TwoFactorEnrollmentResponse, error) {
+	if user.HasTwoFactor() {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+
+	secret, err := auth.GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+	uri := auth.TOTPProvisioningURI(twoFactorIssuer, user.Email, secret)
+
+	if err := user.StartTwoFactorEnrollment(secret, uri); err != nil {
+		return nil, err
+	}
+	if err := uc.repo.UpdateTwoFactor(ctx, user); err != nil {
+		return nil, fmt.Errorf("erro ao salvar 2fa: %w", err)
+	}
+
+	return &dto.TwoFactorEnrollmentResponse{Secret: secret, QRCodeURL: uri}, nil
+}
+
+func (uc *UserUseCase) confirmEnrollment(ctx context.Context, user *entity.User, code string) ([]string, error) {
+	if user.HasTwoFactor() {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+	if user.TwoFactor == nil || user.TwoFactor.Secret == "" {
+		return nil, ErrTwoFactorNoEnrollment
+	}
+
+	ok, step := auth.ValidateTOTP(user.TwoFactor.Secret, code, time.Now())
+	if !ok {
+		return nil, ErrInvalidTwoFactorCode
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := user.EnableTwoFactor(hashes, step); err != nil {
+		return nil, err
+	}
+	if err := uc.repo.UpdateTwoFactor(ctx, user); err != nil {
+		return nil, fmt.Errorf("erro ao salvar 2fa: %w", err)
+	}
+	return codes, nil
+}
+
+// verifySecondFactor aceita o código do app (uma vez por janela) ou um código de recuperação
+func verifySecondFactor(user *entity.User, code string) bool {
+	if !user.HasTwoFactor() {
+		return false
+	}
+	if ok, step := auth.ValidateTOTP(user.TwoFactor.Secret, code, time.Now()); ok {
+		return user.MarkTOTPStepUsed(step)
+	}
+	return user.ConsumeRecoveryCode(auth.HashToken(normalizeRecoveryCode(code)))
+}
+
+// newRecoveryCodes devolve os códigos puros (exibidos uma vez) e os hashes (persistidos)
+func newRecoveryCodes() ([]string, []string, error) {
+	codes, err := auth.GenerateRecoveryCodes(recoveryCodesCount)
+	if err != nil {
+		return nil, nil, err
+	}
+	hashes := make([]string, len(codes))
+	for i, c := range codes {
+		hashes[i] = auth.HashToken(normalizeRecoveryCode(c))
+	}
+	return codes, hashes, nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.TrimSpace(code))
+}
@@ -0,0 +1,340 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	orgEntity "github.com/paulochiaradia/smart-gondola-backend/internal/modules/organizations/domain/entity"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/application/dto"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/domain/entity"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/domain/repository"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/pagination"
+)
+
+var (
+	ErrCannotManageSelf     = errors.New("use as rotas /me para alterar o próprio cadastro")
+	ErrLastTenantAdmin      = errors.New("a organização precisa de pelo menos um administrador ativo")
+	ErrUserNotSuspended     = errors.New("apenas usuários suspensos podem ser reativados")
+	ErrUserAlreadySuspended = errors.New("usuário já está suspenso")
+)
+
+// --- Gestão de usuários da organização (tenant admin / gerente) ---
+
+// ListUsers lista os usuários da organização. Quem está preso a uma loja só vê a própria loja.
+func (uc *UserUseCase) ListUsers(ctx context.Context, actorID, orgID uuid.UUID, filter dto.ListUsersFilter, params pagination.Params) ([]*dto.UserResponse, int64, error) {
+	actor, err := uc.actorInOrg(ctx, actorID, orgID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	repoFilter := repository.UserFilter{Role: filter.Role, Status: filter.Status, StoreID: filter.StoreID}
+	if bound := actor.BoundStoreID(); bound != nil {
+		if filter.StoreID != nil && *filter.StoreID != *bound {
+			return []*dto.UserResponse{}, 0, nil
+		}
+		repoFilter.StoreID = bound
+	}
+
+	users, total, err := uc.repo.ListByOrganization(ctx, orgID, repoFilter, params)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	res := make([]*dto.UserResponse, 0, len(users))
+	for _, u := range users {
+		res = append(res, toUserResponse(u))
+	}
+	return res, total, nil
+}
+
+// GetUser busca um usuário visível para quem consulta
+func (uc *UserUseCase) GetUser(ctx context.Context, actorID, orgID, id uuid.UUID) (*dto.UserResponse, error) {
+	actor, err := uc.actorInOrg(ctx, actorID, orgID)
+	if err != nil {
+		return nil, err
+	}
+	target, err := uc.visibleUser(ctx, actor, id)
+	if err != nil {
+		return nil, err
+	}
+	return toUserResponse(target), nil
+}
+
+// UpdateUser altera o perfil de um usuário gerenciado por quem edita
+func (uc *UserUseCase) UpdateUser(ctx context.Context, actorID, orgID, id uuid.UUID, input dto.UpdateUserRequest) (*dto.UserResponse, error) {
+	_, target, err := uc.manageableUser(ctx, actorID, orgID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	applyProfile(target, input)
+	if err := uc.repo.Update(ctx, target); err != nil {
+		return nil, err
+	}
+	return toUserResponse(target), nil
+}
+
+// ChangeRole troca o papel. Ninguém promove acima do que pode atribuir (gerente não cria gerente).
+func (uc *UserUseCase) ChangeRole(ctx context.Context, actorID, orgID, id uuid.UUID, role entity.UserRole) (*dto.UserResponse, error) {
+	actor, target, err := uc.manageableUser(ctx, actorID, orgID, id)
+	if err != nil {
+		return nil, err
+	}
+	if !actor.Role.CanAssign(role) {
+		return nil, ErrRoleNotAllowed
+	}
+	if target.Role == role {
+		return toUserResponse(target), nil
+	}
+	if target.IsTenantAdmin() {
+		if err := uc.ensureAnotherTenantAdmin(ctx, target); err != nil {
+			return nil, err
+		}
+	}
+
+	target.Role = role
+	if err := uc.repo.Update(ctx, target); err != nil {
+		return nil, err
+	}
+	if err := uc.expireAccessTokens(ctx, target); err != nil {
+		return nil, err
+	}
+	return toUserResponse(target), nil
+}
+
+// Suspend bloqueia o usuário e encerra todas as sessões dele na hora
+func (uc *UserUseCase) Suspend(ctx context.Context, actorID, orgID, id uuid.UUID) (*dto.UserResponse, error) {
+	_, target, err := uc.manageableUser(ctx, actorID, orgID, id)
+	if err != nil {
+		return nil, err
+	}
+	if target.Status == entity.StatusSuspended {
+		return nil, ErrUserAlreadySuspended
+	}
+	if target.IsTenantAdmin() {
+		if err := uc.ensureAnotherTenantAdmin(ctx, target); err != nil {
+			return nil, err
+		}
+	}
+
+	target.Suspend()
+	if err := uc.repo.Update(ctx, target); err != nil {
+		return nil, err
+	}
+	if err := uc.repo.UpdateSecurity(ctx, target); err != nil {
+		return nil, fmt.Errorf("erro ao revogar sessões: %w", err)
+	}
+	if err := uc.refreshRepo.RevokeAllByUser(ctx, target.ID); err != nil {
+		return nil, fmt.Errorf("erro ao encerrar sessões: %w", err)
+	}
+	return toUserResponse(target), nil
+}
+
+// Reactivate devolve o acesso a um usuário suspenso. Suspenso não ocupa cota, então voltar
+// precisa de vaga no plano; os aparelhos antigos são esquecidos e voltam pela cota no próximo login.
+func (uc *UserUseCase) Reactivate(ctx context.Context, actorID, orgID, id uuid.UUID) (*dto.UserResponse, error) {
+	_, target, err := uc.manageableUser(ctx, actorID, orgID, id)
+	if err != nil {
+		return nil, err
+	}
+	if target.Status != entity.StatusSuspended {
+		return nil, ErrUserNotSuspended
+	}
+
+	err = uc.quota.Reserve(ctx, target.OrganizationID, orgEntity.QuotaUsers, func(ctx context.Context) error {
+		target.Reactivate()
+		if err := uc.repo.Update(ctx, target); err != nil {
+			return err
+		}
+		if err := uc.deviceRepo.DeleteByUser(ctx, target.ID); err != nil {
+			return fmt.Errorf("erro ao remover aparelhos: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toUserResponse(target), nil
+}
+
+// UnlockLogin apaga os bloqueios de login da conta (em qualquer IP). Bloqueios só de IP
+// (credential stuffing) ficam com a equipe da plataforma.
+func (uc *UserUseCase) UnlockLogin(ctx context.Context, actorID, orgID, id uuid.UUID) (*dto.UnlockLoginResponse, error) {
+	_, target, err := uc.manageableUser(ctx, actorID, orgID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	cleared, err := uc.throttle.UnlockEmail(ctx, target.Email)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.UnlockLoginResponse{Cleared: cleared}, nil
+}
+
+// MoveStore vincula o usuário a outra loja da organização (ou a toda a organização, com nil)
+func (uc *UserUseCase) MoveStore(ctx context.Context, actorID, orgID, id uuid.UUID, storeID *uuid.UUID) (*dto.UserResponse, error) {
+	actor, target, err := uc.manageableUser(ctx, actorID, orgID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	resolved, err := resolveStore(ctx, uc.storeRepo, actor, storeID)
+	if err != nil {
+		return nil, err
+	}
+
+	target.StoreID = resolved
+	if err := uc.repo.Update(ctx, target); err != nil {
+		return nil, err
+	}
+	if err := uc.expireAccessTokens(ctx, target); err != nil {
+		return nil, err
+	}
+	return toUserResponse(target), nil
+}
+
+// --- Próprio usuário (/me) ---
+
+// GetMe devolve o cadastro do usuário autenticado
+func (uc *UserUseCase) GetMe(ctx context.Context, userID uuid.UUID) (*dto.UserResponse, error) {
+	user, err := uc.getActiveUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return toUserResponse(user), nil
+}
+
+// UpdateMe altera o próprio perfil
+func (uc *UserUseCase) UpdateMe(ctx context.Context, userID uuid.UUID, input dto.UpdateUserRequest) (*dto.UserResponse, error) {
+	user, err := uc.getActiveUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	applyProfile(user, input)
+	if err := uc.repo.Update(ctx, user); err != nil {
+		return nil, err
+	}
+	return toUserResponse(user), nil
+}
+
+// ChangePassword troca a senha conferindo a atual. Todas as sessões são encerradas (inclusive a atual).
+func (uc *UserUseCase) ChangePassword(ctx context.Context, userID uuid.UUID, input dto.ChangePasswordRequest) error {
+	user, err := uc.getActiveUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !user.CheckPassword(input.OldPassword) {
+		return ErrInvalidPassword
+	}
+
+	if err := uc.passwords.SetPassword(ctx, user, input.NewPassword); err != nil {
+		return err
+	}
+	if err := uc.repo.UpdateSecurity(ctx, user); err != nil {
+		return fmt.Errorf("erro ao salvar nova senha: %w", err)
+	}
+	if err := uc.refreshRepo.RevokeAllByUser(ctx, user.ID); err != nil {
+		return fmt.Errorf("erro ao encerrar sessões: %w", err)
+	}
+	return nil
+}
+
+// --- Helpers ---
+
+// actorInOrg carrega quem faz a chamada, garantindo que pertence à organização do token
+func (uc *UserUseCase) actorInOrg(ctx context.Context, actorID, orgID uuid.UUID) (*entity.User, error) {
+	actor, err := uc.getActiveUser(ctx, actorID)
+	if err != nil {
+		return nil, err
+	}
+	if actor.OrganizationID != orgID {
+		return nil, ErrUserNotFound
+	}
+	return actor, nil
+}
+
+// expireAccessTokens invalida os access tokens emitidos com o papel/loja antigos.
+// O refresh continua valendo e emite um token já com os dados novos.
+func (uc *UserUseCase) expireAccessTokens(ctx context.Context, target *entity.User) error {
+	target.RevokeSessions()
+	if err := uc.repo.UpdateSecurity(ctx, target); err != nil {
+		return fmt.Errorf("erro ao revogar sessões: %w", err)
+	}
+	return nil
+}
+
+// visibleUser busca o alvo na mesma organização (e na mesma loja, se quem consulta estiver preso a uma)
+func (uc *UserUseCase) visibleUser(ctx context.Context, actor *entity.User, id uuid.UUID) (*entity.User, error) {
+	target, err := uc.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if target == nil || target.OrganizationID != actor.OrganizationID {
+		return nil, ErrUserNotFound
+	}
+	if bound := actor.BoundStoreID(); bound != nil && (target.StoreID == nil || *target.StoreID != *bound) {
+		return nil, ErrUserNotFound
+	}
+	return target, nil
+}
+
+// manageableUser aplica a hierarquia: só se gerencia quem tem um papel que você poderia atribuir
+func (uc *UserUseCase) manageableUser(ctx context.Context, actorID, orgID, id uuid.UUID) (*entity.User, *entity.User, error) {
+	if actorID == id {
+		return nil, nil, ErrCannotManageSelf
+	}
+
+	actor, err := uc.actorInOrg(ctx, actorID, orgID)
+	if err != nil {
+		return nil, nil, err
+	}
+	target, err := uc.visibleUser(ctx, actor, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !actor.Role.CanAssign(target.Role) {
+		return nil, nil, ErrRoleNotAllowed
+	}
+	return actor, target, nil
+}
+
+// ensureAnotherTenantAdmin impede rebaixar/suspender o último dono ativo da organização
+func (uc *UserUseCase) ensureAnotherTenantAdmin(ctx context.Context, target *entity.User) error {
+	role, status := entity.RoleTenantAdmin, entity.StatusActive
+	_, total, err := uc.repo.ListByOrganization(ctx, target.OrganizationID,
+		repository.UserFilter{Role: &role, Status: &status}, pagination.Params{Page: 1, Limit: 1})
+	if err != nil {
+		return err
+	}
+
+	others := total
+	if target.Status == entity.StatusActive {
+		others--
+	}
+	if others < 1 {
+		return ErrLastTenantAdmin
+	}
+	return nil
+}
+
+func applyProfile(u *entity.User, input dto.UpdateUserRequest) {
+	if input.Name != nil {
+		u.Name = *input.Name
+	}
+	if input.Phone != nil {
+		u.Phone = *input.Phone
+	}
+	if input.AvatarURL != nil {
+		u.AvatarURL = *input.AvatarURL
+	}
+	if input.Timezone != nil {
+		u.Timezone = *input.Timezone
+	}
+	if input.Language != nil {
+		u.Language = *input.Language
+	}
+}
@@ -4,41 +4,127 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log/slog"
 	"time"
 
+	"github.com/google/uuid"
+	orgUseCase "github.com/paulochiaradia/smart-gondola-backend/internal/modules/organizations/application/usecase"
+	orgEntity "github.com/paulochiaradia/smart-gondola-backend/internal/modules/organizations/domain/entity"
+	orgRepository "github.com/paulochiaradia/smart-gondola-backend/internal/modules/organizations/domain/repository"
 	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/application/dto"
 	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/domain/entity"
 	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/domain/repository"
 	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/auth"
 	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/config"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/mailer"
 )
 
 type UserUseCase struct {
-	repo repository.UserRepository
+	repo        repository.UserRepository
+	refreshRepo repository.RefreshTokenRepository
+	revokedRepo repository.RevokedTokenRepository
+	orgRepo     orgRepository.OrganizationRepository // Políticas do tenant (ex: 2FA obrigatório)
+	storeRepo   orgRepository.StoreRepository
+	deviceRepo  repository.DeviceRepository // Aparelhos do App (destino dos push)
+	attemptRepo repository.LoginAttemptRepository
+	throttle    *LoginThrottleUseCase // Falhas de login por IP, email e IP+email
+	passwords   *PasswordPolicyEnforcer
+	quota       *orgUseCase.QuotaUseCase // Cotas do plano (usuários e aparelhos)
+	mailer      mailer.Mailer
 }
 
-const (
-	maxFailedLoginAttempts = 5
-	loginLockoutDuration   = 15 * time.Minute
+var (
+	ErrInvalidRefreshToken = errors.New("refresh token inválido ou expirado")
+	ErrRefreshTokenReused  = errors.New("refresh token reutilizado, sessão encerrada por segurança")
+	ErrEmailAlreadyInUse   = errors.New("email já cadastrado")
+	ErrUserNotFound        = errors.New("usuário não encontrado")
+	ErrRoleNotAllowed      = errors.New("seu perfil não pode atribuir este papel")
+	ErrStoreNotFound       = errors.New("loja não encontrada nesta organização")
 )
 
-func NewUserUseCase(repo repository.UserRepository) *UserUseCase {
-	return &UserUseCase{repo: repo}
+func NewUserUseCase(
+	repo repository.UserRepository,
+	refreshRepo repository.RefreshTokenRepository,
+	revokedRepo repository.RevokedTokenRepository,
+	orgRepo orgRepository.OrganizationRepository,
+	storeRepo orgRepository.StoreRepository,
+	deviceRepo repository.DeviceRepository,
+	attemptRepo repository.LoginAttemptRepository,
+	throttle *LoginThrottleUseCase,
+	passwords *PasswordPolicyEnforcer,
+	quota *orgUseCase.QuotaUseCase,
+	m mailer.Mailer,
+) *UserUseCase {
+	return &UserUseCase{
+		repo: repo, refreshRepo: refreshRepo, revokedRepo: revokedRepo,
+		orgRepo: orgRepo, storeRepo: storeRepo, deviceRepo: deviceRepo,
+		attemptRepo: attemptRepo, throttle: throttle, passwords: passwords, quota: quota, mailer: m,
+	}
+}
+
+// CreateUser é a criação direta por um usuário autenticado: a organização vem sempre do token
+// e o papel atribuído precisa estar abaixo (ou no nível do tenant admin) de quem cria.
+func (uc *UserUseCase) CreateUser(ctx context.Context, actorID, orgID uuid.UUID, input dto.CreateUserRequest) (*dto.UserResponse, error) {
+	actor, err := uc.repo.GetByID(ctx, actorID)
+	if err != nil {
+		return nil, err
+	}
+	if actor == nil || actor.OrganizationID != orgID || actor.Status != entity.StatusActive {
+		return nil, ErrRoleNotAllowed
+	}
+	if !actor.Role.CanAssign(input.Role) {
+		return nil, ErrRoleNotAllowed
+	}
+
+	storeID, err := resolveStore(ctx, uc.storeRepo, actor, input.StoreID)
+	if err != nil {
+		return nil, err
+	}
+	input.StoreID = storeID
+
+	user, err := uc.createUser(ctx, orgID, input)
+	if err != nil {
+		return nil, err
+	}
+	uc.notifyVerification(ctx, user)
+
+	return toUserResponse(user), nil
+}
+
+// createUser aplica a política de senha da organização e cria o usuário.
+// Participa da transação do context (usado também no signup).
+func (uc *UserUseCase) createUser(ctx context.Context, orgID uuid.UUID, input dto.CreateUserRequest) (*entity.User, error) {
+	if err := uc.passwords.Validate(ctx, orgID, nil, input.Password); err != nil {
+		return nil, err
+	}
+	return uc.insertUser(ctx, orgID, input)
 }
 
-func (uc *UserUseCase) Register(ctx context.Context, input dto.CreateUserRequest) (*dto.UserResponse, error) {
-	exists, err := uc.repo.GetByEmail(ctx, input.Email)
+// insertUser valida o email, aplica a política de email da organização e persiste.
+// Não passa pela política de senha: o provisionamento via SSO usa uma senha aleatória que ninguém digita.
+func (uc *UserUseCase) insertUser(ctx context.Context, orgID uuid.UUID, input dto.CreateUserRequest) (*entity.User, error) {
+	input.Email = entity.NormalizeEmail(input.Email)
+	exists, err := uc.repo.EmailExists(ctx, input.Email)
 	if err != nil {
 		return nil, fmt.Errorf("erro verif. email: %w", err)
 	}
-	if exists != nil {
-		return nil, errors.New("email já cadastrado")
+	if exists {
+		return nil, ErrEmailAlreadyInUse
+	}
+
+	user, err := entity.NewUser(orgID, input.Name, input.Email, input.Password, input.Role)
+	if err != nil {
+		return nil, err
 	}
 
-	user, err := entity.NewUser(input.OrganizationID, input.Name, input.Email, input.Password, input.Role)
+	// Com a política "block", o cadastro só é ativado depois da confirmação do email
+	settings, err := uc.organizationSettings(ctx, orgID)
 	if err != nil {
 		return nil, err
 	}
+	if settings.EmailPolicy() == orgEntity.UnverifiedEmailBlock {
+		user.Status = entity.StatusPending
+	}
 
 	if input.StoreID != nil {
 		user.StoreID = input.StoreID
@@ -53,64 +139,409 @@ func (uc *UserUseCase) Register(ctx context.Context, input dto.CreateUserRequest
 		user.Language = input.Language
 	}
 
-	if err := uc.repo.Create(ctx, user); err != nil {
+	err = uc.quota.Reserve(ctx, orgID, orgEntity.QuotaUsers, func(ctx context.Context) error {
+		return uc.repo.Create(ctx, user)
+	})
+	if err != nil {
 		return nil, err
 	}
-
-	return &dto.UserResponse{
-		ID: user.ID, OrganizationID: user.OrganizationID, StoreID: user.StoreID,
-		Name: user.Name, Email: user.Email, Role: user.Role, Status: user.Status,
-		Timezone: user.Timezone, Language: user.Language,
-	}, nil
+	return user, nil
 }
 
 func (uc *UserUseCase) Login(ctx context.Context, input dto.LoginRequest) (*dto.LoginResponse, error) {
+	// "Joao@x.com" e "joao@x.com" são a mesma conta (e o mesmo contador de bloqueio)
+	input.Email = entity.NormalizeEmail(input.Email)
 	user, err := uc.repo.GetByEmail(ctx, input.Email)
 	if err != nil {
 		return nil, err
 	}
-	if user == nil {
-		return nil, errors.New("credenciais inválidas")
+
+	// Bloqueios valem também para emails inexistentes: a resposta não revela quem tem conta
+	if err := uc.throttle.Check(ctx, input.Client.IP, input.Email); err != nil {
+		if errors.Is(err, ErrLoginThrottled) {
+			uc.recordLoginAttempt(ctx, user, input.Email, entity.LoginLocked, input.DeviceInfo, input.Client)
+		}
+		return nil, err
 	}
 
-	if user.IsLocked() {
-		return nil, errors.New("conta temporariamente bloqueada...")
+	if user == nil {
+		uc.recordLoginAttempt(ctx, nil, input.Email, entity.LoginUnknownUser, input.DeviceInfo, input.Client)
+		return nil, uc.loginFailure(ctx, input.Client.IP, input.Email, errors.New("credenciais inválidas"))
 	}
 
 	if !user.CheckPassword(input.Password) {
-		user.RegisterFailedLogin(maxFailedLoginAttempts, loginLockoutDuration)
-		if err := uc.repo.UpdateSecurity(ctx, user); err != nil {
-			return nil, fmt.Errorf("erro ao atualizar tentativas de login: %w", err)
-		}
-
-		if user.IsLocked() {
-			return nil, errors.New("conta temporariamente bloqueada...")
-		}
-
-		return nil, errors.New("credenciais inválidas")
+		uc.recordLoginAttempt(ctx, user, input.Email, entity.LoginInvalidPassword, input.DeviceInfo, input.Client)
+		return nil, uc.loginFailure(ctx, input.Client.IP, input.Email, errors.New("credenciais inválidas"))
+	}
+	uc.upgradePasswordHash(ctx, user, input.Password)
+	// Cadastro aguardando a confirmação do email (política "block" da organização)
+	if user.Status == entity.StatusPending && !user.IsEmailVerified() {
+		uc.recordLoginAttempt(ctx, user, input.Email, entity.LoginEmailNotVerified, input.DeviceInfo, input.Client)
+		return nil, ErrEmailNotVerified
 	}
 	if user.Status != entity.StatusActive {
+		uc.recordLoginAttempt(ctx, user, input.Email, entity.LoginInactive, input.DeviceInfo, input.Client)
 		return nil, errors.New("usuário inativo")
 	}
 
+	policy, err := uc.unverifiedEmailPolicy(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	if policy == orgEntity.UnverifiedEmailBlock {
+		uc.recordLoginAttempt(ctx, user, input.Email, entity.LoginEmailNotVerified, input.DeviceInfo, input.Client)
+		return nil, ErrEmailNotVerified
+	}
+
+	// Senha vencida pela política da organização: o login só continua depois da troca
+	expired, err := uc.passwords.Expired(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	if expired {
+		return uc.passwordChangeChallenge(user.ID)
+	}
+
+	return uc.continueLogin(ctx, user, input.DeviceInfo, input.Client)
+}
+
+// upgradePasswordHash refaz o hash que usa algoritmo ou custo antigos (a senha só existe em texto aqui).
+// Falhar nisso não impede o login: o hash antigo continua válido e a troca fica para a próxima vez.
+func (uc *UserUseCase) upgradePasswordHash(ctx context.Context, user *entity.User, password string) {
+	oldHash := user.PasswordHash
+	rehashed, err := user.RehashPassword(password)
+	if err == nil && rehashed {
+		err = uc.repo.UpdatePasswordHash(ctx, user.ID, oldHash, user.PasswordHash)
+	}
+	if err != nil {
+		slog.WarnContext(ctx, "Falha ao atualizar hash de senha", "user_id", user.ID, "error", err)
+	}
+}
+
+// loginFailure conta a falha no throttle: devolve o bloqueio, se ela causou um, ou o erro original
+func (uc *UserUseCase) loginFailure(ctx context.Context, ip, email string, failure error) error {
+	if err := uc.throttle.RegisterFailure(ctx, ip, email); err != nil {
+		return err
+	}
+	return failure
+}
+
+// continueLogin segue depois da senha conferida: pede o segundo fator quando necessário ou conclui o login
+func (uc *UserUseCase) continueLogin(ctx context.Context, user *entity.User, device dto.DeviceInfo, client dto.ClientInfo) (*dto.LoginResponse, error) {
+	// Senha correta: se a conta usa 2FA, o login só termina em /auth/2fa/verify
+	if user.HasTwoFactor() {
+		return uc.twoFactorChallenge(user.ID, auth.TokenUseTwoFactorChallenge)
+	}
+
+	// Organização exige 2FA e o usuário ainda não cadastrou: só libera o cadastro
+	required, err := uc.organizationRequiresTwoFactor(ctx, user.OrganizationID)
+	if err != nil {
+		return nil, err
+	}
+	if required {
+		return uc.twoFactorChallenge(user.ID, auth.TokenUseTwoFactorSetup)
+	}
+
+	return uc.completeLogin(ctx, user, device, client)
+}
+
+// completeLogin registra o acesso e emite os tokens (última etapa de qualquer fluxo de login)
+func (uc *UserUseCase) completeLogin(ctx context.Context, user *entity.User, device dto.DeviceInfo, client dto.ClientInfo) (*dto.LoginResponse, error) {
+	// Aparelho novo ocupa cota: a recusa vem antes de qualquer efeito de login bem-sucedido
+	if device.DeviceID != "" {
+		if err := uc.registerDevice(ctx, user, device); err != nil {
+			if errors.Is(err, orgEntity.ErrQuotaExceeded) {
+				uc.recordLoginAttempt(ctx, user, user.Email, entity.LoginDeviceQuota, device, client)
+			}
+			return nil, err
+		}
+	}
+
 	now := time.Now()
 	user.LastLoginAt = &now
-	user.ResetLoginAttempts()
+	user.LastLoginIP = client.IP
 	if err := uc.repo.UpdateSecurity(ctx, user); err != nil {
 		return nil, fmt.Errorf("erro ao atualizar segurança do usuário: %w", err)
 	}
+	if err := uc.throttle.RegisterSuccess(ctx, client.IP, user.Email); err != nil {
+		return nil, err
+	}
+
+	// Cada login inicia uma nova família de refresh tokens para o aparelho
+	if device.DeviceID != "" {
+		if err := uc.refreshRepo.RevokeByDevice(ctx, user.ID, device.DeviceID); err != nil {
+			return nil, fmt.Errorf("erro ao encerrar sessão anterior do dispositivo: %w", err)
+		}
+	}
+
+	res, _, err := uc.issueTokens(ctx, user, uuid.New(), device.DeviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	uc.recordLoginAttempt(ctx, user, user.Email, entity.LoginSuccess, device, client)
+	return res, nil
+}
+
+// registerDevice grava o aparelho do login. Aparelho novo ocupa cota do plano; um já conhecido só é atualizado.
+func (uc *UserUseCase) registerDevice(ctx context.Context, user *entity.User, device dto.DeviceInfo) error {
+	d := entity.NewUserDevice(user.ID, device.DeviceID, device.DeviceName, device.Platform, device.PushToken, device.AppVersion)
+	upsert := func(ctx context.Context) error { return uc.deviceRepo.Upsert(ctx, d) }
+
+	known, err := uc.deviceRepo.Exists(ctx, user.ID, device.DeviceID)
+	if err == nil {
+		if known {
+			err = upsert(ctx)
+		} else {
+			err = uc.quota.Reserve(ctx, user.OrganizationID, orgEntity.QuotaDevices, upsert)
+		}
+	}
+	if err != nil {
+		if errors.Is(err, orgEntity.ErrQuotaExceeded) {
+			return err
+		}
+		return fmt.Errorf("erro ao registrar dispositivo: %w", err)
+	}
+	return nil
+}
+
+// Refresh troca um refresh token válido por um novo par de tokens (rotação).
+// Se um token já rotacionado for apresentado de novo, toda a família é revogada.
+func (uc *UserUseCase) Refresh(ctx context.Context, input dto.RefreshTokenRequest) (*dto.LoginResponse, error) {
+	current, err := uc.refreshRepo.GetByHash(ctx, auth.HashToken(input.RefreshToken))
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar refresh token: %w", err)
+	}
+	if current == nil {
+		return nil, ErrInvalidRefreshToken
+	}
 
-	token, err := auth.GenerateToken(user.ID, user.OrganizationID, string(user.Role))
+	// Reuso: alguém (talvez um atacante) está usando um token que já foi trocado
+	if current.IsRevoked() {
+		if err := uc.refreshRepo.RevokeFamily(ctx, current.FamilyID); err != nil {
+			return nil, fmt.Errorf("erro ao revogar família de tokens: %w", err)
+		}
+		return nil, ErrRefreshTokenReused
+	}
+	if current.IsExpired() {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	user, err := uc.repo.GetByID(ctx, current.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil || user.Status != entity.StatusActive {
+		if err := uc.refreshRepo.RevokeFamily(ctx, current.FamilyID); err != nil {
+			return nil, fmt.Errorf("erro ao revogar família de tokens: %w", err)
+		}
+		return nil, ErrInvalidRefreshToken
+	}
+
+	// Senha venceu durante a sessão: o refresh não prolonga o acesso, pede a troca como o login.
+	// A troca encerra todas as sessões, inclusive esta.
+	expired, err := uc.passwords.Expired(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	if expired {
+		return uc.passwordChangeChallenge(user.ID)
+	}
+
+	res, newTokenID, err := uc.issueTokens(ctx, user, current.FamilyID, current.DeviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Só o primeiro que conseguir marcar o token atual leva a sessão.
+	// Se perdermos a corrida, o token foi usado duas vezes: derruba a família inteira.
+	replaced, err := uc.refreshRepo.MarkReplaced(ctx, current.ID, newTokenID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao rotacionar refresh token: %w", err)
+	}
+	if !replaced {
+		if err := uc.refreshRepo.RevokeFamily(ctx, current.FamilyID); err != nil {
+			return nil, fmt.Errorf("erro ao revogar família de tokens: %w", err)
+		}
+		return nil, ErrRefreshTokenReused
+	}
+
+	if current.DeviceID != "" {
+		if err := uc.deviceRepo.Touch(ctx, user.ID, current.DeviceID, time.Now().UTC()); err != nil {
+			return nil, fmt.Errorf("erro ao atualizar dispositivo: %w", err)
+		}
+	}
+
+	return res, nil
+}
+
+// Logout revoga o access token atual e, se informado, a família do refresh token do aparelho
+func (uc *UserUseCase) Logout(ctx context.Context, userID uuid.UUID, tokenID string, tokenExpiresAt time.Time, input dto.LogoutRequest) error {
+	if tokenID != "" {
+		if err := uc.revokedRepo.Revoke(ctx, tokenID, userID, tokenExpiresAt); err != nil {
+			return fmt.Errorf("erro ao revogar access token: %w", err)
+		}
+	}
+
+	if input.RefreshToken == "" {
+		return nil
+	}
+
+	refresh, err := uc.refreshRepo.GetByHash(ctx, auth.HashToken(input.RefreshToken))
+	if err != nil {
+		return fmt.Errorf("erro ao buscar refresh token: %w", err)
+	}
+	// Ignora tokens de outro usuário: ninguém encerra a sessão alheia por aqui
+	if refresh == nil || refresh.UserID != userID {
+		return nil
+	}
+	return uc.refreshRepo.RevokeFamily(ctx, refresh.FamilyID)
+}
+
+// LogoutAll encerra todas as sessões do usuário em todos os aparelhos
+func (uc *UserUseCase) LogoutAll(ctx context.Context, userID uuid.UUID) error {
+	user, err := uc.repo.GetByID(ctx, userID)
 	if err != nil {
-		return nil, fmt.Errorf("erro token: %w", err)
+		return err
+	}
+	if user == nil {
+		return ErrUserNotFound
+	}
+
+	user.RevokeSessions()
+	if err := uc.repo.UpdateSecurity(ctx, user); err != nil {
+		return fmt.Errorf("erro ao revogar sessões: %w", err)
+	}
+	return uc.refreshRepo.RevokeAllByUser(ctx, userID)
+}
+
+// IsSessionActive é consultado pelo AuthMiddleware a cada requisição.
+// Um token deixa de valer se estiver na denylist, se o usuário não estiver mais ativo
+// ou se tiver sido emitido antes de um logout-all / troca de senha.
+func (uc *UserUseCase) IsSessionActive(ctx context.Context, userID uuid.UUID, tokenID string, issuedAt time.Time) (bool, error) {
+	if tokenID != "" {
+		revoked, err := uc.revokedRepo.IsRevoked(ctx, tokenID)
+		if err != nil {
+			return false, fmt.Errorf("erro ao consultar denylist: %w", err)
+		}
+		if revoked {
+			return false, nil
+		}
+	}
+
+	user, err := uc.repo.GetByID(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	if user == nil || user.Status != entity.StatusActive {
+		return false, nil
+	}
+
+	return issuedAfterCutoff(user, issuedAt), nil
+}
+
+// IsImpersonatorActive confere o ator de um token de impersonação: ele precisa continuar ativo, com
+// permissão de impersonar (escrita só super admin) e sem logout-all/troca de senha depois da emissão.
+// Demitir ou rebaixar o suporte encerra na hora as impersonações que ele tinha abertas.
+func (uc *UserUseCase) IsImpersonatorActive(ctx context.Context, actorID uuid.UUID, issuedAt time.Time, writeAccess bool) (bool, error) {
+	actor, err := uc.repo.GetByID(ctx, actorID)
+	if err != nil {
+		return false, err
+	}
+	if actor == nil || actor.Status != entity.StatusActive || !actor.Role.Can(entity.PermUsersImpersonate) {
+		return false, nil
+	}
+	if writeAccess && actor.Role != entity.RoleSuperAdmin {
+		return false, nil
+	}
+	return issuedAfterCutoff(actor, issuedAt), nil
+}
+
+// issuedAfterCutoff diz se o token foi emitido depois do último logout-all / troca de senha do usuário.
+// issuedAt vem do "jti" com precisão de milissegundos (Claims.IssuedAtPrecise), então comparamos na mesma escala.
+func issuedAfterCutoff(user *entity.User, issuedAt time.Time) bool {
+	return !issuedAt.Before(user.SessionsValidAfter().Truncate(time.Millisecond))
+}
+
+// resolveStore valida a loja atribuída a um novo usuário. Quem está preso a uma loja só atribui a própria.
+func resolveStore(ctx context.Context, storeRepo orgRepository.StoreRepository, actor *entity.User, storeID *uuid.UUID) (*uuid.UUID, error) {
+	if bound := actor.BoundStoreID(); bound != nil {
+		if storeID != nil && *storeID != *bound {
+			return nil, ErrStoreNotFound
+		}
+		return bound, nil
+	}
+	if storeID == nil {
+		return nil, nil
+	}
+
+	store, err := storeRepo.GetByID(ctx, *storeID)
+	if err != nil {
+		return nil, err
+	}
+	if store == nil || store.OrganizationID != actor.OrganizationID {
+		return nil, ErrStoreNotFound
+	}
+	return storeID, nil
+}
+
+func toUserResponse(user *entity.User) *dto.UserResponse {
+	return &dto.UserResponse{
+		ID: user.ID, OrganizationID: user.OrganizationID, StoreID: user.StoreID,
+		Name: user.Name, Email: user.Email, Phone: user.Phone, AvatarURL: user.AvatarURL,
+		Role: user.Role, Status: user.Status, Timezone: user.Timezone, Language: user.Language,
+		LastLogin: user.LastLoginAt,
+	}
+}
+
+// organizationSettings busca as políticas do tenant (organização inexistente = configurações padrão)
+func (uc *UserUseCase) organizationSettings(ctx context.Context, orgID uuid.UUID) (orgEntity.OrganizationSettings, error) {
+	org, err := uc.orgRepo.GetByID(ctx, orgID)
+	if err != nil {
+		return orgEntity.OrganizationSettings{}, fmt.Errorf("erro ao buscar políticas da organização: %w", err)
+	}
+	if org == nil {
+		return orgEntity.OrganizationSettings{}, nil
+	}
+	return org.Settings, nil
+}
+
+// issueTokens gera o access token (JWT) e persiste um novo refresh token na família.
+// Retorna também o ID do refresh token criado (usado para encadear a rotação).
+func (uc *UserUseCase) issueTokens(ctx context.Context, user *entity.User, familyID uuid.UUID, deviceID string) (*dto.LoginResponse, uuid.UUID, error) {
+	// A política de email é reavaliada a cada emissão: após confirmar, o refresh já libera a escrita
+	policy, err := uc.unverifiedEmailPolicy(ctx, user)
+	if err != nil {
+		return nil, uuid.Nil, err
+	}
+	if policy == orgEntity.UnverifiedEmailBlock {
+		return nil, uuid.Nil, ErrEmailNotVerified
+	}
+
+	token, err := auth.GenerateToken(user.ID, user.OrganizationID, user.BoundStoreID(), string(user.Role), policy == orgEntity.UnverifiedEmailReadOnly)
+	if err != nil {
+		return nil, uuid.Nil, fmt.Errorf("erro token: %w", err)
 	}
 
 	cfg := config.Get()
+	rawRefresh, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		return nil, uuid.Nil, err
+	}
+
+	refresh := entity.NewRefreshToken(user.ID, familyID, auth.HashToken(rawRefresh), deviceID, cfg.RefreshExpiration)
+	if err := uc.refreshRepo.Create(ctx, refresh); err != nil {
+		return nil, uuid.Nil, fmt.Errorf("erro ao salvar refresh token: %w", err)
+	}
+
 	return &dto.LoginResponse{
-		AccessToken: token, ExpiresIn: int(cfg.JWTExpiration.Seconds()), TokenType: "Bearer",
-		User: dto.UserResponse{
+		AccessToken:  token,
+		RefreshToken: rawRefresh,
+		ExpiresIn:    int(cfg.JWTExpiration.Seconds()),
+		TokenType:    "Bearer",
+		User: &dto.UserResponse{
 			ID: user.ID, Name: user.Name, Email: user.Email, Role: user.Role,
 			AvatarURL: user.AvatarURL, Status: user.Status,
 		},
-	}, nil
+	}, refresh.ID, nil
 }
@@ -0,0 +1,55 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// APIKey é a credencial de uma integração (ERP, BI) da organização. Não age em nome de um usuário:
+// o que ela pode fazer são os escopos, fixados na criação.
+type APIKey struct {
+	ID             uuid.UUID    `json:"id"`
+	OrganizationID uuid.UUID    `json:"organization_id"`
+	Name           string       `json:"name"`
+	Prefix         string       `json:"prefix"`
+	KeyHash        string       `json:"-"`
+	Scopes         []Permission `json:"scopes"`
+	CreatedBy      *uuid.UUID   `json:"created_by,omitempty"`
+
+	ExpiresAt  time.Time  `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// NewAPIKey cria a chave a partir do hash e do prefixo já gerados (a chave pura nunca chega ao banco)
+func NewAPIKey(orgID uuid.UUID, name, prefix, keyHash string, scopes []Permission, createdBy uuid.UUID, ttl time.Duration) *APIKey {
+	now := time.Now().UTC()
+	return &APIKey{
+		ID:             uuid.New(),
+		OrganizationID: orgID,
+		Name:           name,
+		Prefix:         prefix,
+		KeyHash:        keyHash,
+		Scopes:         scopes,
+		CreatedBy:      &createdBy,
+		ExpiresAt:      now.Add(ttl),
+		CreatedAt:      now,
+	}
+}
+
+// IsActive indica se a chave ainda não foi revogada nem expirou
+func (k *APIKey) IsActive() bool {
+	return k.RevokedAt == nil && time.Now().UTC().Before(k.ExpiresAt)
+}
+
+// Can indica se a chave recebeu a permissão
+func (k *APIKey) Can(p Permission) bool {
+	for _, granted := range k.Scopes {
+		if granted == p {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,69 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ImpersonationSession é um acesso da equipe da plataforma "como" um usuário do tenant
+type ImpersonationSession struct {
+	ID           uuid.UUID `json:"id"`
+	ActorID      uuid.UUID `json:"actor_id"`
+	ActorRole    UserRole  `json:"actor_role"`
+	TargetUserID uuid.UUID `json:"target_user_id"`
+	TargetOrgID  uuid.UUID `json:"target_org_id"`
+	Reason       string    `json:"reason"`
+	ReadOnly     bool      `json:"read_only"`
+	TokenID      string    `json:"-"` // "jti" do token emitido
+	IPAddress    string    `json:"ip_address,omitempty"`
+
+	ExpiresAt time.Time  `json:"expires_at"`
+	EndedAt   *time.Time `json:"ended_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// NewImpersonationSession cria a sessão. Sem acesso de escrita explícito ela é somente leitura.
+func NewImpersonationSession(actor, target *User, reason string, writeAccess bool, ip string, ttl time.Duration) *ImpersonationSession {
+	now := time.Now().UTC()
+	return &ImpersonationSession{
+		ID:           uuid.New(),
+		ActorID:      actor.ID,
+		ActorRole:    actor.Role,
+		TargetUserID: target.ID,
+		TargetOrgID:  target.OrganizationID,
+		Reason:       reason,
+		ReadOnly:     !writeAccess,
+		TokenID:      uuid.Must(uuid.NewV7()).String(), // Mesmo formato do "jti" dos demais tokens (auth.NewTokenID)
+		IPAddress:    ip,
+		ExpiresAt:    now.Add(ttl),
+		CreatedAt:    now,
+	}
+}
+
+// IsActive indica se a sessão ainda não foi encerrada nem expirou
+func (s *ImpersonationSession) IsActive() bool {
+	return s.EndedAt == nil && time.Now().UTC().Before(s.ExpiresAt)
+}
+
+// ImpersonationEvent registra uma requisição feita durante a impersonação (trilha de auditoria)
+type ImpersonationEvent struct {
+	ID        uuid.UUID `json:"id"`
+	SessionID uuid.UUID `json:"session_id"`
+	Method    string    `json:"method"`
+	Path      string    `json:"path"`
+	Status    int       `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// NewImpersonationEvent cria o registro de uma requisição
+func NewImpersonationEvent(sessionID uuid.UUID, method, path string, status int) *ImpersonationEvent {
+	return &ImpersonationEvent{
+		ID:        uuid.New(),
+		SessionID: sessionID,
+		Method:    method,
+		Path:      path,
+		Status:    status,
+		CreatedAt: time.Now().UTC(),
+	}
+}
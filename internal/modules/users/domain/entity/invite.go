@@ -0,0 +1,100 @@
+package entity
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// InviteStatus é derivado das datas do convite (não é persistido)
+type InviteStatus string
+
+const (
+	InvitePending  InviteStatus = "pending"
+	InviteAccepted InviteStatus = "accepted"
+	InviteRevoked  InviteStatus = "revoked"
+	InviteExpired  InviteStatus = "expired"
+)
+
+// Invite representa o convite de um usuário para entrar em uma organização.
+// Apenas o hash do token é persistido; o valor puro vai no link do email.
+type Invite struct {
+	ID             uuid.UUID  `json:"id"`
+	OrganizationID uuid.UUID  `json:"organization_id"`
+	StoreID        *uuid.UUID `json:"store_id,omitempty"`
+
+	Email     string    `json:"email"`
+	Role      UserRole  `json:"role"`
+	TokenHash string    `json:"-"`
+	InvitedBy uuid.UUID `json:"invited_by"`
+
+	ExpiresAt  time.Time  `json:"expires_at"`
+	AcceptedAt *time.Time `json:"accepted_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// NewInvite cria um convite válido pelo tempo informado
+func NewInvite(orgID, invitedBy uuid.UUID, storeID *uuid.UUID, email string, role UserRole, tokenHash string, ttl time.Duration) (*Invite, error) {
+	email = NormalizeEmail(email)
+	if orgID == uuid.Nil || invitedBy == uuid.Nil {
+		return nil, errors.New("organização e autor do convite são obrigatórios")
+	}
+	if email == "" {
+		return nil, errors.New("email é obrigatório")
+	}
+
+	now := time.Now().UTC()
+	return &Invite{
+		ID:             uuid.New(),
+		OrganizationID: orgID,
+		StoreID:        storeID,
+		Email:          email,
+		Role:           role,
+		TokenHash:      tokenHash,
+		InvitedBy:      invitedBy,
+		ExpiresAt:      now.Add(ttl),
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}, nil
+}
+
+// Status calcula a situação atual do convite
+func (i *Invite) Status() InviteStatus {
+	switch {
+	case i.AcceptedAt != nil:
+		return InviteAccepted
+	case i.RevokedAt != nil:
+		return InviteRevoked
+	case time.Now().UTC().After(i.ExpiresAt):
+		return InviteExpired
+	default:
+		return InvitePending
+	}
+}
+
+// Renew gera um novo link (reenvio); convites aceitos ou revogados não voltam
+func (i *Invite) Renew(tokenHash string, ttl time.Duration) error {
+	if i.AcceptedAt != nil || i.RevokedAt != nil {
+		return errors.New("convite não está mais em aberto")
+	}
+	now := time.Now().UTC()
+	i.TokenHash = tokenHash
+	i.ExpiresAt = now.Add(ttl)
+	i.UpdatedAt = now
+	return nil
+}
+
+// Revoke cancela o convite
+func (i *Invite) Revoke() error {
+	if i.AcceptedAt != nil || i.RevokedAt != nil {
+		return errors.New("convite não está mais em aberto")
+	}
+	now := time.Now().UTC()
+	i.RevokedAt = &now
+	i.UpdatedAt = now
+	return nil
+}
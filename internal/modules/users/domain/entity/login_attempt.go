@@ -0,0 +1,56 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LoginAttemptStatus é o resultado de uma tentativa de login
+type LoginAttemptStatus string
+
+const (
+	LoginSuccess          LoginAttemptStatus = "success"
+	LoginInvalidPassword  LoginAttemptStatus = "invalid_password"
+	LoginInvalidTwoFactor LoginAttemptStatus = "invalid_two_factor"
+	LoginLocked           LoginAttemptStatus = "locked"
+	LoginInactive         LoginAttemptStatus = "inactive"
+	LoginEmailNotVerified LoginAttemptStatus = "email_not_verified"
+	LoginUnknownUser      LoginAttemptStatus = "unknown_user"
+	LoginDeviceQuota      LoginAttemptStatus = "device_quota_exceeded" // Aparelho novo além da cota do plano
+)
+
+// LoginAttempt registra cada tentativa de login (histórico de segurança do usuário)
+type LoginAttempt struct {
+	ID     uuid.UUID  `json:"id"`
+	UserID *uuid.UUID `json:"user_id,omitempty"` // Nil quando o email não existe
+	Email  string     `json:"email"`
+
+	Status     LoginAttemptStatus `json:"status"`
+	IPAddress  string             `json:"ip_address,omitempty"`
+	UserAgent  string             `json:"user_agent,omitempty"`
+	DeviceID   string             `json:"device_id,omitempty"`
+	DeviceName string             `json:"device_name,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// NewLoginAttempt cria o registro de uma tentativa
+func NewLoginAttempt(userID *uuid.UUID, email string, status LoginAttemptStatus, ip, userAgent, deviceID, deviceName string) *LoginAttempt {
+	return &LoginAttempt{
+		ID:         uuid.New(),
+		UserID:     userID,
+		Email:      email,
+		Status:     status,
+		IPAddress:  ip,
+		UserAgent:  userAgent,
+		DeviceID:   deviceID,
+		DeviceName: deviceName,
+		CreatedAt:  time.Now().UTC(),
+	}
+}
+
+// Succeeded indica se a tentativa resultou em sessão
+func (a *LoginAttempt) Succeeded() bool {
+	return a.Status == LoginSuccess
+}
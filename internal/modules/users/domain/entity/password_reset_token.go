@@ -0,0 +1,32 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PasswordResetToken é o token de uso único enviado por email no "esqueci minha senha".
+// Assim como no refresh token, apenas o hash SHA-256 é persistido.
+type PasswordResetToken struct {
+	ID        uuid.UUID `json:"id"`
+	UserID    uuid.UUID `json:"user_id"`
+	TokenHash string    `json:"-"`
+
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// NewPasswordResetToken cria um token válido pelo tempo informado
+func NewPasswordResetToken(userID uuid.UUID, tokenHash string, ttl time.Duration) *PasswordResetToken {
+	now := time.Now().UTC()
+	return &PasswordResetToken{
+		ID:        uuid.New(),
+		UserID:    userID,
+		TokenHash: tokenHash,
+		ExpiresAt: now.Add(ttl),
+		CreatedAt: now,
+	}
+}
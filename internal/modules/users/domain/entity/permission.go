@@ -0,0 +1,130 @@
+package entity
+
+// Permission identifica uma ação protegida no formato "recurso:ação"
+type Permission string
+
+const (
+	PermOrganizationsRead   Permission = "organizations:read"
+	PermOrganizationsManage Permission = "organizations:manage"
+	PermStoresCreate        Permission = "stores:create"
+	PermStoresRead          Permission = "stores:read"
+	PermUsersRead           Permission = "users:read"
+	PermUsersManage         Permission = "users:manage"
+	PermUsersInvite         Permission = "users:invite"
+	PermUsersAudit          Permission = "users:audit"
+	PermDevicesRead         Permission = "devices:read"
+	PermGondolasRead        Permission = "gondolas:read"
+	PermGondolasManage      Permission = "gondolas:manage"
+	PermAPIKeysManage       Permission = "api_keys:manage"
+
+	// Exclusivas da plataforma
+	PermUsersImpersonate    Permission = "users:impersonate"
+	PermSecurityManage      Permission = "security:manage"      // Bloqueios de login por IP/email
+	PermOrganizationsCreate Permission = "organizations:create" // Criar empresas sem o cadastro público
+	PermPlansManage         Permission = "plans:manage"         // Qualquer plano e exceções de cota por tenant
+)
+
+// tenantPermissions são as permissões que um papel do cliente pode ter (ordem estável para documentação/validação)
+var tenantPermissions = []Permission{
+	PermOrganizationsRead,
+	PermOrganizationsManage,
+	PermStoresCreate,
+	PermStoresRead,
+	PermUsersRead,
+	PermUsersManage,
+	PermUsersInvite,
+	PermUsersAudit,
+	PermDevicesRead,
+	PermGondolasRead,
+	PermGondolasManage,
+	PermAPIKeysManage,
+}
+
+// apiKeyPermissions são as permissões que uma chave de API pode receber: integrações não têm
+// um usuário por trás, então ficam de fora as ações que dependem de "quem sou eu" (gestão de
+// usuários, aparelhos, auditoria) e a emissão de novas chaves
+var apiKeyPermissions = []Permission{
+	PermOrganizationsRead,
+	PermStoresCreate,
+	PermStoresRead,
+	PermGondolasRead,
+	PermGondolasManage,
+}
+
+// allPermissions é o catálogo completo
+var allPermissions = append(append([]Permission(nil), tenantPermissions...), PermUsersImpersonate, PermSecurityManage, PermOrganizationsCreate, PermPlansManage)
+
+// rolePermissions é a matriz papel -> permissões. Papéis fora da matriz não têm acesso algum.
+var rolePermissions = map[UserRole][]Permission{
+	RoleSuperAdmin: allPermissions,
+	RoleSupport: {
+		PermOrganizationsRead,
+		PermStoresRead,
+		PermUsersRead,
+		PermUsersAudit,
+		PermDevicesRead,
+		PermGondolasRead,
+		PermUsersImpersonate,
+		PermSecurityManage,
+	},
+	RoleTenantAdmin: tenantPermissions,
+	RoleManager: {
+		PermOrganizationsRead,
+		PermStoresRead,
+		PermUsersRead,
+		PermUsersManage,
+		PermUsersInvite,
+		PermDevicesRead,
+		PermGondolasRead,
+		PermGondolasManage,
+	},
+	RoleOperator: {
+		PermOrganizationsRead,
+		PermGondolasRead,
+	},
+}
+
+// Roles retorna o catálogo de papéis canônicos
+func Roles() []UserRole {
+	return []UserRole{RoleSuperAdmin, RoleSupport, RoleTenantAdmin, RoleManager, RoleOperator}
+}
+
+// Permissions retorna o catálogo de permissões
+func Permissions() []Permission {
+	return append([]Permission(nil), allPermissions...)
+}
+
+// APIKeyPermissions retorna as permissões concedíveis a chaves de API
+func APIKeyPermissions() []Permission {
+	return append([]Permission(nil), apiKeyPermissions...)
+}
+
+// AllowedForAPIKey indica se a permissão pode ser concedida a uma chave de API
+func (p Permission) AllowedForAPIKey() bool {
+	for _, allowed := range apiKeyPermissions {
+		if allowed == p {
+			return true
+		}
+	}
+	return false
+}
+
+// ParsePermission valida o nome de uma permissão
+func ParsePermission(name string) (Permission, bool) {
+	for _, p := range allPermissions {
+		if string(p) == name {
+			return p, true
+		}
+	}
+	return "", false
+}
+
+// Can indica se o papel concede a permissão
+func (r UserRole) Can(p Permission) bool {
+	for _, granted := range rolePermissions[r] {
+		if granted == p {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,48 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RefreshToken representa uma sessão de longa duração de um dispositivo.
+// O valor puro do token nunca é persistido, apenas o hash SHA-256.
+type RefreshToken struct {
+	ID        uuid.UUID `json:"id"`
+	UserID    uuid.UUID `json:"user_id"`
+	FamilyID  uuid.UUID `json:"family_id"` // Todos os tokens gerados a partir do mesmo login
+	TokenHash string    `json:"-"`
+	DeviceID  string    `json:"device_id,omitempty"`
+
+	ExpiresAt  time.Time  `json:"expires_at"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	ReplacedBy *uuid.UUID `json:"replaced_by,omitempty"` // Preenchido quando o token é rotacionado
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// NewRefreshToken cria um token para a família informada.
+// No login uma nova família é iniciada; na rotação a família é herdada.
+func NewRefreshToken(userID, familyID uuid.UUID, tokenHash, deviceID string, ttl time.Duration) *RefreshToken {
+	now := time.Now().UTC()
+	return &RefreshToken{
+		ID:        uuid.New(),
+		UserID:    userID,
+		FamilyID:  familyID,
+		TokenHash: tokenHash,
+		DeviceID:  deviceID,
+		ExpiresAt: now.Add(ttl),
+		CreatedAt: now,
+	}
+}
+
+// IsExpired verifica se o token passou da validade
+func (t *RefreshToken) IsExpired() bool {
+	return time.Now().UTC().After(t.ExpiresAt)
+}
+
+// IsRevoked indica se o token já foi usado (rotacionado) ou revogado
+func (t *RefreshToken) IsRevoked() bool {
+	return t.RevokedAt != nil
+}
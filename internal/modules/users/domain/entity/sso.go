@@ -0,0 +1,59 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SSOLoginRequest é um login corporativo em andamento (entre o redirecionamento e o callback)
+type SSOLoginRequest struct {
+	ID             uuid.UUID
+	OrganizationID uuid.UUID
+	StateHash      string // Hash do "state" devolvido pelo provedor
+	Nonce          string
+	CodeVerifier   string // PKCE: nunca sai do servidor
+	RedirectURI    string
+	ExpiresAt      time.Time
+	CreatedAt      time.Time
+}
+
+// NewSSOLoginRequest cria o registro do login em andamento
+func NewSSOLoginRequest(orgID uuid.UUID, stateHash, nonce, codeVerifier, redirectURI string, ttl time.Duration) *SSOLoginRequest {
+	now := time.Now().UTC()
+	return &SSOLoginRequest{
+		ID:             uuid.New(),
+		OrganizationID: orgID,
+		StateHash:      stateHash,
+		Nonce:          nonce,
+		CodeVerifier:   codeVerifier,
+		RedirectURI:    redirectURI,
+		ExpiresAt:      now.Add(ttl),
+		CreatedAt:      now,
+	}
+}
+
+// IsExpired indica se o usuário demorou demais no provedor
+func (r *SSOLoginRequest) IsExpired() bool {
+	return time.Now().UTC().After(r.ExpiresAt)
+}
+
+// UserIdentity liga o usuário à identidade no provedor OIDC (issuer + sub)
+type UserIdentity struct {
+	ID        uuid.UUID `json:"id"`
+	UserID    uuid.UUID `json:"user_id"`
+	Issuer    string    `json:"issuer"`
+	Subject   string    `json:"subject"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// NewUserIdentity cria o vínculo
+func NewUserIdentity(userID uuid.UUID, issuer, subject string) *UserIdentity {
+	return &UserIdentity{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Issuer:    issuer,
+		Subject:   subject,
+		CreatedAt: time.Now().UTC(),
+	}
+}
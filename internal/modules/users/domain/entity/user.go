@@ -2,10 +2,11 @@ package entity
 
 import (
 	"errors"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
-	"golang.org/x/crypto/bcrypt"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/hasher"
 )
 
 // UserRole define os papéis de acesso (RBAC)
@@ -22,6 +23,53 @@ const (
 	RoleOperator    UserRole = "operator"     // Funcionário da Loja
 )
 
+// ParseRole converte um nome no papel do catálogo
+func ParseRole(name string) (UserRole, bool) {
+	role := UserRole(name)
+	return role, role.IsValid()
+}
+
+// IsValid indica se o papel faz parte do catálogo
+func (r UserRole) IsValid() bool {
+	return r.rank() > 0
+}
+
+// IsPlatform indica papéis da equipe da plataforma, que atendem qualquer organização
+func (r UserRole) IsPlatform() bool {
+	return r == RoleSuperAdmin || r == RoleSupport
+}
+
+// rank ordena os papéis por nível de acesso (0 = desconhecido)
+func (r UserRole) rank() int {
+	switch r {
+	case RoleSuperAdmin:
+		return 100
+	case RoleSupport:
+		return 90
+	case RoleTenantAdmin:
+		return 50
+	case RoleManager:
+		return 30
+	case RoleOperator:
+		return 10
+	}
+	return 0
+}
+
+// CanAssign diz se quem tem este papel pode atribuir o papel alvo a outro usuário (convite/criação).
+// Apenas papéis do tenant são atribuíveis; o tenant admin atribui qualquer um deles,
+// os demais só papéis abaixo do seu (gerente convida operador).
+func (r UserRole) CanAssign(target UserRole) bool {
+	t := target.rank()
+	if t == 0 || t > RoleTenantAdmin.rank() {
+		return false
+	}
+	if r.rank() >= RoleTenantAdmin.rank() {
+		return true
+	}
+	return r.rank() > t
+}
+
 // UserStatus define o ciclo de vida do cadastro
 type UserStatus string
 
@@ -54,16 +102,16 @@ type User struct {
 	Language string `json:"language"` // Ex: "pt-BR"
 
 	// Segurança & Compliance
-	EmailVerifiedAt   *time.Time     `json:"email_verified_at,omitempty"`
-	TermsAcceptedAt   *time.Time     `json:"terms_accepted_at,omitempty"`
-	PasswordChangedAt *time.Time     `json:"password_changed_at,omitempty"`
-	TwoFactor         *TwoFactorAuth `json:"two_factor,omitempty"`
+	EmailVerifiedAt         *time.Time     `json:"email_verified_at,omitempty"`
+	EmailVerificationSentAt *time.Time     `json:"-"` // Último envio do link de confirmação (throttling)
+	TermsAcceptedAt         *time.Time     `json:"terms_accepted_at,omitempty"`
+	PasswordChangedAt       *time.Time     `json:"password_changed_at,omitempty"`
+	TokensValidAfter        *time.Time     `json:"-"` // Tokens emitidos antes disso são rejeitados (logout-all)
+	TwoFactor               *TwoFactorAuth `json:"two_factor,omitempty"`
 
 	// Auditoria
-	FailedLoginAttempts int        `json:"-"`
-	LockedUntil         *time.Time `json:"locked_until,omitempty"`
-	LastLoginAt         *time.Time `json:"last_login_at,omitempty"`
-	LastLoginIP         string     `json:"last_login_ip,omitempty"`
+	LastLoginAt *time.Time `json:"last_login_at,omitempty"`
+	LastLoginIP string     `json:"last_login_ip,omitempty"`
 
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
@@ -73,11 +121,22 @@ type User struct {
 type TwoFactorAuth struct {
 	Enabled   bool   `json:"enabled"`
 	Secret    string `json:"-"`
-	QRCodeURL string `json:"qr_code_url,omitempty"`
+	QRCodeURL string `json:"qr_code_url,omitempty"` // URI otpauth:// exibida como QR Code
+
+	RecoveryCodes []string   `json:"-"` // Hashes SHA-256 dos códigos de recuperação ainda não usados
+	LastUsedStep  int64      `json:"-"` // Última janela TOTP aceita (impede reuso do mesmo código)
+	EnabledAt     *time.Time `json:"enabled_at,omitempty"`
+}
+
+// NormalizeEmail padroniza o email para gravação e comparação (minúsculas, sem espaços).
+// O email é único na plataforma sem diferenciar maiúsculas.
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
 }
 
 // NewUser Factory - Prepara usuário para fluxo de auto-cadastro ou convite
 func NewUser(orgID uuid.UUID, name, email, password string, role UserRole) (*User, error) {
+	email = NormalizeEmail(email)
 	if orgID == uuid.Nil {
 		return nil, errors.New("organization_id é obrigatório")
 	}
@@ -111,42 +170,168 @@ func NewUser(orgID uuid.UUID, name, email, password string, role UserRole) (*Use
 
 // SetPassword encripta a senha e registra a data da mudança
 func (u *User) SetPassword(password string) error {
-	hash, err := bcrypt.GenerateFromPassword([]byte(password), 12)
+	hash, err := hasher.Hash(password)
 	if err != nil {
 		return err
 	}
-	u.PasswordHash = string(hash)
-	now := time.Now()
+	u.PasswordHash = hash
+	now := time.Now().UTC()
 	u.PasswordChangedAt = &now
 	return nil
 }
 
 // CheckPassword valida a senha
 func (u *User) CheckPassword(password string) bool {
-	err := bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(password))
-	return err == nil
+	return PasswordMatchesHash(u.PasswordHash, password)
+}
+
+// PasswordMatchesHash compara a senha com um hash salvo (o atual ou um do histórico)
+func PasswordMatchesHash(hash, password string) bool {
+	return hasher.Verify(hash, password)
+}
+
+// RehashPassword refaz o hash quando ele usa algoritmo ou parâmetros antigos. Recebe a senha já
+// conferida (só existe em texto no login) e não mexe no PasswordChangedAt: não é uma troca de senha.
+func (u *User) RehashPassword(password string) (bool, error) {
+	if !hasher.NeedsRehash(u.PasswordHash) {
+		return false, nil
+	}
+	hash, err := hasher.Hash(password)
+	if err != nil {
+		return false, err
+	}
+	u.PasswordHash = hash
+	return true, nil
+}
+
+// RevokeSessions invalida todos os tokens emitidos até agora (logout em todos os aparelhos)
+func (u *User) RevokeSessions() {
+	now := time.Now().UTC()
+	u.TokensValidAfter = &now
+	u.UpdatedAt = now
+}
+
+// SessionsValidAfter devolve o marco a partir do qual um token é aceito.
+// Trocar a senha também derruba as sessões antigas.
+func (u *User) SessionsValidAfter() time.Time {
+	var validAfter time.Time
+	if u.PasswordChangedAt != nil {
+		validAfter = *u.PasswordChangedAt
+	}
+	if u.TokensValidAfter != nil && u.TokensValidAfter.After(validAfter) {
+		validAfter = *u.TokensValidAfter
+	}
+	return validAfter
+}
+
+// HasTwoFactor indica se o login exige o segundo fator
+func (u *User) HasTwoFactor() bool {
+	return u.TwoFactor != nil && u.TwoFactor.Enabled && u.TwoFactor.Secret != ""
+}
+
+// StartTwoFactorEnrollment guarda o segredo ainda não confirmado.
+// O 2FA só passa a valer depois que o usuário provar que configurou o app (EnableTwoFactor).
+func (u *User) StartTwoFactorEnrollment(secret, provisioningURI string) error {
+	if u.HasTwoFactor() {
+		return errors.New("autenticação em dois fatores já está ativa")
+	}
+	u.TwoFactor = &TwoFactorAuth{Enabled: false, Secret: secret, QRCodeURL: provisioningURI}
+	u.UpdatedAt = time.Now().UTC()
+	return nil
+}
+
+// EnableTwoFactor ativa o 2FA com os hashes dos códigos de recuperação
+func (u *User) EnableTwoFactor(recoveryCodeHashes []string, step int64) error {
+	if u.TwoFactor == nil || u.TwoFactor.Secret == "" {
+		return errors.New("nenhum cadastro de 2FA em andamento")
+	}
+	now := time.Now().UTC()
+	u.TwoFactor.Enabled = true
+	u.TwoFactor.RecoveryCodes = recoveryCodeHashes
+	u.TwoFactor.LastUsedStep = step
+	u.TwoFactor.EnabledAt = &now
+	u.UpdatedAt = now
+	return nil
+}
+
+// DisableTwoFactor remove segredo e códigos de recuperação
+func (u *User) DisableTwoFactor() {
+	u.TwoFactor = &TwoFactorAuth{Enabled: false}
+	u.UpdatedAt = time.Now().UTC()
+}
+
+// MarkTOTPStepUsed registra a janela aceita; devolve false se ela já foi usada antes
+func (u *User) MarkTOTPStepUsed(step int64) bool {
+	if step <= u.TwoFactor.LastUsedStep {
+		return false
+	}
+	u.TwoFactor.LastUsedStep = step
+	return true
+}
+
+// ConsumeRecoveryCode remove o código (pelo hash) da lista; cada código vale uma única vez
+func (u *User) ConsumeRecoveryCode(codeHash string) bool {
+	if u.TwoFactor == nil {
+		return false
+	}
+	for i, h := range u.TwoFactor.RecoveryCodes {
+		if h == codeHash {
+			u.TwoFactor.RecoveryCodes = append(u.TwoFactor.RecoveryCodes[:i], u.TwoFactor.RecoveryCodes[i+1:]...)
+			u.UpdatedAt = time.Now().UTC()
+			return true
+		}
+	}
+	return false
 }
 
-// IsLocked verifica bloqueio temporário
-func (u *User) IsLocked() bool {
-	return u.LockedUntil != nil && u.LockedUntil.After(time.Now().UTC())
+// IsEmailVerified indica se o usuário já confirmou o email
+func (u *User) IsEmailVerified() bool {
+	return u.EmailVerifiedAt != nil
 }
 
-// RegisterFailedLogin incrementa as tentativas e bloqueia a conta se atingir o limite
-func (u *User) RegisterFailedLogin(maxAttempts int, lockoutDuration time.Duration) {
-	u.FailedLoginAttempts++
-	if u.FailedLoginAttempts >= maxAttempts {
-		lockTime := time.Now().UTC().Add(lockoutDuration)
-		u.LockedUntil = &lockTime
+// MarkEmailVerified confirma o email; cadastros pendentes por falta de confirmação são ativados
+func (u *User) MarkEmailVerified() {
+	now := time.Now().UTC()
+	u.EmailVerifiedAt = &now
+	if u.Status == StatusPending {
+		u.Status = StatusActive
 	}
+	u.UpdatedAt = now
+}
+
+// CanResendVerification aplica o intervalo mínimo entre dois envios do link
+func (u *User) CanResendVerification(interval time.Duration) bool {
+	return u.EmailVerificationSentAt == nil || time.Now().UTC().Sub(*u.EmailVerificationSentAt) >= interval
+}
+
+// MarkVerificationSent registra o envio do link de confirmação
+func (u *User) MarkVerificationSent() {
+	now := time.Now().UTC()
+	u.EmailVerificationSentAt = &now
+}
+
+// Suspend bloqueia o acesso e derruba as sessões abertas
+func (u *User) Suspend() {
+	u.Status = StatusSuspended
+	u.RevokeSessions()
+}
+
+// Reactivate devolve o acesso a um usuário suspenso
+func (u *User) Reactivate() {
+	u.Status = StatusActive
 	u.UpdatedAt = time.Now().UTC()
 }
 
-// ResetLoginAttempts zera o contador após um login com sucesso
-func (u *User) ResetLoginAttempts() {
-	if u.FailedLoginAttempts > 0 || u.LockedUntil != nil {
-		u.FailedLoginAttempts = 0
-		u.LockedUntil = nil
-		u.UpdatedAt = time.Now().UTC()
+// BoundStoreID devolve a loja à qual o acesso do usuário é restrito. Só gerentes e operadores
+// ficam presos à loja; administradores enxergam a organização inteira mesmo com loja vinculada.
+func (u *User) BoundStoreID() *uuid.UUID {
+	if u.Role != RoleManager && u.Role != RoleOperator {
+		return nil
 	}
+	return u.StoreID
+}
+
+// IsTenantAdmin indica se o usuário administra a organização (dono)
+func (u *User) IsTenantAdmin() bool {
+	return u.Role == RoleTenantAdmin
 }
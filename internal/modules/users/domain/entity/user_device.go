@@ -27,6 +27,7 @@ type UserDevice struct {
 
 // NewUserDevice cria um registro de dispositivo
 func NewUserDevice(userID uuid.UUID, deviceID, name, platform, pushToken, version string) *UserDevice {
+	now := time.Now().UTC()
 	return &UserDevice{
 		ID:           uuid.New(),
 		UserID:       userID,
@@ -35,8 +36,8 @@ func NewUserDevice(userID uuid.UUID, deviceID, name, platform, pushToken, versio
 		Platform:     platform,
 		AppVersion:   version,
 		PushToken:    pushToken,
-		LastActiveAt: time.Now(),
-		CreatedAt:    time.Now(),
+		LastActiveAt: now,
+		CreatedAt:    now,
 	}
 }
 
@@ -44,5 +45,18 @@ func NewUserDevice(userID uuid.UUID, deviceID, name, platform, pushToken, versio
 func (d *UserDevice) UpdatePushToken(newToken, appVersion string) {
 	d.PushToken = newToken
 	d.AppVersion = appVersion
-	d.LastActiveAt = time.Now()
+	d.LastActiveAt = time.Now().UTC()
+}
+
+// HasPush indica se o aparelho pode receber notificações
+func (d *UserDevice) HasPush() bool {
+	return d.PushToken != ""
+}
+
+// StoreDevice é o aparelho acompanhado do dono, usado na visão por loja (quem recebe os alertas)
+type StoreDevice struct {
+	UserDevice
+	UserName  string
+	UserEmail string
+	UserRole  UserRole
 }
@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/domain/entity"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/pagination"
+)
+
+// APIKeyRepository define a persistência das chaves de API das organizações
+type APIKeyRepository interface {
+	Create(ctx context.Context, key *entity.APIKey) error
+	GetByID(ctx context.Context, orgID, id uuid.UUID) (*entity.APIKey, error)
+	// GetByHash busca a chave apresentada na requisição (qualquer organização: ainda não há escopo)
+	GetByHash(ctx context.Context, keyHash string) (*entity.APIKey, error)
+	ListByOrganization(ctx context.Context, orgID uuid.UUID, params pagination.Params) ([]*entity.APIKey, int64, error)
+	Revoke(ctx context.Context, orgID, id uuid.UUID, revokedAt time.Time) error
+	// TouchLastUsed registra o uso, no máximo uma escrita por janela (evita um UPDATE por requisição)
+	TouchLastUsed(ctx context.Context, id uuid.UUID, usedAt time.Time, window time.Duration) error
+}
@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/domain/entity"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/pagination"
+)
+
+// DeviceRepository define a persistência dos aparelhos (App) de cada usuário
+type DeviceRepository interface {
+	// Comandos (Escrita)
+	// Upsert grava o aparelho no login: (user_id, device_id) já existente só é atualizado.
+	// O push token deixa de valer para qualquer outro aparelho/usuário que o tivesse.
+	Upsert(ctx context.Context, device *entity.UserDevice) error
+	// Touch marca atividade do aparelho (ex: renovação de sessão)
+	Touch(ctx context.Context, userID uuid.UUID, deviceID string, at time.Time) error
+	// Delete remove o aparelho do usuário; retorna nil se ele não existir
+	Delete(ctx context.Context, userID, id uuid.UUID) (*entity.UserDevice, error)
+	// DeleteByUser remove todos os aparelhos do usuário
+	DeleteByUser(ctx context.Context, userID uuid.UUID) error
+
+	// Consultas (Leitura)
+	// Exists indica se o aparelho já está registrado para o usuário
+	Exists(ctx context.Context, userID uuid.UUID, deviceID string) (bool, error)
+	ListByUser(ctx context.Context, userID uuid.UUID) ([]*entity.UserDevice, error)
+	// ListByStore lista os aparelhos dos usuários ativos vinculados à loja
+	ListByStore(ctx context.Context, orgID, storeID uuid.UUID, params pagination.Params) ([]*entity.StoreDevice, int64, error)
+}
@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/domain/entity"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/pagination"
+)
+
+// ImpersonationRepository define a persistência das sessões de impersonação e da sua auditoria
+type ImpersonationRepository interface {
+	Create(ctx context.Context, session *entity.ImpersonationSession) error
+	GetByID(ctx context.Context, id uuid.UUID) (*entity.ImpersonationSession, error)
+	// EndByTokenID encerra a sessão do token (no-op se o token não for de impersonação)
+	EndByTokenID(ctx context.Context, tokenID string, endedAt time.Time) error
+	List(ctx context.Context, params pagination.Params) ([]*entity.ImpersonationSession, int64, error)
+
+	RecordEvent(ctx context.Context, event *entity.ImpersonationEvent) error
+	ListEvents(ctx context.Context, sessionID uuid.UUID, params pagination.Params) ([]*entity.ImpersonationEvent, int64, error)
+}
@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/domain/entity"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/pagination"
+)
+
+// InviteRepository define a persistência dos convites de usuários
+type InviteRepository interface {
+	// Comandos (Escrita)
+	Create(ctx context.Context, invite *entity.Invite) error
+	Update(ctx context.Context, invite *entity.Invite) error // Reenvio e revogação
+	// MarkAccepted consome o convite se ele ainda estiver em aberto e dentro da validade em "at".
+	// Retorna false se outro aceite chegou antes (ou o convite expirou/foi revogado).
+	MarkAccepted(ctx context.Context, id uuid.UUID, at time.Time) (bool, error)
+
+	// Consultas (Leitura)
+	GetByID(ctx context.Context, id uuid.UUID) (*entity.Invite, error)
+	GetByTokenHash(ctx context.Context, tokenHash string) (*entity.Invite, error)
+	// GetOpenByEmail busca o convite não aceito e não revogado (pode estar expirado)
+	GetOpenByEmail(ctx context.Context, orgID uuid.UUID, email string) (*entity.Invite, error)
+	ListOpenByOrganization(ctx context.Context, orgID uuid.UUID, params pagination.Params) ([]*entity.Invite, int64, error)
+}
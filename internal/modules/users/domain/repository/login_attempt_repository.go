@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/domain/entity"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/pagination"
+)
+
+// LoginAttemptRepository define a persistência do histórico de logins
+type LoginAttemptRepository interface {
+	Create(ctx context.Context, attempt *entity.LoginAttempt) error
+	// ListByUser pagina as tentativas do usuário, das mais recentes para as mais antigas
+	ListByUser(ctx context.Context, userID uuid.UUID, params pagination.Params) ([]*entity.LoginAttempt, int64, error)
+	// CountFailedEmailsByIP conta os emails distintos com falha de login a partir do IP desde "since"
+	CountFailedEmailsByIP(ctx context.Context, ip string, since time.Time) (int, error)
+	// HasSucceededFrom indica se o email já entrou com sucesso a partir do IP desde "since"
+	HasSucceededFrom(ctx context.Context, email, ip string, since time.Time) (bool, error)
+}
@@ -0,0 +1,28 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/domain/entity"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/pagination"
+)
+
+// LoginThrottleRepository guarda os contadores de falhas de login
+type LoginThrottleRepository interface {
+	// Blocking devolve os contadores bloqueados agora entre as chaves informadas
+	Blocking(ctx context.Context, keys []entity.ThrottleKey, now time.Time) ([]*entity.LoginThrottle, error)
+	// RegisterFailure soma uma falha (reiniciando a janela vencida) e, ao atingir o limite da regra,
+	// bloqueia com backoff exponencial. Atômico: falhas simultâneas não geram bloqueio em dobro.
+	RegisterFailure(ctx context.Context, key entity.ThrottleKey, rule entity.ThrottleRule, reason entity.ThrottleReason, now time.Time) (*entity.LoginThrottle, error)
+	// Block bloqueia a chave na hora (padrão de ataque detectado), se ela ainda não estiver bloqueada
+	Block(ctx context.Context, key entity.ThrottleKey, rule entity.ThrottleRule, reason entity.ThrottleReason, now time.Time) (*entity.LoginThrottle, error)
+	// Reset apaga o contador
+	Reset(ctx context.Context, key entity.ThrottleKey) error
+	// ResetEmail apaga os contadores do email (escopos email e ip_email); devolve quantos existiam
+	ResetEmail(ctx context.Context, email string) (int64, error)
+	// ResetIP apaga os contadores do IP (escopos ip e ip_email); devolve quantos existiam
+	ResetIP(ctx context.Context, ip string) (int64, error)
+	// ListBlocked pagina os contadores bloqueados agora, dos que vencem por último para os primeiros
+	ListBlocked(ctx context.Context, now time.Time, params pagination.Params) ([]*entity.LoginThrottle, int64, error)
+}
@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// PasswordHistoryRepository guarda os hashes das senhas anteriores de cada usuário
+type PasswordHistoryRepository interface {
+	// Add registra o hash de uma senha que acabou de ser substituída
+	Add(ctx context.Context, userID uuid.UUID, passwordHash string) error
+	// ListRecent devolve os hashes mais recentes primeiro
+	ListRecent(ctx context.Context, userID uuid.UUID, limit int) ([]string, error)
+	// Prune mantém só os "keep" mais recentes
+	Prune(ctx context.Context, userID uuid.UUID, keep int) error
+}
@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/domain/entity"
+)
+
+// PasswordResetTokenRepository define a persistência dos tokens de redefinição de senha
+type PasswordResetTokenRepository interface {
+	Create(ctx context.Context, token *entity.PasswordResetToken) error
+	// Consume marca o token como usado se ele ainda for válido em "now".
+	// Retorna nil se o token não existe, expirou ou já foi usado.
+	Consume(ctx context.Context, tokenHash string, now time.Time) (*entity.PasswordResetToken, error)
+	// InvalidateAllByUser descarta os tokens pendentes do usuário
+	InvalidateAllByUser(ctx context.Context, userID uuid.UUID) error
+}
@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/domain/entity"
+)
+
+// RefreshTokenRepository define a persistência das sessões de longa duração
+type RefreshTokenRepository interface {
+	// Comandos (Escrita)
+	Create(ctx context.Context, token *entity.RefreshToken) error
+	// MarkReplaced revoga o token apontando para o seu sucessor.
+	// Retorna false se o token já estava revogado (uso concorrente/reuso).
+	MarkReplaced(ctx context.Context, id, replacedBy uuid.UUID) (bool, error)
+	RevokeFamily(ctx context.Context, familyID uuid.UUID) error
+	RevokeByDevice(ctx context.Context, userID uuid.UUID, deviceID string) error
+	RevokeAllByUser(ctx context.Context, userID uuid.UUID) error
+
+	// Consultas (Leitura)
+	GetByHash(ctx context.Context, tokenHash string) (*entity.RefreshToken, error)
+}
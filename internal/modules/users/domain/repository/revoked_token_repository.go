@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RevokedTokenRepository mantém a denylist de access tokens (pelo claim "jti")
+type RevokedTokenRepository interface {
+	// Revoke adiciona o token na denylist até a data de expiração dele
+	Revoke(ctx context.Context, jti string, userID uuid.UUID, expiresAt time.Time) error
+	IsRevoked(ctx context.Context, jti string) (bool, error)
+}
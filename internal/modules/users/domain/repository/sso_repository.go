@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/domain/entity"
+)
+
+// SSORepository guarda os logins corporativos em andamento e as identidades vinculadas
+type SSORepository interface {
+	CreateLoginRequest(ctx context.Context, req *entity.SSOLoginRequest) error
+	// ConsumeLoginRequest busca e apaga o login pelo hash do state (cada state vale uma vez)
+	ConsumeLoginRequest(ctx context.Context, stateHash string) (*entity.SSOLoginRequest, error)
+
+	GetIdentity(ctx context.Context, issuer, subject string) (*entity.UserIdentity, error)
+	CreateIdentity(ctx context.Context, identity *entity.UserIdentity) error
+}
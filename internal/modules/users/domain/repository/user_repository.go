@@ -5,16 +5,30 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/domain/entity"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/pagination"
 )
 
+// UserFilter restringe a listagem de usuários da organização (campos nil não filtram)
+type UserFilter struct {
+	Role    *entity.UserRole
+	Status  *entity.UserStatus
+	StoreID *uuid.UUID
+}
+
 // UserRepository define as operações de banco de dados para Usuários
 type UserRepository interface {
 	// Comandos (Escrita)
 	Create(ctx context.Context, user *entity.User) error
-	Update(ctx context.Context, user *entity.User) error
-	UpdateSecurity(ctx context.Context, user *entity.User) error // Apenas senha, bloqueios, etc.
+	Update(ctx context.Context, user *entity.User) error         // Perfil, papel, status e loja
+	UpdateSecurity(ctx context.Context, user *entity.User) error // Apenas senha, revogação de sessões, último acesso
+	// UpdatePasswordHash troca só o hash (rehash no login), desde que ele ainda seja "oldHash"
+	UpdatePasswordHash(ctx context.Context, id uuid.UUID, oldHash, newHash string) error
+	UpdateTwoFactor(ctx context.Context, user *entity.User) error
+	UpdateEmailVerification(ctx context.Context, user *entity.User) error
 
 	// Consultas (Leitura)
 	GetByID(ctx context.Context, id uuid.UUID) (*entity.User, error)
 	GetByEmail(ctx context.Context, email string) (*entity.User, error)
+	EmailExists(ctx context.Context, email string) (bool, error) // Em toda a plataforma, ignorando a RLS
+	ListByOrganization(ctx context.Context, orgID uuid.UUID, filter UserFilter, params pagination.Params) ([]*entity.User, int64, error)
 }
@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/domain/entity"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/domain/repository"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/database"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/pagination"
+)
+
+type APIKeyRepoPostgres struct {
+	db *sql.DB
+}
+
+// NewAPIKeyRepository cria uma nova instância do repositório
+func NewAPIKeyRepository(db *sql.DB) repository.APIKeyRepository {
+	return &APIKeyRepoPostgres{db: db}
+}
+
+const apiKeyColumns = `
+	id, organization_id, name, prefix, key_hash, scopes, created_by,
+	expires_at, last_used_at, revoked_at, created_at
+`
+
+func scanAPIKey(row interface{ Scan(...any) error }) (*entity.APIKey, error) {
+	var k entity.APIKey
+	var scopes []byte
+	if err := row.Scan(
+		&k.ID, &k.OrganizationID, &k.Name, &k.Prefix, &k.KeyHash, &scopes, &k.CreatedBy,
+		&k.ExpiresAt, &k.LastUsedAt, &k.RevokedAt, &k.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(scopes, &k.Scopes); err != nil {
+		return nil, err
+	}
+	return &k, nil
+}
+
+// Create grava a chave (apenas o hash)
+func (r *APIKeyRepoPostgres) Create(ctx context.Context, k *entity.APIKey) error {
+	scopes, err := json.Marshal(k.Scopes)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO api_keys (id, organization_id, name, prefix, key_hash, scopes, created_by, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+	_, err = database.Conn(ctx, r.db).ExecContext(ctx, query,
+		k.ID, k.OrganizationID, k.Name, k.Prefix, k.KeyHash, scopes, k.CreatedBy, k.ExpiresAt, k.CreatedAt,
+	)
+	return err
+}
+
+func (r *APIKeyRepoPostgres) getOne(ctx context.Context, query string, args ...any) (*entity.APIKey, error) {
+	k, err := scanAPIKey(database.Conn(ctx, r.db).QueryRowContext(ctx, query, args...))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return k, nil
+}
+
+// GetByID busca a chave dentro da organização
+func (r *APIKeyRepoPostgres) GetByID(ctx context.Context, orgID, id uuid.UUID) (*entity.APIKey, error) {
+	return r.getOne(ctx, `SELECT `+apiKeyColumns+` FROM api_keys WHERE id = $1 AND organization_id = $2`, id, orgID)
+}
+
+// GetByHash busca a chave pelo hash
+func (r *APIKeyRepoPostgres) GetByHash(ctx context.Context, keyHash string) (*entity.APIKey, error) {
+	return r.getOne(ctx, `SELECT `+apiKeyColumns+` FROM api_keys WHERE key_hash = $1`, keyHash)
+}
+
+// ListByOrganization pagina as chaves da organização, das mais recentes para as mais antigas
+func (r *APIKeyRepoPostgres) ListByOrganization(ctx context.Context, orgID uuid.UUID, pageParams pagination.Params) ([]*entity.APIKey, int64, error) {
+	conn := database.Conn(ctx, r.db)
+
+	var totalItems int64
+	if err := conn.QueryRowContext(ctx, `SELECT COUNT(*) FROM api_keys WHERE organization_id = $1`, orgID).Scan(&totalItems); err != nil {
+		return nil, 0, err
+	}
+
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE organization_id = $1 ORDER BY created_at DESC LIMIT $2 OFFSET $3`
+	rows, err := conn.QueryContext(ctx, query, orgID, pageParams.Limit, pageParams.Offset())
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var keys []*entity.APIKey
+	for rows.Next() {
+		k, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		keys = append(keys, k)
+	}
+
+	return keys, totalItems, rows.Err()
+}
+
+// Revoke invalida a chave (idempotente)
+func (r *APIKeyRepoPostgres) Revoke(ctx context.Context, orgID, id uuid.UUID, revokedAt time.Time) error {
+	query := `UPDATE api_keys SET revoked_at = $1 WHERE id = $2 AND organization_id = $3 AND revoked_at IS NULL`
+	_, err := database.Conn(ctx, r.db).ExecContext(ctx, query, revokedAt, id, orgID)
+	return err
+}
+
+// TouchLastUsed atualiza last_used_at se o último registro for mais antigo que a janela
+func (r *APIKeyRepoPostgres) TouchLastUsed(ctx context.Context, id uuid.UUID, usedAt time.Time, window time.Duration) error {
+	query := `
+		UPDATE api_keys SET last_used_at = $1
+		WHERE id = $2 AND (last_used_at IS NULL OR last_used_at < $3)
+	`
+	_, err := database.Conn(ctx, r.db).ExecContext(ctx, query, usedAt, id, usedAt.Add(-window))
+	return err
+}
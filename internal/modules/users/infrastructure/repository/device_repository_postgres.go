@@ -0,0 +1,185 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/domain/entity"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/domain/repository"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/database"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/pagination"
+)
+
+type DeviceRepoPostgres struct {
+	db *sql.DB
+}
+
+// NewDeviceRepository cria uma nova instância do repositório
+func NewDeviceRepository(db *sql.DB) repository.DeviceRepository {
+	return &DeviceRepoPostgres{db: db}
+}
+
+const deviceColumns = `
+	d.id, d.user_id, d.device_id, d.device_name, d.platform, d.app_version, d.push_token,
+	d.last_active_at, d.created_at
+`
+
+func scanDevice(row rowScanner, extra ...interface{}) (*entity.UserDevice, error) {
+	var d entity.UserDevice
+	var name, platform, version, pushToken sql.NullString
+
+	dest := []interface{}{
+		&d.ID, &d.UserID, &d.DeviceID, &name, &platform, &version, &pushToken,
+		&d.LastActiveAt, &d.CreatedAt,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+
+	d.DeviceName = name.String
+	d.Platform = platform.String
+	d.AppVersion = version.String
+	d.PushToken = pushToken.String
+	return &d, nil
+}
+
+// Upsert registra o aparelho no login (mantém o push token anterior se o App não enviar um novo)
+func (r *DeviceRepoPostgres) Upsert(ctx context.Context, d *entity.UserDevice) error {
+	conn := database.Conn(ctx, r.db)
+
+	// Um push token pertence a um único aparelho: outro usuário logando no mesmo celular assume os alertas
+	if d.PushToken != "" {
+		release := `
+			UPDATE user_devices SET push_token = NULL
+			WHERE push_token = $1 AND NOT (user_id = $2 AND device_id = $3)
+		`
+		if _, err := conn.ExecContext(ctx, release, d.PushToken, d.UserID, d.DeviceID); err != nil {
+			return err
+		}
+	}
+
+	query := `
+		INSERT INTO user_devices (
+			id, user_id, device_id, device_name, platform, app_version, push_token,
+			last_active_at, created_at
+		) VALUES (
+			$1, $2, $3, NULLIF($4, ''), NULLIF($5, ''), NULLIF($6, ''), NULLIF($7, ''),
+			$8, $9
+		)
+		ON CONFLICT (user_id, device_id) DO UPDATE SET
+			device_name = COALESCE(EXCLUDED.device_name, user_devices.device_name),
+			platform = COALESCE(EXCLUDED.platform, user_devices.platform),
+			app_version = COALESCE(EXCLUDED.app_version, user_devices.app_version),
+			push_token = COALESCE(EXCLUDED.push_token, user_devices.push_token),
+			last_active_at = EXCLUDED.last_active_at
+		RETURNING id, created_at
+	`
+	return conn.QueryRowContext(ctx, query,
+		d.ID, d.UserID, d.DeviceID, d.DeviceName, d.Platform, d.AppVersion, d.PushToken,
+		d.LastActiveAt, d.CreatedAt,
+	).Scan(&d.ID, &d.CreatedAt)
+}
+
+// Touch atualiza o last_active_at do aparelho
+func (r *DeviceRepoPostgres) Touch(ctx context.Context, userID uuid.UUID, deviceID string, at time.Time) error {
+	query := `UPDATE user_devices SET last_active_at = $3 WHERE user_id = $1 AND device_id = $2`
+	_, err := database.Conn(ctx, r.db).ExecContext(ctx, query, userID, deviceID, at)
+	return err
+}
+
+// Delete remove o aparelho (somente se pertencer ao usuário) e devolve o registro apagado
+func (r *DeviceRepoPostgres) Delete(ctx context.Context, userID, id uuid.UUID) (*entity.UserDevice, error) {
+	query := `
+		DELETE FROM user_devices d WHERE d.id = $1 AND d.user_id = $2
+		RETURNING ` + deviceColumns
+	d, err := scanDevice(database.Conn(ctx, r.db).QueryRowContext(ctx, query, id, userID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return d, nil
+}
+
+// DeleteByUser remove todos os aparelhos do usuário
+func (r *DeviceRepoPostgres) DeleteByUser(ctx context.Context, userID uuid.UUID) error {
+	_, err := database.Conn(ctx, r.db).ExecContext(ctx, `DELETE FROM user_devices WHERE user_id = $1`, userID)
+	return err
+}
+
+// Exists indica se o aparelho já foi registrado pelo usuário
+func (r *DeviceRepoPostgres) Exists(ctx context.Context, userID uuid.UUID, deviceID string) (bool, error) {
+	query := `SELECT EXISTS(SELECT 1 FROM user_devices WHERE user_id = $1 AND device_id = $2)`
+	var exists bool
+	err := database.Conn(ctx, r.db).QueryRowContext(ctx, query, userID, deviceID).Scan(&exists)
+	return exists, err
+}
+
+// ListByUser lista os aparelhos do usuário, do mais recente para o mais antigo
+func (r *DeviceRepoPostgres) ListByUser(ctx context.Context, userID uuid.UUID) ([]*entity.UserDevice, error) {
+	query := `
+		SELECT ` + deviceColumns + ` FROM user_devices d
+		WHERE d.user_id = $1
+		ORDER BY d.last_active_at DESC
+	`
+	rows, err := database.Conn(ctx, r.db).QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var devices []*entity.UserDevice
+	for rows.Next() {
+		d, err := scanDevice(rows)
+		if err != nil {
+			return nil, err
+		}
+		devices = append(devices, d)
+	}
+
+	return devices, rows.Err()
+}
+
+// ListByStore lista os aparelhos dos usuários ativos da loja, junto com o dono de cada um
+func (r *DeviceRepoPostgres) ListByStore(ctx context.Context, orgID, storeID uuid.UUID, pageParams pagination.Params) ([]*entity.StoreDevice, int64, error) {
+	conn := database.Conn(ctx, r.db)
+
+	const from = `
+		FROM user_devices d
+		JOIN users u ON u.id = d.user_id
+		WHERE u.organization_id = $1 AND u.store_id = $2 AND u.status = 'active'
+	`
+
+	var totalItems int64
+	if err := conn.QueryRowContext(ctx, `SELECT COUNT(*) `+from, orgID, storeID).Scan(&totalItems); err != nil {
+		return nil, 0, err
+	}
+
+	query := `
+		SELECT ` + deviceColumns + `, u.name, u.email, u.role ` + from + `
+		ORDER BY u.name, d.last_active_at DESC
+		LIMIT $3 OFFSET $4
+	`
+	rows, err := conn.QueryContext(ctx, query, orgID, storeID, pageParams.Limit, pageParams.Offset())
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var devices []*entity.StoreDevice
+	for rows.Next() {
+		var sd entity.StoreDevice
+		d, err := scanDevice(rows, &sd.UserName, &sd.UserEmail, &sd.UserRole)
+		if err != nil {
+			return nil, 0, err
+		}
+		sd.UserDevice = *d
+		devices = append(devices, &sd)
+	}
+
+	return devices, totalItems, rows.Err()
+}
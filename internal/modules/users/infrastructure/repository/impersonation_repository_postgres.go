@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/domain/entity"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/domain/repository"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/database"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/pagination"
+)
+
+type ImpersonationRepoPostgres struct {
+	db *sql.DB
+}
+
+// NewImpersonationRepository cria uma nova instância do repositório
+func NewImpersonationRepository(db *sql.DB) repository.ImpersonationRepository {
+	return &ImpersonationRepoPostgres{db: db}
+}
+
+const impersonationColumns = `
+	id, actor_id, actor_role, target_user_id, target_org_id, reason, read_only,
+	token_id, ip_address, expires_at, ended_at, created_at
+`
+
+func scanImpersonation(row interface{ Scan(...any) error }) (*entity.ImpersonationSession, error) {
+	var s entity.ImpersonationSession
+	var ip sql.NullString
+	if err := row.Scan(
+		&s.ID, &s.ActorID, &s.ActorRole, &s.TargetUserID, &s.TargetOrgID, &s.Reason, &s.ReadOnly,
+		&s.TokenID, &ip, &s.ExpiresAt, &s.EndedAt, &s.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+	s.IPAddress = ip.String
+	return &s, nil
+}
+
+// Create grava a sessão
+func (r *ImpersonationRepoPostgres) Create(ctx context.Context, s *entity.ImpersonationSession) error {
+	query := `
+		INSERT INTO impersonation_sessions (
+			id, actor_id, actor_role, target_user_id, target_org_id, reason, read_only,
+			token_id, ip_address, expires_at, created_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, NULLIF($9, ''), $10, $11
+		)
+	`
+	_, err := database.Conn(ctx, r.db).ExecContext(ctx, query,
+		s.ID, s.ActorID, s.ActorRole, s.TargetUserID, s.TargetOrgID, s.Reason, s.ReadOnly,
+		s.TokenID, s.IPAddress, s.ExpiresAt, s.CreatedAt,
+	)
+	return err
+}
+
+// GetByID busca a sessão pelo ID
+func (r *ImpersonationRepoPostgres) GetByID(ctx context.Context, id uuid.UUID) (*entity.ImpersonationSession, error) {
+	query := `SELECT ` + impersonationColumns + ` FROM impersonation_sessions WHERE id = $1`
+
+	s, err := scanImpersonation(database.Conn(ctx, r.db).QueryRowContext(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return s, nil
+}
+
+// EndByTokenID marca a sessão do token como encerrada
+func (r *ImpersonationRepoPostgres) EndByTokenID(ctx context.Context, tokenID string, endedAt time.Time) error {
+	query := `UPDATE impersonation_sessions SET ended_at = $1 WHERE token_id = $2 AND ended_at IS NULL`
+	_, err := database.Conn(ctx, r.db).ExecContext(ctx, query, endedAt, tokenID)
+	return err
+}
+
+// List pagina as sessões, das mais recentes para as mais antigas
+func (r *ImpersonationRepoPostgres) List(ctx context.Context, pageParams pagination.Params) ([]*entity.ImpersonationSession, int64, error) {
+	conn := database.Conn(ctx, r.db)
+
+	var totalItems int64
+	if err := conn.QueryRowContext(ctx, `SELECT COUNT(*) FROM impersonation_sessions`).Scan(&totalItems); err != nil {
+		return nil, 0, err
+	}
+
+	query := `SELECT ` + impersonationColumns + ` FROM impersonation_sessions ORDER BY created_at DESC LIMIT $1 OFFSET $2`
+	rows, err := conn.QueryContext(ctx, query, pageParams.Limit, pageParams.Offset())
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var sessions []*entity.ImpersonationSession
+	for rows.Next() {
+		s, err := scanImpersonation(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		sessions = append(sessions, s)
+	}
+
+	return sessions, totalItems, rows.Err()
+}
+
+// RecordEvent grava uma requisição feita durante a impersonação
+func (r *ImpersonationRepoPostgres) RecordEvent(ctx context.Context, e *entity.ImpersonationEvent) error {
+	query := `
+		INSERT INTO impersonation_events (id, session_id, method, path, status, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+	_, err := database.Conn(ctx, r.db).ExecContext(ctx, query, e.ID, e.SessionID, e.Method, e.Path, e.Status, e.CreatedAt)
+	return err
+}
+
+// ListEvents pagina a trilha de auditoria da sessão em ordem cronológica
+func (r *ImpersonationRepoPostgres) ListEvents(ctx context.Context, sessionID uuid.UUID, pageParams pagination.Params) ([]*entity.ImpersonationEvent, int64, error) {
+	conn := database.Conn(ctx, r.db)
+
+	var totalItems int64
+	if err := conn.QueryRowContext(ctx, `SELECT COUNT(*) FROM impersonation_events WHERE session_id = $1`, sessionID).Scan(&totalItems); err != nil {
+		return nil, 0, err
+	}
+
+	query := `
+		SELECT id, session_id, method, path, status, created_at
+		FROM impersonation_events
+		WHERE session_id = $1
+		ORDER BY created_at
+		LIMIT $2 OFFSET $3
+	`
+	rows, err := conn.QueryContext(ctx, query, sessionID, pageParams.Limit, pageParams.Offset())
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var events []*entity.ImpersonationEvent
+	for rows.Next() {
+		var e entity.ImpersonationEvent
+		if err := rows.Scan(&e.ID, &e.SessionID, &e.Method, &e.Path, &e.Status, &e.CreatedAt); err != nil {
+			return nil, 0, err
+		}
+		events = append(events, &e)
+	}
+
+	return events, totalItems, rows.Err()
+}
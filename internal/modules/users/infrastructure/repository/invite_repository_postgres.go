@@ -0,0 +1,163 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/domain/entity"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/domain/repository"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/database"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/pagination"
+)
+
+type InviteRepoPostgres struct {
+	db *sql.DB
+}
+
+// NewInviteRepository cria uma nova instância do repositório
+func NewInviteRepository(db *sql.DB) repository.InviteRepository {
+	return &InviteRepoPostgres{db: db}
+}
+
+const inviteColumns = `
+	id, organization_id, store_id, email, role, token_hash, invited_by,
+	expires_at, accepted_at, revoked_at, created_at, updated_at
+`
+
+func scanInvite(row rowScanner) (*entity.Invite, error) {
+	var i entity.Invite
+	var acceptedAt, revokedAt sql.NullTime
+
+	err := row.Scan(
+		&i.ID, &i.OrganizationID, &i.StoreID, &i.Email, &i.Role, &i.TokenHash, &i.InvitedBy,
+		&i.ExpiresAt, &acceptedAt, &revokedAt, &i.CreatedAt, &i.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	i.AcceptedAt = nullTimePtr(acceptedAt)
+	i.RevokedAt = nullTimePtr(revokedAt)
+	return &i, nil
+}
+
+// Create insere um novo convite (apenas o hash do token)
+func (r *InviteRepoPostgres) Create(ctx context.Context, i *entity.Invite) error {
+	query := `
+		INSERT INTO user_invites (
+			id, organization_id, store_id, email, role, token_hash, invited_by,
+			expires_at, created_at, updated_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7,
+			$8, $9, $10
+		)
+	`
+	_, err := database.Conn(ctx, r.db).ExecContext(ctx, query,
+		i.ID, i.OrganizationID, i.StoreID, i.Email, i.Role, i.TokenHash, i.InvitedBy,
+		i.ExpiresAt, i.CreatedAt, i.UpdatedAt,
+	)
+	return err
+}
+
+// Update grava reenvio (novo token/validade) e revogação
+func (r *InviteRepoPostgres) Update(ctx context.Context, i *entity.Invite) error {
+	query := `
+		UPDATE user_invites SET
+			token_hash=$1, expires_at=$2, revoked_at=$3, updated_at=$4
+		WHERE id=$5
+	`
+	_, err := database.Conn(ctx, r.db).ExecContext(ctx, query,
+		i.TokenHash, i.ExpiresAt, timeOrNil(i.RevokedAt), i.UpdatedAt, i.ID,
+	)
+	return err
+}
+
+// MarkAccepted consome o convite em um único UPDATE condicional (dois aceites não passam juntos)
+func (r *InviteRepoPostgres) MarkAccepted(ctx context.Context, id uuid.UUID, at time.Time) (bool, error) {
+	query := `
+		UPDATE user_invites SET accepted_at = $2, updated_at = $2
+		WHERE id = $1 AND accepted_at IS NULL AND revoked_at IS NULL AND expires_at > $2
+	`
+	res, err := database.Conn(ctx, r.db).ExecContext(ctx, query, id, at)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
+}
+
+// GetByID busca um convite pelo ID
+func (r *InviteRepoPostgres) GetByID(ctx context.Context, id uuid.UUID) (*entity.Invite, error) {
+	query := `SELECT ` + inviteColumns + ` FROM user_invites WHERE id = $1`
+	return r.getOne(ctx, query, id)
+}
+
+// GetByTokenHash busca um convite pelo hash do token do link
+func (r *InviteRepoPostgres) GetByTokenHash(ctx context.Context, tokenHash string) (*entity.Invite, error) {
+	query := `SELECT ` + inviteColumns + ` FROM user_invites WHERE token_hash = $1`
+	return r.getOne(ctx, query, tokenHash)
+}
+
+// GetOpenByEmail busca o convite em aberto (não aceito e não revogado) do email na organização
+func (r *InviteRepoPostgres) GetOpenByEmail(ctx context.Context, orgID uuid.UUID, email string) (*entity.Invite, error) {
+	query := `
+		SELECT ` + inviteColumns + ` FROM user_invites
+		WHERE organization_id = $1 AND email = $2 AND accepted_at IS NULL AND revoked_at IS NULL
+	`
+	return r.getOne(ctx, query, orgID, email)
+}
+
+// ListOpenByOrganization lista os convites em aberto (inclusive expirados, para reenvio)
+func (r *InviteRepoPostgres) ListOpenByOrganization(ctx context.Context, orgID uuid.UUID, pageParams pagination.Params) ([]*entity.Invite, int64, error) {
+	conn := database.Conn(ctx, r.db)
+
+	var totalItems int64
+	countQuery := `
+		SELECT COUNT(*) FROM user_invites
+		WHERE organization_id = $1 AND accepted_at IS NULL AND revoked_at IS NULL
+	`
+	if err := conn.QueryRowContext(ctx, countQuery, orgID).Scan(&totalItems); err != nil {
+		return nil, 0, err
+	}
+
+	query := `
+		SELECT ` + inviteColumns + ` FROM user_invites
+		WHERE organization_id = $1 AND accepted_at IS NULL AND revoked_at IS NULL
+		ORDER BY created_at DESC
+		LIMIT $2 OFFSET $3
+	`
+	rows, err := conn.QueryContext(ctx, query, orgID, pageParams.Limit, pageParams.Offset())
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var invites []*entity.Invite
+	for rows.Next() {
+		i, err := scanInvite(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		invites = append(invites, i)
+	}
+
+	return invites, totalItems, rows.Err()
+}
+
+func (r *InviteRepoPostgres) getOne(ctx context.Context, query string, args ...interface{}) (*entity.Invite, error) {
+	i, err := scanInvite(database.Conn(ctx, r.db).QueryRowContext(ctx, query, args...))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return i, nil
+}
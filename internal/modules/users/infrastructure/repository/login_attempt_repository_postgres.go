@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/domain/entity"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/domain/repository"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/database"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/pagination"
+)
+
+type LoginAttemptRepoPostgres struct {
+	db *sql.DB
+}
+
+// NewLoginAttemptRepository cria uma nova instância do repositório
+func NewLoginAttemptRepository(db *sql.DB) repository.LoginAttemptRepository {
+	return &LoginAttemptRepoPostgres{db: db}
+}
+
+// Create grava a tentativa de login
+func (r *LoginAttemptRepoPostgres) Create(ctx context.Context, a *entity.LoginAttempt) error {
+	query := `
+		INSERT INTO login_attempts (
+			id, user_id, email, status, ip_address, user_agent, device_id, device_name, created_at
+		) VALUES (
+			$1, $2, $3, $4, NULLIF($5, ''), NULLIF($6, ''), NULLIF($7, ''), NULLIF($8, ''), $9
+		)
+	`
+	_, err := database.Conn(ctx, r.db).ExecContext(ctx, query,
+		a.ID, a.UserID, a.Email, a.Status, a.IPAddress, a.UserAgent, a.DeviceID, a.DeviceName, a.CreatedAt,
+	)
+	return err
+}
+
+// ListByUser pagina o histórico do usuário
+func (r *LoginAttemptRepoPostgres) ListByUser(ctx context.Context, userID uuid.UUID, pageParams pagination.Params) ([]*entity.LoginAttempt, int64, error) {
+	conn := database.Conn(ctx, r.db)
+
+	var totalItems int64
+	if err := conn.QueryRowContext(ctx, `SELECT COUNT(*) FROM login_attempts WHERE user_id = $1`, userID).Scan(&totalItems); err != nil {
+		return nil, 0, err
+	}
+
+	query := `
+		SELECT id, user_id, email, status, ip_address, user_agent, device_id, device_name, created_at
+		FROM login_attempts
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2 OFFSET $3
+	`
+	rows, err := conn.QueryContext(ctx, query, userID, pageParams.Limit, pageParams.Offset())
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var attempts []*entity.LoginAttempt
+	for rows.Next() {
+		var a entity.LoginAttempt
+		var ip, userAgent, deviceID, deviceName sql.NullString
+		if err := rows.Scan(
+			&a.ID, &a.UserID, &a.Email, &a.Status, &ip, &userAgent, &deviceID, &deviceName, &a.CreatedAt,
+		); err != nil {
+			return nil, 0, err
+		}
+		a.IPAddress = ip.String
+		a.UserAgent = userAgent.String
+		a.DeviceID = deviceID.String
+		a.DeviceName = deviceName.String
+		attempts = append(attempts, &a)
+	}
+
+	return attempts, totalItems, rows.Err()
+}
+
+// CountFailedEmailsByIP conta quantas contas diferentes o IP errou (senha, 2FA ou email inexistente)
+func (r *LoginAttemptRepoPostgres) CountFailedEmailsByIP(ctx context.Context, ip string, since time.Time) (int, error) {
+	query := `
+		SELECT COUNT(DISTINCT LOWER(email)) FROM login_attempts
+		WHERE ip_address = $1 AND created_at >= $2 AND status IN ($3, $4, $5)
+	`
+	var n int
+	err := database.Conn(ctx, r.db).QueryRowContext(ctx, query, ip, since,
+		entity.LoginInvalidPassword, entity.LoginInvalidTwoFactor, entity.LoginUnknownUser,
+	).Scan(&n)
+	return n, err
+}
+
+// HasSucceededFrom indica se o IP é conhecido do email (já houve login com sucesso dele)
+func (r *LoginAttemptRepoPostgres) HasSucceededFrom(ctx context.Context, email, ip string, since time.Time) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM login_attempts
+			WHERE LOWER(email) = $1 AND ip_address = $2 AND status = $3 AND created_at >= $4
+		)
+	`
+	var ok bool
+	err := database.Conn(ctx, r.db).QueryRowContext(ctx, query, email, ip, entity.LoginSuccess, since).Scan(&ok)
+	return ok, err
+}
@@ -0,0 +1,202 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/domain/entity"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/domain/repository"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/database"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/pagination"
+)
+
+const throttleColumns = `scope, ip_address, email, failures, lockouts, window_started_at, last_failure_at, blocked_until, reason`
+
+type LoginThrottleRepoPostgres struct {
+	db *sql.DB
+}
+
+// NewLoginThrottleRepository cria uma nova instância do repositório
+func NewLoginThrottleRepository(db *sql.DB) repository.LoginThrottleRepository {
+	return &LoginThrottleRepoPostgres{db: db}
+}
+
+func (r *LoginThrottleRepoPostgres) Blocking(ctx context.Context, keys []entity.ThrottleKey, now time.Time) ([]*entity.LoginThrottle, error) {
+	if len(keys) == 0 {
+		return nil, nil
+	}
+
+	args := []any{now}
+	conds := make([]string, 0, len(keys))
+	for _, k := range keys {
+		n := len(args)
+		conds = append(conds, fmt.Sprintf("(scope = $%d AND ip_address = $%d AND email = $%d)", n+1, n+2, n+3))
+		args = append(args, k.Scope, k.IPAddress, k.Email)
+	}
+
+	query := `SELECT ` + throttleColumns + ` FROM login_throttles
+		WHERE blocked_until > $1 AND (` + strings.Join(conds, " OR ") + `)
+		ORDER BY blocked_until DESC`
+	rows, err := database.Conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var throttles []*entity.LoginThrottle
+	for rows.Next() {
+		t, err := scanThrottle(rows)
+		if err != nil {
+			return nil, err
+		}
+		throttles = append(throttles, t)
+	}
+	return throttles, rows.Err()
+}
+
+func (r *LoginThrottleRepoPostgres) RegisterFailure(ctx context.Context, key entity.ThrottleKey, rule entity.ThrottleRule, reason entity.ThrottleReason, now time.Time) (*entity.LoginThrottle, error) {
+	conn := database.Conn(ctx, r.db)
+
+	// Janela vencida recomeça a contagem; muito tempo sem falhas zera também o backoff
+	upsert := `
+		INSERT INTO login_throttles (scope, ip_address, email, failures, lockouts, window_started_at, last_failure_at)
+		VALUES ($1, $2, $3, 1, 0, $4::timestamp, $4::timestamp)
+		ON CONFLICT (scope, ip_address, email) DO UPDATE SET
+			failures = CASE WHEN login_throttles.window_started_at <= $5::timestamp
+				THEN 1 ELSE login_throttles.failures + 1 END,
+			window_started_at = CASE WHEN login_throttles.window_started_at <= $5::timestamp
+				THEN $4::timestamp ELSE login_throttles.window_started_at END,
+			lockouts = CASE WHEN login_throttles.last_failure_at <= $6::timestamp
+				THEN 0 ELSE login_throttles.lockouts END,
+			last_failure_at = $4::timestamp
+		RETURNING ` + throttleColumns
+	t, err := scanThrottle(conn.QueryRowContext(ctx, upsert,
+		key.Scope, key.IPAddress, key.Email, now, now.Add(-rule.Window), now.Add(-rule.Decay),
+	))
+	if err != nil {
+		return nil, err
+	}
+	if t.Failures < rule.MaxFailures {
+		return t, nil
+	}
+
+	// Só bloqueia quem ainda está acima do limite: a falha simultânea que chegar depois
+	// encontra o contador zerado e não dobra o castigo
+	lock := `
+		UPDATE login_throttles SET
+			lockouts = lockouts + 1,
+			blocked_until = $4::timestamp + LEAST($6::float8 * POWER(2, lockouts), $7::float8) * INTERVAL '1 second',
+			failures = 0,
+			window_started_at = $4::timestamp,
+			reason = $5
+		WHERE scope = $1 AND ip_address = $2 AND email = $3 AND failures >= $8
+		RETURNING ` + throttleColumns
+	locked, err := scanThrottle(conn.QueryRowContext(ctx, lock,
+		key.Scope, key.IPAddress, key.Email, now, reason,
+		rule.BaseLockout.Seconds(), rule.MaxLockout.Seconds(), rule.MaxFailures,
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		return t, nil
+	}
+	return locked, err
+}
+
+func (r *LoginThrottleRepoPostgres) Block(ctx context.Context, key entity.ThrottleKey, rule entity.ThrottleRule, reason entity.ThrottleReason, now time.Time) (*entity.LoginThrottle, error) {
+	query := `
+		INSERT INTO login_throttles (` + throttleColumns + `)
+		VALUES ($1, $2, $3, 0, 1, $4::timestamp, $4::timestamp, $4::timestamp + $6::float8 * INTERVAL '1 second', $5)
+		ON CONFLICT (scope, ip_address, email) DO UPDATE SET
+			lockouts = (CASE WHEN login_throttles.last_failure_at <= $8::timestamp THEN 0 ELSE login_throttles.lockouts END) + 1,
+			blocked_until = $4::timestamp + LEAST(
+				$6::float8 * POWER(2, CASE WHEN login_throttles.last_failure_at <= $8::timestamp THEN 0 ELSE login_throttles.lockouts END),
+				$7::float8) * INTERVAL '1 second',
+			failures = 0,
+			window_started_at = $4::timestamp,
+			last_failure_at = $4::timestamp,
+			reason = $5
+		WHERE login_throttles.blocked_until IS NULL OR login_throttles.blocked_until <= $4::timestamp
+		RETURNING ` + throttleColumns
+	t, err := scanThrottle(database.Conn(ctx, r.db).QueryRowContext(ctx, query,
+		key.Scope, key.IPAddress, key.Email, now, reason,
+		rule.BaseLockout.Seconds(), rule.MaxLockout.Seconds(), now.Add(-rule.Decay),
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil // Já estava bloqueado
+	}
+	return t, err
+}
+
+func (r *LoginThrottleRepoPostgres) Reset(ctx context.Context, key entity.ThrottleKey) error {
+	query := `DELETE FROM login_throttles WHERE scope = $1 AND ip_address = $2 AND email = $3`
+	_, err := database.Conn(ctx, r.db).ExecContext(ctx, query, key.Scope, key.IPAddress, key.Email)
+	return err
+}
+
+func (r *LoginThrottleRepoPostgres) ResetEmail(ctx context.Context, email string) (int64, error) {
+	query := `DELETE FROM login_throttles WHERE email = $1 AND scope IN ($2, $3)`
+	res, err := database.Conn(ctx, r.db).ExecContext(ctx, query, email, entity.ThrottleEmail, entity.ThrottleIPEmail)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func (r *LoginThrottleRepoPostgres) ResetIP(ctx context.Context, ip string) (int64, error) {
+	query := `DELETE FROM login_throttles WHERE ip_address = $1 AND scope IN ($2, $3)`
+	res, err := database.Conn(ctx, r.db).ExecContext(ctx, query, ip, entity.ThrottleIP, entity.ThrottleIPEmail)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func (r *LoginThrottleRepoPostgres) ListBlocked(ctx context.Context, now time.Time, pageParams pagination.Params) ([]*entity.LoginThrottle, int64, error) {
+	conn := database.Conn(ctx, r.db)
+
+	var totalItems int64
+	if err := conn.QueryRowContext(ctx, `SELECT COUNT(*) FROM login_throttles WHERE blocked_until > $1`, now).Scan(&totalItems); err != nil {
+		return nil, 0, err
+	}
+
+	query := `SELECT ` + throttleColumns + ` FROM login_throttles
+		WHERE blocked_until > $1
+		ORDER BY blocked_until DESC
+		LIMIT $2 OFFSET $3`
+	rows, err := conn.QueryContext(ctx, query, now, pageParams.Limit, pageParams.Offset())
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var throttles []*entity.LoginThrottle
+	for rows.Next() {
+		t, err := scanThrottle(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		throttles = append(throttles, t)
+	}
+	return throttles, totalItems, rows.Err()
+}
+
+func scanThrottle(s rowScanner) (*entity.LoginThrottle, error) {
+	var t entity.LoginThrottle
+	var blockedUntil sql.NullTime
+	var reason sql.NullString
+	if err := s.Scan(
+		&t.Scope, &t.IPAddress, &t.Email, &t.Failures, &t.Lockouts,
+		&t.WindowStartedAt, &t.LastFailureAt, &blockedUntil, &reason,
+	); err != nil {
+		return nil, err
+	}
+	t.BlockedUntil = nullTimePtr(blockedUntil)
+	if reason.Valid {
+		rs := entity.ThrottleReason(reason.String)
+		t.Reason = &rs
+	}
+	return &t, nil
+}
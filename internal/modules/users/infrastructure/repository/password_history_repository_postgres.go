@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/domain/repository"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/database"
+)
+
+type PasswordHistoryRepoPostgres struct {
+	db *sql.DB
+}
+
+// NewPasswordHistoryRepository cria uma nova instância do repositório
+func NewPasswordHistoryRepository(db *sql.DB) repository.PasswordHistoryRepository {
+	return &PasswordHistoryRepoPostgres{db: db}
+}
+
+func (r *PasswordHistoryRepoPostgres) Add(ctx context.Context, userID uuid.UUID, passwordHash string) error {
+	query := `INSERT INTO password_history (id, user_id, password_hash, created_at) VALUES ($1, $2, $3, $4)`
+	_, err := database.Conn(ctx, r.db).ExecContext(ctx, query, uuid.New(), userID, passwordHash, time.Now().UTC())
+	return err
+}
+
+func (r *PasswordHistoryRepoPostgres) ListRecent(ctx context.Context, userID uuid.UUID, limit int) ([]string, error) {
+	query := `
+		SELECT password_hash FROM password_history
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2
+	`
+	rows, err := database.Conn(ctx, r.db).QueryContext(ctx, query, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var hashes []string
+	for rows.Next() {
+		var h string
+		if err := rows.Scan(&h); err != nil {
+			return nil, err
+		}
+		hashes = append(hashes, h)
+	}
+	return hashes, rows.Err()
+}
+
+func (r *PasswordHistoryRepoPostgres) Prune(ctx context.Context, userID uuid.UUID, keep int) error {
+	query := `
+		DELETE FROM password_history
+		WHERE user_id = $1 AND id NOT IN (
+			SELECT id FROM password_history
+			WHERE user_id = $1
+			ORDER BY created_at DESC
+			LIMIT $2
+		)
+	`
+	_, err := database.Conn(ctx, r.db).ExecContext(ctx, query, userID, keep)
+	return err
+}
@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/domain/entity"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/domain/repository"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/database"
+)
+
+type PasswordResetTokenRepoPostgres struct {
+	db *sql.DB
+}
+
+// NewPasswordResetTokenRepository cria uma nova instância do repositório
+func NewPasswordResetTokenRepository(db *sql.DB) repository.PasswordResetTokenRepository {
+	return &PasswordResetTokenRepoPostgres{db: db}
+}
+
+// Create insere um novo token de redefinição (apenas o hash)
+func (r *PasswordResetTokenRepoPostgres) Create(ctx context.Context, t *entity.PasswordResetToken) error {
+	query := `
+		INSERT INTO password_reset_tokens (id, user_id, token_hash, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+	_, err := database.Conn(ctx, r.db).ExecContext(ctx, query, t.ID, t.UserID, t.TokenHash, t.ExpiresAt, t.CreatedAt)
+	return err
+}
+
+// Consume usa o token em um único UPDATE: duas requisições com o mesmo link não passam juntas
+func (r *PasswordResetTokenRepoPostgres) Consume(ctx context.Context, tokenHash string, now time.Time) (*entity.PasswordResetToken, error) {
+	query := `
+		UPDATE password_reset_tokens SET used_at = $2
+		WHERE token_hash = $1 AND used_at IS NULL AND expires_at > $2
+		RETURNING id, user_id, token_hash, expires_at, used_at, created_at
+	`
+
+	var t entity.PasswordResetToken
+	var usedAt time.Time
+
+	err := database.Conn(ctx, r.db).QueryRowContext(ctx, query, tokenHash, now).Scan(
+		&t.ID, &t.UserID, &t.TokenHash, &t.ExpiresAt, &usedAt, &t.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	t.UsedAt = &usedAt
+	return &t, nil
+}
+
+// InvalidateAllByUser encerra os links pendentes (novo pedido ou senha já redefinida)
+func (r *PasswordResetTokenRepoPostgres) InvalidateAllByUser(ctx context.Context, userID uuid.UUID) error {
+	query := `UPDATE password_reset_tokens SET used_at = NOW() WHERE user_id = $1 AND used_at IS NULL`
+	_, err := database.Conn(ctx, r.db).ExecContext(ctx, query, userID)
+	return err
+}
@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/domain/entity"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/domain/repository"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/database"
+)
+
+type RefreshTokenRepoPostgres struct {
+	db *sql.DB
+}
+
+// NewRefreshTokenRepository cria uma nova instância do repositório
+func NewRefreshTokenRepository(db *sql.DB) repository.RefreshTokenRepository {
+	return &RefreshTokenRepoPostgres{db: db}
+}
+
+// Create insere um novo refresh token (apenas o hash)
+func (r *RefreshTokenRepoPostgres) Create(ctx context.Context, t *entity.RefreshToken) error {
+	query := `
+		INSERT INTO refresh_tokens (
+			id, user_id, family_id, token_hash, device_id, expires_at, created_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7
+		)
+	`
+	_, err := database.Conn(ctx, r.db).ExecContext(ctx, query,
+		t.ID, t.UserID, t.FamilyID, t.TokenHash, t.DeviceID, t.ExpiresAt, t.CreatedAt,
+	)
+	return err
+}
+
+// MarkReplaced revoga o token somente se ele ainda estiver ativo.
+// O "AND revoked_at IS NULL" garante que duas rotações simultâneas não passem juntas.
+func (r *RefreshTokenRepoPostgres) MarkReplaced(ctx context.Context, id, replacedBy uuid.UUID) (bool, error) {
+	query := `
+		UPDATE refresh_tokens SET
+			revoked_at = NOW(), replaced_by = $1
+		WHERE id = $2 AND revoked_at IS NULL
+	`
+	res, err := database.Conn(ctx, r.db).ExecContext(ctx, query, replacedBy, id)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
+}
+
+// RevokeFamily revoga todos os tokens ativos de uma cadeia de rotação
+func (r *RefreshTokenRepoPostgres) RevokeFamily(ctx context.Context, familyID uuid.UUID) error {
+	query := `UPDATE refresh_tokens SET revoked_at = NOW() WHERE family_id = $1 AND revoked_at IS NULL`
+	_, err := database.Conn(ctx, r.db).ExecContext(ctx, query, familyID)
+	return err
+}
+
+// RevokeByDevice encerra as sessões anteriores do mesmo aparelho
+func (r *RefreshTokenRepoPostgres) RevokeByDevice(ctx context.Context, userID uuid.UUID, deviceID string) error {
+	query := `
+		UPDATE refresh_tokens SET revoked_at = NOW()
+		WHERE user_id = $1 AND device_id = $2 AND revoked_at IS NULL
+	`
+	_, err := database.Conn(ctx, r.db).ExecContext(ctx, query, userID, deviceID)
+	return err
+}
+
+// RevokeAllByUser encerra todas as sessões do usuário (logout-all, troca de senha)
+func (r *RefreshTokenRepoPostgres) RevokeAllByUser(ctx context.Context, userID uuid.UUID) error {
+	query := `UPDATE refresh_tokens SET revoked_at = NOW() WHERE user_id = $1 AND revoked_at IS NULL`
+	_, err := database.Conn(ctx, r.db).ExecContext(ctx, query, userID)
+	return err
+}
+
+// GetByHash busca o token pelo hash SHA-256
+func (r *RefreshTokenRepoPostgres) GetByHash(ctx context.Context, tokenHash string) (*entity.RefreshToken, error) {
+	query := `
+		SELECT id, user_id, family_id, token_hash, device_id, expires_at, revoked_at, replaced_by, created_at
+		FROM refresh_tokens
+		WHERE token_hash = $1
+	`
+
+	var t entity.RefreshToken
+	var deviceID sql.NullString
+	var revokedAt sql.NullTime
+
+	err := database.Conn(ctx, r.db).QueryRowContext(ctx, query, tokenHash).Scan(
+		&t.ID, &t.UserID, &t.FamilyID, &t.TokenHash, &deviceID, &t.ExpiresAt, &revokedAt, &t.ReplacedBy, &t.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	t.DeviceID = deviceID.String
+	if revokedAt.Valid {
+		revokedValue := revokedAt.Time
+		t.RevokedAt = &revokedValue
+	}
+
+	return &t, nil
+}
@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/domain/repository"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/database"
+)
+
+type RevokedTokenRepoPostgres struct {
+	db *sql.DB
+}
+
+// NewRevokedTokenRepository cria uma nova instância do repositório
+func NewRevokedTokenRepository(db *sql.DB) repository.RevokedTokenRepository {
+	return &RevokedTokenRepoPostgres{db: db}
+}
+
+// Revoke insere o jti na denylist e aproveita para limpar entradas já expiradas
+func (r *RevokedTokenRepoPostgres) Revoke(ctx context.Context, jti string, userID uuid.UUID, expiresAt time.Time) error {
+	query := `
+		WITH purge AS (
+			DELETE FROM revoked_tokens WHERE expires_at < NOW()
+		)
+		INSERT INTO revoked_tokens (jti, user_id, expires_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (jti) DO NOTHING
+	`
+	_, err := database.Conn(ctx, r.db).ExecContext(ctx, query, jti, userID, expiresAt)
+	return err
+}
+
+// IsRevoked verifica se o jti está na denylist
+func (r *RevokedTokenRepoPostgres) IsRevoked(ctx context.Context, jti string) (bool, error) {
+	var exists bool
+	query := `SELECT EXISTS(SELECT 1 FROM revoked_tokens WHERE jti = $1)`
+	if err := database.Conn(ctx, r.db).QueryRowContext(ctx, query, jti).Scan(&exists); err != nil {
+		return false, err
+	}
+	return exists, nil
+}
@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/domain/entity"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/domain/repository"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/database"
+)
+
+type SSORepoPostgres struct {
+	db *sql.DB
+}
+
+// NewSSORepository cria uma nova instância do repositório
+func NewSSORepository(db *sql.DB) repository.SSORepository {
+	return &SSORepoPostgres{db: db}
+}
+
+// CreateLoginRequest grava o login em andamento
+func (r *SSORepoPostgres) CreateLoginRequest(ctx context.Context, req *entity.SSOLoginRequest) error {
+	query := `
+		INSERT INTO sso_login_requests (id, organization_id, state_hash, nonce, code_verifier, redirect_uri, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+	_, err := database.Conn(ctx, r.db).ExecContext(ctx, query,
+		req.ID, req.OrganizationID, req.StateHash, req.Nonce, req.CodeVerifier, req.RedirectURI, req.ExpiresAt, req.CreatedAt,
+	)
+	return err
+}
+
+// ConsumeLoginRequest remove e devolve o login (DELETE ... RETURNING: dois callbacks com o mesmo state, só um vence)
+func (r *SSORepoPostgres) ConsumeLoginRequest(ctx context.Context, stateHash string) (*entity.SSOLoginRequest, error) {
+	query := `
+		DELETE FROM sso_login_requests
+		WHERE state_hash = $1
+		RETURNING id, organization_id, state_hash, nonce, code_verifier, redirect_uri, expires_at, created_at
+	`
+	var req entity.SSOLoginRequest
+	err := database.Conn(ctx, r.db).QueryRowContext(ctx, query, stateHash).Scan(
+		&req.ID, &req.OrganizationID, &req.StateHash, &req.Nonce, &req.CodeVerifier, &req.RedirectURI, &req.ExpiresAt, &req.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &req, nil
+}
+
+// GetIdentity busca o vínculo pela identidade no provedor
+func (r *SSORepoPostgres) GetIdentity(ctx context.Context, issuer, subject string) (*entity.UserIdentity, error) {
+	query := `SELECT id, user_id, issuer, subject, created_at FROM user_identities WHERE issuer = $1 AND subject = $2`
+
+	var identity entity.UserIdentity
+	err := database.Conn(ctx, r.db).QueryRowContext(ctx, query, issuer, subject).Scan(
+		&identity.ID, &identity.UserID, &identity.Issuer, &identity.Subject, &identity.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &identity, nil
+}
+
+// CreateIdentity grava o vínculo
+func (r *SSORepoPostgres) CreateIdentity(ctx context.Context, identity *entity.UserIdentity) error {
+	query := `INSERT INTO user_identities (id, user_id, issuer, subject, created_at) VALUES ($1, $2, $3, $4, $5)`
+	_, err := database.Conn(ctx, r.db).ExecContext(ctx, query,
+		identity.ID, identity.UserID, identity.Issuer, identity.Subject, identity.CreatedAt,
+	)
+	return err
+}
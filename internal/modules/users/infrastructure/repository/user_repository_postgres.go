@@ -6,12 +6,17 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/domain/entity"
 	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/domain/repository"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/database"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/pagination"
 )
 
+// UserRepoPostgres usa database.Conn: participa da transação do context, se houver
+// (ex: aceite de convite, signup)
 type UserRepoPostgres struct {
 	db *sql.DB
 }
@@ -24,7 +29,7 @@ func NewUserRepository(db *sql.DB) repository.UserRepository {
 // Create insere um novo usuário
 func (r *UserRepoPostgres) Create(ctx context.Context, u *entity.User) error {
 	// Converte a struct de 2FA para JSONB antes de salvar
-	twoFactorJSON, err := json.Marshal(u.TwoFactor)
+	twoFactorJSON, err := marshalTwoFactor(u.TwoFactor)
 	if err != nil {
 		return fmt.Errorf("failed to marshal two_factor: %w", err)
 	}
@@ -33,97 +38,156 @@ func (r *UserRepoPostgres) Create(ctx context.Context, u *entity.User) error {
 		INSERT INTO users (
 			id, organization_id, store_id, name, email, phone, avatar_url,
 			password_hash, role, status, invited_by, timezone, language,
-			two_factor_settings, created_at, updated_at
+			two_factor_settings, email_verified_at, password_changed_at, created_at, updated_at
 		) VALUES (
 			$1, $2, $3, $4, $5, $6, $7,
 			$8, $9, $10, $11, $12, $13,
-			$14, $15, $16
+			$14, $15, $16, $17, $18
 		)
 	`
 
-	_, err = r.db.ExecContext(ctx, query,
+	_, err = database.Conn(ctx, r.db).ExecContext(ctx, query,
 		u.ID, u.OrganizationID, u.StoreID, u.Name, u.Email, u.Phone, u.AvatarURL,
 		u.PasswordHash, u.Role, u.Status, u.InvitedBy, u.Timezone, u.Language,
-		twoFactorJSON, u.CreatedAt, u.UpdatedAt,
+		twoFactorJSON, timeOrNil(u.EmailVerifiedAt), timeOrNil(u.PasswordChangedAt), u.CreatedAt, u.UpdatedAt,
 	)
 
 	return err
 }
 
-// GetByEmail busca um usuário pelo email
-func (r *UserRepoPostgres) GetByEmail(ctx context.Context, email string) (*entity.User, error) {
-	query := `
-		SELECT 
-			id, organization_id, store_id, name, email, phone, avatar_url,
-			password_hash, role, status, timezone, language, two_factor_settings,
-			failed_login_attempts, locked_until, last_login_at, created_at
-		FROM users 
-		WHERE email = $1
-	`
+// twoFactorRecord é o formato gravado no JSONB "two_factor_settings".
+// A entidade esconde o segredo do JSON da API (json:"-"), então o banco precisa de tags próprias.
+type twoFactorRecord struct {
+	Enabled       bool       `json:"enabled"`
+	Secret        string     `json:"secret,omitempty"`
+	QRCodeURL     string     `json:"qr_code_url,omitempty"`
+	RecoveryCodes []string   `json:"recovery_codes,omitempty"`
+	LastUsedStep  int64      `json:"last_used_step,omitempty"`
+	EnabledAt     *time.Time `json:"enabled_at,omitempty"`
+}
+
+func marshalTwoFactor(t *entity.TwoFactorAuth) ([]byte, error) {
+	if t == nil {
+		return json.Marshal(twoFactorRecord{Enabled: false})
+	}
+	return json.Marshal(twoFactorRecord{
+		Enabled:       t.Enabled,
+		Secret:        t.Secret,
+		QRCodeURL:     t.QRCodeURL,
+		RecoveryCodes: t.RecoveryCodes,
+		LastUsedStep:  t.LastUsedStep,
+		EnabledAt:     t.EnabledAt,
+	})
+}
+
+func unmarshalTwoFactor(data []byte) (*entity.TwoFactorAuth, error) {
+	var rec twoFactorRecord
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return nil, err
+	}
+	return &entity.TwoFactorAuth{
+		Enabled:       rec.Enabled,
+		Secret:        rec.Secret,
+		QRCodeURL:     rec.QRCodeURL,
+		RecoveryCodes: rec.RecoveryCodes,
+		LastUsedStep:  rec.LastUsedStep,
+		EnabledAt:     rec.EnabledAt,
+	}, nil
+}
+
+// userColumns lista as colunas lidas em todas as consultas de usuário (mesma ordem do scanUser)
+const userColumns = `
+	id, organization_id, store_id, name, email, phone, avatar_url,
+	password_hash, role, status, invited_by, timezone, language, two_factor_settings,
+	email_verified_at, email_verification_sent_at, password_changed_at, tokens_valid_after,
+	last_login_at, last_login_ip, created_at, updated_at
+`
+
+// rowScanner abstrai *sql.Row e *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
 
+// scanUser converte uma linha do banco na entidade, tratando os campos que podem ser NULL
+func scanUser(row rowScanner) (*entity.User, error) {
 	var u entity.User
 	var twoFactorJSON []byte
-	var lockedUntil sql.NullTime
+	var emailVerifiedAt, verificationSentAt, passwordChangedAt, tokensValidAfter sql.NullTime
 	var lastLoginAt sql.NullTime
-	// Nota: Scan direto de campos que podem ser NULL (como StoreID, LockedUntil) exige cuidado.
-	// O pgx geralmente lida bem com *uuid.UUID, mas sql.DB padrão exige scan em sql.Null...
-	// Para simplificar aqui, vamos focar no caminho feliz. Se der erro de NULL, ajustamos.
+	var lastLoginIP sql.NullString
 
-	err := r.db.QueryRowContext(ctx, query, email).Scan(
+	err := row.Scan(
 		&u.ID, &u.OrganizationID, &u.StoreID, &u.Name, &u.Email, &u.Phone, &u.AvatarURL,
-		&u.PasswordHash, &u.Role, &u.Status, &u.Timezone, &u.Language, &twoFactorJSON,
-		&u.FailedLoginAttempts, &lockedUntil, &lastLoginAt, &u.CreatedAt,
+		&u.PasswordHash, &u.Role, &u.Status, &u.InvitedBy, &u.Timezone, &u.Language, &twoFactorJSON,
+		&emailVerifiedAt, &verificationSentAt, &passwordChangedAt, &tokensValidAfter,
+		&lastLoginAt, &lastLoginIP, &u.CreatedAt, &u.UpdatedAt,
 	)
-
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return nil, nil // Retorna nil se não achar (Use Case trata isso)
-		}
 		return nil, err
 	}
 
-	// Reconverte o JSONB para a struct Go
+	// Reconverte o JSONB do banco para a struct Go (TwoFactorAuth)
 	if len(twoFactorJSON) > 0 {
-		u.TwoFactor = &entity.TwoFactorAuth{}
-		_ = json.Unmarshal(twoFactorJSON, u.TwoFactor)
+		twoFactor, err := unmarshalTwoFactor(twoFactorJSON)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao decodificar 2fa: %w", err)
+		}
+		u.TwoFactor = twoFactor
 	}
 
-	if lockedUntil.Valid {
-		lockValue := lockedUntil.Time
-		u.LockedUntil = &lockValue
+	u.EmailVerifiedAt = nullTimePtr(emailVerifiedAt)
+	u.EmailVerificationSentAt = nullTimePtr(verificationSentAt)
+	u.PasswordChangedAt = nullTimePtr(passwordChangedAt)
+	u.TokensValidAfter = nullTimePtr(tokensValidAfter)
+	u.LastLoginAt = nullTimePtr(lastLoginAt)
+	u.LastLoginIP = lastLoginIP.String
+
+	return &u, nil
+}
+
+func nullTimePtr(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
 	}
+	value := t.Time
+	return &value
+}
 
-	if lastLoginAt.Valid {
-		lastLoginValue := lastLoginAt.Time
-		u.LastLoginAt = &lastLoginValue
+// timeOrNil evita gravar o "zero time" do Go quando o ponteiro é nil
+func timeOrNil(t *time.Time) interface{} {
+	if t == nil {
+		return nil
 	}
+	return *t
+}
 
-	return &u, nil
+// GetByEmail busca um usuário pelo email, sem diferenciar maiúsculas (índice em LOWER(email))
+func (r *UserRepoPostgres) GetByEmail(ctx context.Context, email string) (*entity.User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE LOWER(email) = $1`
+
+	u, err := scanUser(database.Conn(ctx, r.db).QueryRowContext(ctx, query, entity.NormalizeEmail(email)))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil // Retorna nil se não achar (Use Case trata isso)
+		}
+		return nil, err
+	}
+	return u, nil
+}
+
+// EmailExists verifica se o email já está em uso em qualquer organização.
+// A função email_in_use é SECURITY DEFINER: atravessa a RLS, mas só devolve se existe.
+func (r *UserRepoPostgres) EmailExists(ctx context.Context, email string) (bool, error) {
+	var exists bool
+	err := database.Conn(ctx, r.db).QueryRowContext(ctx, `SELECT email_in_use($1)`, entity.NormalizeEmail(email)).Scan(&exists)
+	return exists, err
 }
 
 // GetByID busca um usuário pelo ID
 func (r *UserRepoPostgres) GetByID(ctx context.Context, id uuid.UUID) (*entity.User, error) {
-	query := `
-		SELECT 
-			id, organization_id, store_id, name, email, phone, avatar_url,
-			password_hash, role, status, timezone, language, two_factor_settings,
-			failed_login_attempts, locked_until, last_login_at, created_at
-		FROM users 
-		WHERE id = $1
-	`
-
-	var u entity.User
-	var twoFactorJSON []byte
-	var lockedUntil sql.NullTime
-	var lastLoginAt sql.NullTime
-
-	// Executa a query passando o ID
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&u.ID, &u.OrganizationID, &u.StoreID, &u.Name, &u.Email, &u.Phone, &u.AvatarURL,
-		&u.PasswordHash, &u.Role, &u.Status, &u.Timezone, &u.Language, &twoFactorJSON,
-		&u.FailedLoginAttempts, &lockedUntil, &lastLoginAt, &u.CreatedAt,
-	)
+	query := `SELECT ` + userColumns + ` FROM users WHERE id = $1`
 
+	u, err := scanUser(database.Conn(ctx, r.db).QueryRowContext(ctx, query, id))
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			// Não encontrou ninguém com esse ID (retorna nil, nil sem erro)
@@ -132,64 +196,111 @@ func (r *UserRepoPostgres) GetByID(ctx context.Context, id uuid.UUID) (*entity.U
 		// Erro de conexão ou query
 		return nil, fmt.Errorf("erro ao buscar user por id: %w", err)
 	}
+	return u, nil
+}
 
-	// Reconverte o JSONB do banco para a struct Go (TwoFactorAuth)
-	if len(twoFactorJSON) > 0 {
-		u.TwoFactor = &entity.TwoFactorAuth{}
-		if err := json.Unmarshal(twoFactorJSON, u.TwoFactor); err != nil {
-			return nil, fmt.Errorf("erro ao decodificar 2fa: %w", err)
-		}
+// Update atualiza dados cadastrais (perfil, papel, status e loja)
+func (r *UserRepoPostgres) Update(ctx context.Context, u *entity.User) error {
+	query := `
+		UPDATE users SET 
+			name=$1, phone=$2, avatar_url=$3, role=$4, status=$5,
+			store_id=$6, timezone=$7, language=$8, updated_at=NOW()
+		WHERE id=$9
+	`
+	_, err := database.Conn(ctx, r.db).ExecContext(ctx, query,
+		u.Name, u.Phone, u.AvatarURL, u.Role, u.Status,
+		u.StoreID, u.Timezone, u.Language, u.ID,
+	)
+	return err
+}
+
+// ListByOrganization lista os usuários da organização com filtros opcionais e paginação
+func (r *UserRepoPostgres) ListByOrganization(ctx context.Context, orgID uuid.UUID, filter repository.UserFilter, pageParams pagination.Params) ([]*entity.User, int64, error) {
+	conn := database.Conn(ctx, r.db)
+
+	where := ` WHERE organization_id = $1`
+	args := []interface{}{orgID}
+	if filter.Role != nil {
+		args = append(args, *filter.Role)
+		where += fmt.Sprintf(" AND role = $%d", len(args))
+	}
+	if filter.Status != nil {
+		args = append(args, *filter.Status)
+		where += fmt.Sprintf(" AND status = $%d", len(args))
+	}
+	if filter.StoreID != nil {
+		args = append(args, *filter.StoreID)
+		where += fmt.Sprintf(" AND store_id = $%d", len(args))
 	}
 
-	if lockedUntil.Valid {
-		lockValue := lockedUntil.Time
-		u.LockedUntil = &lockValue
+	var totalItems int64
+	if err := conn.QueryRowContext(ctx, `SELECT COUNT(*) FROM users`+where, args...).Scan(&totalItems); err != nil {
+		return nil, 0, err
 	}
 
-	if lastLoginAt.Valid {
-		lastLoginValue := lastLoginAt.Time
-		u.LastLoginAt = &lastLoginValue
+	query := `SELECT ` + userColumns + ` FROM users` + where +
+		fmt.Sprintf(" ORDER BY name, created_at LIMIT $%d OFFSET $%d", len(args)+1, len(args)+2)
+	rows, err := conn.QueryContext(ctx, query, append(args, pageParams.Limit, pageParams.Offset())...)
+	if err != nil {
+		return nil, 0, err
 	}
+	defer rows.Close()
 
-	return &u, nil
+	var users []*entity.User
+	for rows.Next() {
+		u, err := scanUser(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		users = append(users, u)
+	}
+
+	return users, totalItems, rows.Err()
 }
 
-// Update atualiza dados cadastrais
-func (r *UserRepoPostgres) Update(ctx context.Context, u *entity.User) error {
+// UpdateTwoFactor grava as configurações de 2FA (segredo, códigos de recuperação)
+func (r *UserRepoPostgres) UpdateTwoFactor(ctx context.Context, u *entity.User) error {
+	twoFactorJSON, err := marshalTwoFactor(u.TwoFactor)
+	if err != nil {
+		return fmt.Errorf("erro ao serializar 2fa: %w", err)
+	}
+
+	query := `UPDATE users SET two_factor_settings=$1, updated_at=NOW() WHERE id=$2`
+	_, err = database.Conn(ctx, r.db).ExecContext(ctx, query, twoFactorJSON, u.ID)
+	return err
+}
+
+// UpdateEmailVerification grava a confirmação de email (e a ativação de cadastros pendentes)
+func (r *UserRepoPostgres) UpdateEmailVerification(ctx context.Context, u *entity.User) error {
 	query := `
-		UPDATE users SET 
-			name=$1, phone=$2, avatar_url=$3, role=$4, status=$5, updated_at=NOW()
-		WHERE id=$6
+		UPDATE users SET
+			email_verified_at=$1, email_verification_sent_at=$2, status=$3, updated_at=NOW()
+		WHERE id=$4
 	`
-	_, err := r.db.ExecContext(ctx, query, u.Name, u.Phone, u.AvatarURL, u.Role, u.Status, u.ID)
+	_, err := database.Conn(ctx, r.db).ExecContext(ctx, query,
+		timeOrNil(u.EmailVerifiedAt), timeOrNil(u.EmailVerificationSentAt), u.Status, u.ID,
+	)
 	return err
 }
 
-// UpdateSecurity atualiza dados sensíveis (Senha, Bloqueio)
+// UpdateSecurity atualiza dados sensíveis (Senha, Revogação de sessões, Último acesso)
 func (r *UserRepoPostgres) UpdateSecurity(ctx context.Context, u *entity.User) error {
-	var lockedUntil interface{}
-	if u.LockedUntil != nil {
-		lockedUntil = *u.LockedUntil
-	} else {
-		lockedUntil = nil
-	}
-
-	var lastLoginAt interface{}
-	if u.LastLoginAt != nil {
-		lastLoginAt = *u.LastLoginAt
-	} else {
-		lastLoginAt = nil
-	}
-
 	query := `
 		UPDATE users SET 
-			password_hash=$1, failed_login_attempts=$2, locked_until=$3, 
+			password_hash=$1, password_changed_at=$2, tokens_valid_after=$3,
 			last_login_at=$4, last_login_ip=$5
 		WHERE id=$6
 	`
-	_, err := r.db.ExecContext(ctx, query,
-		u.PasswordHash, u.FailedLoginAttempts, lockedUntil,
-		lastLoginAt, u.LastLoginIP, u.ID,
+	_, err := database.Conn(ctx, r.db).ExecContext(ctx, query,
+		u.PasswordHash, timeOrNil(u.PasswordChangedAt), timeOrNil(u.TokensValidAfter),
+		timeOrNil(u.LastLoginAt), u.LastLoginIP, u.ID,
 	)
 	return err
 }
+
+// UpdatePasswordHash grava o hash refeito. Se a senha foi trocada no meio do caminho, não faz nada.
+func (r *UserRepoPostgres) UpdatePasswordHash(ctx context.Context, id uuid.UUID, oldHash, newHash string) error {
+	query := `UPDATE users SET password_hash = $1 WHERE id = $2 AND password_hash = $3`
+	_, err := database.Conn(ctx, r.db).ExecContext(ctx, query, newHash, id, oldHash)
+	return err
+}
@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/interface/http/middleware"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/interface/http/response"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/application/dto"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/application/usecase"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/pagination"
+)
+
+type APIKeyHandler struct {
+	useCase *usecase.APIKeyUseCase
+}
+
+// NewAPIKeyHandler cria o controller das chaves de API da organização
+func NewAPIKeyHandler(uc *usecase.APIKeyUseCase) *APIKeyHandler {
+	return &APIKeyHandler{useCase: uc}
+}
+
+// Create POST /api-keys
+func (h *APIKeyHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req dto.CreateAPIKeyRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+
+	ctx := r.Context()
+	res, err := h.useCase.Create(ctx, middleware.GetUserID(ctx), middleware.GetOrgID(ctx), req)
+	if err != nil {
+		writeAPIKeyError(w, err)
+		return
+	}
+
+	response.Created(w, res)
+}
+
+// List GET /api-keys
+func (h *APIKeyHandler) List(w http.ResponseWriter, r *http.Request) {
+	pageParams := pagination.NewParams(r)
+
+	res, totalItems, err := h.useCase.List(r.Context(), middleware.GetOrgID(r.Context()), pageParams)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Erro ao buscar chaves de API")
+		return
+	}
+
+	writePage(w, res, totalItems, pageParams)
+}
+
+// Revoke DELETE /api-keys/{id}
+func (h *APIKeyHandler) Revoke(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "ID inválido")
+		return
+	}
+
+	if err := h.useCase.Revoke(r.Context(), middleware.GetOrgID(r.Context()), id); err != nil {
+		writeAPIKeyError(w, err)
+		return
+	}
+
+	response.NoContent(w)
+}
+
+func writeAPIKeyError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, usecase.ErrAPIKeyNotFound):
+		response.Error(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, usecase.ErrAPIKeyScopeNotAllowed):
+		response.Error(w, http.StatusForbidden, err.Error())
+	default:
+		writeUserError(w, err)
+	}
+}
@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/interface/http/middleware"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/interface/http/response"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/application/usecase"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/pagination"
+)
+
+type DeviceHandler struct {
+	useCase *usecase.DeviceUseCase
+}
+
+// NewDeviceHandler cria o controller de dispositivos
+func NewDeviceHandler(uc *usecase.DeviceUseCase) *DeviceHandler {
+	return &DeviceHandler{useCase: uc}
+}
+
+// ListMine GET /me/devices
+func (h *DeviceHandler) ListMine(w http.ResponseWriter, r *http.Request) {
+	res, err := h.useCase.ListMine(r.Context(), middleware.GetUserID(r.Context()))
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Erro ao buscar dispositivos")
+		return
+	}
+
+	response.OK(w, res)
+}
+
+// Remove DELETE /me/devices/{id}
+func (h *DeviceHandler) Remove(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "ID inválido")
+		return
+	}
+
+	if err := h.useCase.Remove(r.Context(), middleware.GetUserID(r.Context()), id); err != nil {
+		if errors.Is(err, usecase.ErrDeviceNotFound) {
+			response.Error(w, http.StatusNotFound, err.Error())
+			return
+		}
+		response.Error(w, http.StatusInternalServerError, "Erro ao remover dispositivo")
+		return
+	}
+
+	response.NoContent(w)
+}
+
+// ListByStore GET /stores/{id}/devices
+func (h *DeviceHandler) ListByStore(w http.ResponseWriter, r *http.Request) {
+	storeID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "ID inválido")
+		return
+	}
+
+	ctx := r.Context()
+	pageParams := pagination.NewParams(r)
+
+	res, totalItems, err := h.useCase.ListByStore(ctx, middleware.GetUserID(ctx), middleware.GetOrgID(ctx), storeID, pageParams)
+	if err != nil {
+		if errors.Is(err, usecase.ErrStoreNotFound) {
+			response.Error(w, http.StatusNotFound, err.Error())
+			return
+		}
+		response.Error(w, http.StatusInternalServerError, "Erro ao buscar dispositivos da loja")
+		return
+	}
+
+	writePage(w, res, totalItems, pageParams)
+}
+
+// RegisterRoutes agrupa as rotas de dispositivos
+func (h *DeviceHandler) RegisterRoutes(router chi.Router) {
+	router.Get("/me/devices", h.ListMine)
+	router.Delete("/me/devices/{id}", h.Remove)
+	router.Get("/stores/{id}/devices", h.ListByStore)
+}
@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/interface/http/middleware"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/interface/http/response"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/application/dto"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/application/usecase"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/pagination"
+)
+
+type ImpersonationHandler struct {
+	useCase *usecase.ImpersonationUseCase
+}
+
+// NewImpersonationHandler cria o controller de impersonação (suporte da plataforma)
+func NewImpersonationHandler(uc *usecase.ImpersonationUseCase) *ImpersonationHandler {
+	return &ImpersonationHandler{useCase: uc}
+}
+
+// Start POST /admin/impersonations
+func (h *ImpersonationHandler) Start(w http.ResponseWriter, r *http.Request) {
+	// Token de impersonação não abre outra impersonação
+	if middleware.GetImpersonation(r.Context()) != nil {
+		response.Error(w, http.StatusForbidden, "Ação indisponível durante a impersonação")
+		return
+	}
+
+	var req dto.StartImpersonationRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+	req.Client = clientInfo(r)
+
+	res, err := h.useCase.Start(r.Context(), middleware.GetUserID(r.Context()), req)
+	if err != nil {
+		writeImpersonationError(w, err)
+		return
+	}
+
+	response.Created(w, res)
+}
+
+// List GET /admin/impersonations
+func (h *ImpersonationHandler) List(w http.ResponseWriter, r *http.Request) {
+	pageParams := pagination.NewParams(r)
+
+	res, totalItems, err := h.useCase.List(r.Context(), pageParams)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Erro ao buscar impersonações")
+		return
+	}
+
+	writePage(w, res, totalItems, pageParams)
+}
+
+// Events GET /admin/impersonations/{id}/events
+func (h *ImpersonationHandler) Events(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "ID inválido")
+		return
+	}
+	pageParams := pagination.NewParams(r)
+
+	res, totalItems, err := h.useCase.ListEvents(r.Context(), id, pageParams)
+	if err != nil {
+		writeImpersonationError(w, err)
+		return
+	}
+
+	writePage(w, res, totalItems, pageParams)
+}
+
+// End DELETE /admin/impersonations/{id}
+func (h *ImpersonationHandler) End(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "ID inválido")
+		return
+	}
+
+	if err := h.useCase.End(r.Context(), id); err != nil {
+		writeImpersonationError(w, err)
+		return
+	}
+
+	response.NoContent(w)
+}
+
+// EndCurrent POST /auth/impersonation/end (chamado com o próprio token de impersonação)
+func (h *ImpersonationHandler) EndCurrent(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if middleware.GetImpersonation(ctx) == nil {
+		response.Error(w, http.StatusBadRequest, "Esta sessão não é uma impersonação")
+		return
+	}
+
+	if err := h.useCase.EndCurrent(ctx, middleware.GetUserID(ctx), middleware.GetTokenID(ctx), middleware.GetTokenExpiresAt(ctx)); err != nil {
+		writeImpersonationError(w, err)
+		return
+	}
+
+	response.NoContent(w)
+}
+
+func writeImpersonationError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, usecase.ErrImpersonationNotFound):
+		response.Error(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, usecase.ErrImpersonationNotAllowed), errors.Is(err, usecase.ErrImpersonationWriteNotAllowed):
+		response.Error(w, http.StatusForbidden, err.Error())
+	default:
+		writeUserError(w, err)
+	}
+}
@@ -0,0 +1,131 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/interface/http/middleware"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/interface/http/response"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/application/dto"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/application/usecase"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/pagination"
+)
+
+type InviteHandler struct {
+	useCase *usecase.InviteUseCase
+}
+
+// NewInviteHandler cria o controller de convites
+func NewInviteHandler(uc *usecase.InviteUseCase) *InviteHandler {
+	return &InviteHandler{useCase: uc}
+}
+
+// Create POST /invites
+func (h *InviteHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req dto.CreateInviteRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+
+	ctx := r.Context()
+	res, err := h.useCase.Create(ctx, middleware.GetUserID(ctx), middleware.GetOrgID(ctx), req)
+	if err != nil {
+		writeInviteError(w, err)
+		return
+	}
+
+	response.Created(w, res)
+}
+
+// List GET /invites (convites em aberto da organização do token)
+func (h *InviteHandler) List(w http.ResponseWriter, r *http.Request) {
+	pageParams := pagination.NewParams(r)
+
+	res, totalItems, err := h.useCase.ListOpen(r.Context(), middleware.GetOrgID(r.Context()), pageParams)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Erro ao buscar convites")
+		return
+	}
+
+	writePage(w, res, totalItems, pageParams)
+}
+
+// Resend POST /invites/{id}/resend
+func (h *InviteHandler) Resend(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "ID inválido")
+		return
+	}
+
+	ctx := r.Context()
+	res, err := h.useCase.Resend(ctx, middleware.GetUserID(ctx), middleware.GetOrgID(ctx), id)
+	if err != nil {
+		writeInviteError(w, err)
+		return
+	}
+
+	response.OK(w, res)
+}
+
+// Revoke DELETE /invites/{id}
+func (h *InviteHandler) Revoke(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "ID inválido")
+		return
+	}
+
+	ctx := r.Context()
+	if err := h.useCase.Revoke(ctx, middleware.GetUserID(ctx), middleware.GetOrgID(ctx), id); err != nil {
+		writeInviteError(w, err)
+		return
+	}
+
+	response.NoContent(w)
+}
+
+// Accept POST /auth/invites/accept (rota pública, o convidado define nome e senha)
+func (h *InviteHandler) Accept(w http.ResponseWriter, r *http.Request) {
+	var req dto.AcceptInviteRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+
+	res, err := h.useCase.Accept(r.Context(), req)
+	if err != nil {
+		writeInviteError(w, err)
+		return
+	}
+
+	response.Created(w, res)
+}
+
+// RegisterRoutes agrupa as rotas de convite
+func (h *InviteHandler) RegisterRoutes(router chi.Router) {
+	router.Post("/auth/invites/accept", h.Accept)
+	router.Post("/invites", h.Create)
+	router.Get("/invites", h.List)
+	router.Post("/invites/{id}/resend", h.Resend)
+	router.Delete("/invites/{id}", h.Revoke)
+}
+
+func writeInviteError(w http.ResponseWriter, err error) {
+	if writePasswordPolicyError(w, err) || writeQuotaExceeded(w, err) {
+		return
+	}
+	switch {
+	case errors.Is(err, usecase.ErrInviteNotFound), errors.Is(err, usecase.ErrStoreNotFound):
+		response.Error(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, usecase.ErrRoleNotAllowed):
+		response.Error(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, usecase.ErrInviteAlreadyOpen), errors.Is(err, usecase.ErrEmailAlreadyInUse):
+		response.Error(w, http.StatusConflict, err.Error())
+	case errors.Is(err, usecase.ErrInvalidInvite):
+		response.Error(w, http.StatusBadRequest, err.Error())
+	default:
+		response.Error(w, http.StatusInternalServerError, "Erro ao processar convite")
+	}
+}
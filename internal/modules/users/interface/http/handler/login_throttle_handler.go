@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/paulochiaradia/smart-gondola-backend/internal/interface/http/response"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/application/dto"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/application/usecase"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/pagination"
+)
+
+type LoginThrottleHandler struct {
+	useCase *usecase.LoginThrottleUseCase
+}
+
+// NewLoginThrottleHandler cria o controller dos bloqueios de login (segurança da plataforma)
+func NewLoginThrottleHandler(uc *usecase.LoginThrottleUseCase) *LoginThrottleHandler {
+	return &LoginThrottleHandler{useCase: uc}
+}
+
+// List GET /admin/login-throttles
+func (h *LoginThrottleHandler) List(w http.ResponseWriter, r *http.Request) {
+	pageParams := pagination.NewParams(r)
+
+	res, totalItems, err := h.useCase.ListBlocked(r.Context(), pageParams)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Erro ao buscar bloqueios de login")
+		return
+	}
+
+	writePage(w, res, totalItems, pageParams)
+}
+
+// Unlock POST /admin/login-throttles/unlock
+func (h *LoginThrottleHandler) Unlock(w http.ResponseWriter, r *http.Request) {
+	var req dto.UnlockLoginRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+
+	res, err := h.useCase.Unlock(r.Context(), req)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Erro ao desbloquear login")
+		return
+	}
+
+	response.OK(w, res)
+}
+
+// writeLoginThrottled responde 429 com Retry-After quando o login está bloqueado.
+// Devolve false (sem responder) quando o erro é outro.
+func writeLoginThrottled(w http.ResponseWriter, err error) bool {
+	var throttled *usecase.LoginThrottledError
+	if !errors.As(err, &throttled) {
+		return false
+	}
+	seconds := int(math.Ceil(throttled.RetryAfter.Seconds()))
+	w.Header().Set("Retry-After", strconv.Itoa(max(seconds, 1)))
+	response.Error(w, http.StatusTooManyRequests, throttled.Error())
+	return true
+}
@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/interface/http/response"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/application/dto"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/application/usecase"
+)
+
+type PasswordHandler struct {
+	useCase *usecase.PasswordResetUseCase
+}
+
+// NewPasswordHandler cria o controller de recuperação de senha
+func NewPasswordHandler(uc *usecase.PasswordResetUseCase) *PasswordHandler {
+	return &PasswordHandler{useCase: uc}
+}
+
+// Forgot trata a rota POST /auth/password/forgot
+func (h *PasswordHandler) Forgot(w http.ResponseWriter, r *http.Request) {
+	var req dto.ForgotPasswordRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+
+	if err := h.useCase.ForgotPassword(r.Context(), req); err != nil {
+		response.Error(w, http.StatusInternalServerError, "Erro ao processar pedido de redefinição")
+		return
+	}
+
+	// Mesma resposta para emails cadastrados ou não (evita enumeração de contas)
+	response.JSON(w, http.StatusAccepted, map[string]string{
+		"message": "Se o email estiver cadastrado, você receberá um link para redefinir a senha.",
+	})
+}
+
+// Reset trata a rota POST /auth/password/reset
+func (h *PasswordHandler) Reset(w http.ResponseWriter, r *http.Request) {
+	var req dto.ResetPasswordRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+
+	if err := h.useCase.ResetPassword(r.Context(), req); err != nil {
+		if writePasswordPolicyError(w, err) {
+			return
+		}
+		if errors.Is(err, usecase.ErrInvalidResetToken) {
+			response.Error(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		response.Error(w, http.StatusInternalServerError, "Erro ao redefinir senha")
+		return
+	}
+
+	response.NoContent(w)
+}
+
+// RegisterRoutes agrupa as rotas públicas de recuperação de senha
+func (h *PasswordHandler) RegisterRoutes(router chi.Router) {
+	router.Post("/auth/password/forgot", h.Forgot)
+	router.Post("/auth/password/reset", h.Reset)
+}
+
+// writePasswordPolicyError responde 400 com as regras não atendidas pela senha.
+// Devolve false (sem responder) quando o erro não é da política de senha.
+func writePasswordPolicyError(w http.ResponseWriter, err error) bool {
+	var policyErr *usecase.PasswordPolicyError
+	if !errors.As(err, &policyErr) {
+		return false
+	}
+	response.Error(w, http.StatusBadRequest, usecase.ErrWeakPassword.Error(), policyErr.Violations...)
+	return true
+}
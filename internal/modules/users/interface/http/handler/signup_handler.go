@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/interface/http/response"
+	orgEntity "github.com/paulochiaradia/smart-gondola-backend/internal/modules/organizations/domain/entity"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/application/dto"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/application/usecase"
+)
+
+type SignupHandler struct {
+	useCase *usecase.SignupUseCase
+}
+
+// NewSignupHandler cria o controller de cadastro de novas empresas
+func NewSignupHandler(uc *usecase.SignupUseCase) *SignupHandler {
+	return &SignupHandler{useCase: uc}
+}
+
+// Signup trata a rota POST /auth/signup
+func (h *SignupHandler) Signup(w http.ResponseWriter, r *http.Request) {
+	var req dto.SignupRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+
+	res, err := h.useCase.Signup(r.Context(), req)
+	if err != nil {
+		if writePasswordPolicyError(w, err) || writeQuotaExceeded(w, err) {
+			return
+		}
+		if errors.Is(err, usecase.ErrEmailAlreadyInUse) || err.Error() == "este slug já está em uso por outra empresa" {
+			response.Error(w, http.StatusConflict, err.Error())
+			return
+		}
+		if errors.Is(err, usecase.ErrPlanNotSelfService) || errors.Is(err, orgEntity.ErrTrialNotAllowed) {
+			response.Error(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if err.Error() == "CNPJ inválido" || err.Error() == "setor de atuação inválido" || err.Error() == "nome da loja é obrigatório" {
+			response.Error(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		response.Error(w, http.StatusInternalServerError, "Erro ao criar conta")
+		return
+	}
+
+	response.Created(w, res)
+}
+
+// RegisterRoutes agrupa as rotas de cadastro
+func (h *SignupHandler) RegisterRoutes(router chi.Router) {
+	router.Post("/auth/signup", h.Signup)
+}
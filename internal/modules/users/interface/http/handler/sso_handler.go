@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/paulochiaradia/smart-gondola-backend/internal/interface/http/response"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/application/dto"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/application/usecase"
+)
+
+type SSOHandler struct {
+	useCase *usecase.SSOUseCase
+}
+
+// NewSSOHandler cria o controller do login corporativo (OIDC)
+func NewSSOHandler(uc *usecase.SSOUseCase) *SSOHandler {
+	return &SSOHandler{useCase: uc}
+}
+
+// Start POST /auth/sso/start
+func (h *SSOHandler) Start(w http.ResponseWriter, r *http.Request) {
+	var req dto.SSOStartRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+
+	res, err := h.useCase.Start(r.Context(), req)
+	if err != nil {
+		writeSSOError(w, err)
+		return
+	}
+
+	response.OK(w, res)
+}
+
+// Callback POST /auth/sso/callback
+func (h *SSOHandler) Callback(w http.ResponseWriter, r *http.Request) {
+	var req dto.SSOCallbackRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+	req.Client = clientInfo(r)
+
+	res, err := h.useCase.Callback(r.Context(), req)
+	if err != nil {
+		writeSSOError(w, err)
+		return
+	}
+
+	response.OK(w, res)
+}
+
+func writeSSOError(w http.ResponseWriter, err error) {
+	if writeQuotaExceeded(w, err) {
+		return
+	}
+	switch {
+	case errors.Is(err, usecase.ErrSSOUnavailable):
+		response.Error(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, usecase.ErrSSOInvalidState):
+		response.Error(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, usecase.ErrSSOProviderRejected):
+		response.Error(w, http.StatusUnauthorized, err.Error())
+	case errors.Is(err, usecase.ErrSSOEmailNotAllowed), errors.Is(err, usecase.ErrSSOUserInactive):
+		response.Error(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, usecase.ErrSSOAccountConflict):
+		response.Error(w, http.StatusConflict, err.Error())
+	case errors.Is(err, usecase.ErrSSOProviderUnavailable):
+		response.Error(w, http.StatusBadGateway, err.Error())
+	default:
+		response.Error(w, http.StatusInternalServerError, "Erro no login corporativo")
+	}
+}
@@ -2,13 +2,17 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
 	"github.com/paulochiaradia/smart-gondola-backend/internal/interface/http/middleware"
 	"github.com/paulochiaradia/smart-gondola-backend/internal/interface/http/response"
+	orgEntity "github.com/paulochiaradia/smart-gondola-backend/internal/modules/organizations/domain/entity"
 	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/application/dto"
 	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/application/usecase"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/pagination"
 	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/validator"
 )
 
@@ -21,41 +25,59 @@ func NewUserHandler(uc *usecase.UserUseCase) *UserHandler {
 	return &UserHandler{useCase: uc}
 }
 
-// Register trata a rota POST /auth/register
-func (h *UserHandler) Register(w http.ResponseWriter, r *http.Request) {
+// CreateUser trata a rota POST /users (admin/gerente cria um usuário na própria organização)
+func (h *UserHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
 	var req dto.CreateUserRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+
+	ctx := r.Context()
+	res, err := h.useCase.CreateUser(ctx, middleware.GetUserID(ctx), middleware.GetOrgID(ctx), req)
+	if err != nil {
+		writeUserError(w, err)
+		return
+	}
+
+	response.Created(w, res)
+}
+
+// Login trata a rota POST /auth/login
+func (h *UserHandler) Login(w http.ResponseWriter, r *http.Request) {
+	var req dto.LoginRequest
 
-	// 1. Decodifica o JSON
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		response.Error(w, http.StatusBadRequest, "JSON inválido")
 		return
 	}
 
-	validationErrors := validator.ValidateStruct(req)
-	if len(validationErrors) > 0 {
+	if validationErrors := validator.ValidateStruct(req); len(validationErrors) > 0 {
 		response.Error(w, http.StatusBadRequest, "Falha na validação dos dados", validationErrors...)
 		return
 	}
 
-	// 2. Chama a regra de negócio
-	res, err := h.useCase.Register(r.Context(), req)
+	req.Client = clientInfo(r)
+	res, err := h.useCase.Login(r.Context(), req)
 	if err != nil {
-		// Tratamento de erro simplificado
-		if err.Error() == "email já cadastrado" {
-			response.Error(w, http.StatusConflict, err.Error())
+		if writeLoginThrottled(w, err) || writeQuotaExceeded(w, err) {
+			return
+		}
+		if errors.Is(err, usecase.ErrEmailNotVerified) {
+			response.Error(w, http.StatusForbidden, "Confirme seu email antes de entrar")
 			return
 		}
-		response.Error(w, http.StatusInternalServerError, err.Error())
+
+		// Por segurança, sempre retorna 401 genérico
+		response.Error(w, http.StatusUnauthorized, "email ou senha inválidos")
 		return
 	}
 
-	// 3. Responde Sucesso (201 Created)
-	response.Created(w, res)
+	response.OK(w, res)
 }
 
-// Login trata a rota POST /auth/login
-func (h *UserHandler) Login(w http.ResponseWriter, r *http.Request) {
-	var req dto.LoginRequest
+// Refresh trata a rota POST /auth/refresh
+func (h *UserHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+	var req dto.RefreshTokenRequest
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		response.Error(w, http.StatusBadRequest, "JSON inválido")
@@ -67,42 +89,349 @@ func (h *UserHandler) Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	res, err := h.useCase.Login(r.Context(), req)
+	res, err := h.useCase.Refresh(r.Context(), req)
 	if err != nil {
-		if err.Error() == "conta temporariamente bloqueada..." {
-			response.Error(w, http.StatusTooManyRequests, err.Error())
+		if errors.Is(err, usecase.ErrInvalidRefreshToken) || errors.Is(err, usecase.ErrRefreshTokenReused) {
+			response.Error(w, http.StatusUnauthorized, err.Error())
+			return
+		}
+		if errors.Is(err, usecase.ErrEmailNotVerified) {
+			response.Error(w, http.StatusForbidden, "Confirme seu email antes de entrar")
 			return
 		}
+		response.Error(w, http.StatusInternalServerError, "Erro ao renovar sessão")
+		return
+	}
 
-		// Por segurança, sempre retorna 401 genérico
-		response.Error(w, http.StatusUnauthorized, "email ou senha inválidos")
+	response.OK(w, res)
+}
+
+// Logout trata a rota POST /auth/logout (revoga o token atual)
+func (h *UserHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	var req dto.LogoutRequest
+
+	// O corpo é opcional: sem ele, apenas o access token atual é revogado
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			response.Error(w, http.StatusBadRequest, "JSON inválido")
+			return
+		}
+	}
+
+	ctx := r.Context()
+	err := h.useCase.Logout(ctx, middleware.GetUserID(ctx), middleware.GetTokenID(ctx), middleware.GetTokenExpiresAt(ctx), req)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Erro ao encerrar sessão")
+		return
+	}
+
+	response.NoContent(w)
+}
+
+// LogoutAll trata a rota POST /auth/logout-all (encerra a sessão em todos os aparelhos)
+func (h *UserHandler) LogoutAll(w http.ResponseWriter, r *http.Request) {
+	if err := h.useCase.LogoutAll(r.Context(), middleware.GetUserID(r.Context())); err != nil {
+		response.Error(w, http.StatusInternalServerError, "Erro ao encerrar sessões")
+		return
+	}
+
+	response.NoContent(w)
+}
+
+// VerifyTwoFactor trata a rota POST /auth/2fa/verify (segunda etapa do login)
+func (h *UserHandler) VerifyTwoFactor(w http.ResponseWriter, r *http.Request) {
+	var req dto.TwoFactorLoginRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+
+	req.Client = clientInfo(r)
+	res, err := h.useCase.VerifyTwoFactorLogin(r.Context(), req)
+	if err != nil {
+		writeTwoFactorError(w, err)
+		return
+	}
+
+	response.OK(w, res)
+}
+
+// StartTwoFactorSetup trata a rota POST /auth/2fa/setup (cadastro exigido pela organização)
+func (h *UserHandler) StartTwoFactorSetup(w http.ResponseWriter, r *http.Request) {
+	var req dto.TwoFactorSetupRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+
+	res, err := h.useCase.StartRequiredTwoFactorSetup(r.Context(), req)
+	if err != nil {
+		writeTwoFactorError(w, err)
+		return
+	}
+
+	response.OK(w, res)
+}
+
+// ConfirmTwoFactorSetup trata a rota POST /auth/2fa/setup/confirm (ativa o 2FA e conclui o login)
+func (h *UserHandler) ConfirmTwoFactorSetup(w http.ResponseWriter, r *http.Request) {
+	var req dto.TwoFactorSetupConfirmRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+
+	req.Client = clientInfo(r)
+	res, err := h.useCase.ConfirmRequiredTwoFactorSetup(r.Context(), req)
+	if err != nil {
+		writeTwoFactorError(w, err)
+		return
+	}
+
+	response.OK(w, res)
+}
+
+// ChangeExpiredPassword trata a rota POST /auth/password/expired (senha vencida, segue com o login)
+func (h *UserHandler) ChangeExpiredPassword(w http.ResponseWriter, r *http.Request) {
+	var req dto.ExpiredPasswordRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+
+	req.Client = clientInfo(r)
+	res, err := h.useCase.ChangeExpiredPassword(r.Context(), req)
+	if err != nil {
+		if writePasswordPolicyError(w, err) {
+			return
+		}
+		if errors.Is(err, usecase.ErrInvalidPasswordChallenge) {
+			response.Error(w, http.StatusUnauthorized, err.Error())
+			return
+		}
+		writeTwoFactorError(w, err)
+		return
+	}
+
+	response.OK(w, res)
+}
+
+// EnrollTwoFactor trata a rota POST /me/2fa/enroll
+func (h *UserHandler) EnrollTwoFactor(w http.ResponseWriter, r *http.Request) {
+	res, err := h.useCase.EnrollTwoFactor(r.Context(), middleware.GetUserID(r.Context()))
+	if err != nil {
+		writeTwoFactorError(w, err)
+		return
+	}
+
+	response.OK(w, res)
+}
+
+// ConfirmTwoFactor trata a rota POST /me/2fa/confirm
+func (h *UserHandler) ConfirmTwoFactor(w http.ResponseWriter, r *http.Request) {
+	var req dto.TwoFactorCodeRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+
+	res, err := h.useCase.ConfirmTwoFactor(r.Context(), middleware.GetUserID(r.Context()), req)
+	if err != nil {
+		writeTwoFactorError(w, err)
+		return
+	}
+
+	response.OK(w, res)
+}
+
+// DisableTwoFactor trata a rota POST /me/2fa/disable
+func (h *UserHandler) DisableTwoFactor(w http.ResponseWriter, r *http.Request) {
+	var req dto.TwoFactorDisableRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+
+	if err := h.useCase.DisableTwoFactor(r.Context(), middleware.GetUserID(r.Context()), req); err != nil {
+		writeTwoFactorError(w, err)
+		return
+	}
+
+	response.NoContent(w)
+}
+
+// RegenerateRecoveryCodes trata a rota POST /me/2fa/recovery-codes
+func (h *UserHandler) RegenerateRecoveryCodes(w http.ResponseWriter, r *http.Request) {
+	var req dto.TwoFactorCodeRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+
+	res, err := h.useCase.RegenerateRecoveryCodes(r.Context(), middleware.GetUserID(r.Context()), req)
+	if err != nil {
+		writeTwoFactorError(w, err)
 		return
 	}
 
 	response.OK(w, res)
 }
 
-// Me retorna os dados do usuário logado (extraídos do Token)
-func (h *UserHandler) Me(w http.ResponseWriter, r *http.Request) {
-	// Recupera o ID que o Middleware injetou no contexto
-	userID := middleware.GetUserID(r.Context())
-	orgID := middleware.GetOrgID(r.Context())
-	role := middleware.GetRole(r.Context())
+// VerifyEmail trata a rota POST /auth/email/verify (link enviado por email)
+func (h *UserHandler) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	var req dto.VerifyEmailRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+
+	if err := h.useCase.VerifyEmail(r.Context(), req); err != nil {
+		if errors.Is(err, usecase.ErrInvalidVerificationToken) {
+			response.Error(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		response.Error(w, http.StatusInternalServerError, "Erro ao confirmar email")
+		return
+	}
+
+	response.NoContent(w)
+}
+
+// ResendVerification trata a rota POST /auth/email/resend
+func (h *UserHandler) ResendVerification(w http.ResponseWriter, r *http.Request) {
+	var req dto.ResendVerificationRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+
+	if err := h.useCase.ResendVerification(r.Context(), req); err != nil {
+		response.Error(w, http.StatusInternalServerError, "Erro ao reenviar confirmação")
+		return
+	}
+
+	// Mesma resposta para qualquer email (evita enumeração de contas)
+	response.JSON(w, http.StatusAccepted, map[string]string{
+		"message": "Se houver uma confirmação pendente para este email, um novo link foi enviado.",
+	})
+}
+
+// MyLogins trata a rota GET /me/security/logins
+func (h *UserHandler) MyLogins(w http.ResponseWriter, r *http.Request) {
+	pageParams := pagination.NewParams(r)
+
+	res, totalItems, err := h.useCase.ListMyLogins(r.Context(), middleware.GetUserID(r.Context()), pageParams)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Erro ao buscar histórico de acessos")
+		return
+	}
+
+	writePage(w, res, totalItems, pageParams)
+}
+
+// UserLogins trata a rota GET /users/{id}/security/logins (visão do admin)
+func (h *UserHandler) UserLogins(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "ID inválido")
+		return
+	}
 
-	// Retorna os dados do usuário autenticado
-	data := map[string]interface{}{
-		"id":      userID,
-		"org_id":  orgID,
-		"role":    role,
-		"message": "Token válido! Você está autenticado.",
+	pageParams := pagination.NewParams(r)
+	res, totalItems, err := h.useCase.ListUserLogins(r.Context(), middleware.GetOrgID(r.Context()), userID, pageParams)
+	if err != nil {
+		if errors.Is(err, usecase.ErrUserNotFound) {
+			response.Error(w, http.StatusNotFound, err.Error())
+			return
+		}
+		response.Error(w, http.StatusInternalServerError, "Erro ao buscar histórico de acessos")
+		return
 	}
 
-	response.OK(w, data)
+	writePage(w, res, totalItems, pageParams)
 }
 
 // RegisterRoutes agrupa as rotas do módulo
 func (h *UserHandler) RegisterRoutes(router chi.Router) {
 	// Rotas Públicas
-	router.Post("/auth/register", h.Register)
 	router.Post("/auth/login", h.Login)
+	router.Post("/auth/refresh", h.Refresh)
+	router.Post("/auth/2fa/verify", h.VerifyTwoFactor)
+	router.Post("/auth/2fa/setup", h.StartTwoFactorSetup)
+	router.Post("/auth/2fa/setup/confirm", h.ConfirmTwoFactorSetup)
+	router.Post("/auth/password/expired", h.ChangeExpiredPassword)
+	router.Post("/auth/email/verify", h.VerifyEmail)
+	router.Post("/auth/email/resend", h.ResendVerification)
+
+	// Rotas Protegidas
+	router.Get("/me", h.Me)
+	router.Patch("/me", h.UpdateMe)
+	router.Put("/me/password", h.ChangePassword)
+	router.Get("/users", h.ListUsers)
+	router.Post("/users", h.CreateUser)
+	router.Get("/users/{id}", h.GetUser)
+	router.Patch("/users/{id}", h.UpdateUser)
+	router.Put("/users/{id}/role", h.ChangeRole)
+	router.Put("/users/{id}/store", h.MoveStore)
+	router.Post("/users/{id}/suspend", h.Suspend)
+	router.Post("/users/{id}/reactivate", h.Reactivate)
+	router.Post("/users/{id}/unlock", h.UnlockLogin)
+	router.Get("/me/security/logins", h.MyLogins)
+	router.Get("/users/{id}/security/logins", h.UserLogins)
+}
+
+// clientInfo extrai IP e User-Agent para o histórico de logins
+func clientInfo(r *http.Request) dto.ClientInfo {
+	return dto.ClientInfo{IP: middleware.ClientIP(r), UserAgent: r.UserAgent()}
+}
+
+// writePage responde uma listagem paginada no envelope padrão (data + meta)
+func writePage(w http.ResponseWriter, data interface{}, totalItems int64, pageParams pagination.Params) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response.SuccessPayload{
+		Data: data,
+		Meta: pagination.NewMeta(totalItems, pageParams.Page, pageParams.Limit),
+	})
+}
+
+// decodeAndValidate lê o JSON e aplica as validações; em caso de falha já responde 400
+func decodeAndValidate(w http.ResponseWriter, r *http.Request, dst interface{}) bool {
+	if err := json.NewDecoder(r.Body).Decode(dst); err != nil {
+		response.Error(w, http.StatusBadRequest, "JSON inválido")
+		return false
+	}
+
+	if validationErrors := validator.ValidateStruct(dst); len(validationErrors) > 0 {
+		response.Error(w, http.StatusBadRequest, "Falha na validação dos dados", validationErrors...)
+		return false
+	}
+	return true
+}
+
+// writeQuotaExceeded responde 402 quando a criação esbarrou no limite do plano (usuários, aparelhos).
+// Devolve false (sem responder) quando o erro é outro.
+func writeQuotaExceeded(w http.ResponseWriter, err error) bool {
+	var quota *orgEntity.QuotaExceededError
+	if !errors.As(err, &quota) {
+		return false
+	}
+	response.Error(w, http.StatusPaymentRequired, quota.Error(), "Faça upgrade do plano para continuar")
+	return true
+}
+
+// writeTwoFactorError traduz os erros dos fluxos de 2FA para status HTTP
+func writeTwoFactorError(w http.ResponseWriter, err error) {
+	if writeLoginThrottled(w, err) || writeQuotaExceeded(w, err) {
+		return
+	}
+	switch {
+	case errors.Is(err, usecase.ErrInvalidTwoFactorChallenge),
+		errors.Is(err, usecase.ErrInvalidTwoFactorCode),
+		errors.Is(err, usecase.ErrInvalidPassword):
+		response.Error(w, http.StatusUnauthorized, err.Error())
+	case errors.Is(err, usecase.ErrTwoFactorAlreadyEnabled),
+		errors.Is(err, usecase.ErrTwoFactorNotEnabled),
+		errors.Is(err, usecase.ErrTwoFactorNoEnrollment):
+		response.Error(w, http.StatusConflict, err.Error())
+	case errors.Is(err, usecase.ErrTwoFactorRequiredByOrg):
+		response.Error(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, usecase.ErrEmailNotVerified):
+		response.Error(w, http.StatusForbidden, "Confirme seu email antes de entrar")
+	case err.Error() == "usuário não encontrado":
+		response.Error(w, http.StatusNotFound, err.Error())
+	default:
+		response.Error(w, http.StatusInternalServerError, "Erro ao processar autenticação em dois fatores")
+	}
 }
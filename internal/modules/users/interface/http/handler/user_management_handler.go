@@ -0,0 +1,256 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/interface/http/middleware"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/interface/http/response"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/application/dto"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/application/usecase"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/domain/entity"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/pagination"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/validator"
+)
+
+// Me trata a rota GET /me (cadastro do usuário logado)
+func (h *UserHandler) Me(w http.ResponseWriter, r *http.Request) {
+	res, err := h.useCase.GetMe(r.Context(), middleware.GetUserID(r.Context()))
+	if err != nil {
+		writeUserError(w, err)
+		return
+	}
+
+	response.OK(w, res)
+}
+
+// UpdateMe trata a rota PATCH /me
+func (h *UserHandler) UpdateMe(w http.ResponseWriter, r *http.Request) {
+	var req dto.UpdateUserRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+
+	res, err := h.useCase.UpdateMe(r.Context(), middleware.GetUserID(r.Context()), req)
+	if err != nil {
+		writeUserError(w, err)
+		return
+	}
+
+	response.OK(w, res)
+}
+
+// ChangePassword trata a rota PUT /me/password (encerra todas as sessões)
+func (h *UserHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	var req dto.ChangePasswordRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+
+	if err := h.useCase.ChangePassword(r.Context(), middleware.GetUserID(r.Context()), req); err != nil {
+		writeUserError(w, err)
+		return
+	}
+
+	response.NoContent(w)
+}
+
+// ListUsers trata a rota GET /users?role=&status=&store_id=&page=&limit=
+func (h *UserHandler) ListUsers(w http.ResponseWriter, r *http.Request) {
+	var filter dto.ListUsersFilter
+	q := r.URL.Query()
+	if role := q.Get("role"); role != "" {
+		v := entity.UserRole(role)
+		filter.Role = &v
+	}
+	if status := q.Get("status"); status != "" {
+		v := entity.UserStatus(status)
+		filter.Status = &v
+	}
+	if storeID := q.Get("store_id"); storeID != "" {
+		id, err := uuid.Parse(storeID)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, "store_id inválido")
+			return
+		}
+		filter.StoreID = &id
+	}
+	if validationErrors := validator.ValidateStruct(filter); len(validationErrors) > 0 {
+		response.Error(w, http.StatusBadRequest, "Filtros inválidos", validationErrors...)
+		return
+	}
+
+	ctx := r.Context()
+	pageParams := pagination.NewParams(r)
+	res, totalItems, err := h.useCase.ListUsers(ctx, middleware.GetUserID(ctx), middleware.GetOrgID(ctx), filter, pageParams)
+	if err != nil {
+		writeUserError(w, err)
+		return
+	}
+
+	writePage(w, res, totalItems, pageParams)
+}
+
+// GetUser trata a rota GET /users/{id}
+func (h *UserHandler) GetUser(w http.ResponseWriter, r *http.Request) {
+	id, ok := userIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	ctx := r.Context()
+	res, err := h.useCase.GetUser(ctx, middleware.GetUserID(ctx), middleware.GetOrgID(ctx), id)
+	if err != nil {
+		writeUserError(w, err)
+		return
+	}
+
+	response.OK(w, res)
+}
+
+// UpdateUser trata a rota PATCH /users/{id} (perfil)
+func (h *UserHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
+	id, ok := userIDParam(w, r)
+	if !ok {
+		return
+	}
+	var req dto.UpdateUserRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+
+	ctx := r.Context()
+	res, err := h.useCase.UpdateUser(ctx, middleware.GetUserID(ctx), middleware.GetOrgID(ctx), id, req)
+	if err != nil {
+		writeUserError(w, err)
+		return
+	}
+
+	response.OK(w, res)
+}
+
+// ChangeRole trata a rota PUT /users/{id}/role
+func (h *UserHandler) ChangeRole(w http.ResponseWriter, r *http.Request) {
+	id, ok := userIDParam(w, r)
+	if !ok {
+		return
+	}
+	var req dto.ChangeRoleRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+
+	ctx := r.Context()
+	res, err := h.useCase.ChangeRole(ctx, middleware.GetUserID(ctx), middleware.GetOrgID(ctx), id, req.Role)
+	if err != nil {
+		writeUserError(w, err)
+		return
+	}
+
+	response.OK(w, res)
+}
+
+// MoveStore trata a rota PUT /users/{id}/store
+func (h *UserHandler) MoveStore(w http.ResponseWriter, r *http.Request) {
+	id, ok := userIDParam(w, r)
+	if !ok {
+		return
+	}
+	var req dto.MoveUserStoreRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+
+	ctx := r.Context()
+	res, err := h.useCase.MoveStore(ctx, middleware.GetUserID(ctx), middleware.GetOrgID(ctx), id, req.StoreID)
+	if err != nil {
+		writeUserError(w, err)
+		return
+	}
+
+	response.OK(w, res)
+}
+
+// Suspend trata a rota POST /users/{id}/suspend
+func (h *UserHandler) Suspend(w http.ResponseWriter, r *http.Request) {
+	id, ok := userIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	ctx := r.Context()
+	res, err := h.useCase.Suspend(ctx, middleware.GetUserID(ctx), middleware.GetOrgID(ctx), id)
+	if err != nil {
+		writeUserError(w, err)
+		return
+	}
+
+	response.OK(w, res)
+}
+
+// Reactivate trata a rota POST /users/{id}/reactivate
+func (h *UserHandler) Reactivate(w http.ResponseWriter, r *http.Request) {
+	id, ok := userIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	ctx := r.Context()
+	res, err := h.useCase.Reactivate(ctx, middleware.GetUserID(ctx), middleware.GetOrgID(ctx), id)
+	if err != nil {
+		writeUserError(w, err)
+		return
+	}
+
+	response.OK(w, res)
+}
+
+// UnlockLogin trata a rota POST /users/{id}/unlock
+func (h *UserHandler) UnlockLogin(w http.ResponseWriter, r *http.Request) {
+	id, ok := userIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	ctx := r.Context()
+	res, err := h.useCase.UnlockLogin(ctx, middleware.GetUserID(ctx), middleware.GetOrgID(ctx), id)
+	if err != nil {
+		writeUserError(w, err)
+		return
+	}
+
+	response.OK(w, res)
+}
+
+func userIDParam(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, "ID inválido")
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// writeUserError traduz os erros da gestão de usuários para status HTTP
+func writeUserError(w http.ResponseWriter, err error) {
+	if writePasswordPolicyError(w, err) || writeQuotaExceeded(w, err) {
+		return
+	}
+	switch {
+	case errors.Is(err, usecase.ErrUserNotFound), errors.Is(err, usecase.ErrStoreNotFound):
+		response.Error(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, usecase.ErrRoleNotAllowed), errors.Is(err, usecase.ErrCannotManageSelf):
+		response.Error(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, usecase.ErrEmailAlreadyInUse),
+		errors.Is(err, usecase.ErrLastTenantAdmin),
+		errors.Is(err, usecase.ErrUserNotSuspended),
+		errors.Is(err, usecase.ErrUserAlreadySuspended):
+		response.Error(w, http.StatusConflict, err.Error())
+	case errors.Is(err, usecase.ErrInvalidPassword):
+		response.Error(w, http.StatusUnauthorized, err.Error())
+	default:
+		response.Error(w, http.StatusInternalServerError, "Erro ao processar usuário")
+	}
+}
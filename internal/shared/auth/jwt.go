@@ -9,42 +9,207 @@ import (
 	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/config"
 )
 
+// Finalidades de token: só o "access" abre as rotas protegidas.
+// Os demais são credenciais de curta duração para etapas específicas.
+const (
+	TokenUseAccess             = "access"
+	TokenUseTwoFactorChallenge = "2fa_challenge"   // Senha ok, falta o código TOTP
+	TokenUseTwoFactorSetup     = "2fa_setup"       // Organização exige 2FA e o usuário ainda não cadastrou
+	TokenUseEmailVerification  = "email_verify"    // Link de confirmação de email
+	TokenUsePasswordChange     = "password_change" // Senha expirada: só serve para definir a nova
+)
+
+var (
+	// ErrNotAccessToken indica um token válido, mas emitido para outra finalidade
+	ErrNotAccessToken = errors.New("token não pode ser usado para acessar a API")
+	// ErrMalformedClaims indica um token bem assinado, mas sem os dados obrigatórios
+	ErrMalformedClaims = errors.New("token sem as claims obrigatórias")
+)
+
+// Claims é o payload de todos os nossos tokens: quem emite e quem valida usam a mesma struct
 type Claims struct {
+	UserID          uuid.UUID  `json:"user_id"`
+	OrgID           uuid.UUID  `json:"org_id,omitzero"`
+	StoreID         *uuid.UUID `json:"store_id,omitempty"` // Sessão restrita a uma loja (gerente/operador)
+	Role            string     `json:"role,omitempty"`
+	TokenUse        string     `json:"token_use"`
+	Email           string     `json:"email,omitempty"`     // Link de verificação: vale só para o email ao qual foi enviado
+	ReadOnly        bool       `json:"read_only,omitempty"` // Sessão restrita a leitura (email não verificado, impersonação)
+	Act             *Actor     `json:"act,omitempty"`       // Impersonação: quem realmente age (RFC 8693)
+	ImpersonationID uuid.UUID  `json:"impersonation_id,omitzero"`
+	jwt.RegisteredClaims
+}
+
+// Actor identifica o membro da plataforma por trás de um token de impersonação
+type Actor struct {
 	UserID uuid.UUID `json:"user_id"`
 	Role   string    `json:"role"`
-	jwt.RegisteredClaims
 }
 
-// GenerateToken cria um JWT com os dados do usuário E da organização
-func GenerateToken(userID uuid.UUID, orgID uuid.UUID, role string) (string, error) {
+// NewTokenID gera o "jti" dos tokens: um UUIDv7, que carrega o milissegundo da emissão
+// (o "iat" tem precisão de segundos; ver IssuedAtPrecise)
+func NewTokenID() string {
+	return uuid.Must(uuid.NewV7()).String()
+}
+
+// IssuedAtPrecise devolve o momento da emissão com precisão de milissegundos, lido do "jti".
+// Serve para comparar o token com um logout-all/troca de senha no mesmo segundo do "iat".
+// Um "jti" que não seja UUIDv7 (ou fora do segundo do "iat") fica com o próprio "iat".
+func (c *Claims) IssuedAtPrecise() time.Time {
+	if c.IssuedAt == nil {
+		return time.Time{}
+	}
+	issuedAt := c.IssuedAt.Time
+	id, err := uuid.Parse(c.ID)
+	if err != nil || id.Version() != 7 {
+		return issuedAt
+	}
+	precise := time.Unix(id.Time().UnixTime()).Truncate(time.Millisecond)
+	if precise.Before(issuedAt) || !precise.Before(issuedAt.Add(time.Second)) {
+		return issuedAt
+	}
+	return precise
+}
+
+// GenerateToken cria um JWT com os dados do usuário E da organização, válido por cfg.JWTExpiration.
+// storeID (opcional) restringe a sessão a uma loja; readOnly marca a sessão como somente leitura (ver middleware.EnforceReadOnly).
+func GenerateToken(userID uuid.UUID, orgID uuid.UUID, storeID *uuid.UUID, role string, readOnly bool) (string, error) {
+	now := time.Now()
+	claims := accessClaims(userID, orgID, storeID, role, readOnly)
+	claims.RegisteredClaims = registeredClaims(userID, NewTokenID(), now, now.Add(config.Get().JWTExpiration))
+	return sign(claims)
+}
+
+// Impersonation identifica quem realmente está agindo num token de impersonação
+type Impersonation struct {
+	SessionID uuid.UUID
+	ActorID   uuid.UUID
+	ActorRole string
+	TokenID   string // "jti" reservado para a sessão (encerrar = revogar)
+	ExpiresAt time.Time
+}
+
+// GenerateImpersonationToken cria um access token do usuário impersonado com o claim "act"
+// (quem realmente age, no estilo da RFC 8693). Não existe refresh: ao expirar, a sessão acaba.
+func GenerateImpersonationToken(userID uuid.UUID, orgID uuid.UUID, storeID *uuid.UUID, role string, readOnly bool, imp Impersonation) (string, error) {
+	claims := accessClaims(userID, orgID, storeID, role, readOnly)
+	claims.Act = &Actor{UserID: imp.ActorID, Role: imp.ActorRole}
+	claims.ImpersonationID = imp.SessionID
+	claims.RegisteredClaims = registeredClaims(userID, imp.TokenID, time.Now(), imp.ExpiresAt)
+	return sign(claims)
+}
+
+func accessClaims(userID uuid.UUID, orgID uuid.UUID, storeID *uuid.UUID, role string, readOnly bool) *Claims {
+	return &Claims{
+		UserID:   userID,
+		OrgID:    orgID,
+		StoreID:  storeID,
+		Role:     role,
+		TokenUse: TokenUseAccess,
+		ReadOnly: readOnly,
+	}
+}
+
+// registeredClaims preenche as claims padrão (RFC 7519). O "jti" permite revogar o token (logout).
+func registeredClaims(userID uuid.UUID, tokenID string, issuedAt, expiresAt time.Time) jwt.RegisteredClaims {
 	cfg := config.Get()
+	return jwt.RegisteredClaims{
+		ID:        tokenID,
+		Subject:   userID.String(),
+		Issuer:    cfg.JWTIssuer,
+		Audience:  jwt.ClaimStrings{cfg.JWTAudience},
+		IssuedAt:  jwt.NewNumericDate(issuedAt),
+		NotBefore: jwt.NewNumericDate(issuedAt),
+		ExpiresAt: jwt.NewNumericDate(expiresAt),
+	}
+}
+
+func sign(claims jwt.Claims) (string, error) {
+	ks, err := keys()
+	if err != nil {
+		return "", err
+	}
+	return ks.Sign(claims)
+}
+
+// parse é o único caminho de validação: assinatura pelo "kid", algoritmo, emissor, audiência e datas
+// (com tolerância de relógio de cfg.JWTLeeway)
+func parse(tokenString string, claims *Claims) error {
+	ks, err := keys()
+	if err != nil {
+		return err
+	}
 
-	// Adiciona as Claims (As informações que vão dentro do envelope)
-	claims := jwt.MapClaims{
-		"user_id": userID.String(), // Ajustado para "user_id"
-		"org_id":  orgID.String(),  // <-- ADICIONAMOS A ORGANIZAÇÃO AQUI!
-		"role":    role,
-		"exp":     time.Now().Add(time.Hour * 24).Unix(), // Expira em 24h
-		"iat":     time.Now().Unix(),
-		"iss":     "smart-gondola-api", // Nome correto da sua API
+	cfg := config.Get()
+	token, err := ks.Parse(tokenString, claims,
+		jwt.WithIssuer(cfg.JWTIssuer),
+		jwt.WithAudience(cfg.JWTAudience),
+		jwt.WithLeeway(cfg.JWTLeeway),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+	)
+	if err != nil {
+		return err
+	}
+	if !token.Valid {
+		return errors.New("token inválido")
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(cfg.JWTSecret))
+	// "sub" e "user_id" carregam o mesmo usuário; divergência é token adulterado
+	if claims.UserID == uuid.Nil || claims.Subject != claims.UserID.String() || claims.ID == "" || claims.IssuedAt == nil {
+		return ErrMalformedClaims
+	}
+	return nil
 }
 
 func ValidateToken(tokenString string) (*Claims, error) {
-	cfg := config.Get()
 	claims := &Claims{}
-	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-		return []byte(cfg.JWTSecret), nil
-	})
+	if err := parse(tokenString, claims); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
 
+// ValidateAccessToken valida um access token. Tokens de finalidade específica
+// (desafio 2FA, verificação de email) são recusados.
+func ValidateAccessToken(tokenString string) (*Claims, error) {
+	claims, err := ValidateToken(tokenString)
 	if err != nil {
 		return nil, err
 	}
-	if !token.Valid {
-		return nil, errors.New("token inválido")
+	if claims.TokenUse != TokenUseAccess {
+		return nil, ErrNotAccessToken
+	}
+	if claims.OrgID == uuid.Nil {
+		return nil, ErrMalformedClaims
+	}
+	return claims, nil
+}
+
+// GenerateScopedToken cria um token de curta duração que só serve para a finalidade informada
+func GenerateScopedToken(userID uuid.UUID, tokenUse string, ttl time.Duration) (string, error) {
+	return signScopedToken(Claims{UserID: userID, TokenUse: tokenUse}, ttl)
+}
+
+// GenerateEmailVerificationToken cria o token assinado do link de confirmação de email
+func GenerateEmailVerificationToken(userID uuid.UUID, email string, ttl time.Duration) (string, error) {
+	return signScopedToken(Claims{UserID: userID, TokenUse: TokenUseEmailVerification, Email: email}, ttl)
+}
+
+func signScopedToken(claims Claims, ttl time.Duration) (string, error) {
+	now := time.Now()
+	claims.RegisteredClaims = registeredClaims(claims.UserID, NewTokenID(), now, now.Add(ttl))
+	return sign(&claims)
+}
+
+// ValidateScopedToken valida assinatura, expiração e a finalidade do token
+func ValidateScopedToken(tokenString, tokenUse string) (*Claims, error) {
+	claims, err := ValidateToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if claims.TokenUse != tokenUse {
+		return nil, errors.New("token não serve para esta operação")
 	}
 	return claims, nil
 }
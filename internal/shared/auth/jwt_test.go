@@ -0,0 +1,98 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateToken_TypedClaimsRoundTrip(t *testing.T) {
+	cfg := config.Get()
+	userID, orgID, storeID := uuid.New(), uuid.New(), uuid.New()
+
+	token, err := GenerateToken(userID, orgID, &storeID, "manager", true)
+	require.NoError(t, err)
+
+	claims, err := ValidateAccessToken(token)
+	require.NoError(t, err)
+	assert.Equal(t, userID, claims.UserID)
+	assert.Equal(t, orgID, claims.OrgID)
+	assert.Equal(t, &storeID, claims.StoreID)
+	assert.Equal(t, "manager", claims.Role)
+	assert.True(t, claims.ReadOnly)
+	assert.Nil(t, claims.Act)
+
+	assert.Equal(t, userID.String(), claims.Subject)
+	assert.Equal(t, cfg.JWTIssuer, claims.Issuer)
+	assert.Equal(t, jwt.ClaimStrings{cfg.JWTAudience}, claims.Audience)
+	assert.NotEmpty(t, claims.ID)
+	require.NotNil(t, claims.NotBefore)
+	assert.WithinDuration(t, claims.IssuedAt.Add(cfg.JWTExpiration), claims.ExpiresAt.Time, time.Second)
+}
+
+func TestValidateToken_RejectsForeignAudienceAndIssuer(t *testing.T) {
+	cfg := config.Get()
+	now := time.Now()
+	userID := uuid.New()
+
+	forge := func(mutate func(*Claims)) string {
+		claims := accessClaims(userID, uuid.New(), nil, "operator", false)
+		claims.RegisteredClaims = registeredClaims(userID, uuid.NewString(), now, now.Add(time.Hour))
+		mutate(claims)
+		token, err := sign(claims)
+		require.NoError(t, err)
+		return token
+	}
+
+	_, err := ValidateAccessToken(forge(func(c *Claims) {}))
+	require.NoError(t, err)
+
+	_, err = ValidateAccessToken(forge(func(c *Claims) { c.Audience = jwt.ClaimStrings{"outro-servico"} }))
+	assert.ErrorIs(t, err, jwt.ErrTokenInvalidAudience)
+
+	_, err = ValidateAccessToken(forge(func(c *Claims) { c.Issuer = "outro-emissor" }))
+	assert.ErrorIs(t, err, jwt.ErrTokenInvalidIssuer)
+
+	_, err = ValidateAccessToken(forge(func(c *Claims) { c.NotBefore = jwt.NewNumericDate(now.Add(time.Hour)) }))
+	assert.ErrorIs(t, err, jwt.ErrTokenNotValidYet)
+
+	_, err = ValidateAccessToken(forge(func(c *Claims) { c.Subject = uuid.NewString() }))
+	assert.ErrorIs(t, err, ErrMalformedClaims)
+
+	// Relógio do emissor levemente adiantado: aceito dentro da tolerância
+	_, err = ValidateAccessToken(forge(func(c *Claims) { c.NotBefore = jwt.NewNumericDate(now.Add(cfg.JWTLeeway / 2)) }))
+	assert.NoError(t, err)
+}
+
+func TestValidateAccessToken_RejectsScopedTokens(t *testing.T) {
+	token, err := GenerateScopedToken(uuid.New(), TokenUseTwoFactorChallenge, time.Minute)
+	require.NoError(t, err)
+
+	_, err = ValidateAccessToken(token)
+	assert.ErrorIs(t, err, ErrNotAccessToken)
+
+	claims, err := ValidateScopedToken(token, TokenUseTwoFactorChallenge)
+	require.NoError(t, err)
+	assert.Equal(t, uuid.Nil, claims.OrgID)
+}
+
+func TestClaims_IssuedAtPreciseUsesTokenIDMillisecond(t *testing.T) {
+	token, err := GenerateToken(uuid.New(), uuid.New(), nil, "manager", false)
+	require.NoError(t, err)
+
+	claims, err := ValidateAccessToken(token)
+	require.NoError(t, err)
+	assert.Equal(t, time.Duration(0), claims.IssuedAt.Sub(claims.IssuedAt.Truncate(time.Second)), "iat em segundos inteiros")
+	precise := claims.IssuedAtPrecise()
+	assert.False(t, precise.Before(claims.IssuedAt.Time))
+	assert.WithinDuration(t, claims.IssuedAt.Time, precise, time.Second)
+
+	// "jti" fora do formato: fica com o "iat"
+	claims.ID = uuid.NewString()
+	assert.Equal(t, claims.IssuedAt.Time, claims.IssuedAtPrecise())
+}
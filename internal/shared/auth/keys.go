@@ -0,0 +1,253 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/config"
+)
+
+// KeySet guarda a chave privada que assina os tokens e todas as chaves públicas aceitas na validação.
+// Rotação: publique a chave nova (entra no JWKS), troque JWT_SIGNING_KEY_ID e, quando os tokens
+// antigos expirarem, deixe só a pública da chave velha (ou remova o arquivo).
+type KeySet struct {
+	signingKID string
+	signer     crypto.Signer
+	public     map[string]crypto.PublicKey
+}
+
+// JWK é uma chave pública no formato da RFC 7517
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`   // RSA
+	E   string `json:"e,omitempty"`   // RSA
+	Crv string `json:"crv,omitempty"` // OKP (Ed25519)
+	X   string `json:"x,omitempty"`   // OKP (Ed25519)
+}
+
+// JWKSet é o documento servido em /.well-known/jwks.json
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+var (
+	keysOnce   sync.Once
+	activeSet  *KeySet
+	errKeySet  error
+	errNoKeyID = errors.New("token sem 'kid' ou com chave desconhecida")
+)
+
+// InitKeys carrega as chaves configuradas. Chamado na inicialização para falhar cedo;
+// sem ele, a primeira assinatura/validação carrega as chaves sob demanda.
+func InitKeys(cfg *config.Config) error {
+	keysOnce.Do(func() {
+		activeSet, errKeySet = loadConfiguredKeys(cfg)
+	})
+	return errKeySet
+}
+
+func keys() (*KeySet, error) {
+	if err := InitKeys(config.Get()); err != nil {
+		return nil, err
+	}
+	return activeSet, nil
+}
+
+func loadConfiguredKeys(cfg *config.Config) (*KeySet, error) {
+	if cfg.JWTKeysDir != "" {
+		return LoadKeySet(cfg.JWTKeysDir, cfg.JWTSigningKeyID)
+	}
+	if cfg.AppEnv == "production" {
+		return nil, errors.New("JWT_KEYS_DIR é obrigatório em produção")
+	}
+
+	// Desenvolvimento/testes: chave efêmera (tokens não sobrevivem a um restart)
+	slog.Warn("JWT_KEYS_DIR não configurado: usando chave de assinatura efêmera")
+	return NewEphemeralKeySet()
+}
+
+// LoadKeySet lê as chaves PEM do diretório. O nome do arquivo (sem ".pem") é o "kid".
+// Arquivos com chave privada assinam e validam; arquivos só com chave pública apenas validam.
+func LoadKeySet(dir, signingKID string) (*KeySet, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+
+	ks := &KeySet{public: map[string]crypto.PublicKey{}}
+	signers := map[string]crypto.Signer{}
+	for _, file := range files {
+		kid := strings.TrimSuffix(filepath.Base(file), ".pem")
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao ler chave %s: %w", kid, err)
+		}
+
+		signer, public, err := parsePEMKey(data)
+		if err != nil {
+			return nil, fmt.Errorf("chave %s inválida: %w", kid, err)
+		}
+		ks.public[kid] = public
+		if signer != nil {
+			signers[kid] = signer
+		}
+	}
+
+	if len(ks.public) == 0 {
+		return nil, fmt.Errorf("nenhuma chave .pem encontrada em %s", dir)
+	}
+
+	// Sem kid configurado só aceitamos a escolha óbvia: uma única chave privada
+	if signingKID == "" && len(signers) == 1 {
+		for kid := range signers {
+			signingKID = kid
+		}
+	}
+	signer, ok := signers[signingKID]
+	if !ok {
+		return nil, fmt.Errorf("chave privada de assinatura %q não encontrada (defina JWT_SIGNING_KEY_ID)", signingKID)
+	}
+	ks.signingKID = signingKID
+	ks.signer = signer
+	return ks, nil
+}
+
+// NewEphemeralKeySet gera uma chave Ed25519 só em memória
+func NewEphemeralKeySet() (*KeySet, error) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	kid := "ephemeral-" + base64.RawURLEncoding.EncodeToString(public[:6])
+	return &KeySet{
+		signingKID: kid,
+		signer:     private,
+		public:     map[string]crypto.PublicKey{kid: public},
+	}, nil
+}
+
+func parsePEMKey(data []byte) (crypto.Signer, crypto.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, nil, errors.New("PEM não encontrado")
+	}
+
+	switch block.Type {
+	case "PRIVATE KEY":
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, nil, err
+		}
+		signer, ok := key.(crypto.Signer)
+		if !ok || signingMethodFor(signer.Public()) == nil {
+			return nil, nil, errors.New("tipo de chave não suportado (use RSA ou Ed25519)")
+		}
+		return signer, signer.Public(), nil
+	case "RSA PRIVATE KEY":
+		key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, nil, err
+		}
+		return key, key.Public(), nil
+	case "PUBLIC KEY":
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, nil, err
+		}
+		if signingMethodFor(key) == nil {
+			return nil, nil, errors.New("tipo de chave não suportado (use RSA ou Ed25519)")
+		}
+		return nil, key, nil
+	}
+	return nil, nil, fmt.Errorf("bloco PEM %q não suportado", block.Type)
+}
+
+// signingMethodFor escolhe o algoritmo pelo tipo da chave: RSA -> RS256, Ed25519 -> EdDSA
+func signingMethodFor(key crypto.PublicKey) jwt.SigningMethod {
+	switch key.(type) {
+	case *rsa.PublicKey:
+		return jwt.SigningMethodRS256
+	case ed25519.PublicKey:
+		return jwt.SigningMethodEdDSA
+	}
+	return nil
+}
+
+// Sign assina as claims com a chave ativa e grava o "kid" no header
+func (ks *KeySet) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(signingMethodFor(ks.signer.Public()), claims)
+	token.Header["kid"] = ks.signingKID
+	return token.SignedString(ks.signer)
+}
+
+// Parse valida assinatura (pela chave do "kid") e datas, preenchendo claims.
+// opts acrescenta as verificações de emissor/audiência/tolerância (ver parse).
+func (ks *KeySet) Parse(tokenString string, claims jwt.Claims, opts ...jwt.ParserOption) (*jwt.Token, error) {
+	return jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		key, ok := ks.public[kid]
+		if !ok {
+			return nil, errNoKeyID
+		}
+		// O algoritmo do header precisa ser o da chave (impede trocar RS256 por outro)
+		if method := signingMethodFor(key); method == nil || method.Alg() != token.Method.Alg() {
+			return nil, fmt.Errorf("método de assinatura inesperado: %v", token.Header["alg"])
+		}
+		return key, nil
+	}, append(opts, jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodEdDSA.Alg()}))...)
+}
+
+// JWKS devolve as chaves públicas aceitas (a de assinatura e as ainda válidas da rotação)
+func (ks *KeySet) JWKS() JWKSet {
+	kids := make([]string, 0, len(ks.public))
+	for kid := range ks.public {
+		kids = append(kids, kid)
+	}
+	sort.Strings(kids)
+
+	set := JWKSet{Keys: make([]JWK, 0, len(kids))}
+	for _, kid := range kids {
+		switch key := ks.public[kid].(type) {
+		case *rsa.PublicKey:
+			set.Keys = append(set.Keys, JWK{
+				Kty: "RSA", Kid: kid, Use: "sig", Alg: jwt.SigningMethodRS256.Alg(),
+				N: base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				E: base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			set.Keys = append(set.Keys, JWK{
+				Kty: "OKP", Kid: kid, Use: "sig", Alg: jwt.SigningMethodEdDSA.Alg(),
+				Crv: "Ed25519", X: base64.RawURLEncoding.EncodeToString(key),
+			})
+		}
+	}
+	return set
+}
+
+// JWKS devolve o documento de chaves públicas da aplicação
+func JWKS() (JWKSet, error) {
+	ks, err := keys()
+	if err != nil {
+		return JWKSet{}, err
+	}
+	return ks.JWKS(), nil
+}
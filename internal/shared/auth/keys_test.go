@@ -0,0 +1,111 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writePrivateKey(t *testing.T, dir, kid string, key interface{}) {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, kid+".pem"), pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600))
+}
+
+func writePublicKey(t *testing.T, dir, kid string, key interface{}) {
+	der, err := x509.MarshalPKIXPublicKey(key)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, kid+".pem"), pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0o600))
+}
+
+func testClaims() jwt.MapClaims {
+	return jwt.MapClaims{"user_id": "u-1", "exp": time.Now().Add(time.Hour).Unix()}
+}
+
+// Rotação: a chave antiga vira só pública e os tokens já emitidos continuam válidos
+func TestKeySet_RotationKeepsOldTokensValid(t *testing.T) {
+	oldKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	_, newKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	writePrivateKey(t, dir, "2026-01", oldKey)
+	old, err := LoadKeySet(dir, "")
+	require.NoError(t, err)
+	oldToken, err := old.Sign(testClaims())
+	require.NoError(t, err)
+
+	writePublicKey(t, dir, "2026-01", &oldKey.PublicKey)
+	writePrivateKey(t, dir, "2026-02", newKey)
+	rotated, err := LoadKeySet(dir, "2026-02")
+	require.NoError(t, err)
+
+	newToken, err := rotated.Sign(testClaims())
+	require.NoError(t, err)
+	parsed, err := rotated.Parse(newToken, jwt.MapClaims{})
+	require.NoError(t, err)
+	assert.Equal(t, "2026-02", parsed.Header["kid"])
+	assert.Equal(t, "EdDSA", parsed.Header["alg"])
+
+	parsed, err = rotated.Parse(oldToken, jwt.MapClaims{})
+	require.NoError(t, err, "token assinado com a chave anterior deve continuar válido")
+	assert.Equal(t, "RS256", parsed.Header["alg"])
+
+	jwks := rotated.JWKS()
+	require.Len(t, jwks.Keys, 2)
+	assert.Equal(t, JWK{Kty: "RSA", Kid: "2026-01", Use: "sig", Alg: "RS256", N: jwks.Keys[0].N, E: "AQAB"}, jwks.Keys[0])
+	assert.Equal(t, "OKP", jwks.Keys[1].Kty)
+	assert.Equal(t, "Ed25519", jwks.Keys[1].Crv)
+	assert.NotEmpty(t, jwks.Keys[1].X)
+}
+
+func TestKeySet_RejectsUnknownKidAndSymmetricTokens(t *testing.T) {
+	ks, err := NewEphemeralKeySet()
+	require.NoError(t, err)
+	other, err := NewEphemeralKeySet()
+	require.NoError(t, err)
+
+	foreign, err := other.Sign(testClaims())
+	require.NoError(t, err)
+	_, err = ks.Parse(foreign, jwt.MapClaims{})
+	assert.Error(t, err, "kid desconhecido")
+
+	// Ataque clássico: HS256 assinado com um segredo qualquer e o kid de uma chave válida
+	hmac := jwt.NewWithClaims(jwt.SigningMethodHS256, testClaims())
+	hmac.Header["kid"] = ks.signingKID
+	forged, err := hmac.SignedString([]byte("default_secret"))
+	require.NoError(t, err)
+	_, err = ks.Parse(forged, jwt.MapClaims{})
+	assert.Error(t, err)
+
+	// Sem kid não há como escolher a chave
+	noKid := jwt.NewWithClaims(jwt.SigningMethodEdDSA, testClaims())
+	unsigned, err := noKid.SignedString(ks.signer)
+	require.NoError(t, err)
+	_, err = ks.Parse(unsigned, jwt.MapClaims{})
+	assert.Error(t, err)
+}
+
+func TestLoadKeySet_RequiresSigningKey(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	writePublicKey(t, dir, "only-public", &key.PublicKey)
+	_, err = LoadKeySet(dir, "")
+	assert.Error(t, err)
+
+	_, err = LoadKeySet(t.TempDir(), "")
+	assert.Error(t, err, "diretório vazio")
+}
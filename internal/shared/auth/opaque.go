@@ -0,0 +1,45 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// GenerateOpaqueToken cria um token aleatório (256 bits) seguro para URLs.
+// Usado em refresh tokens e links de uso único; nunca deve ser salvo puro no banco.
+func GenerateOpaqueToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("erro ao gerar token aleatório: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// HashToken devolve o SHA-256 (hex) do token, que é o que vai para o banco
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// APIKeyPrefix identifica as chaves de API (e as diferencia de um JWT no header Authorization)
+const APIKeyPrefix = "sgk_"
+
+// GenerateAPIKey cria uma chave de API. A chave completa é mostrada uma única vez;
+// no banco ficam só o hash e o início (prefix), que identifica a chave nas listagens.
+func GenerateAPIKey() (key string, prefix string, err error) {
+	secret, err := GenerateOpaqueToken()
+	if err != nil {
+		return "", "", err
+	}
+	key = APIKeyPrefix + secret
+	return key, key[:len(APIKeyPrefix)+8], nil
+}
+
+// IsAPIKey indica se a credencial tem o formato de uma chave de API
+func IsAPIKey(credential string) bool {
+	return strings.HasPrefix(credential, APIKeyPrefix)
+}
@@ -0,0 +1,70 @@
+package auth
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Vetores da RFC 6238 (Apêndice B) para SHA1, truncados para 6 dígitos
+func TestTOTPCode_RFC6238Vectors(t *testing.T) {
+	secret := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+	vectors := map[int64]string{
+		59:         "287082",
+		1111111109: "081804",
+		1111111111: "050471",
+		1234567890: "005924",
+		2000000000: "279037",
+	}
+
+	for unix, expected := range vectors {
+		code, err := TOTPCode(secret, time.Unix(unix, 0))
+		require.NoError(t, err)
+		assert.Equal(t, expected, code, "instante %d", unix)
+	}
+}
+
+func TestValidateTOTP_AcceptsAdjacentWindowOnly(t *testing.T) {
+	secret, err := GenerateTOTPSecret()
+	require.NoError(t, err)
+
+	now := time.Now()
+	previous, _ := TOTPCode(secret, now.Add(-30*time.Second))
+	old, _ := TOTPCode(secret, now.Add(-5*time.Minute))
+
+	ok, step := ValidateTOTP(secret, previous, now)
+	assert.True(t, ok)
+	assert.Equal(t, totpStep(now)-1, step)
+
+	ok, _ = ValidateTOTP(secret, old, now)
+	assert.False(t, ok)
+
+	ok, _ = ValidateTOTP(secret, "12345", now)
+	assert.False(t, ok)
+}
+
+func TestTOTPProvisioningURI(t *testing.T) {
+	uri := TOTPProvisioningURI("Smart Gondola", "ana@loja.com", "JBSWY3DPEHPK3PXP")
+
+	assert.True(t, strings.HasPrefix(uri, "otpauth://totp/Smart%20Gondola:ana@loja.com?"))
+	assert.Contains(t, uri, "secret=JBSWY3DPEHPK3PXP")
+	assert.Contains(t, uri, "issuer=Smart+Gondola")
+}
+
+func TestGenerateRecoveryCodes_AreUnique(t *testing.T) {
+	codes, err := GenerateRecoveryCodes(10)
+	require.NoError(t, err)
+	require.Len(t, codes, 10)
+
+	seen := map[string]bool{}
+	for _, c := range codes {
+		assert.Len(t, c, 11)
+		assert.False(t, seen[c])
+		seen[c] = true
+	}
+}
@@ -0,0 +1,95 @@
+// Package breached confere senhas contra uma lista local de senhas vazadas.
+//
+// A lista segue o formato k-anonymity do Have I Been Pwned: o SHA-1 da senha (hex maiúsculo)
+// é dividido em um prefixo de 5 caracteres, que dá nome ao arquivo (<PREFIXO>.txt), e o sufixo,
+// que aparece dentro dele em linhas "SUFIXO:OCORRÊNCIAS". Cada consulta lê só o arquivo do prefixo,
+// e a senha nunca sai do servidor.
+package breached
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/config"
+)
+
+// Checker diz se uma senha aparece em vazamentos conhecidos
+type Checker interface {
+	IsBreached(ctx context.Context, password string) (bool, error)
+}
+
+// New usa a lista de BREACHED_PASSWORDS_DIR; sem diretório configurado a checagem fica desligada
+func New(cfg *config.Config) (Checker, error) {
+	if cfg.BreachedPasswordsDir == "" {
+		return Disabled{}, nil
+	}
+	return NewRangeDir(cfg.BreachedPasswordsDir)
+}
+
+// Disabled não recusa nenhuma senha
+type Disabled struct{}
+
+func (Disabled) IsBreached(context.Context, string) (bool, error) {
+	return false, nil
+}
+
+// RangeDir lê os arquivos de prefixo de um diretório local
+type RangeDir struct {
+	dir string
+}
+
+// NewRangeDir confere se o diretório existe
+func NewRangeDir(dir string) (*RangeDir, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("lista de senhas vazadas: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("lista de senhas vazadas: %s não é um diretório", dir)
+	}
+	return &RangeDir{dir: dir}, nil
+}
+
+// IsBreached procura o sufixo do hash no arquivo do prefixo (sem arquivo = prefixo sem vazamentos)
+func (d *RangeDir) IsBreached(ctx context.Context, password string) (bool, error) {
+	prefix, suffix := HashRange(password)
+
+	f, err := os.Open(filepath.Join(d.dir, prefix+".txt"))
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if err := ctx.Err(); err != nil {
+			return false, err
+		}
+		hashSuffix, count, found := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		if !found || !strings.EqualFold(hashSuffix, suffix) {
+			continue
+		}
+		// Linhas com contagem zero são preenchimento (padding) da lista, não vazamentos
+		n, err := strconv.Atoi(count)
+		return err == nil && n > 0, nil
+	}
+	return false, scanner.Err()
+}
+
+// HashRange devolve o prefixo (nome do arquivo) e o sufixo do SHA-1 da senha
+func HashRange(password string) (prefix, suffix string) {
+	sum := sha1.Sum([]byte(password))
+	h := strings.ToUpper(hex.EncodeToString(sum[:]))
+	return h[:5], h[5:]
+}
@@ -0,0 +1,48 @@
+package breached
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRangeDir_FindsBreachedPasswords(t *testing.T) {
+	dir := t.TempDir()
+	prefix, suffix := HashRange("senha123")
+	content := "0018A45C4D1DEF81644B54AB7F969B88D65:3\n" + suffix + ":4210\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, prefix+".txt"), []byte(content), 0o644))
+
+	checker, err := NewRangeDir(dir)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	breached, err := checker.IsBreached(ctx, "senha123")
+	require.NoError(t, err)
+	assert.True(t, breached)
+
+	// Prefixo sem arquivo e senha ausente do arquivo
+	breached, err = checker.IsBreached(ctx, "Uma-senha-longa-que-ninguem-usa-42")
+	require.NoError(t, err)
+	assert.False(t, breached)
+}
+
+func TestRangeDir_IgnoresPaddingEntries(t *testing.T) {
+	dir := t.TempDir()
+	prefix, suffix := HashRange("SenhaForte123!")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, prefix+".txt"), []byte(suffix+":0\r\n"), 0o644))
+
+	checker, err := NewRangeDir(dir)
+	require.NoError(t, err)
+	breached, err := checker.IsBreached(context.Background(), "SenhaForte123!")
+	require.NoError(t, err)
+	assert.False(t, breached)
+}
+
+func TestNewRangeDir_RequiresDirectory(t *testing.T) {
+	_, err := NewRangeDir(filepath.Join(t.TempDir(), "nao-existe"))
+	assert.Error(t, err)
+}
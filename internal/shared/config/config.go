@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -21,7 +22,10 @@ type Config struct {
 	ServerWriteTimeout time.Duration
 	ServerIdleTimeout  time.Duration
 	LogFormat          string
-	BaseURL            string // Importante para montar URLs de imagens (Avatar)
+	BaseURL            string   // Importante para montar URLs de imagens (Avatar)
+	AppURL             string   // Frontend: base dos links enviados por email
+	TrustProxyHeaders  bool     // Atrás de load balancer: usa X-Forwarded-For/X-Real-IP como IP do cliente
+	TrustedProxies     []string // IPs/CIDRs dos proxies internos além do que conecta na API (pulados no X-Forwarded-For)
 
 	// --- Database ---
 	DBHost string
@@ -31,10 +35,41 @@ type Config struct {
 	DBName string
 
 	// --- Security ---
-	JWTSecret         string
+	JWTKeysDir        string // Diretório com as chaves PEM (nome do arquivo = kid); vazio = chave efêmera (só fora de produção)
+	JWTSigningKeyID   string // kid da chave privada que assina os tokens novos
 	JWTExpiration     time.Duration
+	JWTIssuer         string        // "iss" emitido e exigido na validação
+	JWTAudience       string        // "aud" emitido e exigido na validação
+	JWTLeeway         time.Duration // Tolerância de relógio em exp/nbf/iat
 	RefreshExpiration time.Duration
 
+	PasswordResetExpiration     time.Duration
+	EmailVerificationExpiration time.Duration
+	InviteExpiration            time.Duration
+	ImpersonationTTL            time.Duration // Validade do token de impersonação (suporte)
+	SSORedirectURL              string        // Frontend: para onde o provedor OIDC devolve o code
+	BreachedPasswordsDir        string        // Lista local de senhas vazadas (arquivos de prefixo SHA-1); vazio = checagem desligada
+
+	// Hash de senha: o algoritmo vale para as senhas novas; hashes antigos são refeitos no login
+	PasswordHashAlgorithm string // 'argon2id', 'bcrypt'
+	BcryptCost            int
+	Argon2Memory          int // KiB
+	Argon2Iterations      int
+	Argon2Parallelism     int
+
+	// --- Planos ---
+	TrialDuration      time.Duration // Período de teste do plano pro no cadastro
+	TrialSweepInterval time.Duration // De quanto em quanto tempo os testes vencidos são rebaixados para o free e as trocas agendadas aplicadas
+
+	// --- Mail ---
+	MailDriver    string // 'log' (dev/testes), 'smtp'
+	MailFrom      string
+	MailOutboxDir string // Driver 'log': se preenchido, grava cada email como .eml
+	SMTPHost      string
+	SMTPPort      string
+	SMTPUser      string
+	SMTPPass      string
+
 	// --- Storage (Para Avatars e Imagens de Produtos) ---
 	StorageDriver string // 'local', 's3'
 	AWSBucket     string
@@ -58,6 +93,9 @@ func Get() *Config {
 			ServerIdleTimeout:  getEnvDuration("SERVER_IDLE_TIMEOUT", 120*time.Second),
 			LogFormat:          getEnv("LOG_FORMAT", "json"),
 			BaseURL:            getEnv("BASE_URL", "http://localhost:8080"),
+			AppURL:             getEnv("APP_URL", "http://localhost:3000"),
+			TrustProxyHeaders:  getEnvBool("TRUST_PROXY_HEADERS", false),
+			TrustedProxies:     getEnvList("TRUSTED_PROXIES"),
 
 			DBHost: getEnv("DB_HOST", "127.0.0.1"),
 			DBPort: getEnv("DB_PORT", "5432"),
@@ -65,10 +103,40 @@ func Get() *Config {
 			DBPass: getEnv("DB_PASS", "postgres"),
 			DBName: getEnv("DB_NAME", "smartgondola"),
 
-			JWTSecret:         getEnv("JWT_SECRET", "default_secret"),
-			JWTExpiration:     time.Hour * 24,      // 1 dia (Access Token)
+			JWTKeysDir:        getEnv("JWT_KEYS_DIR", ""),
+			JWTSigningKeyID:   getEnv("JWT_SIGNING_KEY_ID", ""),
+			JWTExpiration:     getEnvDuration("JWT_EXPIRATION", 24*time.Hour), // 1 dia (Access Token)
+			JWTIssuer:         getEnv("JWT_ISSUER", "smart-gondola-api"),
+			JWTAudience:       getEnv("JWT_AUDIENCE", "smart-gondola-api"),
+			JWTLeeway:         getEnvDuration("JWT_LEEWAY", 30*time.Second),
 			RefreshExpiration: time.Hour * 24 * 30, // 30 dias (Mobile não desloga fácil)
 
+			PasswordResetExpiration:     getEnvDuration("PASSWORD_RESET_EXPIRATION", time.Hour),
+			EmailVerificationExpiration: getEnvDuration("EMAIL_VERIFICATION_EXPIRATION", 48*time.Hour),
+			InviteExpiration:            getEnvDuration("INVITE_EXPIRATION", 7*24*time.Hour),
+			ImpersonationTTL:            getEnvDuration("IMPERSONATION_TTL", 30*time.Minute),
+			SSORedirectURL:              getEnv("SSO_REDIRECT_URL", getEnv("APP_URL", "http://localhost:3000")+"/auth/sso/callback"),
+			BreachedPasswordsDir:        getEnv("BREACHED_PASSWORDS_DIR", ""),
+
+			// Padrões recomendados pela OWASP
+			PasswordHashAlgorithm: getEnv("PASSWORD_HASH_ALGORITHM", "argon2id"),
+			BcryptCost:            getEnvInt("BCRYPT_COST", 12),
+			Argon2Memory:          getEnvInt("ARGON2_MEMORY_KB", 19*1024),
+			Argon2Iterations:      getEnvInt("ARGON2_ITERATIONS", 2),
+			Argon2Parallelism:     getEnvInt("ARGON2_PARALLELISM", 1),
+
+			TrialDuration:      getEnvDuration("TRIAL_DURATION", 14*24*time.Hour),
+			TrialSweepInterval: getEnvDuration("TRIAL_SWEEP_INTERVAL", time.Hour),
+
+			// Mail Defaults (Log para dev)
+			MailDriver:    getEnv("MAIL_DRIVER", "log"),
+			MailFrom:      getEnv("MAIL_FROM", "Smart Gondola <no-reply@smartgondola.com>"),
+			MailOutboxDir: getEnv("MAIL_OUTBOX_DIR", ""),
+			SMTPHost:      getEnv("SMTP_HOST", "localhost"),
+			SMTPPort:      getEnv("SMTP_PORT", "587"),
+			SMTPUser:      getEnv("SMTP_USER", ""),
+			SMTPPass:      getEnv("SMTP_PASS", ""),
+
 			// Storage Defaults (Local para dev)
 			StorageDriver: getEnv("STORAGE_DRIVER", "local"),
 			AWSBucket:     getEnv("AWS_BUCKET", ""),
@@ -90,6 +158,41 @@ func getEnv(key, fallback string) string {
 	return fallback
 }
 
+// getEnvList lê uma lista separada por vírgulas (itens vazios são ignorados)
+func getEnvList(key string) []string {
+	var list []string
+	for _, item := range strings.Split(os.Getenv(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+func getEnvBool(key string, fallback bool) bool {
+	value, exists := os.LookupEnv(key)
+	if !exists || value == "" {
+		return fallback
+	}
+
+	if b, err := strconv.ParseBool(value); err == nil {
+		return b
+	}
+	return fallback
+}
+
+func getEnvInt(key string, fallback int) int {
+	value, exists := os.LookupEnv(key)
+	if !exists || value == "" {
+		return fallback
+	}
+
+	if n, err := strconv.Atoi(value); err == nil {
+		return n
+	}
+	return fallback
+}
+
 func getEnvDuration(key string, fallback time.Duration) time.Duration {
 	value, exists := os.LookupEnv(key)
 	if !exists || value == "" {
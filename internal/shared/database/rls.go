@@ -0,0 +1,61 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Papéis do Postgres usados pela Row-Level Security (criados na migration 000011)
+const (
+	TenantRole = "smart_gondola_app"    // Sujeito às políticas: só enxerga a organização da sessão
+	BypassRole = "smart_gondola_bypass" // BYPASSRLS: equipe da plataforma e jobs administrativos
+)
+
+type connKey struct{}
+
+// WithTenantSession reserva uma conexão do pool para a requisição e a prepara para a RLS:
+// assume o papel restrito e define "app.current_org_id". Com bypass, assume o papel sem restrição.
+// Repositórios que usam Conn(ctx, db) passam a rodar nessa conexão.
+// A função devolvida limpa a sessão e devolve a conexão ao pool; deve ser chamada sempre.
+func WithTenantSession(ctx context.Context, db *sql.DB, orgID uuid.UUID, bypass bool) (context.Context, func(), error) {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("erro ao reservar conexão: %w", err)
+	}
+
+	release := func() {
+		// O context da requisição pode já ter sido cancelado: a limpeza usa o seu próprio
+		cleanupCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		for _, stmt := range []string{`RESET ROLE`, `RESET app.current_org_id`} {
+			if _, err := conn.ExecContext(cleanupCtx, stmt); err != nil {
+				// Conexão em estado desconhecido não volta para o pool
+				_ = conn.Raw(func(any) error { return driver.ErrBadConn })
+				break
+			}
+		}
+		_ = conn.Close()
+	}
+
+	role := TenantRole
+	if bypass {
+		role = BypassRole
+	}
+
+	if _, err := conn.ExecContext(ctx, `SELECT set_config('app.current_org_id', $1, false)`, orgID.String()); err != nil {
+		release()
+		return nil, nil, fmt.Errorf("erro ao definir organização da sessão: %w", err)
+	}
+	if _, err := conn.ExecContext(ctx, `SET ROLE `+role); err != nil {
+		release()
+		return nil, nil, fmt.Errorf("erro ao assumir papel %s: %w", role, err)
+	}
+
+	return context.WithValue(ctx, connKey{}, conn), release, nil
+}
@@ -0,0 +1,80 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// DBTX é o que os repositórios precisam para executar SQL: atendido por *sql.DB e *sql.Tx
+type DBTX interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// Transactor executa várias operações de repositórios diferentes de forma atômica
+type Transactor interface {
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+type txKey struct{}
+
+// TxManager guarda a transação no context: repositórios que usam Conn(ctx, db) participam dela
+type TxManager struct {
+	db *sql.DB
+}
+
+// NewTxManager cria o gerenciador de transações
+func NewTxManager(db *sql.DB) *TxManager {
+	return &TxManager{db: db}
+}
+
+// WithinTransaction abre uma transação, executa fn e faz commit (ou rollback em erro/panic).
+// Chamadas aninhadas reaproveitam a transação já aberta.
+func (m *TxManager) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	if _, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return fn(ctx)
+	}
+
+	// Dentro de uma sessão de tenant (RLS) a transação precisa usar a mesma conexão
+	var tx *sql.Tx
+	if conn, ok := ctx.Value(connKey{}).(*sql.Conn); ok {
+		tx, err = conn.BeginTx(ctx, nil)
+	} else {
+		tx, err = m.db.BeginTx(ctx, nil)
+	}
+	if err != nil {
+		return fmt.Errorf("erro ao iniciar transação: %w", err)
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback()
+			panic(p)
+		}
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	if err = fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("erro ao confirmar transação: %w", err)
+	}
+	return nil
+}
+
+// Conn devolve a transação do context, se houver, a conexão da sessão de tenant (RLS) ou o pool
+func Conn(ctx context.Context, db *sql.DB) DBTX {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return tx
+	}
+	if conn, ok := ctx.Value(connKey{}).(*sql.Conn); ok {
+		return conn
+	}
+	return db
+}
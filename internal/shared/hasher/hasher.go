@@ -0,0 +1,143 @@
+// Package hasher gera e confere os hashes de senha.
+//
+// Senhas novas usam o algoritmo configurado (PASSWORD_HASH_ALGORITHM); hashes de qualquer algoritmo
+// conhecido continuam sendo aceitos. Os parâmetros ficam codificados no próprio hash, então dá para
+// aumentar o custo com o tempo: NeedsRehash aponta os hashes antigos, que são refeitos no login.
+package hasher
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/config"
+)
+
+// Algorithm é uma implementação de hash de senha
+type Algorithm interface {
+	Name() string
+	Hash(password string) (string, error)
+	// Owns indica se o hash codificado é deste algoritmo
+	Owns(encoded string) bool
+	// Verify confere a senha com um hash deste algoritmo
+	Verify(encoded, password string) (bool, error)
+	// Outdated indica se o hash foi gerado com parâmetros diferentes dos configurados
+	Outdated(encoded string) bool
+}
+
+var ErrUnknownHash = errors.New("formato de hash de senha desconhecido")
+
+// Hasher gera hashes com o algoritmo atual e confere hashes de todos os conhecidos
+type Hasher struct {
+	current Algorithm
+	known   []Algorithm
+}
+
+// NewHasher usa "current" para as senhas novas; "legacy" são os algoritmos ainda aceitos na conferência
+func NewHasher(current Algorithm, legacy ...Algorithm) *Hasher {
+	return &Hasher{current: current, known: append([]Algorithm{current}, legacy...)}
+}
+
+// New monta o Hasher a partir da configuração
+func New(cfg *config.Config) (*Hasher, error) {
+	bc, err := NewBcrypt(cfg.BcryptCost)
+	if err != nil {
+		return nil, err
+	}
+	a2, err := NewArgon2id(Argon2Params{
+		Memory:      uint32(cfg.Argon2Memory),
+		Iterations:  uint32(cfg.Argon2Iterations),
+		Parallelism: uint8(cfg.Argon2Parallelism),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	switch cfg.PasswordHashAlgorithm {
+	case AlgorithmArgon2id:
+		return NewHasher(a2, bc), nil
+	case AlgorithmBcrypt:
+		return NewHasher(bc, a2), nil
+	}
+	return nil, fmt.Errorf("algoritmo de hash de senha desconhecido: %q", cfg.PasswordHashAlgorithm)
+}
+
+func (h *Hasher) Hash(password string) (string, error) {
+	return h.current.Hash(password)
+}
+
+// Verify confere a senha com o hash salvo. Hash malformado ou de algoritmo desconhecido nunca confere.
+func (h *Hasher) Verify(encoded, password string) bool {
+	alg := h.algorithm(encoded)
+	if alg == nil {
+		return false
+	}
+	ok, err := alg.Verify(encoded, password)
+	return err == nil && ok
+}
+
+// NeedsRehash indica se o hash deve ser refeito com o algoritmo e os parâmetros atuais
+func (h *Hasher) NeedsRehash(encoded string) bool {
+	if !h.current.Owns(encoded) {
+		return true
+	}
+	return h.current.Outdated(encoded)
+}
+
+func (h *Hasher) algorithm(encoded string) Algorithm {
+	for _, alg := range h.known {
+		if alg.Owns(encoded) {
+			return alg
+		}
+	}
+	return nil
+}
+
+var (
+	defaultOnce   sync.Once
+	defaultHasher *Hasher
+	errDefault    error
+)
+
+// Init monta o Hasher padrão a partir da configuração. Chamado na inicialização para falhar cedo;
+// sem ele, o primeiro uso monta o Hasher sob demanda.
+func Init(cfg *config.Config) error {
+	defaultOnce.Do(func() {
+		defaultHasher, errDefault = New(cfg)
+	})
+	return errDefault
+}
+
+func std() (*Hasher, error) {
+	if err := Init(config.Get()); err != nil {
+		return nil, err
+	}
+	return defaultHasher, nil
+}
+
+// Hash gera o hash da senha com o Hasher padrão
+func Hash(password string) (string, error) {
+	h, err := std()
+	if err != nil {
+		return "", err
+	}
+	return h.Hash(password)
+}
+
+// Verify confere a senha com o Hasher padrão
+func Verify(encoded, password string) bool {
+	h, err := std()
+	if err != nil {
+		return false
+	}
+	return h.Verify(encoded, password)
+}
+
+// NeedsRehash consulta o Hasher padrão
+func NeedsRehash(encoded string) bool {
+	h, err := std()
+	if err != nil {
+		return false
+	}
+	return h.NeedsRehash(encoded)
+}
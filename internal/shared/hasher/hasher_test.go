@@ -0,0 +1,111 @@
+package hasher
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Parâmetros baixos: os testes conferem o formato, não o custo
+var testArgon2 = Argon2Params{Memory: 64, Iterations: 1, Parallelism: 1}
+
+func newTestHasher(t *testing.T, current string, cost int, params Argon2Params) *Hasher {
+	t.Helper()
+	bc, err := NewBcrypt(cost)
+	require.NoError(t, err)
+	a2, err := NewArgon2id(params)
+	require.NoError(t, err)
+	if current == AlgorithmBcrypt {
+		return NewHasher(bc, a2)
+	}
+	return NewHasher(a2, bc)
+}
+
+func TestHasher_HashAndVerifyBothAlgorithms(t *testing.T) {
+	for _, alg := range []string{AlgorithmArgon2id, AlgorithmBcrypt} {
+		t.Run(alg, func(t *testing.T) {
+			h := newTestHasher(t, alg, 4, testArgon2)
+
+			hash, err := h.Hash("SenhaForte123!")
+			require.NoError(t, err)
+			assert.True(t, h.Verify(hash, "SenhaForte123!"))
+			assert.False(t, h.Verify(hash, "SenhaForte123?"))
+			assert.False(t, h.NeedsRehash(hash))
+
+			// Salt aleatório: a mesma senha nunca gera o mesmo hash
+			again, err := h.Hash("SenhaForte123!")
+			require.NoError(t, err)
+			assert.NotEqual(t, hash, again)
+		})
+	}
+}
+
+func TestHasher_EncodesArgon2Parameters(t *testing.T) {
+	h := newTestHasher(t, AlgorithmArgon2id, 4, testArgon2)
+
+	hash, err := h.Hash("SenhaForte123!")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(hash, "$argon2id$v=19$m=64,t=1,p=1$"), hash)
+}
+
+func TestHasher_NeedsRehashWhenAlgorithmOrCostChanges(t *testing.T) {
+	oldBcrypt := newTestHasher(t, AlgorithmBcrypt, 4, testArgon2)
+	bcryptHash, err := oldBcrypt.Hash("SenhaForte123!")
+	require.NoError(t, err)
+
+	// Custo do bcrypt aumentado
+	stronger := newTestHasher(t, AlgorithmBcrypt, 5, testArgon2)
+	assert.True(t, stronger.NeedsRehash(bcryptHash))
+	assert.True(t, stronger.Verify(bcryptHash, "SenhaForte123!"), "hash antigo continua válido")
+
+	// Troca de algoritmo: o bcrypt ainda confere, mas deve virar argon2id
+	argon := newTestHasher(t, AlgorithmArgon2id, 4, testArgon2)
+	assert.True(t, argon.NeedsRehash(bcryptHash))
+	assert.True(t, argon.Verify(bcryptHash, "SenhaForte123!"))
+
+	// Parâmetros do argon2id aumentados
+	argonHash, err := argon.Hash("SenhaForte123!")
+	require.NoError(t, err)
+	moreMemory := newTestHasher(t, AlgorithmArgon2id, 4, Argon2Params{Memory: 128, Iterations: 1, Parallelism: 1})
+	assert.True(t, moreMemory.NeedsRehash(argonHash))
+	assert.True(t, moreMemory.Verify(argonHash, "SenhaForte123!"))
+}
+
+func TestHasher_RejectsMalformedHashes(t *testing.T) {
+	h := newTestHasher(t, AlgorithmArgon2id, 4, testArgon2)
+
+	for _, hash := range []string{
+		"",
+		"senha-em-texto",
+		"$argon2id$v=19$m=64,t=1,p=1$semhash",
+		"$argon2id$v=18$m=64,t=1,p=1$c2FsdHNhbHQ$a2V5",
+		"$argon2id$v=19$m=64,t=0,p=1$c2FsdHNhbHQ$a2V5",
+		"$2a$04$curto",
+	} {
+		assert.False(t, h.Verify(hash, "senha-em-texto"), hash)
+		assert.True(t, h.NeedsRehash(hash), hash)
+	}
+}
+
+func TestNew_ValidatesConfiguration(t *testing.T) {
+	_, err := NewBcrypt(3)
+	assert.Error(t, err)
+	_, err = NewArgon2id(Argon2Params{Memory: 4, Iterations: 1, Parallelism: 1})
+	assert.Error(t, err)
+	_, err = NewArgon2id(Argon2Params{Memory: 64, Iterations: 0, Parallelism: 1})
+	assert.Error(t, err)
+
+	cfg := &config.Config{PasswordHashAlgorithm: "md5", BcryptCost: 4, Argon2Memory: 64, Argon2Iterations: 1, Argon2Parallelism: 1}
+	_, err = New(cfg)
+	assert.Error(t, err)
+
+	cfg.PasswordHashAlgorithm = AlgorithmBcrypt
+	h, err := New(cfg)
+	require.NoError(t, err)
+	hash, err := h.Hash("SenhaForte123!")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(hash, "$2a$04$"), hash)
+}
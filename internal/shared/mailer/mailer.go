@@ -0,0 +1,39 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/config"
+)
+
+// Message é um email simples em texto puro
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer abstrai o envio de emails (SMTP em produção, log/arquivo em dev e testes)
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+var ErrInvalidHeader = errors.New("cabeçalho de email inválido")
+
+// New escolhe a implementação a partir do MAIL_DRIVER ('smtp' ou 'log')
+func New(cfg *config.Config) Mailer {
+	if strings.ToLower(cfg.MailDriver) == "smtp" {
+		return NewSMTPMailer(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUser, cfg.SMTPPass, cfg.MailFrom)
+	}
+	return NewLogMailer(cfg.MailOutboxDir)
+}
+
+// validate impede quebra de linha nos cabeçalhos (header injection)
+func (m Message) validate() error {
+	if m.To == "" || strings.ContainsAny(m.To, "\r\n") || strings.ContainsAny(m.Subject, "\r\n") {
+		return ErrInvalidHeader
+	}
+	return nil
+}
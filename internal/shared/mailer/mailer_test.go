@@ -0,0 +1,37 @@
+package mailer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLogMailer_WritesOutboxFile(t *testing.T) {
+	dir := t.TempDir()
+	m := NewLogMailer(dir)
+
+	err := m.Send(context.Background(), Message{To: "joao@smartgondola.com", Subject: "Redefinição de senha", Body: "link: https://app/x"})
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.eml"))
+	if len(files) != 1 {
+		t.Fatalf("esperava 1 arquivo, encontrou %d", len(files))
+	}
+	content, _ := os.ReadFile(files[0])
+	if !strings.Contains(string(content), "To: joao@smartgondola.com") || !strings.Contains(string(content), "https://app/x") {
+		t.Errorf("conteúdo inesperado: %s", content)
+	}
+}
+
+func TestMessage_RejectsHeaderInjection(t *testing.T) {
+	m := NewLogMailer("")
+
+	err := m.Send(context.Background(), Message{To: "a@b.com\r\nBcc: vitima@x.com", Subject: "oi"})
+	if err != ErrInvalidHeader {
+		t.Errorf("esperava ErrInvalidHeader, obteve %v", err)
+	}
+}
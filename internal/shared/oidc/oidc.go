@@ -0,0 +1,360 @@
+// Package oidc implementa o lado "cliente" do OpenID Connect (login corporativo dos tenants):
+// discovery, fluxo authorization code + PKCE e validação do ID token pelas chaves do provedor.
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	// discoveryTTL é por quanto tempo o documento de discovery fica em cache
+	discoveryTTL = time.Hour
+	// jwksRefetchInterval é o intervalo mínimo entre leituras do JWKS: "kid" desconhecido
+	// dentro dele é recusado sem ir ao provedor (tokens forjados não viram tráfego para o IdP)
+	jwksRefetchInterval = time.Minute
+)
+
+var (
+	ErrInvalidIDToken = errors.New("ID token inválido")
+	ErrNonceMismatch  = errors.New("nonce do ID token não confere")
+)
+
+// Provider é um provedor OIDC já descoberto
+type Provider struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+
+	fetchedAt time.Time
+
+	mu            sync.Mutex
+	keys          map[string]crypto.PublicKey
+	keysFetchedAt time.Time
+	keysFetching  chan struct{} // Fechado ao terminar a leitura do JWKS em andamento
+}
+
+// IDToken são as claims do ID token que usamos para identificar a pessoa
+type IDToken struct {
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"email_verified"`
+	Name          string `json:"name"`
+	Nonce         string `json:"nonce"`
+	jwt.RegisteredClaims
+}
+
+// Client conversa com os provedores (com cache de discovery e chaves por issuer)
+type Client struct {
+	http *http.Client
+
+	mu        sync.Mutex
+	providers map[string]*Provider
+}
+
+// NewClient cria o cliente OIDC. httpClient nil usa um cliente com timeout de 10s.
+func NewClient(httpClient *http.Client) *Client {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &Client{http: httpClient, providers: map[string]*Provider{}}
+}
+
+// Discover lê o /.well-known/openid-configuration do issuer
+func (c *Client) Discover(ctx context.Context, issuer string) (*Provider, error) {
+	issuer = strings.TrimSuffix(issuer, "/")
+
+	c.mu.Lock()
+	cached, ok := c.providers[issuer]
+	c.mu.Unlock()
+	if ok && time.Since(cached.fetchedAt) < discoveryTTL {
+		return cached, nil
+	}
+
+	var p Provider
+	if err := c.getJSON(ctx, issuer+"/.well-known/openid-configuration", &p); err != nil {
+		return nil, fmt.Errorf("erro no discovery do provedor: %w", err)
+	}
+	// O documento precisa ser do próprio issuer (OIDC Discovery, seção 4.3)
+	if strings.TrimSuffix(p.Issuer, "/") != issuer {
+		return nil, fmt.Errorf("issuer do discovery (%s) difere do configurado", p.Issuer)
+	}
+	if p.AuthorizationEndpoint == "" || p.TokenEndpoint == "" || p.JWKSURI == "" {
+		return nil, errors.New("discovery do provedor incompleto")
+	}
+	p.fetchedAt = time.Now()
+
+	c.mu.Lock()
+	c.providers[issuer] = &p
+	c.mu.Unlock()
+	return &p, nil
+}
+
+// AuthCodeURL monta a URL para onde o navegador é enviado (authorization code + PKCE S256)
+func (p *Provider) AuthCodeURL(clientID, redirectURI, state, nonce, codeChallenge string) string {
+	q := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {clientID},
+		"redirect_uri":          {redirectURI},
+		"scope":                 {"openid email profile"},
+		"state":                 {state},
+		"nonce":                 {nonce},
+		"code_challenge":        {codeChallenge},
+		"code_challenge_method": {"S256"},
+	}
+	sep := "?"
+	if strings.Contains(p.AuthorizationEndpoint, "?") {
+		sep = "&"
+	}
+	return p.AuthorizationEndpoint + sep + q.Encode()
+}
+
+// Exchange troca o code pelo ID token (autenticação client_secret_basic)
+func (c *Client) Exchange(ctx context.Context, p *Provider, clientID, clientSecret, code, redirectURI, codeVerifier string) (string, error) {
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {redirectURI},
+		"code_verifier": {codeVerifier},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(clientID), url.QueryEscape(clientSecret))
+
+	res, err := c.http.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("erro ao trocar o code: %w", err)
+	}
+	defer res.Body.Close()
+
+	var body struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.NewDecoder(io.LimitReader(res.Body, 1<<20)).Decode(&body); err != nil {
+		return "", fmt.Errorf("resposta inválida do provedor: %w", err)
+	}
+	if res.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("provedor recusou o code: %s %s", body.Error, body.ErrorDescription)
+	}
+	if body.IDToken == "" {
+		return "", errors.New("provedor não devolveu id_token")
+	}
+	return body.IDToken, nil
+}
+
+// VerifyIDToken valida assinatura (chaves do provedor), issuer, audiência, validade e nonce
+func (c *Client) VerifyIDToken(ctx context.Context, p *Provider, rawIDToken, clientID, nonce string) (*IDToken, error) {
+	claims := &IDToken{}
+	_, err := jwt.ParseWithClaims(rawIDToken, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return c.providerKey(ctx, p, kid)
+	},
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "ES256", "ES384", "ES512", "EdDSA"}),
+		jwt.WithIssuer(p.Issuer),
+		jwt.WithAudience(clientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(time.Minute),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidIDToken, err)
+	}
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("%w: sem 'sub'", ErrInvalidIDToken)
+	}
+	if claims.Nonce != nonce {
+		return nil, ErrNonceMismatch
+	}
+	return claims, nil
+}
+
+// providerKey devolve a chave do "kid"; kid desconhecido força uma nova leitura do JWKS (rotação no provedor),
+// no máximo uma por jwksRefetchInterval. A leitura acontece fora do lock: quem chega durante ela espera o resultado.
+func (c *Client) providerKey(ctx context.Context, p *Provider, kid string) (crypto.PublicKey, error) {
+	p.mu.Lock()
+	if key, ok := p.lookupKey(kid); ok {
+		p.mu.Unlock()
+		return key, nil
+	}
+
+	fetching := p.keysFetching
+	if fetching == nil {
+		if time.Since(p.keysFetchedAt) < jwksRefetchInterval {
+			p.mu.Unlock()
+			return nil, fmt.Errorf("chave %q não encontrada no provedor", kid)
+		}
+		// O intervalo conta mesmo se a leitura falhar: provedor fora do ar não é consultado a cada login
+		fetching = make(chan struct{})
+		p.keysFetching, p.keysFetchedAt = fetching, time.Now()
+		p.mu.Unlock()
+
+		keys, err := c.fetchKeys(context.WithoutCancel(ctx), p.JWKSURI)
+
+		p.mu.Lock()
+		if err == nil {
+			p.keys = keys
+		}
+		p.keysFetching = nil
+		close(fetching)
+		p.mu.Unlock()
+		if err != nil {
+			return nil, fmt.Errorf("erro ao buscar chaves do provedor: %w", err)
+		}
+	} else {
+		p.mu.Unlock()
+		select {
+		case <-fetching:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if key, ok := p.lookupKey(kid); ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("chave %q não encontrada no provedor", kid)
+}
+
+// fetchKeys lê as chaves de assinatura publicadas no JWKS
+func (c *Client) fetchKeys(ctx context.Context, jwksURI string) (map[string]crypto.PublicKey, error) {
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := c.getJSON(ctx, jwksURI, &set); err != nil {
+		return nil, err
+	}
+	keys := map[string]crypto.PublicKey{}
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		if key, err := k.publicKey(); err == nil {
+			keys[k.Kid] = key
+		}
+	}
+	return keys, nil
+}
+
+// lookupKey aceita token sem "kid" apenas quando o provedor publica uma única chave
+func (p *Provider) lookupKey(kid string) (crypto.PublicKey, bool) {
+	if kid == "" && len(p.keys) == 1 {
+		for _, key := range p.keys {
+			return key, true
+		}
+	}
+	key, ok := p.keys[kid]
+	return key, ok
+}
+
+func (c *Client) getJSON(ctx context.Context, endpoint string, dst interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	res, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("status %d em %s", res.StatusCode, endpoint)
+	}
+	return json.NewDecoder(io.LimitReader(res.Body, 1<<20)).Decode(dst)
+}
+
+// jwk é uma chave pública do provedor (RFC 7517)
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func (k jwk) publicKey() (crypto.PublicKey, error) {
+	decode := base64.RawURLEncoding.DecodeString
+
+	switch k.Kty {
+	case "RSA":
+		n, err := decode(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decode(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "EC":
+		curves := map[string]elliptic.Curve{"P-256": elliptic.P256(), "P-384": elliptic.P384(), "P-521": elliptic.P521()}
+		curve, ok := curves[k.Crv]
+		if !ok {
+			return nil, fmt.Errorf("curva %q não suportada", k.Crv)
+		}
+		x, err := decode(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decode(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("curva %q não suportada", k.Crv)
+		}
+		x, err := decode(k.X)
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("chave Ed25519 inválida")
+		}
+		return ed25519.PublicKey(x), nil
+	}
+	return nil, fmt.Errorf("tipo de chave %q não suportado", k.Kty)
+}
+
+// NewPKCE gera o code_verifier (guardado no servidor) e o code_challenge S256 (enviado ao provedor)
+func NewPKCE() (verifier, challenge string, err error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	verifier = base64.RawURLEncoding.EncodeToString(b)
+	sum := sha256.Sum256([]byte(verifier))
+	return verifier, base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
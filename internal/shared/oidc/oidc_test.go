@@ -0,0 +1,118 @@
+package oidc_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/oidc"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/oidc/oidctest"
+)
+
+const redirectURI = "http://localhost:3000/auth/sso/callback"
+
+func TestAuthorizationCodeFlowWithPKCE(t *testing.T) {
+	idp := oidctest.NewProvider("smart-gondola", "segredo")
+	defer idp.Close()
+
+	ctx := context.Background()
+	client := oidc.NewClient(nil)
+	provider, err := client.Discover(ctx, idp.Issuer())
+	require.NoError(t, err)
+
+	verifier, challenge, err := oidc.NewPKCE()
+	require.NoError(t, err)
+	authURL := provider.AuthCodeURL(idp.ClientID, redirectURI, "estado", "nonce-1", challenge)
+
+	code, state, err := idp.Authorize(authURL, oidctest.Identity{Subject: "abc", Email: "maria@empresa.com", EmailVerified: true})
+	require.NoError(t, err)
+	assert.Equal(t, "estado", state)
+
+	rawIDToken, err := client.Exchange(ctx, provider, idp.ClientID, idp.ClientSecret, code, redirectURI, verifier)
+	require.NoError(t, err)
+
+	idToken, err := client.VerifyIDToken(ctx, provider, rawIDToken, idp.ClientID, "nonce-1")
+	require.NoError(t, err)
+	assert.Equal(t, "abc", idToken.Subject)
+	assert.Equal(t, "maria@empresa.com", idToken.Email)
+	assert.True(t, idToken.EmailVerified)
+
+	// Nonce de outro login, audiência de outro cliente
+	_, err = client.VerifyIDToken(ctx, provider, rawIDToken, idp.ClientID, "nonce-2")
+	assert.ErrorIs(t, err, oidc.ErrNonceMismatch)
+	_, err = client.VerifyIDToken(ctx, provider, rawIDToken, "outro-cliente", "nonce-1")
+	assert.ErrorIs(t, err, oidc.ErrInvalidIDToken)
+
+	// O code vale uma vez
+	_, err = client.Exchange(ctx, provider, idp.ClientID, idp.ClientSecret, code, redirectURI, verifier)
+	assert.Error(t, err)
+}
+
+func TestExchange_RejectsWrongVerifierAndSecret(t *testing.T) {
+	idp := oidctest.NewProvider("smart-gondola", "segredo")
+	defer idp.Close()
+
+	ctx := context.Background()
+	client := oidc.NewClient(nil)
+	provider, err := client.Discover(ctx, idp.Issuer())
+	require.NoError(t, err)
+
+	_, challenge, err := oidc.NewPKCE()
+	require.NoError(t, err)
+	otherVerifier, _, err := oidc.NewPKCE()
+	require.NoError(t, err)
+
+	authURL := provider.AuthCodeURL(idp.ClientID, redirectURI, "estado", "nonce", challenge)
+	code, _, err := idp.Authorize(authURL, oidctest.Identity{Subject: "abc"})
+	require.NoError(t, err)
+	_, err = client.Exchange(ctx, provider, idp.ClientID, idp.ClientSecret, code, redirectURI, otherVerifier)
+	assert.Error(t, err, "code_verifier de outro login")
+
+	code, _, err = idp.Authorize(authURL, oidctest.Identity{Subject: "abc"})
+	require.NoError(t, err)
+	_, err = client.Exchange(ctx, provider, idp.ClientID, "segredo-errado", code, redirectURI, otherVerifier)
+	assert.Error(t, err)
+}
+
+func TestVerifyIDToken_UnknownKidRefetchesKeysAtMostOncePerInterval(t *testing.T) {
+	idp := oidctest.NewProvider("smart-gondola", "segredo")
+	defer idp.Close()
+
+	ctx := context.Background()
+	client := oidc.NewClient(nil)
+	provider, err := client.Discover(ctx, idp.Issuer())
+	require.NoError(t, err)
+
+	// Tokens forjados com "kid" aleatório: só o primeiro vai ao provedor
+	forged, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	for _, kid := range []string{"forjada-1", "forjada-2", "forjada-1"} {
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+			"iss": idp.Issuer(), "sub": "abc", "aud": idp.ClientID, "exp": time.Now().Add(time.Minute).Unix(),
+		})
+		token.Header["kid"] = kid
+		raw, err := token.SignedString(forged)
+		require.NoError(t, err)
+
+		_, err = client.VerifyIDToken(ctx, provider, raw, idp.ClientID, "")
+		assert.ErrorIs(t, err, oidc.ErrInvalidIDToken)
+	}
+	assert.Equal(t, 1, idp.JWKSRequests())
+
+	// A chave já conhecida continua valendo sem nova leitura
+	verifier, challenge, err := oidc.NewPKCE()
+	require.NoError(t, err)
+	code, _, err := idp.Authorize(provider.AuthCodeURL(idp.ClientID, redirectURI, "estado", "nonce-1", challenge), oidctest.Identity{Subject: "abc"})
+	require.NoError(t, err)
+	rawIDToken, err := client.Exchange(ctx, provider, idp.ClientID, idp.ClientSecret, code, redirectURI, verifier)
+	require.NoError(t, err)
+	_, err = client.VerifyIDToken(ctx, provider, rawIDToken, idp.ClientID, "nonce-1")
+	require.NoError(t, err)
+	assert.Equal(t, 1, idp.JWKSRequests())
+}
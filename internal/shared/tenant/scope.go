@@ -0,0 +1,84 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+// Scope define quais organizações a requisição atual pode enxergar
+type Scope struct {
+	OrgID       uuid.UUID  // Organização do token
+	StoreID     *uuid.UUID // Loja do token (gerente/operador); nil = todas as lojas da organização
+	CrossTenant bool       // Papéis de plataforma (super_admin/support) enxergam qualquer organização
+}
+
+// ErrOutOfScope é devolvido pelos repositórios ao tentar gravar em outra organização
+var ErrOutOfScope = errors.New("organização fora do escopo da requisição")
+
+type scopeKey struct{}
+
+// WithScope grava o escopo no context (feito pelo middleware logo após a autenticação)
+func WithScope(ctx context.Context, scope Scope) context.Context {
+	return context.WithValue(ctx, scopeKey{}, scope)
+}
+
+// FromContext devolve o escopo da requisição. Sem escopo (ok=false) a chamada é interna
+// (login, signup, aceite de convite) e não sofre restrição de tenant.
+func FromContext(ctx context.Context) (Scope, bool) {
+	scope, ok := ctx.Value(scopeKey{}).(Scope)
+	return scope, ok
+}
+
+// Allows indica se o escopo permite acessar a organização
+func (s Scope) Allows(orgID uuid.UUID) bool {
+	return s.CrossTenant || (orgID != uuid.Nil && orgID == s.OrgID)
+}
+
+// AllowsStore indica se o escopo permite acessar a loja (já pertencente a uma organização permitida)
+func (s Scope) AllowsStore(storeID uuid.UUID) bool {
+	return s.CrossTenant || s.StoreID == nil || *s.StoreID == storeID
+}
+
+// Allows é o guard usado pelos repositórios: bloqueia organizações fora do escopo da requisição
+func Allows(ctx context.Context, orgID uuid.UUID) bool {
+	scope, ok := FromContext(ctx)
+	return !ok || scope.Allows(orgID)
+}
+
+// AllowsStore é o guard de loja usado pelos repositórios
+func AllowsStore(ctx context.Context, storeID uuid.UUID) bool {
+	scope, ok := FromContext(ctx)
+	return !ok || scope.AllowsStore(storeID)
+}
+
+// StoreRestriction devolve a loja à qual as consultas devem ser limitadas (ok=false = sem restrição)
+func StoreRestriction(ctx context.Context) (uuid.UUID, bool) {
+	scope, ok := FromContext(ctx)
+	if !ok || scope.CrossTenant || scope.StoreID == nil {
+		return uuid.Nil, false
+	}
+	return *scope.StoreID, true
+}
+
+// Restriction devolve a organização à qual as consultas devem ser limitadas.
+// ok=false quando não há restrição (chamada interna ou papel de plataforma).
+func Restriction(ctx context.Context) (uuid.UUID, bool) {
+	scope, ok := FromContext(ctx)
+	if !ok || scope.CrossTenant {
+		return uuid.Nil, false
+	}
+	return scope.OrgID, true
+}
+
+// Resolve devolve a organização a usar quando o cliente não informou uma (a do token)
+func Resolve(ctx context.Context, orgID uuid.UUID) uuid.UUID {
+	if orgID != uuid.Nil {
+		return orgID
+	}
+	if scope, ok := FromContext(ctx); ok {
+		return scope.OrgID
+	}
+	return orgID
+}
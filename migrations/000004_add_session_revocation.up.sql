@@ -0,0 +1,17 @@
+-- Marco de revogação em massa: tokens emitidos antes desta data são rejeitados (logout-all)
+ALTER TABLE users ADD COLUMN IF NOT EXISTS tokens_valid_after TIMESTAMP;
+
+-- TABELA REVOKED_TOKENS (Denylist de access tokens pelo "jti")
+-- A linha só precisa existir até o token expirar naturalmente
+CREATE TABLE IF NOT EXISTS revoked_tokens (
+    jti VARCHAR(64) PRIMARY KEY,
+    user_id UUID NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT fk_revoked_tokens_user
+        FOREIGN KEY (user_id)
+        REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_revoked_tokens_expires_at ON revoked_tokens(expires_at);
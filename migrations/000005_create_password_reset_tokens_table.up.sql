@@ -0,0 +1,18 @@
+-- TABELA PASSWORD_RESET_TOKENS
+-- Tokens de uso único para redefinição de senha (apenas o HASH é salvo)
+CREATE TABLE IF NOT EXISTS password_reset_tokens (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP, -- Preenchido no uso ou quando um novo pedido invalida o anterior
+
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT fk_password_reset_tokens_user
+        FOREIGN KEY (user_id)
+        REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_password_reset_tokens_user_id ON password_reset_tokens(user_id);
@@ -0,0 +1,2 @@
+-- Controle de reenvio do link de confirmação de email (throttling)
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email_verification_sent_at TIMESTAMP;
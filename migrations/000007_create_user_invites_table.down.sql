@@ -0,0 +1,8 @@
+-- Os emails continuam em minúsculas: não há como recuperar a grafia original
+DROP INDEX IF EXISTS uq_users_email_lower;
+CREATE INDEX idx_users_email ON users(email);
+ALTER TABLE users ADD CONSTRAINT users_email_key UNIQUE (email);
+
+DROP INDEX IF EXISTS idx_user_invites_org_id;
+DROP INDEX IF EXISTS idx_user_invites_open_email;
+DROP TABLE IF EXISTS user_invites;
@@ -0,0 +1,54 @@
+-- TABELA USER_INVITES
+-- Convites enviados por tenant admins/gerentes (o token puro só existe no email)
+CREATE TABLE IF NOT EXISTS user_invites (
+    id UUID PRIMARY KEY,
+    organization_id UUID NOT NULL,
+    store_id UUID, -- Opcional: restringe o convidado a uma loja
+
+    email VARCHAR(255) NOT NULL, -- Sempre em minúsculas
+    role VARCHAR(50) NOT NULL,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    invited_by UUID NOT NULL,
+
+    expires_at TIMESTAMP NOT NULL,
+    accepted_at TIMESTAMP,
+    revoked_at TIMESTAMP,
+
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT fk_user_invites_org
+        FOREIGN KEY (organization_id)
+        REFERENCES organizations(id) ON DELETE CASCADE,
+    CONSTRAINT fk_user_invites_store
+        FOREIGN KEY (store_id)
+        REFERENCES stores(id),
+    CONSTRAINT fk_user_invites_invited_by
+        FOREIGN KEY (invited_by)
+        REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- Apenas um convite em aberto por email em cada organização
+CREATE UNIQUE INDEX idx_user_invites_open_email
+    ON user_invites(organization_id, email)
+    WHERE accepted_at IS NULL AND revoked_at IS NULL;
+
+CREATE INDEX idx_user_invites_org_id ON user_invites(organization_id);
+
+-- EMAIL SEM DIFERENCIAR MAIÚSCULAS
+-- O convite casa com a conta pelo email: "Joao@x.com" e "joao@x.com" são a mesma pessoa. Contas
+-- duplicadas só por maiúsculas precisam ser resolvidas por um operador antes (a migração falha em
+-- vez de escolher uma delas).
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM users GROUP BY LOWER(TRIM(email)) HAVING COUNT(*) > 1) THEN
+        RAISE EXCEPTION 'existem usuários com o mesmo email em maiúsculas/minúsculas diferentes: unifique-os antes de migrar';
+    END IF;
+END $$;
+
+UPDATE users SET email = LOWER(TRIM(email)) WHERE email <> LOWER(TRIM(email));
+
+-- A unicidade passa a valer sobre LOWER(email)
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_email_key;
+DROP INDEX IF EXISTS idx_users_email;
+CREATE UNIQUE INDEX uq_users_email_lower ON users(LOWER(email));
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_user_devices_push_token;
+DROP INDEX IF EXISTS idx_user_devices_user_id;
+DROP TABLE IF EXISTS user_devices;
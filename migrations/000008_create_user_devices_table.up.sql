@@ -0,0 +1,24 @@
+-- TABELA USER_DEVICES
+-- Aparelhos que já fizeram login no App (destino dos push de alertas da gôndola)
+CREATE TABLE IF NOT EXISTS user_devices (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL,
+
+    device_id VARCHAR(255) NOT NULL, -- ID do hardware informado pelo App
+    device_name VARCHAR(255),
+    platform VARCHAR(20),
+    app_version VARCHAR(50),
+    push_token TEXT,
+
+    last_active_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT fk_user_devices_user
+        FOREIGN KEY (user_id)
+        REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT uq_user_devices_user_device
+        UNIQUE (user_id, device_id)
+);
+
+CREATE INDEX idx_user_devices_user_id ON user_devices(user_id);
+CREATE INDEX idx_user_devices_push_token ON user_devices(push_token) WHERE push_token IS NOT NULL;
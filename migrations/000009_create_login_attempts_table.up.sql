@@ -0,0 +1,21 @@
+-- TABELA LOGIN_ATTEMPTS
+-- Histórico de tentativas de login (sucesso e falha) para a tela de segurança e auditoria
+CREATE TABLE IF NOT EXISTS login_attempts (
+    id UUID PRIMARY KEY,
+    user_id UUID, -- NULL quando o email não pertence a nenhum usuário
+    email VARCHAR(255) NOT NULL,
+
+    status VARCHAR(30) NOT NULL, -- success, invalid_password, locked, inactive...
+    ip_address VARCHAR(45),
+    user_agent TEXT,
+    device_id VARCHAR(255),
+    device_name VARCHAR(255),
+
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT fk_login_attempts_user
+        FOREIGN KEY (user_id)
+        REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_login_attempts_user_created ON login_attempts(user_id, created_at DESC);
@@ -0,0 +1,4 @@
+-- Os nomes legados não voltam: não dá para saber quais tenant_admin eram "admin" ou "tenant",
+-- e os nomes canônicos já eram aceitos antes da unificação.
+ALTER TABLE user_invites DROP CONSTRAINT IF EXISTS chk_user_invites_role;
+ALTER TABLE users DROP CONSTRAINT IF EXISTS chk_users_role;
@@ -0,0 +1,10 @@
+-- Catálogo único de papéis: os nomes legados passam a ser os mesmos da aplicação.
+-- O "admin" legado vira dono da organização (nunca super_admin, que enxerga todos os tenants):
+-- quem é da equipe da plataforma é promovido explicitamente.
+UPDATE users SET role = 'tenant_admin' WHERE role IN ('admin', 'tenant');
+UPDATE user_invites SET role = 'tenant_admin' WHERE role IN ('admin', 'tenant');
+
+ALTER TABLE users ADD CONSTRAINT chk_users_role
+    CHECK (role IN ('super_admin', 'support', 'tenant_admin', 'manager', 'operator'));
+ALTER TABLE user_invites ADD CONSTRAINT chk_user_invites_role
+    CHECK (role IN ('tenant_admin', 'manager', 'operator'));
@@ -0,0 +1,34 @@
+DROP POLICY IF EXISTS tenant_isolation ON password_reset_tokens;
+DROP POLICY IF EXISTS tenant_isolation ON revoked_tokens;
+DROP POLICY IF EXISTS tenant_isolation ON refresh_tokens;
+DROP POLICY IF EXISTS tenant_isolation ON login_attempts;
+DROP POLICY IF EXISTS tenant_isolation ON user_devices;
+DROP POLICY IF EXISTS tenant_isolation ON user_invites;
+DROP POLICY IF EXISTS tenant_isolation ON users;
+DROP POLICY IF EXISTS tenant_isolation ON stores;
+DROP POLICY IF EXISTS tenant_isolation ON organizations;
+
+ALTER TABLE password_reset_tokens DISABLE ROW LEVEL SECURITY;
+ALTER TABLE revoked_tokens DISABLE ROW LEVEL SECURITY;
+ALTER TABLE refresh_tokens DISABLE ROW LEVEL SECURITY;
+ALTER TABLE login_attempts DISABLE ROW LEVEL SECURITY;
+ALTER TABLE user_devices DISABLE ROW LEVEL SECURITY;
+ALTER TABLE user_invites DISABLE ROW LEVEL SECURITY;
+ALTER TABLE users DISABLE ROW LEVEL SECURITY;
+ALTER TABLE stores DISABLE ROW LEVEL SECURITY;
+ALTER TABLE organizations DISABLE ROW LEVEL SECURITY;
+
+DROP FUNCTION IF EXISTS email_in_use(TEXT);
+DROP FUNCTION IF EXISTS app_current_org_id();
+
+ALTER DEFAULT PRIVILEGES IN SCHEMA public
+    REVOKE USAGE, SELECT ON SEQUENCES FROM smart_gondola_app, smart_gondola_bypass;
+ALTER DEFAULT PRIVILEGES IN SCHEMA public
+    REVOKE SELECT, INSERT, UPDATE, DELETE ON TABLES FROM smart_gondola_app, smart_gondola_bypass;
+REVOKE ALL ON ALL SEQUENCES IN SCHEMA public FROM smart_gondola_app, smart_gondola_bypass;
+REVOKE ALL ON ALL TABLES IN SCHEMA public FROM smart_gondola_app, smart_gondola_bypass;
+REVOKE USAGE ON SCHEMA public FROM smart_gondola_app, smart_gondola_bypass;
+
+-- Os papéis são globais no cluster: só são removidos se nada mais depender deles
+DROP ROLE IF EXISTS smart_gondola_bypass;
+DROP ROLE IF EXISTS smart_gondola_app;
@@ -0,0 +1,78 @@
+-- Row-Level Security: segunda linha de defesa do isolamento entre organizações.
+-- Requisições autenticadas rodam com SET ROLE smart_gondola_app e "app.current_org_id" definido
+-- (ver database.WithTenantSession). O papel de login (dono das tabelas) continua sem restrição
+-- para migrations e fluxos sem usuário (login, signup, aceite de convite).
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'smart_gondola_app') THEN
+        CREATE ROLE smart_gondola_app NOLOGIN;
+    END IF;
+    -- Papel de bypass: equipe da plataforma (super_admin/support) e jobs administrativos
+    IF NOT EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'smart_gondola_bypass') THEN
+        CREATE ROLE smart_gondola_bypass NOLOGIN BYPASSRLS;
+    END IF;
+END
+$$;
+
+-- O usuário da aplicação precisa poder assumir os dois papéis (SET ROLE)
+GRANT smart_gondola_app, smart_gondola_bypass TO CURRENT_USER;
+
+GRANT USAGE ON SCHEMA public TO smart_gondola_app, smart_gondola_bypass;
+GRANT SELECT, INSERT, UPDATE, DELETE ON ALL TABLES IN SCHEMA public TO smart_gondola_app, smart_gondola_bypass;
+GRANT USAGE, SELECT ON ALL SEQUENCES IN SCHEMA public TO smart_gondola_app, smart_gondola_bypass;
+ALTER DEFAULT PRIVILEGES IN SCHEMA public
+    GRANT SELECT, INSERT, UPDATE, DELETE ON TABLES TO smart_gondola_app, smart_gondola_bypass;
+ALTER DEFAULT PRIVILEGES IN SCHEMA public
+    GRANT USAGE, SELECT ON SEQUENCES TO smart_gondola_app, smart_gondola_bypass;
+
+-- Organização da sessão atual (NULL quando não definida: nenhuma linha é visível)
+CREATE OR REPLACE FUNCTION app_current_org_id() RETURNS UUID
+LANGUAGE sql STABLE AS $$
+    SELECT NULLIF(current_setting('app.current_org_id', true), '')::uuid
+$$;
+
+-- O email é único na plataforma inteira: a checagem precisa enxergar outras organizações,
+-- mas só devolve se existe (nenhum dado do outro tenant vaza). Sem diferenciar maiúsculas, como o índice.
+CREATE OR REPLACE FUNCTION email_in_use(p_email TEXT) RETURNS BOOLEAN
+LANGUAGE sql STABLE SECURITY DEFINER SET search_path = public AS $$
+    SELECT EXISTS (SELECT 1 FROM users WHERE LOWER(email) = LOWER(p_email))
+$$;
+
+-- Tabelas com organization_id
+ALTER TABLE organizations ENABLE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON organizations
+    USING (id = app_current_org_id());
+
+ALTER TABLE stores ENABLE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON stores
+    USING (organization_id = app_current_org_id());
+
+ALTER TABLE users ENABLE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON users
+    USING (organization_id = app_current_org_id());
+
+ALTER TABLE user_invites ENABLE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON user_invites
+    USING (organization_id = app_current_org_id());
+
+-- Tabelas ligadas ao usuário: visíveis apenas se o usuário for da organização da sessão
+ALTER TABLE user_devices ENABLE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON user_devices
+    USING (user_id IN (SELECT id FROM users WHERE organization_id = app_current_org_id()));
+
+ALTER TABLE login_attempts ENABLE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON login_attempts
+    USING (user_id IN (SELECT id FROM users WHERE organization_id = app_current_org_id()));
+
+ALTER TABLE refresh_tokens ENABLE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON refresh_tokens
+    USING (user_id IN (SELECT id FROM users WHERE organization_id = app_current_org_id()));
+
+ALTER TABLE revoked_tokens ENABLE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON revoked_tokens
+    USING (user_id IN (SELECT id FROM users WHERE organization_id = app_current_org_id()));
+
+ALTER TABLE password_reset_tokens ENABLE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON password_reset_tokens
+    USING (user_id IN (SELECT id FROM users WHERE organization_id = app_current_org_id()));
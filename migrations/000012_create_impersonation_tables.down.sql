@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS impersonation_events;
+DROP TABLE IF EXISTS impersonation_sessions;
@@ -0,0 +1,55 @@
+-- TABELA IMPERSONATION_SESSIONS
+-- Equipe da plataforma agindo como um usuário do tenant (suporte). Uma linha por token emitido.
+CREATE TABLE IF NOT EXISTS impersonation_sessions (
+    id UUID PRIMARY KEY,
+    actor_id UUID NOT NULL,          -- Quem realmente está agindo (super_admin/support)
+    actor_role VARCHAR(50) NOT NULL,
+    target_user_id UUID NOT NULL,    -- Usuário impersonado
+    target_org_id UUID NOT NULL,
+    reason TEXT NOT NULL,
+    read_only BOOLEAN NOT NULL DEFAULT TRUE,
+    token_id VARCHAR(64) NOT NULL UNIQUE, -- "jti" do access token (encerrar = revogar)
+    ip_address VARCHAR(45),
+    expires_at TIMESTAMP NOT NULL,
+    ended_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT fk_impersonation_actor
+        FOREIGN KEY (actor_id)
+        REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT fk_impersonation_target
+        FOREIGN KEY (target_user_id)
+        REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_impersonation_sessions_created ON impersonation_sessions(created_at DESC);
+CREATE INDEX idx_impersonation_sessions_target ON impersonation_sessions(target_user_id);
+
+-- TABELA IMPERSONATION_EVENTS
+-- Trilha de auditoria: cada requisição feita com o token de impersonação
+CREATE TABLE IF NOT EXISTS impersonation_events (
+    id UUID PRIMARY KEY,
+    session_id UUID NOT NULL,
+    method VARCHAR(10) NOT NULL,
+    path TEXT NOT NULL,
+    status INT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT fk_impersonation_events_session
+        FOREIGN KEY (session_id)
+        REFERENCES impersonation_sessions(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_impersonation_events_session ON impersonation_events(session_id, created_at);
+
+-- O tenant enxerga quem acessou a sua organização (RLS como nas demais tabelas)
+ALTER TABLE impersonation_sessions ENABLE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON impersonation_sessions
+    USING (target_org_id = app_current_org_id());
+
+ALTER TABLE impersonation_events ENABLE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON impersonation_events
+    USING (session_id IN (SELECT id FROM impersonation_sessions WHERE target_org_id = app_current_org_id()));
+
+GRANT SELECT, INSERT, UPDATE, DELETE ON impersonation_sessions, impersonation_events
+    TO smart_gondola_app, smart_gondola_bypass;
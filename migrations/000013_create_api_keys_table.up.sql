@@ -0,0 +1,30 @@
+-- TABELA API_KEYS
+-- Credenciais de integrações (ERP, BI) sem login humano. A chave em si nunca é gravada, só o hash.
+CREATE TABLE IF NOT EXISTS api_keys (
+    id UUID PRIMARY KEY,
+    organization_id UUID NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    prefix VARCHAR(16) NOT NULL,             -- Início da chave, para identificá-la nas listagens
+    key_hash VARCHAR(64) NOT NULL UNIQUE,    -- SHA-256 da chave completa
+    scopes JSONB NOT NULL DEFAULT '[]',      -- Permissões concedidas (subconjunto das de quem criou)
+    created_by UUID,
+    expires_at TIMESTAMP NOT NULL,
+    last_used_at TIMESTAMP,
+    revoked_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT fk_api_keys_organization
+        FOREIGN KEY (organization_id)
+        REFERENCES organizations(id) ON DELETE CASCADE,
+    CONSTRAINT fk_api_keys_created_by
+        FOREIGN KEY (created_by)
+        REFERENCES users(id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_api_keys_organization ON api_keys(organization_id, created_at DESC);
+
+ALTER TABLE api_keys ENABLE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON api_keys
+    USING (organization_id = app_current_org_id());
+
+GRANT SELECT, INSERT, UPDATE, DELETE ON api_keys TO smart_gondola_app, smart_gondola_bypass;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS user_identities;
+DROP TABLE IF EXISTS sso_login_requests;
+DROP TABLE IF EXISTS organization_sso_configs;
@@ -0,0 +1,72 @@
+-- TABELA ORGANIZATION_SSO_CONFIGS
+-- Login corporativo (OpenID Connect) por organização. Disponível no plano enterprise.
+CREATE TABLE IF NOT EXISTS organization_sso_configs (
+    organization_id UUID PRIMARY KEY,
+    issuer TEXT NOT NULL,
+    client_id VARCHAR(255) NOT NULL,
+    client_secret TEXT NOT NULL,
+    allowed_domains JSONB NOT NULL DEFAULT '[]', -- Domínios de email aceitos
+    default_role VARCHAR(50) NOT NULL,           -- Papel do usuário provisionado no primeiro login
+    enabled BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT fk_sso_configs_organization
+        FOREIGN KEY (organization_id)
+        REFERENCES organizations(id) ON DELETE CASCADE,
+    CONSTRAINT chk_sso_configs_default_role
+        CHECK (default_role IN ('manager', 'operator'))
+);
+
+-- TABELA SSO_LOGIN_REQUESTS
+-- Login em andamento: guarda o que não pode passar pelo navegador (code_verifier do PKCE).
+-- Cada "state" é consumido uma única vez no callback.
+CREATE TABLE IF NOT EXISTS sso_login_requests (
+    id UUID PRIMARY KEY,
+    organization_id UUID NOT NULL,
+    state_hash VARCHAR(64) NOT NULL UNIQUE,
+    nonce VARCHAR(64) NOT NULL,
+    code_verifier VARCHAR(128) NOT NULL,
+    redirect_uri TEXT NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT fk_sso_login_requests_organization
+        FOREIGN KEY (organization_id)
+        REFERENCES organizations(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_sso_login_requests_expires ON sso_login_requests(expires_at);
+
+-- TABELA USER_IDENTITIES
+-- Vínculo entre o usuário e a identidade no provedor (issuer + sub não mudam, o email pode mudar)
+CREATE TABLE IF NOT EXISTS user_identities (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL,
+    issuer TEXT NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT fk_user_identities_user
+        FOREIGN KEY (user_id)
+        REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT uq_user_identities_subject
+        UNIQUE (issuer, subject)
+);
+
+CREATE INDEX idx_user_identities_user ON user_identities(user_id);
+
+ALTER TABLE organization_sso_configs ENABLE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON organization_sso_configs
+    USING (organization_id = app_current_org_id());
+
+ALTER TABLE sso_login_requests ENABLE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON sso_login_requests
+    USING (organization_id = app_current_org_id());
+
+ALTER TABLE user_identities ENABLE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON user_identities
+    USING (user_id IN (SELECT id FROM users WHERE organization_id = app_current_org_id()));
+
+GRANT SELECT, INSERT, UPDATE, DELETE ON organization_sso_configs, sso_login_requests, user_identities
+    TO smart_gondola_app, smart_gondola_bypass;
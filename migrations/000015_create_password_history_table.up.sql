@@ -0,0 +1,20 @@
+-- TABELA PASSWORD_HISTORY
+-- Hashes das senhas anteriores, para a política de senha impedir a reutilização
+CREATE TABLE IF NOT EXISTS password_history (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL,
+    password_hash VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(), -- Quando a senha deixou de ser a atual
+
+    CONSTRAINT fk_password_history_user
+        FOREIGN KEY (user_id)
+        REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_password_history_user ON password_history(user_id, created_at DESC);
+
+ALTER TABLE password_history ENABLE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON password_history
+    USING (user_id IN (SELECT id FROM users WHERE organization_id = app_current_org_id()));
+
+GRANT SELECT, INSERT, UPDATE, DELETE ON password_history TO smart_gondola_app, smart_gondola_bypass;
@@ -0,0 +1,8 @@
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS failed_login_attempts INT DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS locked_until TIMESTAMP;
+
+DROP INDEX IF EXISTS idx_login_attempts_email_created;
+DROP INDEX IF EXISTS idx_login_attempts_ip_created;
+
+DROP TABLE IF EXISTS login_throttles;
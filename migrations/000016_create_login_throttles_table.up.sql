@@ -0,0 +1,37 @@
+-- TABELA LOGIN_THROTTLES
+-- Contadores de falhas de login por IP, por email e por IP+email (com backoff exponencial).
+-- Substitui o bloqueio por conta (users.failed_login_attempts/locked_until), que deixava
+-- qualquer um bloquear a vítima só errando a senha dela.
+CREATE TABLE IF NOT EXISTS login_throttles (
+    scope VARCHAR(10) NOT NULL,                 -- ip, email, ip_email
+    ip_address VARCHAR(45) NOT NULL DEFAULT '', -- Vazio no escopo "email"
+    email VARCHAR(255) NOT NULL DEFAULT '',     -- Vazio no escopo "ip"
+
+    failures INT NOT NULL DEFAULT 0,            -- Falhas dentro da janela atual
+    lockouts INT NOT NULL DEFAULT 0,            -- Bloqueios seguidos (expoente do backoff)
+    window_started_at TIMESTAMP NOT NULL,
+    last_failure_at TIMESTAMP NOT NULL,
+    blocked_until TIMESTAMP,
+    reason VARCHAR(30),                         -- typos, distributed, credential_stuffing
+
+    PRIMARY KEY (scope, ip_address, email)
+);
+
+CREATE INDEX idx_login_throttles_blocked ON login_throttles(blocked_until) WHERE blocked_until IS NOT NULL;
+CREATE INDEX idx_login_throttles_email ON login_throttles(email) WHERE email <> '';
+
+-- Padrões de ataque são detectados no histórico de tentativas
+CREATE INDEX idx_login_attempts_ip_created ON login_attempts(ip_address, created_at DESC);
+CREATE INDEX idx_login_attempts_email_created ON login_attempts(LOWER(email), created_at DESC);
+
+-- O tenant admin só enxerga (e desbloqueia) os contadores dos emails da própria organização.
+-- Linhas só de IP ficam com a plataforma (papel bypass).
+ALTER TABLE login_throttles ENABLE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON login_throttles
+    USING (email <> '' AND email IN (SELECT LOWER(email) FROM users WHERE organization_id = app_current_org_id()));
+
+GRANT SELECT, INSERT, UPDATE, DELETE ON login_throttles TO smart_gondola_app, smart_gondola_bypass;
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS failed_login_attempts,
+    DROP COLUMN IF EXISTS locked_until;
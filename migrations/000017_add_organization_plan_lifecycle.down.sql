@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS organization_plan_history;
+
+DROP INDEX IF EXISTS idx_organizations_trial_ends;
+ALTER TABLE organizations DROP COLUMN IF EXISTS trial_ends_at;
@@ -0,0 +1,36 @@
+-- CICLO DE VIDA DO PLANO
+-- Período de teste do plano pro: ao vencer, a organização volta sozinha para o free.
+ALTER TABLE organizations ADD COLUMN IF NOT EXISTS trial_ends_at TIMESTAMP;
+
+CREATE INDEX idx_organizations_trial_ends ON organizations(trial_ends_at) WHERE trial_ends_at IS NOT NULL;
+
+-- TABELA ORGANIZATION_PLAN_HISTORY
+-- Cada linha é um período com um plano; effective_to NULL = período vigente
+CREATE TABLE IF NOT EXISTS organization_plan_history (
+    id UUID PRIMARY KEY,
+    organization_id UUID NOT NULL,
+    plan VARCHAR(50) NOT NULL,
+    is_trial BOOLEAN NOT NULL DEFAULT FALSE,
+    reason VARCHAR(30) NOT NULL,            -- created, trial_expired
+    effective_from TIMESTAMP NOT NULL,
+    effective_to TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT fk_plan_history_organization
+        FOREIGN KEY (organization_id)
+        REFERENCES organizations(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_plan_history_org ON organization_plan_history(organization_id, effective_from DESC);
+-- No máximo um período vigente por organização
+CREATE UNIQUE INDEX uq_plan_history_current ON organization_plan_history(organization_id) WHERE effective_to IS NULL;
+
+-- Organizações existentes começam o histórico com o plano atual desde a criação
+INSERT INTO organization_plan_history (id, organization_id, plan, is_trial, reason, effective_from)
+SELECT gen_random_uuid(), id, plan, FALSE, 'created', created_at FROM organizations;
+
+ALTER TABLE organization_plan_history ENABLE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON organization_plan_history
+    USING (organization_id = app_current_org_id());
+
+GRANT SELECT, INSERT, UPDATE, DELETE ON organization_plan_history TO smart_gondola_app, smart_gondola_bypass;
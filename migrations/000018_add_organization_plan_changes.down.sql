@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS organization_plan_changes;
+
+UPDATE organizations SET settings = settings - 'limit_overrides';
+
+ALTER TABLE organization_plan_history
+    DROP COLUMN IF EXISTS limits,
+    DROP COLUMN IF EXISTS changed_by;
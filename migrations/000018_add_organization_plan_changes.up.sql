@@ -0,0 +1,53 @@
+-- TROCA DE PLANO
+-- Histórico passa a guardar quem fez a troca e os limites efetivos de cada período
+ALTER TABLE organization_plan_history
+    ADD COLUMN IF NOT EXISTS changed_by UUID,              -- NULL = sistema (cadastro, fim do teste)
+    ADD COLUMN IF NOT EXISTS limits JSONB NOT NULL DEFAULT '{}';
+
+-- Limites diferentes do padrão do plano eram ajustes manuais: viram exceções do tenant,
+-- que sobrevivem à troca de plano
+WITH defaults(plan, max_users, max_devices, max_stores) AS (
+    VALUES ('free', 2, 10, 1), ('pro', 10, 500, 10), ('enterprise', 9999, 99999, 9999)
+), custom AS (
+    SELECT o.id, jsonb_strip_nulls(jsonb_build_object(
+        'max_users', NULLIF(NULLIF((o.settings->>'max_users')::int, 0), d.max_users),
+        'max_devices', NULLIF(NULLIF((o.settings->>'max_devices')::int, 0), d.max_devices),
+        'max_stores', NULLIF(NULLIF((o.settings->>'max_stores')::int, 0), d.max_stores)
+    )) AS overrides
+    FROM organizations o
+    JOIN defaults d ON d.plan = o.plan
+)
+UPDATE organizations o SET settings = o.settings || jsonb_build_object('limit_overrides', c.overrides)
+FROM custom c
+WHERE c.id = o.id AND c.overrides <> '{}'::jsonb;
+
+-- TABELA ORGANIZATION_PLAN_CHANGES
+-- Trocas agendadas: aplicadas pelo job na data se o consumo couber nos novos limites; senão ficam
+-- bloqueadas com os excessos até o tenant cancelar ou agendar outra
+CREATE TABLE IF NOT EXISTS organization_plan_changes (
+    id UUID PRIMARY KEY,
+    organization_id UUID NOT NULL,
+    from_plan VARCHAR(50) NOT NULL,
+    to_plan VARCHAR(50) NOT NULL,
+    requested_by UUID NOT NULL,
+    effective_at TIMESTAMP NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending', -- pending, applied, blocked, canceled
+    violations JSONB,                              -- Excessos de consumo que bloquearam a troca
+    resolved_by UUID,
+    resolved_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT fk_plan_changes_organization
+        FOREIGN KEY (organization_id)
+        REFERENCES organizations(id) ON DELETE CASCADE
+);
+
+-- No máximo uma troca em aberto (pendente ou bloqueada) por organização
+CREATE UNIQUE INDEX uq_plan_changes_open ON organization_plan_changes(organization_id) WHERE status IN ('pending', 'blocked');
+CREATE INDEX idx_plan_changes_due ON organization_plan_changes(effective_at) WHERE status = 'pending';
+
+ALTER TABLE organization_plan_changes ENABLE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON organization_plan_changes
+    USING (organization_id = app_current_org_id());
+
+GRANT SELECT, INSERT, UPDATE, DELETE ON organization_plan_changes TO smart_gondola_app, smart_gondola_bypass;
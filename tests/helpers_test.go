@@ -0,0 +1,24 @@
+package tests
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/domain/entity"
+	userRepo "github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/infrastructure/repository"
+)
+
+// seedUser grava um usuário ativo direto no banco. Não existe mais cadastro anônimo numa
+// organização existente, então os testes montam seus dados por aqui.
+func seedUser(t *testing.T, db *sql.DB, orgID uuid.UUID, name, email, password string, role entity.UserRole) *entity.User {
+	t.Helper()
+
+	user, err := entity.NewUser(orgID, name, email, password, role)
+	require.NoError(t, err)
+	require.NoError(t, userRepo.NewUserRepository(db).Create(context.Background(), user))
+	return user
+}
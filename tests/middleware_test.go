@@ -12,6 +12,7 @@ import (
 	"github.com/paulochiaradia/smart-gondola-backend/internal/di"
 	routerLib "github.com/paulochiaradia/smart-gondola-backend/internal/interface/http"
 	"github.com/paulochiaradia/smart-gondola-backend/internal/interface/http/middleware"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/domain/entity"
 	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/config"
 	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/database"
 )
@@ -76,7 +77,7 @@ func (s *MiddlewareSuite) TestRBAC_ShouldBlockOperatorFromCreatingStore() {
 
 	// Para manter este teste focado apenas no RBAC sem precisar gerar JWT real no teste:
 	// Vamos criar um Handler fake encapsulado pelo RequireRole
-	rbacMiddleware := middleware.RequireRole("admin", "tenant")
+	rbacMiddleware := middleware.RequireRole("super_admin", "tenant_admin")
 	fakeHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK) // Se passar pelo RBAC, dá 200
 	})
@@ -95,11 +96,11 @@ func (s *MiddlewareSuite) TestRBAC_ShouldBlockOperatorFromCreatingStore() {
 func (s *MiddlewareSuite) TestRBAC_ShouldAllowTenantToCreateStore() {
 	req, _ := http.NewRequest("POST", "/api/v1/stores", nil)
 
-	// Simulamos um usuário com a role "tenant"
-	ctx := context.WithValue(req.Context(), middleware.RoleContextKey, "tenant")
+	// Simulamos um usuário com a role "tenant_admin"
+	ctx := context.WithValue(req.Context(), middleware.RoleContextKey, "tenant_admin")
 	req = req.WithContext(ctx)
 
-	rbacMiddleware := middleware.RequireRole("admin", "tenant")
+	rbacMiddleware := middleware.RequireRole("super_admin", "tenant_admin")
 	fakeHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK) // Se passar pelo RBAC, dá 200
 	})
@@ -113,6 +114,66 @@ func (s *MiddlewareSuite) TestRBAC_ShouldAllowTenantToCreateStore() {
 	s.Equal(http.StatusOK, w.Code)
 }
 
+func (s *MiddlewareSuite) TestRBAC_PermissionMatrix() {
+	cases := []struct {
+		role       string
+		permission string
+		expected   int
+	}{
+		{"operator", "stores:create", http.StatusForbidden},
+		{"operator", "gondolas:read", http.StatusOK},
+		{"manager", "users:invite", http.StatusOK},
+		{"manager", "organizations:manage", http.StatusForbidden},
+		{"support", "users:audit", http.StatusOK},
+		{"support", "users:manage", http.StatusForbidden},
+		{"tenant_admin", "stores:create", http.StatusOK},
+		{"tenant", "stores:create", http.StatusForbidden}, // Nome legado: tokens anteriores à unificação já foram invalidados
+		{"admin", "organizations:create", http.StatusForbidden},
+		{"desconhecido", "gondolas:read", http.StatusForbidden},
+	}
+
+	fakeHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	for _, tc := range cases {
+		req, _ := http.NewRequest("GET", "/api/v1/qualquer", nil)
+		req = req.WithContext(context.WithValue(req.Context(), middleware.RoleContextKey, tc.role))
+		w := httptest.NewRecorder()
+
+		middleware.RequirePermission(tc.permission)(fakeHandler).ServeHTTP(w, req)
+
+		s.Equal(tc.expected, w.Code, "%s -> %s", tc.role, tc.permission)
+	}
+}
+
+func (s *MiddlewareSuite) TestRBAC_APIKeyUsesScopesNotRole() {
+	key := &entity.APIKey{Scopes: []entity.Permission{entity.PermStoresRead}}
+	fakeHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	for permission, expected := range map[string]int{
+		"stores:read":   http.StatusOK,
+		"stores:create": http.StatusForbidden,
+		"users:read":    http.StatusForbidden,
+	} {
+		req, _ := http.NewRequest("GET", "/api/v1/qualquer", nil)
+		ctx := context.WithValue(req.Context(), middleware.APIKeyContextKey, key)
+		ctx = context.WithValue(ctx, middleware.RoleContextKey, "tenant_admin") // Ignorado: vale o escopo da chave
+		w := httptest.NewRecorder()
+
+		middleware.RequirePermission(permission)(fakeHandler).ServeHTTP(w, req.WithContext(ctx))
+
+		s.Equal(expected, w.Code, permission)
+	}
+}
+
+func (s *MiddlewareSuite) TestRBAC_RoutesReferenceOnlyKnownRules() {
+	// O router real já foi montado no SetupSuite: nenhuma regra pode estar fora do catálogo
+	s.NoError(middleware.CheckAccessRules())
+}
+
 func (s *MiddlewareSuite) TestSecurityHeaders_ShouldBePresent() {
 	req, _ := http.NewRequest("GET", "/api/v1/health", nil)
 	w := httptest.NewRecorder()
@@ -148,6 +209,47 @@ func (s *MiddlewareSuite) TestRateLimiter_ShouldBlockAfterLimit() {
 
 	s.Equal(http.StatusTooManyRequests, w.Code, "A 101ª requisição deve ser bloqueada pelo Rate Limiter")
 }
+
+func (s *MiddlewareSuite) TestClientIP_IgnoresProxyHeadersUnlessTrusted() {
+	cfg := config.Get()
+	defer func(trust bool, proxies []string) {
+		cfg.TrustProxyHeaders, cfg.TrustedProxies = trust, proxies
+	}(cfg.TrustProxyHeaders, cfg.TrustedProxies)
+
+	req, _ := http.NewRequest("POST", "/api/v1/auth/login", nil)
+	req.RemoteAddr = "10.0.0.7:5555"
+	req.Header.Set("X-Forwarded-For", "203.0.113.9, 10.0.0.1")
+
+	// Sem proxy confiável o header pode ser forjado pelo cliente
+	cfg.TrustProxyHeaders = false
+	s.Equal("10.0.0.7", middleware.ClientIP(req))
+
+	// Só o load balancer na frente: vale o último endereço que ele acrescentou
+	cfg.TrustProxyHeaders = true
+	s.Equal("10.0.0.1", middleware.ClientIP(req))
+
+	// Com o proxy interno configurado, pula até o cliente
+	cfg.TrustedProxies = []string{"10.0.0.0/8"}
+	s.Equal("203.0.113.9", middleware.ClientIP(req))
+}
+
+func (s *MiddlewareSuite) TestClientIP_ForgedForwardedEntryDoesNotChangeKey() {
+	cfg := config.Get()
+	defer func(trust bool, proxies []string) {
+		cfg.TrustProxyHeaders, cfg.TrustedProxies = trust, proxies
+	}(cfg.TrustProxyHeaders, cfg.TrustedProxies)
+	cfg.TrustProxyHeaders, cfg.TrustedProxies = true, []string{"10.0.0.1"}
+
+	req, _ := http.NewRequest("POST", "/api/v1/auth/login", nil)
+	req.RemoteAddr = "10.0.0.7:5555"
+
+	// O cliente manda o próprio X-Forwarded-For; os proxies só acrescentam à direita
+	for _, forged := range []string{"", "198.51.100.1, ", "192.0.2.44, 198.51.100.1, ", "lixo, "} {
+		req.Header.Set("X-Forwarded-For", forged+"203.0.113.9, 10.0.0.1")
+		s.Equal("203.0.113.9", middleware.ClientIP(req), forged)
+	}
+}
+
 func TestMiddlewareSuite(t *testing.T) {
 	suite.Run(t, new(MiddlewareSuite))
 }
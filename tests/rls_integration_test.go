@@ -0,0 +1,120 @@
+package tests
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+
+	orgEntity "github.com/paulochiaradia/smart-gondola-backend/internal/modules/organizations/domain/entity"
+	orgRepo "github.com/paulochiaradia/smart-gondola-backend/internal/modules/organizations/infrastructure/repository"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/domain/entity"
+	userRepo "github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/infrastructure/repository"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/config"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/database"
+)
+
+// RLSSuite prova o isolamento no próprio banco: as consultas abaixo não têm WHERE de organização
+// e o context não carrega o escopo da aplicação (tenant.Scope), só a sessão de RLS.
+type RLSSuite struct {
+	suite.Suite
+	db             *sql.DB
+	orgA, orgB     uuid.UUID
+	storeA, storeB uuid.UUID
+}
+
+func (s *RLSSuite) SetupSuite() {
+	cfg := config.Get()
+	if cfg.DBHost == "localhost" {
+		cfg.DBHost = "127.0.0.1"
+	}
+
+	db, err := database.NewPostgres(cfg)
+	s.Require().NoError(err)
+	// Uma única conexão: garante que a sessão limpa é a mesma reaproveitada pelo pool
+	db.SetMaxOpenConns(1)
+	s.db = db
+}
+
+func (s *RLSSuite) SetupTest() {
+	_, err := s.db.Exec("TRUNCATE organizations, users, stores CASCADE")
+	s.Require().NoError(err)
+
+	s.orgA, s.orgB = uuid.New(), uuid.New()
+	s.storeA, s.storeB = uuid.New(), uuid.New()
+	_, err = s.db.Exec(`
+		INSERT INTO organizations (id, name, document, slug, plan, sector, settings, is_active) VALUES
+			($1, 'Org A', '11111111111111', 'org-a', 'pro', 'retail', '{}', true),
+			($2, 'Org B', '22222222222222', 'org-b', 'pro', 'retail', '{}', true)
+	`, s.orgA, s.orgB)
+	s.Require().NoError(err)
+	_, err = s.db.Exec(`
+		INSERT INTO stores (id, organization_id, name, code) VALUES
+			($1, $2, 'Loja A', 'A01'),
+			($3, $4, 'Loja B', 'B01')
+	`, s.storeA, s.orgA, s.storeB, s.orgB)
+	s.Require().NoError(err)
+
+	seedUser(s.T(), s.db, s.orgB, "Dono B", "dono.b@smartgondola.com", "SenhaForte123!", entity.RoleTenantAdmin)
+}
+
+func (s *RLSSuite) TearDownSuite() {
+	if s.db != nil {
+		s.db.Close()
+	}
+}
+
+// count roda a consulta na conexão da sessão (sem filtro de organização)
+func (s *RLSSuite) count(ctx context.Context, table string) int {
+	var n int
+	s.Require().NoError(database.Conn(ctx, s.db).QueryRowContext(ctx, `SELECT COUNT(*) FROM `+table).Scan(&n))
+	return n
+}
+
+func (s *RLSSuite) TestTenantSession_BlocksCrossTenantReadsAndWrites() {
+	ctx, release, err := database.WithTenantSession(context.Background(), s.db, s.orgA, false)
+	s.Require().NoError(err)
+
+	s.Equal(1, s.count(ctx, "organizations"))
+	s.Equal(1, s.count(ctx, "stores"))
+	s.Equal(0, s.count(ctx, "users"), "O usuário da organização B não pode aparecer")
+
+	// Repositório sem guard de aplicação no context: quem barra é o banco
+	store, err := orgRepo.NewStoreRepository(s.db).GetByID(ctx, s.storeB)
+	s.NoError(err)
+	s.Nil(store)
+
+	// Escrita em outra organização viola a política
+	intruder, err := orgEntity.NewStore(s.orgB, "Intrusa", "X01", "")
+	s.Require().NoError(err)
+	s.Error(orgRepo.NewStoreRepository(s.db).Create(ctx, intruder))
+
+	// A unicidade do email continua global, sem expor o usuário
+	exists, err := userRepo.NewUserRepository(s.db).EmailExists(ctx, "dono.b@smartgondola.com")
+	s.NoError(err)
+	s.True(exists)
+
+	release()
+
+	// A conexão devolvida ao pool não carrega o papel nem a organização da sessão anterior
+	s.Equal(2, s.count(context.Background(), "stores"))
+	var orgSetting sql.NullString
+	s.Require().NoError(s.db.QueryRow(`SELECT NULLIF(current_setting('app.current_org_id', true), '')`).Scan(&orgSetting))
+	s.False(orgSetting.Valid)
+}
+
+func (s *RLSSuite) TestBypassSession_SeesEveryTenant() {
+	ctx, release, err := database.WithTenantSession(context.Background(), s.db, s.orgA, true)
+	s.Require().NoError(err)
+	defer release()
+
+	s.Equal(2, s.count(ctx, "organizations"))
+	s.Equal(2, s.count(ctx, "stores"))
+	s.Equal(1, s.count(ctx, "users"))
+}
+
+func TestRLSSuite(t *testing.T) {
+	suite.Run(t, new(RLSSuite))
+}
@@ -9,6 +9,7 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/suite"
 
@@ -17,6 +18,7 @@ import (
 	"github.com/paulochiaradia/smart-gondola-backend/internal/interface/http/response"
 	orgDTO "github.com/paulochiaradia/smart-gondola-backend/internal/modules/organizations/application/dto"
 	userDTO "github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/application/dto"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/domain/entity"
 	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/config"
 	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/database"
 	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/pagination"
@@ -59,25 +61,17 @@ func (s *StoreE2ESuite) SetupTest() {
 	`, s.validOrgID)
 	s.Require().NoError(err)
 
-	// 3. Registra um Usuário "tenant" (Dono) via HTTP
+	// 3. Cria o Usuário "tenant_admin" (Dono) direto no banco
 	email := "dono@smartgondola.com"
 	password := "SenhaForte123!"
-
-	registerBody := userDTO.CreateUserRequest{
-		OrganizationID: s.validOrgID,
-		Name:           "Dono da Empresa",
-		Email:          email,
-		Password:       password,
-		Role:           "tenant", // Role que tem permissão no RBAC
-	}
-	body, _ := json.Marshal(registerBody)
-	req, _ := http.NewRequest("POST", "/api/v1/auth/register", bytes.NewBuffer(body))
-	req.Header.Set("Content-Type", "application/json")
-	w := httptest.NewRecorder()
-	s.handler.ServeHTTP(w, req)
-	s.Require().Equal(http.StatusCreated, w.Code)
+	seedUser(s.T(), s.db, s.validOrgID, "Dono da Empresa", email, password, entity.RoleTenantAdmin) // Role que tem permissão no RBAC
 
 	// 4. Faz Login para pegar o Token JWT Real
+	s.validToken = s.loginAs(email, password)
+}
+
+// loginAs faz login e devolve o access token
+func (s *StoreE2ESuite) loginAs(email, password string) string {
 	loginBody := userDTO.LoginRequest{
 		Email:    email,
 		Password: password,
@@ -93,7 +87,35 @@ func (s *StoreE2ESuite) SetupTest() {
 		Data map[string]interface{} `json:"data"`
 	}
 	json.Unmarshal(wLogin.Body.Bytes(), &loginResp)
-	s.validToken = loginResp.Data["access_token"].(string)
+	return loginResp.Data["access_token"].(string)
+}
+
+// request executa uma chamada autenticada (payload nil = sem corpo)
+func (s *StoreE2ESuite) request(method, path, token string, payload interface{}) *httptest.ResponseRecorder {
+	var body bytes.Buffer
+	if payload != nil {
+		json.NewEncoder(&body).Encode(payload)
+	}
+	req, _ := http.NewRequest(method, path, &body)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	w := httptest.NewRecorder()
+	s.handler.ServeHTTP(w, req)
+	return w
+}
+
+// seedOrganization cria outra organização com o seu dono e devolve o ID e o token do dono
+func (s *StoreE2ESuite) seedOrganization(name, document, slug, ownerEmail string) (uuid.UUID, string) {
+	orgID := uuid.New()
+	_, err := s.db.Exec(`
+		INSERT INTO organizations (id, name, document, slug, plan, sector, settings, is_active)
+		VALUES ($1, $2, $3, $4, 'pro', 'retail', '{}', true)
+	`, orgID, name, document, slug)
+	s.Require().NoError(err)
+
+	seedUser(s.T(), s.db, orgID, "Dono "+name, ownerEmail, "SenhaForte123!", entity.RoleTenantAdmin)
+	return orgID, s.loginAs(ownerEmail, "SenhaForte123!")
 }
 
 func (s *StoreE2ESuite) TearDownSuite() {
@@ -200,6 +222,87 @@ func (s *StoreE2ESuite) TestStoreEndpoints_CreateWithValidationFailure() {
 	s.True(len(errResp.Error.Details) > 0, "Deve listar os campos faltantes")
 }
 
+func (s *StoreE2ESuite) TestTenantIsolation_CrossTenantAccessReturns404() {
+	// Loja da organização A (a do SetupTest)
+	w := s.request("POST", "/api/v1/stores", s.validToken, orgDTO.CreateStoreRequest{Name: "Loja A", Code: "A-01"})
+	s.Require().Equal(http.StatusCreated, w.Code, "Sem organization_id a loja vai para a organização do token")
+
+	// Dono da organização B tenta enxergar e alterar a organização A
+	otherOrgID, otherToken := s.seedOrganization("Org B", "22222222222222", "org-b", "dono.b@smartgondola.com")
+	orgPath := fmt.Sprintf("/api/v1/organizations/%s", s.validOrgID)
+
+	s.Equal(http.StatusNotFound, s.request("GET", orgPath, otherToken, nil).Code)
+	s.Equal(http.StatusNotFound, s.request("GET", orgPath+"/stores", otherToken, nil).Code)
+	requireTwoFactor := true
+	settings := orgDTO.UpdateOrganizationSettingsRequest{RequireTwoFactor: &requireTwoFactor}
+	s.Equal(http.StatusNotFound, s.request("PUT", orgPath+"/settings", otherToken, settings).Code)
+
+	w = s.request("POST", "/api/v1/stores", otherToken, orgDTO.CreateStoreRequest{OrganizationID: s.validOrgID, Name: "Intrusa", Code: "X-01"})
+	s.Equal(http.StatusNotFound, w.Code)
+
+	var count int
+	s.Require().NoError(s.db.QueryRow(`SELECT COUNT(*) FROM stores WHERE organization_id = $1`, s.validOrgID).Scan(&count))
+	s.Equal(1, count, "Nenhuma loja pode ter sido criada na organização A")
+
+	// A própria organização continua acessível
+	s.Equal(http.StatusOK, s.request("GET", orgPath, s.validToken, nil).Code)
+
+	// Equipe da plataforma enxerga qualquer organização
+	seedUser(s.T(), s.db, otherOrgID, "Suporte", "suporte@smartgondola.com", "SenhaForte123!", entity.RoleSupport)
+	supportToken := s.loginAs("suporte@smartgondola.com", "SenhaForte123!")
+
+	w = s.request("GET", orgPath+"/stores", supportToken, nil)
+	s.Equal(http.StatusOK, w.Code)
+	s.Contains(w.Body.String(), "Loja A")
+}
+
+func (s *StoreE2ESuite) TestStoreScope_BoundManagerSeesOnlyOwnStore() {
+	var stores [2]orgDTO.StoreResponse
+	for i, code := range []string{"CENTRO", "NORTE"} {
+		w := s.request("POST", "/api/v1/stores", s.validToken, orgDTO.CreateStoreRequest{Name: "Loja " + code, Code: code})
+		s.Require().Equal(http.StatusCreated, w.Code)
+		var created struct {
+			Data orgDTO.StoreResponse `json:"data"`
+		}
+		s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &created))
+		stores[i] = created.Data
+	}
+
+	manager := seedUser(s.T(), s.db, s.validOrgID, "Gerente Centro", "gerente@smartgondola.com", "SenhaForte123!", entity.RoleManager)
+	_, err := s.db.Exec(`UPDATE users SET store_id = $1 WHERE id = $2`, stores[0].ID, manager.ID)
+	s.Require().NoError(err)
+	managerToken := s.loginAs(manager.Email, "SenhaForte123!")
+
+	// A loja viaja no token
+	claims := jwt.MapClaims{}
+	_, _, err = jwt.NewParser().ParseUnverified(managerToken, claims)
+	s.Require().NoError(err)
+	s.Equal(stores[0].ID.String(), claims["store_id"])
+
+	listPath := fmt.Sprintf("/api/v1/organizations/%s/stores", s.validOrgID)
+	var list struct {
+		Data []orgDTO.StoreResponse `json:"data"`
+		Meta pagination.Meta        `json:"meta"`
+	}
+
+	w := s.request("GET", listPath, managerToken, nil)
+	s.Require().Equal(http.StatusOK, w.Code)
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &list))
+	s.Require().Len(list.Data, 1)
+	s.Equal(stores[0].ID, list.Data[0].ID)
+	s.Equal(int64(1), list.Meta.TotalItems)
+
+	s.Equal(http.StatusOK, s.request("GET", fmt.Sprintf("/api/v1/stores/%s/devices", stores[0].ID), managerToken, nil).Code)
+	s.Equal(http.StatusNotFound, s.request("GET", fmt.Sprintf("/api/v1/stores/%s/devices", stores[1].ID), managerToken, nil).Code)
+
+	// O dono (tenant admin) continua vendo todas as lojas
+	w = s.request("GET", listPath, s.validToken, nil)
+	s.Require().Equal(http.StatusOK, w.Code)
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &list))
+	s.Equal(int64(2), list.Meta.TotalItems)
+	s.Equal(http.StatusOK, s.request("GET", fmt.Sprintf("/api/v1/stores/%s/devices", stores[1].ID), s.validToken, nil).Code)
+}
+
 func TestStoreE2ESuite(t *testing.T) {
 	suite.Run(t, new(StoreE2ESuite))
 }
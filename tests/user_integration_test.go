@@ -4,22 +4,35 @@ import (
 	"bytes"
 	"database/sql"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/suite"
+	"golang.org/x/crypto/bcrypt"
 
 	"github.com/paulochiaradia/smart-gondola-backend/internal/di"
 	routerLib "github.com/paulochiaradia/smart-gondola-backend/internal/interface/http"
 	"github.com/paulochiaradia/smart-gondola-backend/internal/interface/http/response"
 	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/organizations/application/dto"
+	orgEntity "github.com/paulochiaradia/smart-gondola-backend/internal/modules/organizations/domain/entity"
 	userDTO "github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/application/dto"
 	"github.com/paulochiaradia/smart-gondola-backend/internal/modules/users/domain/entity"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/auth"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/breached"
 	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/config"
 	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/database"
+	"github.com/paulochiaradia/smart-gondola-backend/internal/shared/oidc/oidctest"
 )
 
 type UserE2ESuite struct {
@@ -29,7 +42,9 @@ type UserE2ESuite struct {
 	container *di.Container
 
 	// Dados de apoio para os testes
-	validOrgID uuid.UUID
+	validOrgID  uuid.UUID
+	mailDir     string // Emails do driver "log" caem aqui
+	breachedDir string // Lista local de senhas vazadas (arquivos de prefixo SHA-1)
 }
 
 // SetupSuite: Roda uma vez antes de tudo. Sobe banco e configura a aplicação.
@@ -45,6 +60,13 @@ func (s *UserE2ESuite) SetupSuite() {
 	s.Require().NoError(err)
 	s.db = db
 
+	// Emails de teste são gravados em disco para lermos os links enviados
+	s.mailDir = s.T().TempDir()
+	cfg.MailDriver = "log"
+	cfg.MailOutboxDir = s.mailDir
+	s.breachedDir = s.T().TempDir()
+	cfg.BreachedPasswordsDir = s.breachedDir
+
 	// 2. Inicializa o Container (Injeção de Dependência Real)
 	container, _, err := di.NewContainer(cfg)
 	s.Require().NoError(err)
@@ -57,7 +79,7 @@ func (s *UserE2ESuite) SetupSuite() {
 // SetupTest: Roda antes de CADA teste. Limpa o banco e cria dados base.
 func (s *UserE2ESuite) SetupTest() {
 	// 1. Limpa tudo
-	_, err := s.db.Exec("TRUNCATE organizations, users CASCADE")
+	_, err := s.db.Exec("TRUNCATE organizations, users, login_throttles CASCADE")
 	s.Require().NoError(err)
 
 	// 2. CRIA UMA ORGANIZAÇÃO BASE (Necessária para criar usuário)
@@ -87,39 +109,41 @@ func (s *UserE2ESuite) TearDownSuite() {
 
 // --- TESTES DE ROTA (E2E) ---
 
-func (s *UserE2ESuite) TestRegisterAndLoginFlow() {
+func (s *UserE2ESuite) TestSignupAndLoginFlow() {
 	email := "usuario.teste@smartgondola.com"
 	password := "SenhaForte123!"
 
 	// ==========================================
-	// 1. REGISTRAR USUÁRIO
+	// 1. CRIAR EMPRESA + DONO
 	// ==========================================
-	registerBody := userDTO.CreateUserRequest{
-		OrganizationID: s.validOrgID,
-		Name:           "João da Silva",
-		Email:          email,
-		Password:       password,
-		Role:           entity.RoleManager,
+	signupBody := userDTO.SignupRequest{
+		Organization: dto.CreateOrganizationRequest{
+			Name:     "Nova Empresa",
+			Document: "11222333000181",
+			Slug:     "nova-empresa",
+			Sector:   "retail",
+			Plan:     "pro",
+		},
+		Owner: userDTO.SignupOwnerRequest{
+			Name:     "João da Silva",
+			Email:    email,
+			Password: password,
+		},
 	}
-	body, _ := json.Marshal(registerBody)
-
-	req, _ := http.NewRequest("POST", "/api/v1/auth/register", bytes.NewBuffer(body))
-	req.Header.Set("Content-Type", "application/json")
-	w := httptest.NewRecorder()
-
-	s.handler.ServeHTTP(w, req)
+	w := s.postJSON("/api/v1/auth/signup", "", signupBody)
 
 	// Validações Registro
-	s.Equal(http.StatusCreated, w.Code)
+	s.Require().Equal(http.StatusCreated, w.Code)
 
-	// NOVO: Criamos uma struct anônima para ler o envelope "data"
-	var registerResp struct {
-		Data userDTO.UserResponse `json:"data"`
+	var signupResp struct {
+		Data userDTO.SignupResponse `json:"data"`
 	}
-	err := json.Unmarshal(w.Body.Bytes(), &registerResp)
+	err := json.Unmarshal(w.Body.Bytes(), &signupResp)
 	s.NoError(err)
-	s.Equal(email, registerResp.Data.Email)
-	s.NotEmpty(registerResp.Data.ID)
+	s.Equal(email, signupResp.Data.User.Email)
+	s.NotEmpty(signupResp.Data.User.ID)
+	s.Equal(signupResp.Data.Organization.ID, signupResp.Data.User.OrganizationID)
+	s.Equal(entity.RoleTenantAdmin, signupResp.Data.User.Role)
 
 	// ==========================================
 	// 2. FAZER LOGIN
@@ -147,6 +171,367 @@ func (s *UserE2ESuite) TestRegisterAndLoginFlow() {
 	s.NoError(err)
 	s.NotEmpty(loginResp.Data["access_token"], "Token não deve ser vazio")
 }
+
+func (s *UserE2ESuite) TestSignup_IsAtomicAndRegisterIsGone() {
+	password := "SenhaForte123!"
+	s.registerUser("ja.existe@smartgondola.com", password, entity.RoleManager)
+
+	// Email do dono já em uso: a empresa também não pode ficar criada
+	signupBody := userDTO.SignupRequest{
+		Organization: dto.CreateOrganizationRequest{
+			Name: "Empresa Órfã", Document: "11222333000181", Slug: "empresa-orfa", Sector: "retail", Plan: "pro",
+		},
+		Owner: userDTO.SignupOwnerRequest{Name: "Dono", Email: "ja.existe@smartgondola.com", Password: password},
+	}
+	s.Equal(http.StatusConflict, s.postJSON("/api/v1/auth/signup", "", signupBody).Code)
+
+	var orgs int
+	s.Require().NoError(s.db.QueryRow(`SELECT COUNT(*) FROM organizations WHERE slug = 'empresa-orfa'`).Scan(&orgs))
+	s.Equal(0, orgs)
+
+	// O cadastro anônimo numa organização existente não existe mais
+	legacy := map[string]interface{}{
+		"organization_id": s.validOrgID, "name": "Intruso", "email": "intruso@smartgondola.com",
+		"password": password, "role": "tenant_admin",
+	}
+	s.Equal(http.StatusNotFound, s.postJSON("/api/v1/auth/register", "", legacy).Code)
+}
+
+func (s *UserE2ESuite) TestSignup_CreatesFirstStoreAndProTrial() {
+	password := "SenhaForte123!"
+	owner := userDTO.SignupOwnerRequest{Name: "Dona", Email: "dona@mercadinho.com", Password: password}
+
+	// Enterprise não é self-service; teste fora do pro também não
+	enterprise := userDTO.SignupRequest{
+		Organization: dto.CreateOrganizationRequest{Name: "Rede", Document: "11222333000181", Slug: "rede", Sector: "retail", Plan: "enterprise"},
+		Owner:        owner,
+	}
+	s.Equal(http.StatusBadRequest, s.postJSON("/api/v1/auth/signup", "", enterprise).Code)
+
+	// Pro no cadastro é sempre teste, com a primeira loja informada pelo cliente
+	signup := userDTO.SignupRequest{
+		Organization: dto.CreateOrganizationRequest{Name: "Mercadinho", Document: "11222333000181", Slug: "mercadinho", Sector: "supermarket", Plan: "pro"},
+		Owner:        owner,
+		Store:        &userDTO.SignupStoreRequest{Name: "Centro", Code: "CTR-01", Address: dto.AddressInput{City: "Campinas", State: "SP"}},
+	}
+	w := s.postJSON("/api/v1/auth/signup", "", signup)
+	s.Require().Equal(http.StatusCreated, w.Code, w.Body.String())
+
+	var res struct {
+		Data userDTO.SignupResponse `json:"data"`
+	}
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &res))
+	org := res.Data.Organization
+	s.Equal(orgEntity.PlanPro, org.Plan)
+	s.Require().NotNil(org.TrialEndsAt)
+	s.WithinDuration(time.Now().Add(config.Get().TrialDuration), *org.TrialEndsAt, time.Minute)
+	s.Require().NotNil(res.Data.Store)
+	s.Equal(org.ID, res.Data.Store.OrganizationID)
+	s.Equal("CTR-01", res.Data.Store.Code)
+	s.Equal("Campinas", res.Data.Store.Address.City)
+
+	// O histórico já começa com o teste do pro
+	tokens := s.login(owner.Email, password)
+	w = s.doJSON("GET", "/api/v1/organizations/"+org.ID.String()+"/plan-history", tokens.AccessToken, nil)
+	s.Require().Equal(http.StatusOK, w.Code)
+	var history struct {
+		Data []dto.PlanPeriodResponse `json:"data"`
+	}
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &history))
+	s.Require().Len(history.Data, 1)
+	s.Equal(orgEntity.PlanPro, history.Data[0].Plan)
+	s.True(history.Data[0].Trial)
+	s.Nil(history.Data[0].EffectiveTo)
+
+	// Sem loja no pedido, o free ganha a loja padrão
+	free := userDTO.SignupRequest{
+		Organization: dto.CreateOrganizationRequest{Name: "Farmácia", Document: "06990590000123", Slug: "farmacia", Sector: "pharmacy", Plan: "free", Trial: true},
+		Owner:        userDTO.SignupOwnerRequest{Name: "Dono", Email: "dono@farmacia.com", Password: password},
+	}
+	w = s.postJSON("/api/v1/auth/signup", "", free)
+	s.Require().Equal(http.StatusCreated, w.Code, w.Body.String())
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &res))
+	s.Equal(orgEntity.PlanFree, res.Data.Organization.Plan)
+	s.Nil(res.Data.Organization.TrialEndsAt, "free nunca é teste")
+	s.Equal("LJ-001", res.Data.Store.Code)
+}
+
+func (s *UserE2ESuite) TestCreateOrganization_OnlyPlatform() {
+	password := "SenhaForte123!"
+	s.registerUser("admin.cliente@smartgondola.com", password, entity.RoleTenantAdmin)
+	admin := s.login("admin.cliente@smartgondola.com", password)
+
+	input := dto.CreateOrganizationRequest{Name: "Conta Enterprise", Document: "06990590000123", Slug: "conta-enterprise", Sector: "retail", Plan: "enterprise"}
+	s.Equal(http.StatusForbidden, s.postJSON("/api/v1/organizations", admin.AccessToken, input).Code)
+
+	platformOrgID := uuid.New()
+	_, err := s.db.Exec(`
+		INSERT INTO organizations (id, name, document, slug, plan, sector, settings, is_active)
+		VALUES ($1, 'Smart Gondola', '11222333000181', 'smart-gondola', 'enterprise', 'retail', '{}', true)
+	`, platformOrgID)
+	s.Require().NoError(err)
+	seedUser(s.T(), s.db, platformOrgID, "Root", "root@smartgondola.com", password, entity.RoleSuperAdmin)
+	root := s.login("root@smartgondola.com", password)
+
+	w := s.postJSON("/api/v1/organizations", root.AccessToken, input)
+	s.Require().Equal(http.StatusCreated, w.Code, w.Body.String())
+
+	// Teste só no pro, mesmo para a plataforma
+	input.Slug, input.Document, input.Trial = "outra-conta", "13347016000117", true
+	s.Equal(http.StatusBadRequest, s.postJSON("/api/v1/organizations", root.AccessToken, input).Code)
+}
+
+func (s *UserE2ESuite) TestQuota_ConcurrentUserCreationsStopAtPlanLimit() {
+	password := "SenhaForte123!"
+	_, err := s.db.Exec(`UPDATE organizations SET plan = 'free' WHERE id = $1`, s.validOrgID)
+	s.Require().NoError(err)
+	s.registerUser("admin.free@smartgondola.com", password, entity.RoleTenantAdmin)
+	admin := s.login("admin.free@smartgondola.com", password)
+
+	// Free = 2 usuários: o admin já ocupa um, só um dos pedidos simultâneos pode passar
+	const attempts = 8
+	var wg sync.WaitGroup
+	results := make(chan int, attempts)
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			w := s.postJSON("/api/v1/users", admin.AccessToken, userDTO.CreateUserRequest{
+				Name: "Operador", Email: fmt.Sprintf("operador%d@smartgondola.com", i), Password: password, Role: entity.RoleOperator,
+			})
+			results <- w.Code
+		}(i)
+	}
+	wg.Wait()
+	close(results)
+
+	codes := map[int]int{}
+	for code := range results {
+		codes[code]++
+	}
+	s.Equal(1, codes[http.StatusCreated], codes)
+	s.Equal(attempts-1, codes[http.StatusPaymentRequired], codes)
+
+	var users int
+	s.Require().NoError(s.db.QueryRow(`SELECT COUNT(*) FROM users WHERE organization_id = $1`, s.validOrgID).Scan(&users))
+	s.Equal(2, users)
+
+	// Convite aceito também ocupa cota: com o plano cheio, o convite continua pendente
+	w := s.postJSON("/api/v1/invites", admin.AccessToken, userDTO.CreateInviteRequest{Email: "convidado@smartgondola.com", Role: entity.RoleOperator})
+	s.Require().Equal(http.StatusCreated, w.Code, w.Body.String())
+	token := s.lastEmailToken("convidado@smartgondola.com")
+	accept := userDTO.AcceptInviteRequest{Token: token, Name: "Convidado", Password: password}
+	s.Equal(http.StatusPaymentRequired, s.postJSON("/api/v1/auth/invites/accept", "", accept).Code)
+
+	// Upgrade libera
+	_, err = s.db.Exec(`UPDATE organizations SET plan = 'pro' WHERE id = $1`, s.validOrgID)
+	s.Require().NoError(err)
+	s.Equal(http.StatusCreated, s.postJSON("/api/v1/auth/invites/accept", "", accept).Code)
+}
+
+func (s *UserE2ESuite) TestQuota_DevicesAndUsageEndpoint() {
+	password := "SenhaForte123!"
+	_, err := s.db.Exec(`UPDATE organizations SET settings = '{"max_devices": 1}' WHERE id = $1`, s.validOrgID)
+	s.Require().NoError(err)
+	s.registerUser("gerente.app@smartgondola.com", password, entity.RoleTenantAdmin)
+
+	loginWith := func(deviceID string) *httptest.ResponseRecorder {
+		return s.postJSON("/api/v1/auth/login", "", userDTO.LoginRequest{
+			Email: "gerente.app@smartgondola.com", Password: password, DeviceInfo: userDTO.DeviceInfo{DeviceID: deviceID},
+		})
+	}
+
+	first := loginWith("celular-1")
+	s.Require().Equal(http.StatusOK, first.Code)
+	lastLogin := func() (at time.Time) {
+		s.Require().NoError(s.db.QueryRow(`SELECT last_login_at FROM users WHERE email = 'gerente.app@smartgondola.com'`).Scan(&at))
+		return at
+	}
+	before := lastLogin()
+	s.Equal(http.StatusPaymentRequired, loginWith("celular-2").Code, "aparelho novo além da cota")
+	s.Equal(before, lastLogin(), "login recusado não conta como acesso")
+	var status string
+	s.Require().NoError(s.db.QueryRow(`SELECT status FROM login_attempts WHERE device_id = 'celular-2'`).Scan(&status))
+	s.Equal(string(entity.LoginDeviceQuota), status)
+	s.Equal(http.StatusOK, loginWith("celular-1").Code, "aparelho já registrado não ocupa cota nova")
+	s.Equal(http.StatusOK, s.postJSON("/api/v1/auth/login", "", userDTO.LoginRequest{
+		Email: "gerente.app@smartgondola.com", Password: password,
+	}).Code, "login sem aparelho (web) não é afetado")
+
+	var login struct {
+		Data userDTO.LoginResponse `json:"data"`
+	}
+	s.Require().NoError(json.Unmarshal(first.Body.Bytes(), &login))
+
+	w := s.postJSON("/api/v1/stores", login.Data.AccessToken, dto.CreateStoreRequest{Name: "Loja 1", Code: "L-01"})
+	s.Require().Equal(http.StatusCreated, w.Code, w.Body.String())
+
+	w = s.doJSON("GET", "/api/v1/organizations/"+s.validOrgID.String()+"/usage", login.Data.AccessToken, nil)
+	s.Require().Equal(http.StatusOK, w.Code)
+	var usage struct {
+		Data dto.UsageResponse `json:"data"`
+	}
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &usage))
+	s.Equal(orgEntity.PlanPro, usage.Data.Plan)
+	s.Equal(dto.QuotaUsage{Used: 1, Limit: 10}, usage.Data.Users, "limite vem do plano quando o settings não define")
+	s.Equal(dto.QuotaUsage{Used: 1, Limit: 1}, usage.Data.Devices, "limite próprio do tenant")
+	s.Equal(dto.QuotaUsage{Used: 1, Limit: 10}, usage.Data.Stores)
+
+	// Outra organização na URL: 404
+	w = s.doJSON("GET", "/api/v1/organizations/"+uuid.New().String()+"/usage", login.Data.AccessToken, nil)
+	s.Equal(http.StatusNotFound, w.Code)
+}
+
+func (s *UserE2ESuite) TestQuota_SuspendedUsersFreeTheirSeatAndDevices() {
+	password := "SenhaForte123!"
+	_, err := s.db.Exec(`UPDATE organizations SET plan = 'free', settings = '{"max_devices": 1}' WHERE id = $1`, s.validOrgID)
+	s.Require().NoError(err)
+	s.registerUser("admin.free@smartgondola.com", password, entity.RoleTenantAdmin)
+	admin := s.login("admin.free@smartgondola.com", password)
+
+	createOperator := func(email string) *httptest.ResponseRecorder {
+		return s.postJSON("/api/v1/users", admin.AccessToken, userDTO.CreateUserRequest{Name: "Operador", Email: email, Password: password, Role: entity.RoleOperator})
+	}
+	loginWith := func(email, deviceID string) int {
+		return s.postJSON("/api/v1/auth/login", "", userDTO.LoginRequest{Email: email, Password: password, DeviceInfo: userDTO.DeviceInfo{DeviceID: deviceID}}).Code
+	}
+
+	// Free = 2 usuários; o aparelho do operador ocupa a única vaga de aparelho
+	w := createOperator("demitido@smartgondola.com")
+	s.Require().Equal(http.StatusCreated, w.Code, w.Body.String())
+	var fired struct {
+		Data userDTO.UserResponse `json:"data"`
+	}
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &fired))
+	s.Require().Equal(http.StatusOK, loginWith("demitido@smartgondola.com", "celular-1"))
+	s.Equal(http.StatusPaymentRequired, createOperator("substituto@smartgondola.com").Code)
+	s.Equal(http.StatusPaymentRequired, loginWith("admin.free@smartgondola.com", "celular-2"))
+
+	// Suspenso libera a vaga dele e a dos aparelhos
+	s.Require().Equal(http.StatusOK, s.postJSON("/api/v1/users/"+fired.Data.ID.String()+"/suspend", admin.AccessToken, nil).Code)
+	s.Equal(http.StatusCreated, createOperator("substituto@smartgondola.com").Code)
+	s.Equal(http.StatusOK, loginWith("admin.free@smartgondola.com", "celular-2"))
+
+	// Voltar exige vaga no plano
+	reactivate := "/api/v1/users/" + fired.Data.ID.String() + "/reactivate"
+	s.Equal(http.StatusPaymentRequired, s.postJSON(reactivate, admin.AccessToken, nil).Code)
+	_, err = s.db.Exec(`UPDATE organizations SET plan = 'pro' WHERE id = $1`, s.validOrgID)
+	s.Require().NoError(err)
+	s.Require().Equal(http.StatusOK, s.postJSON(reactivate, admin.AccessToken, nil).Code)
+
+	// Os aparelhos antigos não voltam a ocupar cota por conta própria
+	w = s.doJSON("GET", "/api/v1/organizations/"+s.validOrgID.String()+"/usage", admin.AccessToken, nil)
+	s.Require().Equal(http.StatusOK, w.Code)
+	var usage struct {
+		Data dto.UsageResponse `json:"data"`
+	}
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &usage))
+	s.Equal(3, usage.Data.Users.Used)
+	s.Equal(1, usage.Data.Devices.Used)
+}
+
+func (s *UserE2ESuite) TestPlanChange_PreviewRefuseScheduleAndPlatformOverrides() {
+	password := "SenhaForte123!"
+	s.registerUser("dono.plano@smartgondola.com", password, entity.RoleTenantAdmin)
+	admin := s.login("dono.plano@smartgondola.com", password)
+	orgPath := "/api/v1/organizations/" + s.validOrgID.String()
+
+	for _, code := range []string{"L-01", "L-02"} {
+		w := s.postJSON("/api/v1/stores", admin.AccessToken, dto.CreateStoreRequest{Name: "Loja " + code, Code: code})
+		s.Require().Equal(http.StatusCreated, w.Code, w.Body.String())
+	}
+
+	// Prévia: free comporta 1 loja
+	w := s.doJSON("GET", orgPath+"/plan/preview?plan=free", admin.AccessToken, nil)
+	s.Require().Equal(http.StatusOK, w.Code, w.Body.String())
+	var preview struct {
+		Data dto.PlanChangePreviewResponse `json:"data"`
+	}
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &preview))
+	s.False(preview.Data.AllowedNow)
+	s.True(preview.Data.Stores.Exceeded)
+
+	// Em teste, o tenant não se efetiva no pro sozinho
+	_, err := s.db.Exec(`UPDATE organizations SET trial_ends_at = NOW() + INTERVAL '7 days' WHERE id = $1`, s.validOrgID)
+	s.Require().NoError(err)
+	s.Equal(http.StatusForbidden, s.doJSON("PUT", orgPath+"/plan", admin.AccessToken, dto.ChangePlanRequest{Plan: "pro"}).Code)
+	_, err = s.db.Exec(`UPDATE organizations SET trial_ends_at = NULL WHERE id = $1`, s.validOrgID)
+	s.Require().NoError(err)
+
+	// Rebaixamento imediato recusado; agendado aceito
+	s.Equal(http.StatusConflict, s.doJSON("PUT", orgPath+"/plan", admin.AccessToken, dto.ChangePlanRequest{Plan: "free"}).Code)
+	s.Equal(http.StatusForbidden, s.doJSON("PUT", orgPath+"/plan", admin.AccessToken, dto.ChangePlanRequest{Plan: "enterprise"}).Code)
+
+	effectiveAt := time.Now().Add(72 * time.Hour)
+	w = s.doJSON("PUT", orgPath+"/plan", admin.AccessToken, dto.ChangePlanRequest{Plan: "free", EffectiveAt: &effectiveAt})
+	s.Require().Equal(http.StatusAccepted, w.Code, w.Body.String())
+	s.Equal(http.StatusOK, s.doJSON("GET", orgPath+"/plan/scheduled", admin.AccessToken, nil).Code)
+	s.Equal(http.StatusNoContent, s.doJSON("DELETE", orgPath+"/plan/scheduled", admin.AccessToken, nil).Code)
+	s.Equal(http.StatusNotFound, s.doJSON("GET", orgPath+"/plan/scheduled", admin.AccessToken, nil).Code)
+
+	// Exceções de cota e planos fora do self-service são da plataforma
+	limits := dto.UpdateLimitsRequest{MaxStores: 5}
+	s.Equal(http.StatusForbidden, s.doJSON("PUT", "/api/v1/admin/organizations/"+s.validOrgID.String()+"/limits", admin.AccessToken, limits).Code)
+
+	platformOrgID := uuid.New()
+	_, err = s.db.Exec(`
+		INSERT INTO organizations (id, name, document, slug, plan, sector, settings, is_active)
+		VALUES ($1, 'Smart Gondola', '11222333000181', 'smart-gondola', 'enterprise', 'retail', '{}', true)
+	`, platformOrgID)
+	s.Require().NoError(err)
+	seedUser(s.T(), s.db, platformOrgID, "Root", "root@smartgondola.com", password, entity.RoleSuperAdmin)
+	root := s.login("root@smartgondola.com", password)
+
+	w = s.doJSON("PUT", "/api/v1/admin/organizations/"+s.validOrgID.String()+"/limits", root.AccessToken, limits)
+	s.Require().Equal(http.StatusOK, w.Code, w.Body.String())
+	w = s.doJSON("PUT", "/api/v1/admin/organizations/"+s.validOrgID.String()+"/plan", root.AccessToken, dto.ChangePlanRequest{Plan: "free"})
+	s.Require().Equal(http.StatusOK, w.Code, w.Body.String())
+
+	var changed struct {
+		Data dto.PlanChangeResponse `json:"data"`
+	}
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &changed))
+	s.True(changed.Data.Applied)
+	s.Equal(orgEntity.PlanFree, changed.Data.Organization.Plan)
+	s.Equal(5, changed.Data.Organization.Settings.MaxStores, "exceção da plataforma vale no plano novo")
+
+	w = s.doJSON("GET", orgPath+"/plan-history", admin.AccessToken, nil)
+	s.Require().Equal(http.StatusOK, w.Code)
+	var history struct {
+		Data []dto.PlanPeriodResponse `json:"data"`
+	}
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &history))
+	s.Require().NotEmpty(history.Data)
+	s.Equal(orgEntity.PlanChangeRequested, history.Data[0].Reason)
+	s.Require().NotNil(history.Data[0].ChangedBy)
+}
+
+func (s *UserE2ESuite) TestCreateUser_UsesTokenOrganizationAndRoleHierarchy() {
+	password := "SenhaForte123!"
+	s.registerUser("gerente@smartgondola.com", password, entity.RoleManager)
+	manager := s.login("gerente@smartgondola.com", password)
+
+	// Gerente não cria usuários acima do próprio papel
+	tenant := userDTO.CreateUserRequest{Name: "Novo Dono", Email: "novo.dono@smartgondola.com", Password: password, Role: entity.RoleTenantAdmin}
+	s.Equal(http.StatusForbidden, s.postJSON("/api/v1/users", manager.AccessToken, tenant).Code)
+
+	operator := userDTO.CreateUserRequest{Name: "Operador", Email: "operador@smartgondola.com", Password: password, Role: entity.RoleOperator}
+	w := s.postJSON("/api/v1/users", manager.AccessToken, operator)
+	s.Require().Equal(http.StatusCreated, w.Code)
+
+	var created struct {
+		Data userDTO.UserResponse `json:"data"`
+	}
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &created))
+	s.Equal(s.validOrgID, created.Data.OrganizationID)
+	s.Equal(entity.RoleOperator, created.Data.Role)
+
+	// Operador não cria ninguém
+	operatorSession := s.login("operador@smartgondola.com", password)
+	other := userDTO.CreateUserRequest{Name: "Outro", Email: "outro@smartgondola.com", Password: password, Role: entity.RoleOperator}
+	s.Equal(http.StatusForbidden, s.postJSON("/api/v1/users", operatorSession.AccessToken, other).Code)
+}
+
 func (s *UserE2ESuite) TestLogin_InvalidCredentials() {
 	loginBody := userDTO.LoginRequest{
 		Email:    "naoexiste@email.com",
@@ -178,20 +563,7 @@ func (s *UserE2ESuite) TestLogin_LockoutMechanism() {
 	email := "alvo_bruteforce@smartgondola.com"
 	correctPassword := "SenhaSegura123!"
 
-	// (Simulando o registro rápido desse usuário alvo para o teste)
-	registerReq := userDTO.CreateUserRequest{
-		OrganizationID: s.validOrgID, // Assumindo que você tem isso no seu Suite
-		Name:           "Alvo Lockout",
-		Email:          email,
-		Password:       correctPassword,
-		Role:           entity.RoleManager,
-	}
-	bodyReg, _ := json.Marshal(registerReq)
-	reqReg, _ := http.NewRequest("POST", "/api/v1/auth/register", bytes.NewBuffer(bodyReg))
-	reqReg.Header.Set("Content-Type", "application/json")
-	wReg := httptest.NewRecorder()
-	s.handler.ServeHTTP(wReg, reqReg)
-	s.Require().Equal(http.StatusCreated, wReg.Code)
+	seedUser(s.T(), s.db, s.validOrgID, "Alvo Lockout", email, correctPassword, entity.RoleManager)
 
 	// 2. O Atacante tenta errar a senha 5 vezes seguidas
 	for i := 1; i <= 5; i++ {
@@ -242,19 +614,7 @@ func (s *UserE2ESuite) TestLogin_LockoutExpired_AllowsLogin() {
 	email := "expira_lockout@smartgondola.com"
 	correctPassword := "SenhaSegura123!"
 
-	registerReq := userDTO.CreateUserRequest{
-		OrganizationID: s.validOrgID,
-		Name:           "Usuário Expira Lockout",
-		Email:          email,
-		Password:       correctPassword,
-		Role:           entity.RoleManager,
-	}
-	bodyReg, _ := json.Marshal(registerReq)
-	reqReg, _ := http.NewRequest("POST", "/api/v1/auth/register", bytes.NewBuffer(bodyReg))
-	reqReg.Header.Set("Content-Type", "application/json")
-	wReg := httptest.NewRecorder()
-	s.handler.ServeHTTP(wReg, reqReg)
-	s.Require().Equal(http.StatusCreated, wReg.Code)
+	seedUser(s.T(), s.db, s.validOrgID, "Usuário Expira Lockout", email, correctPassword, entity.RoleManager)
 
 	for i := 1; i <= 5; i++ {
 		loginReq := userDTO.LoginRequest{
@@ -276,8 +636,8 @@ func (s *UserE2ESuite) TestLogin_LockoutExpired_AllowsLogin() {
 	}
 
 	_, err := s.db.Exec(`
-		UPDATE users
-		SET locked_until = NOW() - INTERVAL '1 minute'
+		UPDATE login_throttles
+		SET blocked_until = NOW() - INTERVAL '1 minute'
 		WHERE email = $1
 	`, email)
 	s.Require().NoError(err)
@@ -306,19 +666,7 @@ func (s *UserE2ESuite) TestLogin_SuccessResetsSecurityFields() {
 	email := "reset_security_fields@smartgondola.com"
 	correctPassword := "SenhaSegura123!"
 
-	registerReq := userDTO.CreateUserRequest{
-		OrganizationID: s.validOrgID,
-		Name:           "Usuário Reset Segurança",
-		Email:          email,
-		Password:       correctPassword,
-		Role:           entity.RoleManager,
-	}
-	bodyReg, _ := json.Marshal(registerReq)
-	reqReg, _ := http.NewRequest("POST", "/api/v1/auth/register", bytes.NewBuffer(bodyReg))
-	reqReg.Header.Set("Content-Type", "application/json")
-	wReg := httptest.NewRecorder()
-	s.handler.ServeHTTP(wReg, reqReg)
-	s.Require().Equal(http.StatusCreated, wReg.Code)
+	seedUser(s.T(), s.db, s.validOrgID, "Usuário Reset Segurança", email, correctPassword, entity.RoleManager)
 
 	for i := 0; i < 2; i++ {
 		badLogin := userDTO.LoginRequest{Email: email, Password: "SenhaIncorreta"}
@@ -338,18 +686,16 @@ func (s *UserE2ESuite) TestLogin_SuccessResetsSecurityFields() {
 	s.handler.ServeHTTP(wGood, reqGood)
 	s.Require().Equal(http.StatusOK, wGood.Code)
 
-	var failedAttempts int
-	var lockedUntil sql.NullTime
+	var pairCounters int
 	var lastLoginAt sql.NullTime
 	err := s.db.QueryRow(`
-		SELECT failed_login_attempts, locked_until, last_login_at
-		FROM users
-		WHERE email = $1
-	`, email).Scan(&failedAttempts, &lockedUntil, &lastLoginAt)
+		SELECT
+			(SELECT COUNT(*) FROM login_throttles WHERE scope = 'ip_email' AND email = $1),
+			(SELECT last_login_at FROM users WHERE email = $1)
+	`, email).Scan(&pairCounters, &lastLoginAt)
 	s.Require().NoError(err)
 
-	s.Equal(0, failedAttempts)
-	s.False(lockedUntil.Valid)
+	s.Equal(0, pairCounters)
 	s.True(lastLoginAt.Valid)
 }
 
@@ -357,19 +703,7 @@ func (s *UserE2ESuite) TestLogin_InactiveUser_ShouldReturnUnauthorized() {
 	email := "inactive_user@smartgondola.com"
 	password := "SenhaSegura123!"
 
-	registerReq := userDTO.CreateUserRequest{
-		OrganizationID: s.validOrgID,
-		Name:           "Usuário Inativo",
-		Email:          email,
-		Password:       password,
-		Role:           entity.RoleManager,
-	}
-	bodyReg, _ := json.Marshal(registerReq)
-	reqReg, _ := http.NewRequest("POST", "/api/v1/auth/register", bytes.NewBuffer(bodyReg))
-	reqReg.Header.Set("Content-Type", "application/json")
-	wReg := httptest.NewRecorder()
-	s.handler.ServeHTTP(wReg, reqReg)
-	s.Require().Equal(http.StatusCreated, wReg.Code)
+	seedUser(s.T(), s.db, s.validOrgID, "Usuário Inativo", email, password, entity.RoleManager)
 
 	_, err := s.db.Exec(`
 		UPDATE users
@@ -400,19 +734,7 @@ func (s *UserE2ESuite) TestLogin_LockoutConcurrentAttempts() {
 	email := "concorrente_lockout@smartgondola.com"
 	password := "SenhaSegura123!"
 
-	registerReq := userDTO.CreateUserRequest{
-		OrganizationID: s.validOrgID,
-		Name:           "Usuário Concorrência Lockout",
-		Email:          email,
-		Password:       password,
-		Role:           entity.RoleManager,
-	}
-	bodyReg, _ := json.Marshal(registerReq)
-	reqReg, _ := http.NewRequest("POST", "/api/v1/auth/register", bytes.NewBuffer(bodyReg))
-	reqReg.Header.Set("Content-Type", "application/json")
-	wReg := httptest.NewRecorder()
-	s.handler.ServeHTTP(wReg, reqReg)
-	s.Require().Equal(http.StatusCreated, wReg.Code)
+	seedUser(s.T(), s.db, s.validOrgID, "Usuário Concorrência Lockout", email, password, entity.RoleManager)
 
 	const attempts = 10
 	var wg sync.WaitGroup
@@ -459,35 +781,25 @@ func (s *UserE2ESuite) TestLogin_LockoutConcurrentAttempts() {
 	}
 	s.True(has429, "após burst concorrente, o lockout deve ocorrer de forma eventual")
 
-	var failedAttempts int
-	var lockedUntil sql.NullTime
+	// O bloqueio é gravado uma vez por estouro do limite, não uma vez por requisição concorrente
+	var lockouts int
+	var blockedUntil sql.NullTime
 	err := s.db.QueryRow(`
-		SELECT failed_login_attempts, locked_until
-		FROM users
-		WHERE email = $1
-	`, email).Scan(&failedAttempts, &lockedUntil)
+		SELECT lockouts, blocked_until
+		FROM login_throttles
+		WHERE scope = 'ip_email' AND email = $1
+	`, email).Scan(&lockouts, &blockedUntil)
 	s.Require().NoError(err)
-	s.GreaterOrEqual(failedAttempts, 1)
-	s.True(lockedUntil.Valid)
+	s.GreaterOrEqual(lockouts, 1)
+	s.LessOrEqual(lockouts, attempts/5)
+	s.True(blockedUntil.Valid)
 }
 
 func (s *UserE2ESuite) TestLogin_AfterLockoutExpiresAndSuccess_FirstFailureStartsFromOne() {
 	email := "apos_expirar_reinicia_contador@smartgondola.com"
 	password := "SenhaSegura123!"
 
-	registerReq := userDTO.CreateUserRequest{
-		OrganizationID: s.validOrgID,
-		Name:           "Usuário Reinício Contador",
-		Email:          email,
-		Password:       password,
-		Role:           entity.RoleManager,
-	}
-	bodyReg, _ := json.Marshal(registerReq)
-	reqReg, _ := http.NewRequest("POST", "/api/v1/auth/register", bytes.NewBuffer(bodyReg))
-	reqReg.Header.Set("Content-Type", "application/json")
-	wReg := httptest.NewRecorder()
-	s.handler.ServeHTTP(wReg, reqReg)
-	s.Require().Equal(http.StatusCreated, wReg.Code)
+	seedUser(s.T(), s.db, s.validOrgID, "Usuário Reinício Contador", email, password, entity.RoleManager)
 
 	for i := 1; i <= 5; i++ {
 		badLogin := userDTO.LoginRequest{Email: email, Password: "SenhaIncorreta"}
@@ -499,8 +811,8 @@ func (s *UserE2ESuite) TestLogin_AfterLockoutExpiresAndSuccess_FirstFailureStart
 	}
 
 	_, err := s.db.Exec(`
-		UPDATE users
-		SET locked_until = NOW() - INTERVAL '1 minute'
+		UPDATE login_throttles
+		SET blocked_until = NOW() - INTERVAL '1 minute'
 		WHERE email = $1
 	`, email)
 	s.Require().NoError(err)
@@ -522,15 +834,1147 @@ func (s *UserE2ESuite) TestLogin_AfterLockoutExpiresAndSuccess_FirstFailureStart
 	s.Equal(http.StatusUnauthorized, wFail.Code)
 
 	var failedAttempts int
-	var lockedUntil sql.NullTime
+	var blockedUntil sql.NullTime
 	err = s.db.QueryRow(`
-		SELECT failed_login_attempts, locked_until
-		FROM users
-		WHERE email = $1
-	`, email).Scan(&failedAttempts, &lockedUntil)
+		SELECT failures, blocked_until
+		FROM login_throttles
+		WHERE scope = 'ip_email' AND email = $1
+	`, email).Scan(&failedAttempts, &blockedUntil)
 	s.Require().NoError(err)
 	s.Equal(1, failedAttempts)
-	s.False(lockedUntil.Valid)
+	s.False(blockedUntil.Valid)
+}
+
+func (s *UserE2ESuite) TestLoginThrottle_TyposStuffingAndUnlock() {
+	password := "SenhaSegura123!"
+	victim := seedUser(s.T(), s.db, s.validOrgID, "Vítima", "vitima@smartgondola.com", password, entity.RoleOperator)
+	seedUser(s.T(), s.db, s.validOrgID, "Admin", "admin.throttle@smartgondola.com", password, entity.RoleTenantAdmin)
+	homeIP := "198.51.100.7"
+	s.Require().Equal(http.StatusOK, s.loginFrom(homeIP, victim.Email, password).Code)
+
+	// 1. Um IP errando a mesma conta: bloqueia só esse par, com Retry-After
+	attackerIP := "203.0.113.9"
+	for i := 1; i <= 5; i++ {
+		w := s.loginFrom(attackerIP, victim.Email, "SenhaErrada")
+		if i < 5 {
+			s.Equal(http.StatusUnauthorized, w.Code)
+		} else {
+			s.Equal(http.StatusTooManyRequests, w.Code)
+			s.NotEmpty(w.Header().Get("Retry-After"))
+		}
+	}
+	s.Equal(http.StatusTooManyRequests, s.loginFrom(attackerIP, victim.Email, password).Code)
+	s.Equal(http.StatusOK, s.loginFrom(homeIP, victim.Email, password).Code, "a vítima continua entrando")
+
+	// 2. A mesma conta atacada de vários IPs: bloqueia IPs desconhecidos, nunca os da vítima
+	for i := 10; i < 24; i++ {
+		s.Equal(http.StatusUnauthorized, s.loginFrom(fmt.Sprintf("203.0.113.%d", i), victim.Email, "SenhaErrada").Code)
+	}
+	s.Equal(http.StatusTooManyRequests, s.loginFrom("203.0.113.24", victim.Email, "SenhaErrada").Code, "20ª falha da conta")
+	s.Equal(http.StatusTooManyRequests, s.loginFrom("203.0.113.50", victim.Email, password).Code)
+	s.Equal(http.StatusOK, s.loginFrom(homeIP, victim.Email, password).Code)
+
+	var reason string
+	s.Require().NoError(s.db.QueryRow(`SELECT reason FROM login_throttles WHERE scope = 'email' AND email = $1`, victim.Email).Scan(&reason))
+	s.Equal("distributed", reason)
+
+	// 3. O tenant admin desbloqueia a conta em qualquer IP
+	admin := s.login("admin.throttle@smartgondola.com", password)
+	unlock := s.postJSON("/api/v1/users/"+victim.ID.String()+"/unlock", admin.AccessToken, nil)
+	s.Require().Equal(http.StatusOK, unlock.Code)
+	s.Equal(http.StatusOK, s.loginFrom("203.0.113.50", victim.Email, password).Code)
+	s.Equal(http.StatusOK, s.loginFrom(attackerIP, victim.Email, password).Code)
+
+	// 4. Um IP testando muitas contas (credential stuffing): o IP inteiro é bloqueado
+	stuffingIP := "203.0.113.200"
+	var last *httptest.ResponseRecorder
+	for i := 0; i < 10; i++ {
+		last = s.loginFrom(stuffingIP, fmt.Sprintf("lista%d@vazamento.com", i), "123456")
+	}
+	s.Equal(http.StatusTooManyRequests, last.Code)
+	s.Equal(http.StatusTooManyRequests, s.loginFrom(stuffingIP, victim.Email, password).Code)
+	s.Require().NoError(s.db.QueryRow(`SELECT reason FROM login_throttles WHERE scope = 'ip' AND ip_address = $1`, stuffingIP).Scan(&reason))
+	s.Equal("credential_stuffing", reason)
+
+	// 5. Bloqueio de IP é da plataforma: o tenant não enxerga; o super admin lista e libera
+	platformOrgID := uuid.New()
+	_, err := s.db.Exec(`
+		INSERT INTO organizations (id, name, document, slug, plan, sector, settings, is_active)
+		VALUES ($1, 'Smart Gondola', '11222333000181', 'smart-gondola', 'enterprise', 'retail', '{}', true)
+	`, platformOrgID)
+	s.Require().NoError(err)
+	seedUser(s.T(), s.db, platformOrgID, "Root", "root@smartgondola.com", password, entity.RoleSuperAdmin)
+	root := s.login("root@smartgondola.com", password)
+
+	s.Equal(http.StatusForbidden, s.doJSON("GET", "/api/v1/admin/login-throttles", admin.AccessToken, nil).Code)
+	list := s.doJSON("GET", "/api/v1/admin/login-throttles", root.AccessToken, nil)
+	s.Require().Equal(http.StatusOK, list.Code)
+	s.Contains(list.Body.String(), stuffingIP)
+
+	bad := s.postJSON("/api/v1/admin/login-throttles/unlock", root.AccessToken, map[string]string{})
+	s.Equal(http.StatusBadRequest, bad.Code)
+	ok := s.postJSON("/api/v1/admin/login-throttles/unlock", root.AccessToken, userDTO.UnlockLoginRequest{IPAddress: stuffingIP})
+	s.Require().Equal(http.StatusOK, ok.Code)
+	s.Equal(http.StatusOK, s.loginFrom(stuffingIP, victim.Email, password).Code)
+}
+
+func (s *UserE2ESuite) TestLogin_UpgradesOutdatedPasswordHash() {
+	email := "hash_antigo@smartgondola.com"
+	password := "SenhaSegura123!"
+	user := seedUser(s.T(), s.db, s.validOrgID, "Hash Antigo", email, password, entity.RoleManager)
+
+	// Hash de antes da migração: bcrypt com custo baixo
+	legacy, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	s.Require().NoError(err)
+	_, err = s.db.Exec(`UPDATE users SET password_hash = $1 WHERE id = $2`, string(legacy), user.ID)
+	s.Require().NoError(err)
+
+	var changedBefore time.Time
+	s.Require().NoError(s.db.QueryRow(`SELECT password_changed_at FROM users WHERE id = $1`, user.ID).Scan(&changedBefore))
+
+	// Senha errada não mexe no hash
+	s.Equal(http.StatusUnauthorized, s.postJSON("/api/v1/auth/login", "", userDTO.LoginRequest{Email: email, Password: "SenhaErrada"}).Code)
+	var stored string
+	s.Require().NoError(s.db.QueryRow(`SELECT password_hash FROM users WHERE id = $1`, user.ID).Scan(&stored))
+	s.Equal(string(legacy), stored)
+
+	// Login certo refaz o hash com o algoritmo atual, sem contar como troca de senha
+	session := s.login(email, password)
+	var changedAfter time.Time
+	s.Require().NoError(s.db.QueryRow(`SELECT password_hash, password_changed_at FROM users WHERE id = $1`, user.ID).Scan(&stored, &changedAfter))
+	s.True(strings.HasPrefix(stored, "$argon2id$"), stored)
+	s.True(changedBefore.Equal(changedAfter))
+	s.Equal(http.StatusOK, s.getOrganization(session.AccessToken))
+
+	// O hash novo confere e já está atualizado
+	s.login(email, password)
+	var again string
+	s.Require().NoError(s.db.QueryRow(`SELECT password_hash FROM users WHERE id = $1`, user.ID).Scan(&again))
+	s.Equal(stored, again)
+}
+
+func (s *UserE2ESuite) TestRefreshToken_RotationAndReuseDetection() {
+	email := "refresh_rotation@smartgondola.com"
+	password := "SenhaSegura123!"
+
+	seedUser(s.T(), s.db, s.validOrgID, "Usuário Refresh", email, password, entity.RoleManager)
+
+	// 1. Login entrega o refresh token
+	loginReq := userDTO.LoginRequest{Email: email, Password: password, DeviceInfo: userDTO.DeviceInfo{DeviceID: "device-refresh-01"}}
+	bodyLogin, _ := json.Marshal(loginReq)
+	reqLogin, _ := http.NewRequest("POST", "/api/v1/auth/login", bytes.NewBuffer(bodyLogin))
+	reqLogin.Header.Set("Content-Type", "application/json")
+	wLogin := httptest.NewRecorder()
+	s.handler.ServeHTTP(wLogin, reqLogin)
+	s.Require().Equal(http.StatusOK, wLogin.Code)
+
+	var loginResp struct {
+		Data userDTO.LoginResponse `json:"data"`
+	}
+	s.Require().NoError(json.Unmarshal(wLogin.Body.Bytes(), &loginResp))
+	firstRefresh := loginResp.Data.RefreshToken
+	s.Require().NotEmpty(firstRefresh, "login deve devolver refresh token")
+
+	refresh := func(token string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(userDTO.RefreshTokenRequest{RefreshToken: token})
+		req, _ := http.NewRequest("POST", "/api/v1/auth/refresh", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		s.handler.ServeHTTP(w, req)
+		return w
+	}
+
+	// 2. Rotação: o refresh token antigo gera um par novo
+	wRefresh := refresh(firstRefresh)
+	s.Require().Equal(http.StatusOK, wRefresh.Code)
+
+	var refreshResp struct {
+		Data userDTO.LoginResponse `json:"data"`
+	}
+	s.Require().NoError(json.Unmarshal(wRefresh.Body.Bytes(), &refreshResp))
+	secondRefresh := refreshResp.Data.RefreshToken
+	s.NotEmpty(refreshResp.Data.AccessToken)
+	s.NotEqual(firstRefresh, secondRefresh, "refresh token deve ser rotacionado a cada uso")
+
+	// 3. Reuso do token antigo derruba a família inteira
+	s.Equal(http.StatusUnauthorized, refresh(firstRefresh).Code)
+	s.Equal(http.StatusUnauthorized, refresh(secondRefresh).Code, "token legítimo também deve ser revogado após reuso")
+
+	// 4. Apenas o hash fica no banco
+	var stored int
+	err := s.db.QueryRow(`SELECT COUNT(*) FROM refresh_tokens WHERE token_hash = $1`, secondRefresh).Scan(&stored)
+	s.Require().NoError(err)
+	s.Equal(0, stored)
+}
+
+func (s *UserE2ESuite) TestLogout_RevokesAccessTokenImmediately() {
+	email := "logout@smartgondola.com"
+	password := "SenhaSegura123!"
+	s.registerUser(email, password, entity.RoleManager)
+	session := s.login(email, password)
+
+	s.Equal(http.StatusOK, s.getOrganization(session.AccessToken))
+
+	req, _ := http.NewRequest("POST", "/api/v1/auth/logout", nil)
+	req.Header.Set("Authorization", "Bearer "+session.AccessToken)
+	w := httptest.NewRecorder()
+	s.handler.ServeHTTP(w, req)
+	s.Require().Equal(http.StatusNoContent, w.Code)
+
+	// O mesmo token, ainda dentro do "exp", deixa de valer
+	s.Equal(http.StatusUnauthorized, s.getOrganization(session.AccessToken))
+}
+
+func (s *UserE2ESuite) TestLogoutAll_RevokesEverySession() {
+	email := "logout_all@smartgondola.com"
+	password := "SenhaSegura123!"
+	s.registerUser(email, password, entity.RoleManager)
+	phone := s.login(email, password)
+	tablet := s.login(email, password)
+
+	req, _ := http.NewRequest("POST", "/api/v1/auth/logout-all", nil)
+	req.Header.Set("Authorization", "Bearer "+phone.AccessToken)
+	w := httptest.NewRecorder()
+	s.handler.ServeHTTP(w, req)
+	s.Require().Equal(http.StatusNoContent, w.Code)
+
+	s.Equal(http.StatusUnauthorized, s.getOrganization(tablet.AccessToken))
+
+	body, _ := json.Marshal(userDTO.RefreshTokenRequest{RefreshToken: tablet.RefreshToken})
+	reqRefresh, _ := http.NewRequest("POST", "/api/v1/auth/refresh", bytes.NewBuffer(body))
+	reqRefresh.Header.Set("Content-Type", "application/json")
+	wRefresh := httptest.NewRecorder()
+	s.handler.ServeHTTP(wRefresh, reqRefresh)
+	s.Equal(http.StatusUnauthorized, wRefresh.Code)
+}
+
+func (s *UserE2ESuite) TestSuspendedUser_LosesLiveSession() {
+	email := "demitido@smartgondola.com"
+	password := "SenhaSegura123!"
+	s.registerUser(email, password, entity.RoleOperator)
+	session := s.login(email, password)
+	s.Require().Equal(http.StatusOK, s.getOrganization(session.AccessToken))
+
+	_, err := s.db.Exec(`UPDATE users SET status = 'suspended' WHERE email = $1`, email)
+	s.Require().NoError(err)
+
+	s.Equal(http.StatusUnauthorized, s.getOrganization(session.AccessToken))
+}
+
+func (s *UserE2ESuite) TestTwoFactor_EnrollAndTwoStepLogin() {
+	email := "2fa@smartgondola.com"
+	password := "SenhaSegura123!"
+	s.registerUser(email, password, entity.RoleManager)
+	session := s.login(email, password)
+
+	// 1. Cadastro: segredo -> primeiro código -> códigos de recuperação
+	wEnroll := s.postJSON("/api/v1/me/2fa/enroll", session.AccessToken, nil)
+	s.Require().Equal(http.StatusOK, wEnroll.Code)
+	var enroll struct {
+		Data userDTO.TwoFactorEnrollmentResponse `json:"data"`
+	}
+	s.Require().NoError(json.Unmarshal(wEnroll.Body.Bytes(), &enroll))
+
+	now := time.Now()
+	code, err := auth.TOTPCode(enroll.Data.Secret, now)
+	s.Require().NoError(err)
+	wConfirm := s.postJSON("/api/v1/me/2fa/confirm", session.AccessToken, userDTO.TwoFactorCodeRequest{Code: code})
+	s.Require().Equal(http.StatusOK, wConfirm.Code)
+	var recovery struct {
+		Data userDTO.RecoveryCodesResponse `json:"data"`
+	}
+	s.Require().NoError(json.Unmarshal(wConfirm.Body.Bytes(), &recovery))
+	s.Require().NotEmpty(recovery.Data.RecoveryCodes)
+
+	// 2. Login agora só devolve o desafio, sem tokens
+	challenge := s.login(email, password)
+	s.True(challenge.TwoFactorRequired)
+	s.Empty(challenge.AccessToken)
+	s.Require().NotEmpty(challenge.ChallengeToken)
+
+	// O desafio não serve como access token
+	s.Equal(http.StatusUnauthorized, s.getOrganization(challenge.ChallengeToken))
+
+	// 3. Código da próxima janela (dentro da tolerância) conclui o login
+	nextCode, err := auth.TOTPCode(enroll.Data.Secret, now.Add(30*time.Second))
+	s.Require().NoError(err)
+	verify := userDTO.TwoFactorLoginRequest{ChallengeToken: challenge.ChallengeToken, Code: nextCode}
+	wVerify := s.postJSON("/api/v1/auth/2fa/verify", "", verify)
+	s.Require().Equal(http.StatusOK, wVerify.Code)
+	var logged struct {
+		Data userDTO.LoginResponse `json:"data"`
+	}
+	s.Require().NoError(json.Unmarshal(wVerify.Body.Bytes(), &logged))
+	s.Equal(http.StatusOK, s.getOrganization(logged.Data.AccessToken))
+
+	// 4. O mesmo código não pode ser reutilizado
+	s.Equal(http.StatusUnauthorized, s.postJSON("/api/v1/auth/2fa/verify", "", verify).Code)
+
+	// 5. Código de recuperação funciona uma única vez
+	byRecovery := userDTO.TwoFactorLoginRequest{ChallengeToken: challenge.ChallengeToken, Code: recovery.Data.RecoveryCodes[0]}
+	s.Equal(http.StatusOK, s.postJSON("/api/v1/auth/2fa/verify", "", byRecovery).Code)
+	s.Equal(http.StatusUnauthorized, s.postJSON("/api/v1/auth/2fa/verify", "", byRecovery).Code)
+
+	// 6. Desafio emitido antes de um logout-all não conclui mais o login
+	stale := s.login(email, password)
+	s.Require().NotEmpty(stale.ChallengeToken)
+	time.Sleep(5 * time.Millisecond)
+	s.Require().Equal(http.StatusNoContent, s.postJSON("/api/v1/auth/logout-all", logged.Data.AccessToken, nil).Code)
+	byStale := userDTO.TwoFactorLoginRequest{ChallengeToken: stale.ChallengeToken, Code: recovery.Data.RecoveryCodes[1]}
+	s.Equal(http.StatusUnauthorized, s.postJSON("/api/v1/auth/2fa/verify", "", byStale).Code)
+	byStale.ChallengeToken = s.login(email, password).ChallengeToken
+	s.Equal(http.StatusOK, s.postJSON("/api/v1/auth/2fa/verify", "", byStale).Code, "o código de recuperação não foi gasto")
+}
+
+func (s *UserE2ESuite) TestPasswordReset_FullFlow() {
+	email := "esqueci@smartgondola.com"
+	password := "SenhaAntiga123!"
+	newPassword := "SenhaNova456!"
+	s.registerUser(email, password, entity.RoleManager)
+	session := s.login(email, password)
+
+	// Conta bloqueada por tentativas erradas (no IP de onde o usuário entra e em qualquer outro)
+	for _, scope := range []string{"ip_email", "email"} {
+		ip := "192.0.2.1"
+		if scope == "email" {
+			ip = ""
+		}
+		_, err := s.db.Exec(`
+			INSERT INTO login_throttles (scope, ip_address, email, failures, lockouts, window_started_at, last_failure_at, blocked_until, reason)
+			VALUES ($1, $2, $3, 0, 1, $4, $4, $5, 'typos')
+		`, scope, ip, email, time.Now().UTC(), time.Now().UTC().Add(time.Hour))
+		s.Require().NoError(err)
+	}
+	s.Equal(http.StatusTooManyRequests, s.postJSON("/api/v1/auth/login", "", userDTO.LoginRequest{Email: email, Password: password}).Code)
+
+	// 1. Email desconhecido recebe a mesma resposta
+	s.Equal(http.StatusAccepted, s.postJSON("/api/v1/auth/password/forgot", "", userDTO.ForgotPasswordRequest{Email: "ninguem@smartgondola.com"}).Code)
+	s.Equal(http.StatusAccepted, s.postJSON("/api/v1/auth/password/forgot", "", userDTO.ForgotPasswordRequest{Email: email}).Code)
+	s.container.PassUseCase.Wait() // O link é enviado fora da requisição
+
+	token := s.lastEmailToken(email)
+
+	// 2. Redefine a senha
+	reset := userDTO.ResetPasswordRequest{Token: token, NewPassword: newPassword}
+	s.Require().Equal(http.StatusNoContent, s.postJSON("/api/v1/auth/password/reset", "", reset).Code)
+
+	// 3. Sessões anteriores caem, o bloqueio é removido e a nova senha funciona
+	s.Equal(http.StatusUnauthorized, s.getOrganization(session.AccessToken))
+	s.Equal(http.StatusUnauthorized, s.postJSON("/api/v1/auth/refresh", "", userDTO.RefreshTokenRequest{RefreshToken: session.RefreshToken}).Code)
+	relogged := s.login(email, newPassword)
+	s.NotEmpty(relogged.AccessToken)
+
+	// 4. O link é de uso único
+	s.Equal(http.StatusBadRequest, s.postJSON("/api/v1/auth/password/reset", "", reset).Code)
+}
+
+func (s *UserE2ESuite) TestEmailVerification_ReadOnlyPolicy() {
+	s.setEmailPolicy("read_only")
+	email := "naoverificado@smartgondola.com"
+	password := "SenhaSegura123!"
+	s.registerUser(email, password, entity.RoleManager)
+	s.Require().Equal(http.StatusAccepted, s.postJSON("/api/v1/auth/email/resend", "", userDTO.ResendVerificationRequest{Email: email}).Code)
+
+	// 1. Sem confirmar: leitura liberada, escrita bloqueada
+	session := s.login(email, password)
+	s.Equal(http.StatusOK, s.getOrganization(session.AccessToken))
+	s.Equal(http.StatusForbidden, s.postJSON("/api/v1/me/2fa/enroll", session.AccessToken, nil).Code)
+
+	// 2. Confirma pelo link recebido
+	verify := userDTO.VerifyEmailRequest{Token: s.lastEmailToken(email)}
+	s.Require().Equal(http.StatusNoContent, s.postJSON("/api/v1/auth/email/verify", "", verify).Code)
+
+	// 3. O refresh emite um token sem a restrição
+	wRefresh := s.postJSON("/api/v1/auth/refresh", "", userDTO.RefreshTokenRequest{RefreshToken: session.RefreshToken})
+	s.Require().Equal(http.StatusOK, wRefresh.Code)
+	var refreshed struct {
+		Data userDTO.LoginResponse `json:"data"`
+	}
+	s.Require().NoError(json.Unmarshal(wRefresh.Body.Bytes(), &refreshed))
+	s.Equal(http.StatusOK, s.postJSON("/api/v1/me/2fa/enroll", refreshed.Data.AccessToken, nil).Code)
+}
+
+func (s *UserE2ESuite) TestEmailVerification_BlockPolicy() {
+	s.setEmailPolicy("block")
+	password := "SenhaSegura123!"
+	adminEmail := "dono@smartgondola.com"
+	s.registerUser(adminEmail, password, entity.RoleTenantAdmin)
+	_, err := s.db.Exec(`UPDATE users SET email_verified_at = NOW() WHERE email = $1`, adminEmail)
+	s.Require().NoError(err)
+	admin := s.login(adminEmail, password)
+
+	// Usuário criado pelo admin recebe o link de confirmação
+	email := "bloqueado@smartgondola.com"
+	newUser := userDTO.CreateUserRequest{Name: "Usuário Bloqueado", Email: email, Password: password, Role: entity.RoleManager}
+	s.Require().Equal(http.StatusCreated, s.postJSON("/api/v1/users", admin.AccessToken, newUser).Code)
+
+	// Cadastro fica pendente e o login é recusado até a confirmação
+	var status string
+	s.Require().NoError(s.db.QueryRow(`SELECT status FROM users WHERE email = $1`, email).Scan(&status))
+	s.Equal(string(entity.StatusPending), status)
+
+	loginBody := userDTO.LoginRequest{Email: email, Password: password}
+	s.Equal(http.StatusForbidden, s.postJSON("/api/v1/auth/login", "", loginBody).Code)
+
+	// Reenvio respeita o intervalo mínimo: o link do cadastro continua sendo o último
+	s.Equal(http.StatusAccepted, s.postJSON("/api/v1/auth/email/resend", "", userDTO.ResendVerificationRequest{Email: email}).Code)
+	files, _ := filepath.Glob(filepath.Join(s.mailDir, "*_"+email+".eml"))
+	s.Len(files, 1)
+
+	verify := userDTO.VerifyEmailRequest{Token: s.lastEmailToken(email)}
+	s.Require().Equal(http.StatusNoContent, s.postJSON("/api/v1/auth/email/verify", "", verify).Code)
+
+	session := s.login(email, password)
+	s.NotEmpty(session.AccessToken)
+}
+
+func (s *UserE2ESuite) TestInvite_CreateListAndAccept() {
+	adminEmail := "dono@smartgondola.com"
+	password := "SenhaSegura123!"
+	s.registerUser(adminEmail, password, "tenant_admin")
+	admin := s.login(adminEmail, password)
+
+	guestEmail := "convidado@smartgondola.com"
+	invite := userDTO.CreateInviteRequest{Email: guestEmail, Role: entity.RoleOperator}
+	s.Require().Equal(http.StatusCreated, s.postJSON("/api/v1/invites", admin.AccessToken, invite).Code)
+
+	// Um segundo convite para o mesmo email é recusado enquanto o primeiro estiver válido
+	s.Equal(http.StatusConflict, s.postJSON("/api/v1/invites", admin.AccessToken, invite).Code)
+
+	// Listagem de convites em aberto
+	req, _ := http.NewRequest("GET", "/api/v1/invites", nil)
+	req.Header.Set("Authorization", "Bearer "+admin.AccessToken)
+	w := httptest.NewRecorder()
+	s.handler.ServeHTTP(w, req)
+	s.Require().Equal(http.StatusOK, w.Code)
+	var list struct {
+		Data []userDTO.InviteResponse `json:"data"`
+	}
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &list))
+	s.Require().Len(list.Data, 1)
+	s.Equal(entity.InvitePending, list.Data[0].Status)
+
+	// Aceite: organização e papel vêm do convite, não do convidado
+	accept := userDTO.AcceptInviteRequest{Token: s.lastEmailToken(guestEmail), Name: "Operador Convidado", Password: password}
+	wAccept := s.postJSON("/api/v1/auth/invites/accept", "", accept)
+	s.Require().Equal(http.StatusCreated, wAccept.Code)
+	var created struct {
+		Data userDTO.UserResponse `json:"data"`
+	}
+	s.Require().NoError(json.Unmarshal(wAccept.Body.Bytes(), &created))
+	s.Equal(s.validOrgID, created.Data.OrganizationID)
+	s.Equal(entity.RoleOperator, created.Data.Role)
+
+	// O link é de uso único e o convidado já consegue entrar
+	s.Equal(http.StatusBadRequest, s.postJSON("/api/v1/auth/invites/accept", "", accept).Code)
+	s.NotEmpty(s.login(guestEmail, password).AccessToken)
+}
+
+func (s *UserE2ESuite) TestEmail_IsCaseInsensitiveEverywhere() {
+	password := "SenhaSegura123!"
+	s.registerUser("Dono@SmartGondola.com", password, entity.RoleTenantAdmin)
+
+	var stored string
+	s.Require().NoError(s.db.QueryRow(`SELECT email FROM users WHERE organization_id = $1`, s.validOrgID).Scan(&stored))
+	s.Equal("dono@smartgondola.com", stored, "gravado sempre em minúsculas")
+
+	// Login com outra grafia encontra a mesma conta
+	admin := s.login("DONO@smartgondola.COM", password)
+
+	// Nem convite, nem criação direta abrem uma segunda conta com o mesmo email
+	invite := userDTO.CreateInviteRequest{Email: "dono@smartgondola.com", Role: entity.RoleOperator}
+	s.Equal(http.StatusConflict, s.postJSON("/api/v1/invites", admin.AccessToken, invite).Code)
+	create := userDTO.CreateUserRequest{Name: "Clone", Email: "DONO@SMARTGONDOLA.COM", Password: password, Role: entity.RoleOperator}
+	s.Equal(http.StatusConflict, s.postJSON("/api/v1/users", admin.AccessToken, create).Code)
+
+	// O banco garante a unicidade mesmo para quem grava por fora do use case
+	_, err := s.db.Exec(`
+		INSERT INTO users (id, organization_id, name, email, password_hash, role, status)
+		VALUES ($1, $2, 'Clone', 'DONO@smartgondola.com', 'x', 'operator', 'active')
+	`, uuid.New(), s.validOrgID)
+	s.Error(err)
+}
+
+func (s *UserE2ESuite) TestInvite_ManagerCannotInviteAboveOwnRole() {
+	email := "gerente@smartgondola.com"
+	password := "SenhaSegura123!"
+	s.registerUser(email, password, entity.RoleManager)
+	manager := s.login(email, password)
+
+	invite := userDTO.CreateInviteRequest{Email: "novo.dono@smartgondola.com", Role: "tenant_admin"}
+	s.Equal(http.StatusForbidden, s.postJSON("/api/v1/invites", manager.AccessToken, invite).Code)
+
+	invite = userDTO.CreateInviteRequest{Email: "novo.gerente@smartgondola.com", Role: entity.RoleManager}
+	s.Equal(http.StatusForbidden, s.postJSON("/api/v1/invites", manager.AccessToken, invite).Code)
+
+	invite = userDTO.CreateInviteRequest{Email: "novo.operador@smartgondola.com", Role: entity.RoleOperator}
+	s.Equal(http.StatusCreated, s.postJSON("/api/v1/invites", manager.AccessToken, invite).Code)
+}
+
+func (s *UserE2ESuite) TestDevices_RegisteredOnLoginAndVisiblePerStore() {
+	password := "SenhaSegura123!"
+	storeID := uuid.New()
+	_, err := s.db.Exec(`INSERT INTO stores (id, organization_id, name, code) VALUES ($1, $2, 'Loja Centro', 'L01')`, storeID, s.validOrgID)
+	s.Require().NoError(err)
+
+	s.registerUser("dono@smartgondola.com", password, entity.RoleTenantAdmin)
+	s.registerUser("operador@smartgondola.com", password, entity.RoleOperator)
+	_, err = s.db.Exec(`UPDATE users SET store_id = $1 WHERE email = 'operador@smartgondola.com'`, storeID)
+	s.Require().NoError(err)
+
+	// 1. Dois logins no mesmo aparelho geram um único registro
+	device := userDTO.DeviceInfo{DeviceID: "android-01", DeviceName: "Moto G", Platform: "android", PushToken: "push-01", AppVersion: "1.0.0"}
+	loginBody := userDTO.LoginRequest{Email: "operador@smartgondola.com", Password: password, DeviceInfo: device}
+	s.Require().Equal(http.StatusOK, s.postJSON("/api/v1/auth/login", "", loginBody).Code)
+	wLogin := s.postJSON("/api/v1/auth/login", "", loginBody)
+	s.Require().Equal(http.StatusOK, wLogin.Code)
+	var session struct {
+		Data userDTO.LoginResponse `json:"data"`
+	}
+	s.Require().NoError(json.Unmarshal(wLogin.Body.Bytes(), &session))
+
+	w := s.doJSON("GET", "/api/v1/me/devices", session.Data.AccessToken, nil)
+	s.Require().Equal(http.StatusOK, w.Code)
+	var mine struct {
+		Data []userDTO.DeviceResponse `json:"data"`
+	}
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &mine))
+	s.Require().Len(mine.Data, 1)
+	s.Equal("android-01", mine.Data[0].DeviceID)
+	s.True(mine.Data[0].PushEnabled)
+
+	// 2. O admin vê quem recebe os alertas da loja
+	admin := s.login("dono@smartgondola.com", password)
+	w = s.doJSON("GET", "/api/v1/stores/"+storeID.String()+"/devices", admin.AccessToken, nil)
+	s.Require().Equal(http.StatusOK, w.Code)
+	var perStore struct {
+		Data []userDTO.StoreDeviceResponse `json:"data"`
+	}
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &perStore))
+	s.Require().Len(perStore.Data, 1)
+	s.Equal("operador@smartgondola.com", perStore.Data[0].User.Email)
+
+	s.Equal(http.StatusNotFound, s.doJSON("GET", "/api/v1/stores/"+uuid.NewString()+"/devices", admin.AccessToken, nil).Code)
+	s.Equal(http.StatusForbidden, s.doJSON("GET", "/api/v1/stores/"+storeID.String()+"/devices", session.Data.AccessToken, nil).Code)
+
+	// 3. Remover o aparelho encerra a sessão dele
+	s.Equal(http.StatusNoContent, s.doJSON("DELETE", "/api/v1/me/devices/"+mine.Data[0].ID.String(), session.Data.AccessToken, nil).Code)
+	s.Equal(http.StatusNotFound, s.doJSON("DELETE", "/api/v1/me/devices/"+mine.Data[0].ID.String(), session.Data.AccessToken, nil).Code)
+
+	refresh := userDTO.RefreshTokenRequest{RefreshToken: session.Data.RefreshToken}
+	s.Equal(http.StatusUnauthorized, s.postJSON("/api/v1/auth/refresh", "", refresh).Code)
+}
+
+func (s *UserE2ESuite) TestLoginHistory_RecordsAttemptsAndAdminView() {
+	password := "SenhaSegura123!"
+	email := "historico@smartgondola.com"
+	target := seedUser(s.T(), s.db, s.validOrgID, "Usuário Histórico", email, password, entity.RoleOperator)
+	s.registerUser("dono@smartgondola.com", password, entity.RoleTenantAdmin)
+
+	loginFrom := func(pass string) int {
+		body, _ := json.Marshal(userDTO.LoginRequest{Email: email, Password: pass, DeviceInfo: userDTO.DeviceInfo{DeviceID: "ios-01", DeviceName: "iPhone 13"}})
+		req, _ := http.NewRequest("POST", "/api/v1/auth/login", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("User-Agent", "SmartGondola/1.0 (iOS)")
+		req.RemoteAddr = "198.51.100.20:40000"
+		w := httptest.NewRecorder()
+		s.handler.ServeHTTP(w, req)
+		return w.Code
+	}
+	s.Equal(http.StatusUnauthorized, loginFrom("SenhaIncorreta"))
+	s.Require().Equal(http.StatusOK, loginFrom(password))
+
+	var lastIP string
+	s.Require().NoError(s.db.QueryRow(`SELECT last_login_ip FROM users WHERE id = $1`, target.ID).Scan(&lastIP))
+	s.Equal("198.51.100.20", lastIP)
+
+	// 1. O próprio usuário vê as duas tentativas, a mais recente primeiro
+	session := s.login(email, password)
+	w := s.doJSON("GET", "/api/v1/me/security/logins?limit=2", session.AccessToken, nil)
+	s.Require().Equal(http.StatusOK, w.Code)
+	var history struct {
+		Data []userDTO.RecentLoginResponse `json:"data"`
+		Meta struct {
+			TotalItems int64 `json:"total_items"`
+		} `json:"meta"`
+	}
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &history))
+	s.EqualValues(3, history.Meta.TotalItems)
+	s.Require().Len(history.Data, 2)
+	s.True(history.Data[0].Success)
+
+	// 2. O admin enxerga o histórico de usuários da própria organização
+	admin := s.login("dono@smartgondola.com", password)
+	w = s.doJSON("GET", "/api/v1/users/"+target.ID.String()+"/security/logins", admin.AccessToken, nil)
+	s.Require().Equal(http.StatusOK, w.Code)
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &history))
+	s.Require().Len(history.Data, 3)
+	failed := history.Data[2]
+	s.Equal(string(entity.LoginInvalidPassword), failed.Status)
+	s.Equal("198.51.100.20", failed.IP)
+	s.Equal("iPhone 13", failed.Device)
+	s.Equal("SmartGondola/1.0 (iOS)", failed.UserAgent)
+
+	s.Equal(http.StatusNotFound, s.doJSON("GET", "/api/v1/users/"+uuid.NewString()+"/security/logins", admin.AccessToken, nil).Code)
+	s.Equal(http.StatusForbidden, s.doJSON("GET", "/api/v1/users/"+target.ID.String()+"/security/logins", session.AccessToken, nil).Code)
+}
+
+func (s *UserE2ESuite) TestUserManagement_RoleHierarchyAndLifecycle() {
+	password := "SenhaSegura123!"
+	s.registerUser("dono@smartgondola.com", password, entity.RoleTenantAdmin)
+	manager := seedUser(s.T(), s.db, s.validOrgID, "Gerente", "gerente@smartgondola.com", password, entity.RoleManager)
+	operator := seedUser(s.T(), s.db, s.validOrgID, "Operador", "operador@smartgondola.com", password, entity.RoleOperator)
+	owner := s.login("dono@smartgondola.com", password)
+	managerSession := s.login("gerente@smartgondola.com", password)
+
+	// 1. Listagem com filtro
+	w := s.doJSON("GET", "/api/v1/users?role=operator", managerSession.AccessToken, nil)
+	s.Require().Equal(http.StatusOK, w.Code)
+	var list struct {
+		Data []userDTO.UserResponse `json:"data"`
+	}
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &list))
+	s.Require().Len(list.Data, 1)
+	s.Equal(operator.ID, list.Data[0].ID)
+	s.Equal(http.StatusBadRequest, s.doJSON("GET", "/api/v1/users?status=deleted", managerSession.AccessToken, nil).Code)
+
+	// 2. Gerente não promove ninguém ao próprio nível (ou acima) nem mexe no dono
+	operatorPath := "/api/v1/users/" + operator.ID.String()
+	s.Equal(http.StatusForbidden, s.doJSON("PUT", operatorPath+"/role", managerSession.AccessToken, userDTO.ChangeRoleRequest{Role: entity.RoleManager}).Code)
+	s.Equal(http.StatusForbidden, s.doJSON("PUT", operatorPath+"/role", managerSession.AccessToken, userDTO.ChangeRoleRequest{Role: entity.RoleTenantAdmin}).Code)
+	var ownerID uuid.UUID
+	s.Require().NoError(s.db.QueryRow(`SELECT id FROM users WHERE email = 'dono@smartgondola.com'`).Scan(&ownerID))
+	s.Equal(http.StatusForbidden, s.postJSON("/api/v1/users/"+ownerID.String()+"/suspend", managerSession.AccessToken, nil).Code)
+
+	// 3. Dono promove, mas não rebaixa a si mesmo por aqui
+	s.Equal(http.StatusOK, s.doJSON("PUT", "/api/v1/users/"+manager.ID.String()+"/role", owner.AccessToken, userDTO.ChangeRoleRequest{Role: entity.RoleTenantAdmin}).Code)
+	s.Equal(http.StatusForbidden, s.doJSON("PUT", "/api/v1/users/"+ownerID.String()+"/role", owner.AccessToken, userDTO.ChangeRoleRequest{Role: entity.RoleOperator}).Code)
+
+	// 4. Suspensão derruba o acesso; reativação devolve
+	s.Require().Equal(http.StatusOK, s.postJSON(operatorPath+"/suspend", owner.AccessToken, nil).Code)
+	s.Equal(http.StatusUnauthorized, s.postJSON("/api/v1/auth/login", "", userDTO.LoginRequest{Email: operator.Email, Password: password}).Code)
+	s.Equal(http.StatusOK, s.postJSON(operatorPath+"/reactivate", owner.AccessToken, nil).Code)
+	s.Equal(http.StatusConflict, s.postJSON(operatorPath+"/reactivate", owner.AccessToken, nil).Code)
+	s.login(operator.Email, password)
+
+	// 5. Troca de loja só para lojas da organização
+	storeID := uuid.New()
+	_, err := s.db.Exec(`INSERT INTO stores (id, organization_id, name, code) VALUES ($1, $2, 'Loja Centro', 'L01')`, storeID, s.validOrgID)
+	s.Require().NoError(err)
+	w = s.doJSON("PUT", operatorPath+"/store", owner.AccessToken, userDTO.MoveUserStoreRequest{StoreID: &storeID})
+	s.Require().Equal(http.StatusOK, w.Code)
+	var moved struct {
+		Data userDTO.UserResponse `json:"data"`
+	}
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &moved))
+	s.Require().NotNil(moved.Data.StoreID)
+	s.Equal(storeID, *moved.Data.StoreID)
+	otherStore := uuid.New()
+	s.Equal(http.StatusNotFound, s.doJSON("PUT", operatorPath+"/store", owner.AccessToken, userDTO.MoveUserStoreRequest{StoreID: &otherStore}).Code)
+}
+
+func (s *UserE2ESuite) TestMe_ProfileAndPasswordChange() {
+	email := "perfil@smartgondola.com"
+	password := "SenhaSegura123!"
+	s.registerUser(email, password, entity.RoleOperator)
+	session := s.login(email, password)
+
+	name := "Nome Novo"
+	w := s.doJSON("PATCH", "/api/v1/me", session.AccessToken, userDTO.UpdateUserRequest{Name: &name})
+	s.Require().Equal(http.StatusOK, w.Code)
+
+	w = s.doJSON("GET", "/api/v1/me", session.AccessToken, nil)
+	s.Require().Equal(http.StatusOK, w.Code)
+	var me struct {
+		Data userDTO.UserResponse `json:"data"`
+	}
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &me))
+	s.Equal(name, me.Data.Name)
+	s.Equal(email, me.Data.Email)
+
+	// Senha atual errada é recusada; a troca encerra as sessões abertas
+	wrong := userDTO.ChangePasswordRequest{OldPassword: "SenhaIncorreta", NewPassword: "NovaSenha123!"}
+	s.Equal(http.StatusUnauthorized, s.doJSON("PUT", "/api/v1/me/password", session.AccessToken, wrong).Code)
+
+	time.Sleep(5 * time.Millisecond) // iat em milissegundos: garante que a troca é posterior ao token
+	change := userDTO.ChangePasswordRequest{OldPassword: password, NewPassword: "NovaSenha123!"}
+	s.Require().Equal(http.StatusNoContent, s.doJSON("PUT", "/api/v1/me/password", session.AccessToken, change).Code)
+	s.Equal(http.StatusUnauthorized, s.doJSON("GET", "/api/v1/me", session.AccessToken, nil).Code)
+	s.login(email, "NovaSenha123!")
+}
+
+// --- HELPERS ---
+
+func (s *UserE2ESuite) TestImpersonation_ReadOnlyFlaggedAndAudited() {
+	password := "SenhaSegura123!"
+	target := seedUser(s.T(), s.db, s.validOrgID, "Dono", "dono@smartgondola.com", password, entity.RoleTenantAdmin)
+
+	// Equipe da plataforma mora na própria organização
+	platformOrgID := uuid.New()
+	_, err := s.db.Exec(`
+		INSERT INTO organizations (id, name, document, slug, plan, sector, settings, is_active)
+		VALUES ($1, 'Smart Gondola', '11222333000181', 'smart-gondola', 'enterprise', 'retail', '{}', true)
+	`, platformOrgID)
+	s.Require().NoError(err)
+	support := seedUser(s.T(), s.db, platformOrgID, "Suporte", "suporte@smartgondola.com", password, entity.RoleSupport)
+	seedUser(s.T(), s.db, platformOrgID, "Root", "root@smartgondola.com", password, entity.RoleSuperAdmin)
+	supportSession := s.login(support.Email, password)
+	rootSession := s.login("root@smartgondola.com", password)
+
+	// Tenant não impersona; suporte não pede escrita
+	ownerSession := s.login(target.Email, password)
+	start := userDTO.StartImpersonationRequest{UserID: target.ID, Reason: "Reproduzir erro no painel de lojas"}
+	s.Equal(http.StatusForbidden, s.postJSON("/api/v1/admin/impersonations", ownerSession.AccessToken, start).Code)
+	withWrite := start
+	withWrite.WriteAccess = true
+	s.Equal(http.StatusForbidden, s.postJSON("/api/v1/admin/impersonations", supportSession.AccessToken, withWrite).Code)
+
+	w := s.postJSON("/api/v1/admin/impersonations", supportSession.AccessToken, start)
+	s.Require().Equal(http.StatusCreated, w.Code)
+	var started struct {
+		Data userDTO.ImpersonationResponse `json:"data"`
+	}
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &started))
+	s.True(started.Data.ReadOnly)
+	token := started.Data.AccessToken
+
+	// Age como o dono, mas toda resposta denuncia quem está por trás
+	w = s.doJSON("GET", "/api/v1/me", token, nil)
+	s.Require().Equal(http.StatusOK, w.Code)
+	s.Equal(support.ID.String(), w.Header().Get("X-Impersonated-By"))
+	s.Equal(started.Data.SessionID.String(), w.Header().Get("X-Impersonation-Id"))
+	s.Contains(w.Body.String(), target.Email)
+
+	name := "Alterado pelo suporte"
+	s.Equal(http.StatusForbidden, s.doJSON("PATCH", "/api/v1/me", token, userDTO.UpdateUserRequest{Name: &name}).Code)
+	s.Equal(http.StatusForbidden, s.doJSON("PUT", "/api/v1/me/password", token, userDTO.ChangePasswordRequest{OldPassword: password, NewPassword: "OutraSenha123!"}).Code)
+
+	// Encerrar revoga o token na hora
+	s.Equal(http.StatusNoContent, s.postJSON("/api/v1/auth/impersonation/end", token, nil).Code)
+	s.Equal(http.StatusUnauthorized, s.doJSON("GET", "/api/v1/me", token, nil).Code)
+
+	// Trilha de auditoria (visível para a plataforma)
+	w = s.doJSON("GET", "/api/v1/admin/impersonations/"+started.Data.SessionID.String()+"/events", rootSession.AccessToken, nil)
+	s.Require().Equal(http.StatusOK, w.Code)
+	var events struct {
+		Data []userDTO.ImpersonationEventResponse `json:"data"`
+	}
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &events))
+	s.Require().Len(events.Data, 4)
+	s.Equal("GET", events.Data[0].Method)
+	s.Equal("/api/v1/me", events.Data[0].Path)
+	s.Equal(http.StatusOK, events.Data[0].Status)
+	s.Equal(http.StatusForbidden, events.Data[1].Status)
+
+	w = s.doJSON("GET", "/api/v1/admin/impersonations", rootSession.AccessToken, nil)
+	s.Require().Equal(http.StatusOK, w.Code)
+	var sessions struct {
+		Data []userDTO.ImpersonationSessionResponse `json:"data"`
+	}
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &sessions))
+	s.Require().Len(sessions.Data, 1)
+	s.Equal(support.ID, sessions.Data[0].ActorID)
+	s.Equal("Reproduzir erro no painel de lojas", sessions.Data[0].Reason)
+	s.False(sessions.Data[0].Active)
+	s.NotNil(sessions.Data[0].EndedAt)
+}
+
+func (s *UserE2ESuite) TestImpersonation_EndsWhenActorLosesAccess() {
+	password := "SenhaSegura123!"
+	target := seedUser(s.T(), s.db, s.validOrgID, "Dono", "dono@smartgondola.com", password, entity.RoleTenantAdmin)
+
+	platformOrgID := uuid.New()
+	_, err := s.db.Exec(`
+		INSERT INTO organizations (id, name, document, slug, plan, sector, settings, is_active)
+		VALUES ($1, 'Smart Gondola', '11222333000181', 'smart-gondola', 'enterprise', 'retail', '{}', true)
+	`, platformOrgID)
+	s.Require().NoError(err)
+	support := seedUser(s.T(), s.db, platformOrgID, "Suporte", "suporte@smartgondola.com", password, entity.RoleSupport)
+	root := seedUser(s.T(), s.db, platformOrgID, "Root", "root@smartgondola.com", password, entity.RoleSuperAdmin)
+
+	impersonate := func(actor *entity.User, writeAccess bool) string {
+		session := s.login(actor.Email, password)
+		start := userDTO.StartImpersonationRequest{UserID: target.ID, Reason: "Conferir configuração da loja", WriteAccess: writeAccess}
+		w := s.postJSON("/api/v1/admin/impersonations", session.AccessToken, start)
+		s.Require().Equal(http.StatusCreated, w.Code)
+		var started struct {
+			Data userDTO.ImpersonationResponse `json:"data"`
+		}
+		s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &started))
+		s.Require().Equal(http.StatusOK, s.doJSON("GET", "/api/v1/me", started.Data.AccessToken, nil).Code)
+		return started.Data.AccessToken
+	}
+
+	// Logout-all do ator derruba também a impersonação aberta
+	token := impersonate(support, false)
+	time.Sleep(5 * time.Millisecond)
+	s.Require().Equal(http.StatusNoContent, s.postJSON("/api/v1/auth/logout-all", s.login(support.Email, password).AccessToken, nil).Code)
+	s.Equal(http.StatusUnauthorized, s.doJSON("GET", "/api/v1/me", token, nil).Code)
+
+	// Suporte suspenso
+	token = impersonate(support, false)
+	_, err = s.db.Exec(`UPDATE users SET status = 'suspended' WHERE id = $1`, support.ID)
+	s.Require().NoError(err)
+	s.Equal(http.StatusUnauthorized, s.doJSON("GET", "/api/v1/me", token, nil).Code)
+
+	// Super admin rebaixado a suporte perde a impersonação com escrita (e a de leitura só se perder o papel)
+	writeToken := impersonate(root, true)
+	_, err = s.db.Exec(`UPDATE users SET role = 'support' WHERE id = $1`, root.ID)
+	s.Require().NoError(err)
+	s.Equal(http.StatusUnauthorized, s.doJSON("GET", "/api/v1/me", writeToken, nil).Code)
+	_, err = s.db.Exec(`UPDATE users SET role = 'super_admin' WHERE id = $1`, root.ID)
+	s.Require().NoError(err)
+	readToken := impersonate(root, false)
+	_, err = s.db.Exec(`UPDATE users SET role = 'tenant_admin' WHERE id = $1`, root.ID)
+	s.Require().NoError(err)
+	s.Equal(http.StatusUnauthorized, s.doJSON("GET", "/api/v1/me", readToken, nil).Code)
+}
+
+func (s *UserE2ESuite) TestAPIKeys_ScopedExpiringAndRevocable() {
+	password := "SenhaSegura123!"
+	admin := seedUser(s.T(), s.db, s.validOrgID, "Admin", "admin@empresa.com", password, entity.RoleTenantAdmin)
+	session := s.login(admin.Email, password)
+
+	// Escopo que depende de um usuário por trás não vai para chave de API
+	denied := userDTO.CreateAPIKeyRequest{Name: "ERP", Scopes: []string{"users:read"}, ExpiresInDays: 30}
+	s.Equal(http.StatusForbidden, s.postJSON("/api/v1/api-keys", session.AccessToken, denied).Code)
+
+	w := s.postJSON("/api/v1/api-keys", session.AccessToken, userDTO.CreateAPIKeyRequest{
+		Name: "ERP", Scopes: []string{"organizations:read", "stores:read"}, ExpiresInDays: 30,
+	})
+	s.Require().Equal(http.StatusCreated, w.Code)
+	var created struct {
+		Data userDTO.APIKeyCreatedResponse `json:"data"`
+	}
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &created))
+	key := created.Data.Key
+	s.True(auth.IsAPIKey(key))
+	s.True(strings.HasPrefix(key, created.Data.Prefix))
+
+	// Só o hash fica no banco
+	var stored string
+	s.Require().NoError(s.db.QueryRow(`SELECT key_hash FROM api_keys WHERE id = $1`, created.Data.ID).Scan(&stored))
+	s.Equal(auth.HashToken(key), stored)
+
+	// Aceita no header X-API-Key e como Bearer
+	req, _ := http.NewRequest("GET", "/api/v1/organizations/"+s.validOrgID.String(), nil)
+	req.Header.Set("X-API-Key", key)
+	rec := httptest.NewRecorder()
+	s.handler.ServeHTTP(rec, req)
+	s.Equal(http.StatusOK, rec.Code)
+	s.Equal(http.StatusOK, s.doJSON("GET", "/api/v1/organizations/"+s.validOrgID.String()+"/stores", key, nil).Code)
+
+	// Fora dos escopos, rotas de usuário e gestão de chaves: negado
+	s.Equal(http.StatusForbidden, s.postJSON("/api/v1/stores", key, map[string]string{"name": "Loja ERP"}).Code)
+	s.Equal(http.StatusForbidden, s.doJSON("GET", "/api/v1/users", key, nil).Code)
+	s.Equal(http.StatusForbidden, s.doJSON("GET", "/api/v1/me", key, nil).Code)
+	s.Equal(http.StatusForbidden, s.doJSON("GET", "/api/v1/api-keys", key, nil).Code)
+
+	w = s.doJSON("GET", "/api/v1/api-keys", session.AccessToken, nil)
+	s.Require().Equal(http.StatusOK, w.Code)
+	s.NotContains(w.Body.String(), key)
+	var listed struct {
+		Data []userDTO.APIKeyResponse `json:"data"`
+	}
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &listed))
+	s.Require().Len(listed.Data, 1)
+	s.NotNil(listed.Data[0].LastUsedAt)
+	s.True(listed.Data[0].Active)
+
+	// Revogada, para de funcionar na hora
+	s.Equal(http.StatusNoContent, s.doJSON("DELETE", "/api/v1/api-keys/"+created.Data.ID.String(), session.AccessToken, nil).Code)
+	s.Equal(http.StatusUnauthorized, s.doJSON("GET", "/api/v1/organizations/"+s.validOrgID.String(), key, nil).Code)
+
+	// Expirada também
+	w = s.postJSON("/api/v1/api-keys", session.AccessToken, userDTO.CreateAPIKeyRequest{Name: "BI", Scopes: []string{"stores:read"}, ExpiresInDays: 1})
+	s.Require().Equal(http.StatusCreated, w.Code)
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &created))
+	_, err := s.db.Exec(`UPDATE api_keys SET expires_at = NOW() - INTERVAL '1 minute' WHERE id = $1`, created.Data.ID)
+	s.Require().NoError(err)
+	s.Equal(http.StatusUnauthorized, s.doJSON("GET", "/api/v1/organizations/"+s.validOrgID.String()+"/stores", created.Data.Key, nil).Code)
+}
+
+func (s *UserE2ESuite) TestSSO_OIDCLoginProvisionsAndLinksUsers() {
+	idp := oidctest.NewProvider("smart-gondola", "segredo-do-cliente")
+	defer idp.Close()
+
+	password := "SenhaSegura123!"
+	admin := seedUser(s.T(), s.db, s.validOrgID, "Admin", "admin@empresa.com", password, entity.RoleTenantAdmin)
+	session := s.login(admin.Email, password)
+	ssoPath := "/api/v1/organizations/" + s.validOrgID.String() + "/sso"
+	config := dto.UpdateSSOConfigRequest{
+		Issuer:         idp.Issuer(),
+		ClientID:       idp.ClientID,
+		ClientSecret:   idp.ClientSecret,
+		AllowedDomains: []string{"empresa.com"},
+		DefaultRole:    "operator",
+		Enabled:        true,
+	}
+
+	// SSO é recurso do plano enterprise
+	s.Equal(http.StatusForbidden, s.doJSON("PUT", ssoPath, session.AccessToken, config).Code)
+	_, err := s.db.Exec(`UPDATE organizations SET plan = 'enterprise' WHERE id = $1`, s.validOrgID)
+	s.Require().NoError(err)
+
+	w := s.doJSON("PUT", ssoPath, session.AccessToken, config)
+	s.Require().Equal(http.StatusOK, w.Code)
+	s.NotContains(w.Body.String(), idp.ClientSecret)
+	s.Contains(w.Body.String(), `"has_client_secret":true`)
+
+	// Primeiro acesso: usuário criado na hora com o papel padrão e email já verificado
+	first := s.ssoLogin(idp, oidctest.Identity{Subject: "idp-123", Email: "Maria@Empresa.com", EmailVerified: true, Name: "Maria Souza"})
+	s.Require().Equal(http.StatusOK, first.Code)
+	var resp struct {
+		Data userDTO.LoginResponse `json:"data"`
+	}
+	s.Require().NoError(json.Unmarshal(first.Body.Bytes(), &resp))
+	s.NotEmpty(resp.Data.AccessToken)
+	s.NotEmpty(resp.Data.RefreshToken)
+	s.Require().NotNil(resp.Data.User)
+	userID := resp.Data.User.ID
+	s.Equal(http.StatusOK, s.doJSON("GET", "/api/v1/me", resp.Data.AccessToken, nil).Code)
+
+	var role string
+	var verified bool
+	s.Require().NoError(s.db.QueryRow(`SELECT role, email_verified_at IS NOT NULL FROM users WHERE id = $1`, userID).Scan(&role, &verified))
+	s.Equal(string(entity.RoleOperator), role)
+	s.True(verified)
+
+	// Segundo acesso cai no mesmo usuário pelo vínculo (issuer, sub), mesmo com outro email no provedor
+	second := s.ssoLogin(idp, oidctest.Identity{Subject: "idp-123", Email: "maria.souza@empresa.com", EmailVerified: true})
+	s.Require().Equal(http.StatusOK, second.Code)
+	s.Require().NoError(json.Unmarshal(second.Body.Bytes(), &resp))
+	s.Equal(userID, resp.Data.User.ID)
+
+	// Usuário local existente é vinculado pelo email verificado, sem trocar o papel
+	s.registerUser("joao@empresa.com", password, entity.RoleManager)
+	linked := s.ssoLogin(idp, oidctest.Identity{Subject: "idp-456", Email: "joao@empresa.com", EmailVerified: true})
+	s.Require().Equal(http.StatusOK, linked.Code)
+	s.Contains(linked.Body.String(), `"role":"manager"`)
+
+	// Domínio fora da lista ou email não verificado: negado
+	s.Equal(http.StatusForbidden, s.ssoLogin(idp, oidctest.Identity{Subject: "idp-789", Email: "ana@gmail.com", EmailVerified: true}).Code)
+	s.Equal(http.StatusForbidden, s.ssoLogin(idp, oidctest.Identity{Subject: "idp-789", Email: "ana@empresa.com"}).Code)
+
+	// O state vale uma vez
+	w = s.postJSON("/api/v1/auth/sso/start", "", userDTO.SSOStartRequest{Organization: "empresa-teste-user"})
+	s.Require().Equal(http.StatusOK, w.Code)
+	code, state := s.authorizeSSO(idp, w, oidctest.Identity{Subject: "idp-123", Email: "maria@empresa.com", EmailVerified: true})
+	s.Equal(http.StatusOK, s.postJSON("/api/v1/auth/sso/callback", "", userDTO.SSOCallbackRequest{Code: code, State: state}).Code)
+	s.Equal(http.StatusBadRequest, s.postJSON("/api/v1/auth/sso/callback", "", userDTO.SSOCallbackRequest{Code: code, State: state}).Code)
+
+	// Desligado, a organização volta para login com senha
+	config.Enabled = false
+	config.ClientSecret = ""
+	s.Require().Equal(http.StatusOK, s.doJSON("PUT", ssoPath, session.AccessToken, config).Code)
+	s.Equal(http.StatusNotFound, s.postJSON("/api/v1/auth/sso/start", "", userDTO.SSOStartRequest{Organization: "empresa-teste-user"}).Code)
+}
+
+// ssoLogin faz o fluxo completo: start na API, login no provedor falso e callback
+func (s *UserE2ESuite) ssoLogin(idp *oidctest.Provider, identity oidctest.Identity) *httptest.ResponseRecorder {
+	w := s.postJSON("/api/v1/auth/sso/start", "", userDTO.SSOStartRequest{Organization: "empresa-teste-user"})
+	s.Require().Equal(http.StatusOK, w.Code)
+	code, state := s.authorizeSSO(idp, w, identity)
+	return s.postJSON("/api/v1/auth/sso/callback", "", userDTO.SSOCallbackRequest{Code: code, State: state})
+}
+
+// authorizeSSO lê a URL de autorização devolvida pelo start e "loga" a identidade no provedor falso
+func (s *UserE2ESuite) authorizeSSO(idp *oidctest.Provider, start *httptest.ResponseRecorder, identity oidctest.Identity) (code, state string) {
+	var started struct {
+		Data userDTO.SSOStartResponse `json:"data"`
+	}
+	s.Require().NoError(json.Unmarshal(start.Body.Bytes(), &started))
+	s.Require().True(strings.HasPrefix(started.Data.AuthorizationURL, idp.Issuer()+"/authorize?"))
+
+	code, state, err := idp.Authorize(started.Data.AuthorizationURL, identity)
+	s.Require().NoError(err)
+	return code, state
+}
+
+func (s *UserE2ESuite) TestPasswordPolicy_RulesHistoryBreachesAndExpiry() {
+	password := "SenhaSegura123!"
+	admin := seedUser(s.T(), s.db, s.validOrgID, "Admin", "admin@empresa.com", password, entity.RoleTenantAdmin)
+	session := s.login(admin.Email, password)
+	settingsPath := "/api/v1/organizations/" + s.validOrgID.String() + "/settings"
+
+	invalid := dto.UpdateOrganizationSettingsRequest{PasswordPolicy: &orgEntity.PasswordPolicy{MinLength: 4}}
+	s.Equal(http.StatusBadRequest, s.doJSON("PUT", settingsPath, session.AccessToken, invalid).Code)
+	policy := dto.UpdateOrganizationSettingsRequest{PasswordPolicy: &orgEntity.PasswordPolicy{
+		MinLength: 12, RequireUppercase: true, RequireDigit: true, RequireSymbol: true, HistorySize: 2, MaxAgeDays: 90,
+	}}
+	s.Require().Equal(http.StatusOK, s.doJSON("PUT", settingsPath, session.AccessToken, policy).Code)
+
+	// Cadastro: regras de formato voltam uma a uma nos detalhes
+	newUser := userDTO.CreateUserRequest{Name: "Operador", Email: "operador@empresa.com", Password: "senhafraca", Role: entity.RoleOperator}
+	w := s.postJSON("/api/v1/users", session.AccessToken, newUser)
+	s.Require().Equal(http.StatusBadRequest, w.Code)
+	var failure struct {
+		Error response.ErrorPayload `json:"error"`
+	}
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &failure))
+	s.Len(failure.Error.Details, 4) // tamanho, maiúscula, número e símbolo
+
+	// Senha que atende às regras, mas está na lista de vazamentos
+	s.addBreachedPassword("Gondola@2024!")
+	newUser.Password = "Gondola@2024!"
+	w = s.postJSON("/api/v1/users", session.AccessToken, newUser)
+	s.Equal(http.StatusBadRequest, w.Code)
+	s.Contains(w.Body.String(), "vazamentos")
+
+	first := "Primeira#Senha1"
+	newUser.Password = first
+	s.Require().Equal(http.StatusCreated, s.postJSON("/api/v1/users", session.AccessToken, newUser).Code)
+
+	// Histórico: a atual e as duas anteriores não voltam
+	changeTo := func(current, next string) int {
+		time.Sleep(5 * time.Millisecond)
+		token := s.login(newUser.Email, current).AccessToken
+		return s.doJSON("PUT", "/api/v1/me/password", token, userDTO.ChangePasswordRequest{OldPassword: current, NewPassword: next}).Code
+	}
+	s.Equal(http.StatusBadRequest, changeTo(first, first))
+	s.Require().Equal(http.StatusNoContent, changeTo(first, "Segunda#Senha2"))
+	s.Equal(http.StatusBadRequest, changeTo("Segunda#Senha2", first))
+	s.Require().Equal(http.StatusNoContent, changeTo("Segunda#Senha2", "Terceira#Senha3"))
+	s.Require().Equal(http.StatusNoContent, changeTo("Terceira#Senha3", "Quarta#Senha4"))
+	s.Equal(http.StatusNoContent, changeTo("Quarta#Senha4", first), "fora da janela do histórico")
+
+	// Validade: senha vencida só libera o login depois da troca
+	session = s.login(newUser.Email, first)
+	_, err := s.db.Exec(`UPDATE users SET password_changed_at = NOW() - INTERVAL '91 days' WHERE email = $1`, newUser.Email)
+	s.Require().NoError(err)
+	w = s.postJSON("/api/v1/auth/login", "", userDTO.LoginRequest{Email: newUser.Email, Password: first})
+	s.Require().Equal(http.StatusOK, w.Code)
+	var expired struct {
+		Data userDTO.LoginResponse `json:"data"`
+	}
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &expired))
+	s.True(expired.Data.PasswordChangeRequired)
+	s.Empty(expired.Data.AccessToken)
+
+	// A sessão aberta antes do vencimento também não renova: o refresh devolve o mesmo desafio
+	refresh := userDTO.RefreshTokenRequest{RefreshToken: session.RefreshToken}
+	w = s.postJSON("/api/v1/auth/refresh", "", refresh)
+	s.Require().Equal(http.StatusOK, w.Code)
+	var refreshed struct {
+		Data userDTO.LoginResponse `json:"data"`
+	}
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &refreshed))
+	s.True(refreshed.Data.PasswordChangeRequired)
+	s.NotEmpty(refreshed.Data.ChallengeToken)
+	s.Empty(refreshed.Data.AccessToken)
+	s.Empty(refreshed.Data.RefreshToken)
+
+	renew := userDTO.ExpiredPasswordRequest{ChallengeToken: expired.Data.ChallengeToken, NewPassword: first}
+	s.Equal(http.StatusBadRequest, s.postJSON("/api/v1/auth/password/expired", "", renew).Code)
+	renew.NewPassword = "Quinta#Senha5"
+	w = s.postJSON("/api/v1/auth/password/expired", "", renew)
+	s.Require().Equal(http.StatusOK, w.Code)
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &expired))
+	s.NotEmpty(expired.Data.AccessToken)
+	s.Equal(http.StatusUnauthorized, s.postJSON("/api/v1/auth/password/expired", "", renew).Code)
+	s.Equal(http.StatusUnauthorized, s.postJSON("/api/v1/auth/refresh", "", refresh).Code, "a troca encerra a sessão antiga")
+
+	// Redefinição: senha recusada não gasta o link
+	s.Equal(http.StatusAccepted, s.postJSON("/api/v1/auth/password/forgot", "", userDTO.ForgotPasswordRequest{Email: newUser.Email}).Code)
+	s.container.PassUseCase.Wait()
+	reset := userDTO.ResetPasswordRequest{Token: s.lastEmailToken(newUser.Email), NewPassword: "Quinta#Senha5"}
+	s.Equal(http.StatusBadRequest, s.postJSON("/api/v1/auth/password/reset", "", reset).Code)
+	reset.NewPassword = "Sexta#Senha6"
+	s.Equal(http.StatusNoContent, s.postJSON("/api/v1/auth/password/reset", "", reset).Code)
+	s.login(newUser.Email, "Sexta#Senha6")
+}
+
+func (s *UserE2ESuite) registerUser(email, password string, role entity.UserRole) {
+	seedUser(s.T(), s.db, s.validOrgID, "Usuário de Teste", email, password, role)
+}
+
+func (s *UserE2ESuite) login(email, password string) userDTO.LoginResponse {
+	body, _ := json.Marshal(userDTO.LoginRequest{Email: email, Password: password})
+	req, _ := http.NewRequest("POST", "/api/v1/auth/login", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	s.handler.ServeHTTP(w, req)
+	s.Require().Equal(http.StatusOK, w.Code)
+
+	var resp struct {
+		Data userDTO.LoginResponse `json:"data"`
+	}
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &resp))
+	return resp.Data
+}
+
+// loginFrom tenta o login a partir de um IP específico (o throttle conta por IP)
+func (s *UserE2ESuite) loginFrom(ip, email, password string) *httptest.ResponseRecorder {
+	body, _ := json.Marshal(userDTO.LoginRequest{Email: email, Password: password})
+	req, _ := http.NewRequest("POST", "/api/v1/auth/login", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.RemoteAddr = ip + ":40000"
+	w := httptest.NewRecorder()
+	s.handler.ServeHTTP(w, req)
+	return w
+}
+
+// getOrganization chama uma rota protegida qualquer e devolve o status HTTP
+func (s *UserE2ESuite) getOrganization(accessToken string) int {
+	req, _ := http.NewRequest("GET", "/api/v1/organizations/"+s.validOrgID.String(), nil)
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	w := httptest.NewRecorder()
+	s.handler.ServeHTTP(w, req)
+	return w.Code
+}
+
+// postJSON envia um POST (com token opcional) e devolve a resposta gravada
+func (s *UserE2ESuite) postJSON(path, accessToken string, payload interface{}) *httptest.ResponseRecorder {
+	return s.doJSON("POST", path, accessToken, payload)
+}
+
+// doJSON envia uma requisição qualquer (com token e corpo opcionais)
+func (s *UserE2ESuite) doJSON(method, path, accessToken string, payload interface{}) *httptest.ResponseRecorder {
+	var body []byte
+	if payload != nil {
+		body, _ = json.Marshal(payload)
+	}
+	req, _ := http.NewRequest(method, path, bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	if accessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+	}
+	w := httptest.NewRecorder()
+	s.handler.ServeHTTP(w, req)
+	return w
+}
+
+// lastEmailToken lê o email mais recente enviado para o endereço e extrai o token do link (reset, verificação)
+func (s *UserE2ESuite) lastEmailToken(email string) string {
+	files, err := filepath.Glob(filepath.Join(s.mailDir, "*_"+email+".eml"))
+	s.Require().NoError(err)
+	s.Require().NotEmpty(files, "nenhum email enviado para %s", email)
+	sort.Strings(files)
+
+	content, err := os.ReadFile(files[len(files)-1])
+	s.Require().NoError(err)
+
+	match := regexp.MustCompile(`token=([^\s]+)`).FindStringSubmatch(string(content))
+	s.Require().Len(match, 2)
+	token, err := url.QueryUnescape(match[1])
+	s.Require().NoError(err)
+	return token
+}
+
+// addBreachedPassword inclui a senha na lista local de vazamentos
+func (s *UserE2ESuite) addBreachedPassword(password string) {
+	prefix, suffix := breached.HashRange(password)
+	f, err := os.OpenFile(filepath.Join(s.breachedDir, prefix+".txt"), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	s.Require().NoError(err)
+	defer f.Close()
+	_, err = f.WriteString(suffix + ":1\n")
+	s.Require().NoError(err)
+}
+
+// setEmailPolicy altera a política de email não verificado da organização base
+func (s *UserE2ESuite) setEmailPolicy(policy string) {
+	_, err := s.db.Exec(`UPDATE organizations SET settings = jsonb_build_object('unverified_email_policy', $1::text) WHERE id = $2`, policy, s.validOrgID)
+	s.Require().NoError(err)
 }
 
 func TestUserE2ESuite(t *testing.T) {